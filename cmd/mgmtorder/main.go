@@ -1,18 +1,48 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 
-	"github.com/moledoc/orderly/internal/repository/local"
+	"github.com/moledoc/orderly/internal/repository/storage"
 	"github.com/moledoc/orderly/internal/router"
 	"github.com/moledoc/orderly/internal/service/mgmtorder"
+	"github.com/moledoc/orderly/internal/service/trash"
 )
 
 func main() {
+	cfg := storage.RegisterFlags(flag.CommandLine)
+	trashCfg := trash.RegisterFlags(flag.CommandLine)
+	orderCfg := mgmtorder.RegisterFlags(flag.CommandLine)
+	flag.Parse()
 
-	router.RouteOrder(mgmtorder.NewServiceMgmtOrder(local.NewLocalRepositoryOrder()))
+	repo, err := cfg.NewRepositoryOrder()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[ERROR]: %s\n", err)
+		os.Exit(1)
+	}
 
-	fmt.Println("Server running on http://localhost:8080")
-	fmt.Println(http.ListenAndServe(":8080", nil))
+	svc := mgmtorder.NewServiceMgmtOrder(repo, nil, orderCfg) // NOTE: runs without the user service, users referenced by orders aren't checked
+	router.RouteOrder(svc)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go trashCfg.Run(ctx, svc, nil)
+
+	go func() {
+		fmt.Println("Server running on http://localhost:8080")
+		fmt.Println(http.ListenAndServe(":8080", nil))
+		stop()
+	}()
+	<-ctx.Done()
+
+	if err := repo.Close(context.Background()); err != nil {
+		fmt.Fprintf(os.Stderr, "[ERROR]: closing repository failed: %s\n", err)
+	}
 }
@@ -1,18 +1,47 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 
-	"github.com/moledoc/orderly/internal/repository/local"
+	"github.com/moledoc/orderly/internal/repository/storage"
 	"github.com/moledoc/orderly/internal/router"
 	"github.com/moledoc/orderly/internal/service/mgmtuser"
+	"github.com/moledoc/orderly/internal/service/trash"
 )
 
 func main() {
+	cfg := storage.RegisterFlags(flag.CommandLine)
+	trashCfg := trash.RegisterFlags(flag.CommandLine)
+	flag.Parse()
 
-	router.RouteUser(mgmtuser.NewServiceMgmtUser(local.NewLocalRepositoryUser()))
+	repo, err := cfg.NewRepositoryUser()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[ERROR]: %s\n", err)
+		os.Exit(1)
+	}
 
-	fmt.Println("Server running on http://localhost:8080")
-	fmt.Println(http.ListenAndServe(":8080", nil))
+	svc := mgmtuser.NewServiceMgmtUser(repo, nil) // NOTE: runs without the order service, email changes aren't carried over to orders
+	router.RouteUser(svc)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go trashCfg.Run(ctx, nil, svc)
+
+	go func() {
+		fmt.Println("Server running on http://localhost:8080")
+		fmt.Println(http.ListenAndServe(":8080", nil))
+		stop()
+	}()
+	<-ctx.Done()
+
+	if err := repo.Close(context.Background()); err != nil {
+		fmt.Fprintf(os.Stderr, "[ERROR]: closing repository failed: %s\n", err)
+	}
 }
@@ -2,23 +2,32 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"html/template"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"slices"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/moledoc/orderly/internal/domain/errwrap"
 	"github.com/moledoc/orderly/internal/domain/meta"
 	"github.com/moledoc/orderly/internal/domain/order"
 	"github.com/moledoc/orderly/internal/domain/request"
+	"github.com/moledoc/orderly/internal/domain/response"
 	"github.com/moledoc/orderly/internal/domain/user"
-	"github.com/moledoc/orderly/internal/repository/local"
+	"github.com/moledoc/orderly/internal/repository/storage"
 	"github.com/moledoc/orderly/internal/router"
+	"github.com/moledoc/orderly/internal/service/admin"
+	"github.com/moledoc/orderly/internal/service/jira"
 	"github.com/moledoc/orderly/internal/service/mgmtorder"
 	"github.com/moledoc/orderly/internal/service/mgmtuser"
+	"github.com/moledoc/orderly/internal/service/trash"
 )
 
 // MAYBE: cache result
@@ -31,6 +40,10 @@ func formatToDate(t time.Time) string {
 	return t.Format("2006-01-02")
 }
 
+func formatToDateTime(t time.Time) string {
+	return t.Format("2006-01-02 15:04")
+}
+
 func firstLine(lines string) string {
 	elems := strings.Split(lines, "\n")
 	if len(elems) == 0 {
@@ -56,9 +69,10 @@ func somethingWentWrong(w http.ResponseWriter, err errwrap.Error) {
 
 var (
 	templFuncMap = template.FuncMap{
-		"formatToDate": formatToDate,
-		"firstLine":    firstLine,
-		"States":       order.ListStates,
+		"formatToDate":     formatToDate,
+		"formatToDateTime": formatToDateTime,
+		"firstLine":        firstLine,
+		"States":           order.ListStates,
 	}
 
 	templOrders = template.Must(template.New("orders").Funcs(templFuncMap).ParseFiles(
@@ -82,6 +96,11 @@ var (
 		"./templates/footer.templ.html",
 		"./templates/user.templ.html",
 	))
+	templOrgChart = template.Must(template.New("org_chart").Funcs(templFuncMap).ParseFiles(
+		"./templates/header.templ.html",
+		"./templates/footer.templ.html",
+		"./templates/org_chart.templ.html",
+	))
 	templNewUser = template.Must(template.New("new_user").Funcs(templFuncMap).ParseFiles(
 		"./templates/header.templ.html",
 		"./templates/footer.templ.html",
@@ -96,6 +115,11 @@ var (
 	templNewTask = template.Must(template.New("new_task").Funcs(templFuncMap).ParseFiles(
 		"./templates/new_task.templ.html",
 	))
+	templSearch = template.Must(template.New("search").Funcs(templFuncMap).ParseFiles(
+		"./templates/header.templ.html",
+		"./templates/footer.templ.html",
+		"./templates/search.templ.html",
+	))
 	templHome = template.Must(template.New("home").Funcs(templFuncMap).ParseFiles(
 		"./templates/header.templ.html",
 		"./templates/footer.templ.html",
@@ -187,6 +211,7 @@ func serveOrder(w http.ResponseWriter, r *http.Request) {
 	var orders []*order.Order
 	var parentOrder *order.Order
 	var emails []user.Email
+	var history []*order.Diff
 	cherr := make(chan errwrap.Error, 5)
 	defer close(cherr)
 
@@ -244,6 +269,19 @@ func serveOrder(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 	}()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		respGetOrderDiff, errr := mgmtorder.GetServiceMgmtOrder().GetOrderDiff(context.Background(), &request.GetOrderDiffRequest{
+			ID: respGetOrderByID.GetOrder().GetID(),
+		})
+		if errr != nil {
+			cherr <- errr
+		} else {
+			history = respGetOrderDiff.GetDiffs()
+			slices.Reverse(history) // NOTE: latest change first
+		}
+	}()
 	wg.Wait()
 
 	type extendedOrder struct {
@@ -252,6 +290,7 @@ func serveOrder(w http.ResponseWriter, r *http.Request) {
 		Orders          []*order.Order
 		ParentOrder     *order.Order
 		Emails          []user.Email
+		History         []*order.Diff
 	}
 
 	eo := &extendedOrder{
@@ -260,6 +299,7 @@ func serveOrder(w http.ResponseWriter, r *http.Request) {
 		AccountableUser: accountable,
 		ParentOrder:     parentOrder,
 		Emails:          emails,
+		History:         history,
 	}
 
 	w.Header().Set("Content-Type", "text/html")
@@ -366,14 +406,23 @@ func serveUser(w http.ResponseWriter, r *http.Request) {
 		SubOrdinates   []*user.User
 		AccountableFor []*order.Order
 		Emails         []user.Email
+		CalendarURL    string
+		CalendarSubURL template.URL // NOTE: webcal scheme opens the subscription in the calendar app, it's not an URL html/template lets through
 	}
 
+	calendarPath := fmt.Sprintf("%s/calendar/%s.ics", r.Host, respGetUserByID.GetUser().GetID())
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
 	ue := &userExtended{
 		User:           respGetUserByID.GetUser(),
 		SupervisorID:   supervisor.GetID(),
 		SubOrdinates:   subordinates,
 		AccountableFor: orders,
 		Emails:         emails,
+		CalendarURL:    fmt.Sprintf("%s://%s", scheme, calendarPath),
+		CalendarSubURL: template.URL(fmt.Sprintf("webcal://%s", calendarPath)),
 	}
 
 	w.Header().Set("Content-Type", "text/html")
@@ -383,6 +432,52 @@ func serveUser(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func serveOrgChart(w http.ResponseWriter, _ *http.Request) {
+	root := mgmtuser.GetServiceMgmtUser().GetRootUser(context.Background())
+	respGetSubordinates, errr := mgmtuser.GetServiceMgmtUser().GetUserSubordinates(context.Background(), &request.GetUserSubordinatesRequest{
+		ID: root.GetID(),
+	})
+	if errr != nil {
+		somethingWentWrong(w, errr)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	err := templOrgChart.Execute(w, &user.Subordinate{
+		User:         root,
+		Subordinates: respGetSubordinates.GetSubordinates(),
+	})
+	if err != nil {
+		log.Printf("[ERROR]: executing org_chart html tmpl failed: %s\n", err)
+	}
+}
+
+func serveSearch(w http.ResponseWriter, r *http.Request) {
+	type searchPage struct {
+		Query string
+		Hits  []*order.SearchHit
+	}
+	page := &searchPage{
+		Query: r.URL.Query().Get("q"),
+	}
+	if len(order.Tokenize(page.Query)) > 0 {
+		resp, errr := mgmtorder.GetServiceMgmtOrder().Search(context.Background(), &request.SearchRequest{
+			Query: page.Query,
+		})
+		if errr != nil {
+			somethingWentWrong(w, errr)
+			return
+		}
+		page.Hits = resp.GetHits()
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	err := templSearch.Execute(w, page)
+	if err != nil {
+		log.Printf("[ERROR]: executing search html tmpl failed: %s\n", err)
+	}
+}
+
 func serveNewUser(w http.ResponseWriter, _ *http.Request) {
 	var wg sync.WaitGroup
 	var emails []user.Email
@@ -546,12 +641,107 @@ func serveNewOrder(w http.ResponseWriter, _ *http.Request) {
 	}
 }
 
+// importJira imports the issues of a Jira export into the storage, eg `orderly import-jira -storage file issues.json`.
+func importJira(args []string) int {
+	fs := flag.NewFlagSet("import-jira", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: %s import-jira [flags] <export.json|export.csv>\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	cfg := storage.RegisterFlags(fs)
+	orderCfg := mgmtorder.RegisterFlags(fs)
+	jiraCfg := jira.RegisterFlags(fs)
+	reportFormat := fs.String("report", "text", "format of the mapping report: text or json")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	if *reportFormat != "text" && *reportFormat != "json" {
+		log.Printf("[ERROR]: unknown report format '%s'\n", *reportFormat)
+		return 2
+	}
+	if cfg.Kind == storage.KindLocal {
+		log.Printf("[WARNING]: storage '%s' is in-memory, imported orders are lost on exit\n", cfg.Kind)
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		log.Printf("[ERROR]: %s\n", err)
+		return 1
+	}
+	issues, err := jira.Parse(f)
+	f.Close()
+	if err != nil {
+		log.Printf("[ERROR]: %s\n", err)
+		return 1
+	}
+
+	orderRepo, errr := cfg.NewRepositoryOrder()
+	if errr != nil {
+		log.Printf("[ERROR]: %s\n", errr)
+		return 1
+	}
+	defer orderRepo.Close(context.Background())
+	userRepo, errr := cfg.NewRepositoryUser()
+	if errr != nil {
+		log.Printf("[ERROR]: %s\n", errr)
+		return 1
+	}
+	defer userRepo.Close(context.Background())
+
+	var mgmtOrderSvc mgmtorder.ServiceMgmtOrderAPI
+	mgmtUserSvc := mgmtuser.NewServiceMgmtUser(userRepo, mgmtuser.EmailPropagatorFunc(func(ctx context.Context, req *request.ChangeUserEmailRequest) (*response.ChangeUserEmailResponse, errwrap.Error) {
+		return mgmtOrderSvc.ChangeUserEmail(ctx, req)
+	}))
+	mgmtOrderSvc = mgmtorder.NewServiceMgmtOrder(orderRepo, mgmtUserSvc, orderCfg)
+
+	report, errr := jiraCfg.Import(context.Background(), issues, mgmtOrderSvc, mgmtUserSvc)
+	if *reportFormat == "json" {
+		err = report.WriteJSON(os.Stdout)
+	} else {
+		err = report.WriteText(os.Stdout)
+	}
+	if err != nil {
+		log.Printf("[ERROR]: writing report failed: %s\n", err)
+	}
+	if errr != nil {
+		log.Printf("[ERROR]: %s\n", errr)
+		return 1
+	}
+	return 0
+}
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "import-jira" {
+		os.Exit(importJira(os.Args[2:]))
+	}
 
-	router.Route(&router.Service{
-		MgmtOrder: mgmtorder.NewServiceMgmtOrder(local.NewLocalRepositoryOrder()),
-		MgmtUser:  mgmtuser.NewServiceMgmtUser(local.NewLocalRepositoryUser()),
-	})
+	cfg := storage.RegisterFlags(flag.CommandLine)
+	trashCfg := trash.RegisterFlags(flag.CommandLine)
+	orderCfg := mgmtorder.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+
+	orderRepo, errr := cfg.NewRepositoryOrder()
+	if errr != nil {
+		log.Fatalf("[ERROR]: %s\n", errr)
+	}
+	userRepo, errr := cfg.NewRepositoryUser()
+	if errr != nil {
+		log.Fatalf("[ERROR]: %s\n", errr)
+	}
+
+	var mgmtOrderSvc mgmtorder.ServiceMgmtOrderAPI
+	mgmtUserSvc := mgmtuser.NewServiceMgmtUser(userRepo, mgmtuser.EmailPropagatorFunc(func(ctx context.Context, req *request.ChangeUserEmailRequest) (*response.ChangeUserEmailResponse, errwrap.Error) {
+		return mgmtOrderSvc.ChangeUserEmail(ctx, req)
+	}))
+	mgmtOrderSvc = mgmtorder.NewServiceMgmtOrder(orderRepo, mgmtUserSvc, orderCfg)
+	svcs := &router.Service{
+		MgmtOrder: mgmtOrderSvc,
+		MgmtUser:  mgmtUserSvc,
+		Admin:     admin.NewServiceAdmin(orderRepo, userRepo, mgmtOrderSvc.GetRootOrder(context.Background()), mgmtUserSvc.GetRootUser(context.Background())),
+	}
+	router.Route(svcs)
 
 	// http.HandleFunc("GET /", serveLogin) // NOTE: login
 
@@ -566,7 +756,26 @@ func main() {
 	http.HandleFunc("GET /users", serveUsers)
 	http.HandleFunc("GET /user/{id}", serveUser)
 	http.HandleFunc("GET /user/new", serveNewUser)
+	http.HandleFunc("GET /users/chart", serveOrgChart)
+
+	http.HandleFunc("GET /search", serveSearch)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go trashCfg.Run(ctx, svcs.MgmtOrder, svcs.MgmtUser)
 
-	fmt.Println("Server running on http://localhost:8080")
-	http.ListenAndServe(":8080", nil)
+	go func() {
+		fmt.Println("Server running on http://localhost:8080")
+		fmt.Println(http.ListenAndServe(":8080", nil))
+		stop()
+	}()
+	<-ctx.Done()
+
+	if err := orderRepo.Close(context.Background()); err != nil {
+		log.Printf("[ERROR]: closing order repository failed: %s\n", err)
+	}
+	if err := userRepo.Close(context.Background()); err != nil {
+		log.Printf("[ERROR]: closing user repository failed: %s\n", err)
+	}
 }
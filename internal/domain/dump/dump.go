@@ -0,0 +1,27 @@
+package dump
+
+import (
+	"time"
+
+	"github.com/moledoc/orderly/internal/domain/order"
+	"github.com/moledoc/orderly/internal/domain/user"
+)
+
+// Version is the version of the document format, documents of other versions aren't imported.
+const Version uint = 1
+
+type Mode string
+
+const (
+	ModeMerge   Mode = "merge"   // NOTE: objects already stored are kept, the document's copies of them are skipped
+	ModeReplace Mode = "replace" // NOTE: every object but the root order and root user is removed before importing
+)
+
+// Document holds the whole organisation: every user and order, the ones in trash included.
+// NOTE: objects are kept as stored, ids and meta included; version history isn't part of the document
+type Document struct {
+	Version  uint           `json:"version"`
+	Exported time.Time      `json:"exported,omitempty"`
+	Users    []*user.User   `json:"users,omitempty"`
+	Orders   []*order.Order `json:"orders,omitempty"`
+}
@@ -0,0 +1,36 @@
+package dump
+
+import (
+	"time"
+
+	"github.com/moledoc/orderly/internal/domain/order"
+	"github.com/moledoc/orderly/internal/domain/user"
+)
+
+func (d *Document) GetVersion() uint {
+	if d == nil {
+		return 0
+	}
+	return d.Version
+}
+
+func (d *Document) GetExported() time.Time {
+	if d == nil {
+		return time.Time{}
+	}
+	return d.Exported
+}
+
+func (d *Document) GetUsers() []*user.User {
+	if d == nil {
+		return nil
+	}
+	return d.Users
+}
+
+func (d *Document) GetOrders() []*order.Order {
+	if d == nil {
+		return nil
+	}
+	return d.Orders
+}
@@ -3,6 +3,7 @@ package errwrap
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 )
 
 type Error interface {
@@ -12,12 +13,20 @@ type Error interface {
 	GetStatusMessage() string
 	GetTraceID() string
 	SetTraceID(traceID string)
+	GetFields() []FieldError
 }
 
-type Err struct {
-	Code    int    `json:"code,omitempty"`
+// FieldError tells which field of the request was rejected and why.
+type FieldError struct {
+	Field   string `json:"field,omitempty"`
 	Message string `json:"message,omitempty"`
-	TraceID string `json:"trace_id,omitempty"`
+}
+
+type Err struct {
+	Code    int          `json:"code,omitempty"`
+	Message string       `json:"message,omitempty"`
+	TraceID string       `json:"trace_id,omitempty"`
+	Fields  []FieldError `json:"fields,omitempty"`
 }
 
 func (e *Err) Error() string {
@@ -66,9 +75,28 @@ func (e *Err) SetTraceID(traceID string) {
 	e.TraceID = traceID
 }
 
+func (e *Err) GetFields() []FieldError {
+	if e == nil {
+		return nil
+	}
+	return e.Fields
+}
+
 func NewError(code uint, format string, a ...any) Error {
 	return &Err{
 		Code:    int(code),
 		Message: fmt.Sprintf(format, a...),
 	}
 }
+
+func NewFieldError(code uint, fields ...FieldError) Error {
+	messages := make([]string, 0, len(fields))
+	for _, field := range fields {
+		messages = append(messages, fmt.Sprintf("invalid %s: %s", field.Field, field.Message))
+	}
+	return &Err{
+		Code:    int(code),
+		Message: strings.Join(messages, "; "),
+		Fields:  fields,
+	}
+}
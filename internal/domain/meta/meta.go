@@ -17,9 +17,11 @@ func NewID() ID {
 }
 
 type Meta struct {
-	Version uint      `json:"version,omitempty"`
-	Created time.Time `json:"created,omitempty"`
-	Updated time.Time `json:"updated,omitempty"`
+	Version   uint       `json:"version,omitempty"`
+	Created   time.Time  `json:"created,omitempty"`
+	Updated   time.Time  `json:"updated,omitempty"`
+	UpdatedBy string     `json:"updated_by,omitempty"` // NOTE: email of the user who made the latest change, if known
+	Deleted   *time.Time `json:"deleted,omitempty"`    // NOTE: set while the object is in trash
 }
 
 func (m *Meta) VersionIncr() {
@@ -34,9 +36,14 @@ func (m *Meta) Clone() *Meta {
 		return nil
 	}
 	clone := Meta{
-		Version: m.Version,
-		Created: m.Created,
-		Updated: m.Updated,
+		Version:   m.Version,
+		Created:   m.Created,
+		Updated:   m.Updated,
+		UpdatedBy: m.UpdatedBy,
+	}
+	if m.Deleted != nil {
+		deleted := *m.Deleted
+		clone.Deleted = &deleted
 	}
 	return &clone
 }
@@ -24,3 +24,35 @@ func (m *Meta) GetUpdated() time.Time {
 	}
 	return m.Updated
 }
+
+func (m *Meta) GetUpdatedBy() string {
+	if m == nil {
+		return ""
+	}
+	return m.UpdatedBy
+}
+
+func (m *Meta) GetDeleted() time.Time {
+	if m == nil || m.Deleted == nil {
+		return time.Time{}
+	}
+	return *m.Deleted
+}
+
+func (m *Meta) IsDeleted() bool {
+	return !m.GetDeleted().IsZero()
+}
+
+func (p *Page) GetTotal() int {
+	if p == nil {
+		return 0
+	}
+	return p.Total
+}
+
+func (p *Page) GetNextCursor() string {
+	if p == nil {
+		return ""
+	}
+	return p.NextCursor
+}
@@ -24,3 +24,22 @@ func (m *Meta) SetUpdated(updated time.Time) {
 	}
 	m.Updated = updated
 }
+
+func (m *Meta) SetUpdatedBy(updatedBy string) {
+	if m == nil {
+		return
+	}
+	m.UpdatedBy = updatedBy
+}
+
+// SetDeleted moves the object to trash, zero time restores it.
+func (m *Meta) SetDeleted(deleted time.Time) {
+	if m == nil {
+		return
+	}
+	if deleted.IsZero() {
+		m.Deleted = nil
+		return
+	}
+	m.Deleted = &deleted
+}
@@ -0,0 +1,93 @@
+package meta
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sort is the order objects are listed in, the field prefixed with '-' lists in descending order.
+// NOTE: empty sort lists by created, ties are broken by id
+type Sort string
+
+const (
+	SortCreated  Sort = "created"
+	SortUpdated  Sort = "updated"
+	SortDeadline Sort = "deadline"
+	SortState    Sort = "state"
+)
+
+func (s Sort) Field() Sort {
+	field := Sort(strings.TrimPrefix(string(s), "-"))
+	if len(field) == 0 {
+		return SortCreated
+	}
+	return field
+}
+
+func (s Sort) IsDesc() bool {
+	return strings.HasPrefix(string(s), "-")
+}
+
+// Normalized spells out the default field, so equal sorts compare equal.
+func (s Sort) Normalized() Sort {
+	if s.IsDesc() {
+		return "-" + s.Field()
+	}
+	return s.Field()
+}
+
+// TimeKey is the sort key of a time, zero time sorts first.
+func TimeKey(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+// Page describes a listed page: Total counts every match regardless of the limit.
+type Page struct {
+	Total      int    `json:"total"`
+	NextCursor string `json:"next_cursor,omitempty"` // NOTE: empty on the last page
+}
+
+// Cursor points at the last listed object by its sort key and id, the next page continues after it.
+type Cursor struct {
+	Sort Sort
+	Key  int64
+	ID   ID
+}
+
+func (c *Cursor) Encode() string {
+	return base64.RawURLEncoding.EncodeToString(fmt.Appendf(nil, "%s|%d|%s", c.Sort, c.Key, c.ID))
+}
+
+// DecodeCursor decodes the cursor, which has to be made with the same sort.
+func DecodeCursor(cursor string, sort Sort) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("malformed cursor")
+	}
+	parts := strings.SplitN(string(raw), "|", 3)
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed cursor")
+	}
+	key, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("malformed cursor")
+	}
+	if Sort(parts[0]) != sort.Normalized() {
+		return nil, fmt.Errorf("cursor is for sort '%s'", parts[0])
+	}
+	return &Cursor{Sort: sort.Normalized(), Key: key, ID: ID(parts[2])}, nil
+}
+
+// IsAfter reports whether the object with key and id is listed after the cursor.
+func (c *Cursor) IsAfter(key int64, id ID) bool {
+	if c.Sort.IsDesc() {
+		return key < c.Key || key == c.Key && id > c.ID
+	}
+	return key > c.Key || key == c.Key && id > c.ID
+}
@@ -2,6 +2,9 @@ package order
 
 import (
 	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/moledoc/orderly/internal/domain/meta"
@@ -59,6 +62,19 @@ func (s *State) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// ParseState parses the state from its name or number, case-insensitively.
+func ParseState(str string) (State, error) {
+	if nr, err := strconv.Atoi(str); err == nil && NotStarted <= State(nr) && State(nr) <= Completed {
+		return State(nr), nil
+	}
+	for s := NotStarted; s <= Completed; s++ {
+		if strings.EqualFold(str, s.String()) {
+			return s, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown state '%s'", str)
+}
+
 func (s *State) MarshalJSON() ([]byte, error) {
 	return json.Marshal(s.String())
 }
@@ -103,12 +119,21 @@ type SitRep struct {
 	Issues    string `json:"issues,omitempty"`
 }
 
+// RollupPolicy opts the order into keeping its state in line with its delegated tasks.
+type RollupPolicy struct {
+	IssuesOnBlocked   bool `json:"issues_on_blocked,omitempty"`  // NOTE: order is 'Having Issues' while any delegated task is 'Blocked'
+	CompleteDelegated bool `json:"complete_delegated,omitempty"` // NOTE: order can be 'Completed' only once every delegated task is
+
+	PreRollupState State `json:"pre_rollup_state,omitempty"` // NOTE: state the rollup made 'Having Issues', restored once no delegated task is 'Blocked'; set by the service
+}
+
 type Order struct {
-	Task           *Task      `json:"task,omitempty"`
-	DelegatedTasks []*Task    `json:"delegated_tasks,omitempty"`
-	ParentOrderID  meta.ID    `json:"parent_order_id,omitempty"`
-	SitReps        []*SitRep  `json:"sitreps,omitempty"`
-	Meta           *meta.Meta `json:"meta,omitempty"`
+	Task           *Task         `json:"task,omitempty"`
+	DelegatedTasks []*Task       `json:"delegated_tasks,omitempty"`
+	ParentOrderID  meta.ID       `json:"parent_order_id,omitempty"`
+	SitReps        []*SitRep     `json:"sitreps,omitempty"`
+	RollupPolicy   *RollupPolicy `json:"rollup_policy,omitempty"`
+	Meta           *meta.Meta    `json:"meta,omitempty"`
 }
 
 func Empty() *Order {
@@ -130,31 +155,81 @@ func (o *Order) Clone() *Order {
 		ParentOrderID:  o.GetParentOrderID(),
 		DelegatedTasks: make([]*Task, len(o.GetDelegatedTasks())),
 		SitReps:        make([]*SitRep, len(o.GetSitReps())),
+		RollupPolicy:   o.GetRollupPolicy().Clone(),
 		Meta:           o.GetMeta().Clone(),
 	}
 
 	for i, delegatedTask := range o.GetDelegatedTasks() {
-		clone.DelegatedTasks[i] = &Task{
-			ID:          delegatedTask.GetID(),
-			State:       utils.Ptr(delegatedTask.GetState()),
-			Accountable: delegatedTask.GetAccountable(),
-			Objective:   delegatedTask.GetObjective(),
-			Deadline:    delegatedTask.GetDeadline(),
-		}
+		clone.DelegatedTasks[i] = delegatedTask.Clone()
 	}
 
 	for i, sitrep := range o.GetSitReps() {
-		clone.SitReps[i] = &SitRep{
-			ID: sitrep.GetID(),
+		clone.SitReps[i] = sitrep.Clone()
+	}
+	return &clone
+}
 
-			DateTime: sitrep.GetDateTime(),
-			By:       sitrep.GetBy(),
+// SortKey is the order's key when listed by the sort field.
+func (o *Order) SortKey(field meta.Sort) int64 {
+	switch field {
+	case meta.SortUpdated:
+		return meta.TimeKey(o.GetMeta().GetUpdated())
+	case meta.SortDeadline:
+		return meta.TimeKey(o.GetTask().GetDeadline())
+	case meta.SortState:
+		return int64(o.GetTask().GetState())
+	default:
+		return meta.TimeKey(o.GetMeta().GetCreated())
+	}
+}
 
-			Situation: sitrep.GetSituation(),
-			Actions:   sitrep.GetActions(),
-			TODO:      sitrep.GetTODO(),
-			Issues:    sitrep.GetIssues(),
-		}
+func (tt *Task) Clone() *Task {
+	if tt == nil {
+		return nil
 	}
-	return &clone
+	return &Task{
+		ID:          tt.GetID(),
+		State:       utils.Ptr(tt.GetState()),
+		Accountable: tt.GetAccountable(),
+		Objective:   tt.GetObjective(),
+		Deadline:    tt.GetDeadline(),
+	}
+}
+
+func (sr *SitRep) Clone() *SitRep {
+	if sr == nil {
+		return nil
+	}
+	return &SitRep{
+		ID: sr.GetID(),
+
+		DateTime: sr.GetDateTime(),
+		By:       sr.GetBy(),
+
+		Situation: sr.GetSituation(),
+		Actions:   sr.GetActions(),
+		TODO:      sr.GetTODO(),
+		Issues:    sr.GetIssues(),
+	}
+}
+
+func (rp *RollupPolicy) Clone() *RollupPolicy {
+	if rp == nil {
+		return nil
+	}
+	return &RollupPolicy{
+		IssuesOnBlocked:   rp.GetIssuesOnBlocked(),
+		CompleteDelegated: rp.GetCompleteDelegated(),
+		PreRollupState:    rp.GetPreRollupState(),
+	}
+}
+
+// IsEnabled reports whether any of the policy's rules is turned on.
+func (rp *RollupPolicy) IsEnabled() bool {
+	return rp.GetIssuesOnBlocked() || rp.GetCompleteDelegated()
+}
+
+// Equal compares the policies' rules.
+func (rp *RollupPolicy) Equal(other *RollupPolicy) bool {
+	return rp.GetIssuesOnBlocked() == other.GetIssuesOnBlocked() && rp.GetCompleteDelegated() == other.GetCompleteDelegated()
 }
@@ -0,0 +1,89 @@
+package order
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	CalendarContentType = "text/calendar; charset=utf-8"
+	calendarProdID      = "-//orderly//deadlines//EN"
+	calendarUIDDomain   = "orderly"
+	calendarLineOctets  = 75
+)
+
+// escapeCalendarText escapes a TEXT value, see RFC 5545 section 3.3.11.
+func escapeCalendarText(text string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		`;`, `\;`,
+		`,`, `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	).Replace(text)
+}
+
+// foldCalendarLine splits the content line into lines of at most 75 octets, see RFC 5545 section 3.1.
+// NOTE: multi-octet characters aren't split between lines
+func foldCalendarLine(b *strings.Builder, line string) {
+	limit := calendarLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = calendarLineOctets - 1 // NOTE: continuation lines start with a space
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+func formatCalendarTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// CalendarUID is the stable UID of the task's event.
+func CalendarUID(id string) string {
+	return fmt.Sprintf("%s@%s", id, calendarUIDDomain)
+}
+
+// Calendar writes the deadlines of the tasks as an iCalendar feed, one event per task, see RFC 5545.
+// NOTE: tasks without a deadline have no event
+func Calendar(name string, tasks []*Task, now time.Time) string {
+	var b strings.Builder
+	line := func(name string, value string) {
+		foldCalendarLine(&b, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", calendarProdID)
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	line("X-WR-CALNAME", escapeCalendarText(name))
+	for _, task := range tasks {
+		if task.GetDeadline().IsZero() {
+			continue
+		}
+		state := task.GetState().String()
+
+		line("BEGIN", "VEVENT")
+		line("UID", CalendarUID(string(task.GetID())))
+		line("DTSTAMP", formatCalendarTime(now))
+		line("DTSTART", formatCalendarTime(task.GetDeadline()))
+		line("SUMMARY", escapeCalendarText(fmt.Sprintf("%s [%s]", firstLine(task.GetObjective()), state)))
+		line("DESCRIPTION", escapeCalendarText(fmt.Sprintf("%s\n\nState: %s", strings.TrimSpace(task.GetObjective()), state)))
+		line("CATEGORIES", escapeCalendarText(state))
+		line("STATUS", "CONFIRMED")
+		line("TRANSP", "TRANSPARENT")
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+	return b.String()
+}
@@ -0,0 +1,35 @@
+package order
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/moledoc/orderly/internal/domain/meta"
+)
+
+// DeadlineConflict is an order due after an order it's delegated from.
+type DeadlineConflict struct {
+	OrderID          meta.ID   `json:"order_id"`
+	Deadline         time.Time `json:"deadline"`
+	AncestorID       meta.ID   `json:"ancestor_id"`
+	AncestorDeadline time.Time `json:"ancestor_deadline"`
+}
+
+// NewDeadlineConflict returns the conflict when the task is due after its ancestor, nil otherwise.
+// NOTE: tasks without a deadline never conflict
+func NewDeadlineConflict(task *Task, ancestor *Task) *DeadlineConflict {
+	if task.GetDeadline().IsZero() || ancestor.GetDeadline().IsZero() || !task.GetDeadline().After(ancestor.GetDeadline()) {
+		return nil
+	}
+	return &DeadlineConflict{
+		OrderID:          task.GetID(),
+		Deadline:         task.GetDeadline(),
+		AncestorID:       ancestor.GetID(),
+		AncestorDeadline: ancestor.GetDeadline(),
+	}
+}
+
+func (dc *DeadlineConflict) String() string {
+	return fmt.Sprintf("order '%s' is due %s, after order '%s' it's delegated from, due %s",
+		dc.GetOrderID(), dc.GetDeadline().Format(time.RFC3339), dc.GetAncestorID(), dc.GetAncestorDeadline().Format(time.RFC3339))
+}
@@ -0,0 +1,133 @@
+package order
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/moledoc/orderly/internal/domain/meta"
+)
+
+type ChangeKind string
+
+const (
+	FieldAdded   ChangeKind = "added"
+	FieldRemoved ChangeKind = "removed"
+	FieldChanged ChangeKind = "changed"
+)
+
+type FieldChange struct {
+	Field string     `json:"field"` // NOTE: path to the field, eg 'task.objective', 'delegated_tasks[<id>]', 'sitreps[<id>].situation'
+	Kind  ChangeKind `json:"kind"`
+	Old   string     `json:"old,omitempty"`
+	New   string     `json:"new,omitempty"`
+}
+
+// Diff holds the field level changes that turned version From into version To.
+type Diff struct {
+	From      uint           `json:"from"`
+	To        uint           `json:"to"`
+	Updated   time.Time      `json:"updated,omitempty"`
+	UpdatedBy string         `json:"updated_by,omitempty"`
+	Changes   []*FieldChange `json:"changes,omitempty"`
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func diffField(changes []*FieldChange, field string, old string, new string) []*FieldChange {
+	if old == new {
+		return changes
+	}
+	return append(changes, &FieldChange{
+		Field: field,
+		Kind:  FieldChanged,
+		Old:   old,
+		New:   new,
+	})
+}
+
+func diffTask(changes []*FieldChange, prefix string, old *Task, new *Task) []*FieldChange {
+	changes = diffField(changes, prefix+".state", old.GetState().String(), new.GetState().String())
+	changes = diffField(changes, prefix+".accountable", string(old.GetAccountable()), string(new.GetAccountable()))
+	changes = diffField(changes, prefix+".objective", old.GetObjective(), new.GetObjective())
+	changes = diffField(changes, prefix+".deadline", formatTime(old.GetDeadline()), formatTime(new.GetDeadline()))
+	return changes
+}
+
+func diffSitRep(changes []*FieldChange, prefix string, old *SitRep, new *SitRep) []*FieldChange {
+	changes = diffField(changes, prefix+".datetime", formatTime(old.GetDateTime()), formatTime(new.GetDateTime()))
+	changes = diffField(changes, prefix+".by", string(old.GetBy()), string(new.GetBy()))
+	changes = diffField(changes, prefix+".situation", old.GetSituation(), new.GetSituation())
+	changes = diffField(changes, prefix+".actions", old.GetActions(), new.GetActions())
+	changes = diffField(changes, prefix+".todo", old.GetTODO(), new.GetTODO())
+	changes = diffField(changes, prefix+".issues", old.GetIssues(), new.GetIssues())
+	return changes
+}
+
+// NewDiff compares two versions of the same order.
+// NOTE: added and removed delegated tasks and sitreps are reported by their objective and situation respectively
+func NewDiff(from *Order, to *Order) *Diff {
+	var changes []*FieldChange
+
+	changes = diffField(changes, "parent_order_id", string(from.GetParentOrderID()), string(to.GetParentOrderID()))
+	changes = diffTask(changes, "task", from.GetTask(), to.GetTask())
+	changes = diffField(changes, "rollup_policy.issues_on_blocked", strconv.FormatBool(from.GetRollupPolicy().GetIssuesOnBlocked()), strconv.FormatBool(to.GetRollupPolicy().GetIssuesOnBlocked()))
+	changes = diffField(changes, "rollup_policy.complete_delegated", strconv.FormatBool(from.GetRollupPolicy().GetCompleteDelegated()), strconv.FormatBool(to.GetRollupPolicy().GetCompleteDelegated()))
+
+	fromDelegated := make(map[meta.ID]*Task)
+	for _, delegated := range from.GetDelegatedTasks() {
+		fromDelegated[delegated.GetID()] = delegated
+	}
+	toDelegated := make(map[meta.ID]*Task)
+	for _, delegated := range to.GetDelegatedTasks() {
+		toDelegated[delegated.GetID()] = delegated
+		field := fmt.Sprintf("delegated_tasks[%v]", delegated.GetID())
+		old, ok := fromDelegated[delegated.GetID()]
+		if !ok {
+			changes = append(changes, &FieldChange{Field: field, Kind: FieldAdded, New: delegated.GetObjective()})
+			continue
+		}
+		changes = diffTask(changes, field, old, delegated)
+	}
+	for _, delegated := range from.GetDelegatedTasks() {
+		if _, ok := toDelegated[delegated.GetID()]; !ok {
+			field := fmt.Sprintf("delegated_tasks[%v]", delegated.GetID())
+			changes = append(changes, &FieldChange{Field: field, Kind: FieldRemoved, Old: delegated.GetObjective()})
+		}
+	}
+
+	fromSitReps := make(map[meta.ID]*SitRep)
+	for _, sitrep := range from.GetSitReps() {
+		fromSitReps[sitrep.GetID()] = sitrep
+	}
+	toSitReps := make(map[meta.ID]*SitRep)
+	for _, sitrep := range to.GetSitReps() {
+		toSitReps[sitrep.GetID()] = sitrep
+		field := fmt.Sprintf("sitreps[%v]", sitrep.GetID())
+		old, ok := fromSitReps[sitrep.GetID()]
+		if !ok {
+			changes = append(changes, &FieldChange{Field: field, Kind: FieldAdded, New: sitrep.GetSituation()})
+			continue
+		}
+		changes = diffSitRep(changes, field, old, sitrep)
+	}
+	for _, sitrep := range from.GetSitReps() {
+		if _, ok := toSitReps[sitrep.GetID()]; !ok {
+			field := fmt.Sprintf("sitreps[%v]", sitrep.GetID())
+			changes = append(changes, &FieldChange{Field: field, Kind: FieldRemoved, Old: sitrep.GetSituation()})
+		}
+	}
+
+	return &Diff{
+		From:      from.GetMeta().GetVersion(),
+		To:        to.GetMeta().GetVersion(),
+		Updated:   to.GetMeta().GetUpdated(),
+		UpdatedBy: to.GetMeta().GetUpdatedBy(),
+		Changes:   changes,
+	}
+}
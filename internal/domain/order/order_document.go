@@ -0,0 +1,345 @@
+package order
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/moledoc/orderly/internal/domain/meta"
+	"github.com/moledoc/orderly/internal/domain/user"
+)
+
+type DocumentFormat string
+
+const (
+	DocumentMarkdown DocumentFormat = "md"
+	DocumentHTML     DocumentFormat = "html"
+)
+
+func (f DocumentFormat) ContentType() string {
+	switch f {
+	case DocumentMarkdown:
+		return "text/markdown; charset=utf-8"
+	case DocumentHTML:
+		return "text/html; charset=utf-8"
+	default:
+		return "text/plain; charset=utf-8"
+	}
+}
+
+// Document is an order subtree written up as a five-paragraph order: situation, mission, execution, sustainment, command and signal.
+type Document struct {
+	Title     string
+	OrderID   meta.ID
+	Version   uint
+	Generated time.Time
+
+	Rollup  *Rollup   // NOTE: situation of the delegated tasks
+	SitReps []*SitRep // NOTE: latest first
+
+	Mission   *Task
+	Execution []*DocumentTask
+	Issues    []*DocumentIssue // NOTE: sustainment, issues of the latest sitreps in the subtree
+
+	Accountable string
+	ReportsTo   string // NOTE: accountable of the parent order, empty for the root order
+}
+
+// DocumentTask is a delegated task numbered by its place in the execution paragraph, eg 3.2.1.
+type DocumentTask struct {
+	Number      string
+	Level       int
+	Title       string
+	Accountable string
+	Deadline    time.Time
+	State       string
+	Overdue     bool
+}
+
+type DocumentIssue struct {
+	Number   string // NOTE: number of the task the issue was reported on, empty for the order itself
+	Title    string
+	By       string
+	DateTime time.Time
+	Issues   string
+}
+
+func firstLine(lines string) string {
+	for _, line := range strings.Split(lines, "\n") {
+		if len(strings.TrimSpace(line)) > 0 {
+			return strings.TrimSpace(line)
+		}
+	}
+	return ""
+}
+
+func latestSitRep(sitreps []*SitRep) *SitRep {
+	var latest *SitRep
+	for _, sitrep := range sitreps {
+		if latest == nil || sitrep.GetDateTime().After(latest.GetDateTime()) {
+			latest = sitrep
+		}
+	}
+	return latest
+}
+
+// NewDocument writes up the tree, names are the display names of the users by email.
+// NOTE: parent is nil for the root order, sitreps limits the latest sitreps in the situation paragraph
+func NewDocument(tree *Tree, parent *Order, names map[user.Email]string, sitreps int, now time.Time) *Document {
+	display := func(email user.Email) string {
+		if name, ok := names[email]; ok && len(name) > 0 {
+			return fmt.Sprintf("%s <%s>", name, email)
+		}
+		return string(email)
+	}
+
+	o := tree.GetOrder()
+	doc := &Document{
+		Title:       firstLine(o.GetTask().GetObjective()),
+		OrderID:     o.GetID(),
+		Version:     o.GetMeta().GetVersion(),
+		Generated:   now,
+		Rollup:      tree.GetRollup(),
+		Mission:     o.GetTask(),
+		Accountable: display(o.GetTask().GetAccountable()),
+	}
+	if parent != nil {
+		doc.ReportsTo = display(parent.GetTask().GetAccountable())
+	}
+
+	doc.SitReps = slices.Clone(o.GetSitReps())
+	slices.SortStableFunc(doc.SitReps, func(a, b *SitRep) int {
+		return b.GetDateTime().Compare(a.GetDateTime())
+	})
+	if sitreps >= 0 && len(doc.SitReps) > sitreps {
+		doc.SitReps = doc.SitReps[:sitreps]
+	}
+
+	addIssues := func(number string, o *Order) {
+		latest := latestSitRep(o.GetSitReps())
+		if latest == nil || len(strings.TrimSpace(latest.GetIssues())) == 0 {
+			return
+		}
+		doc.Issues = append(doc.Issues, &DocumentIssue{
+			Number:   number,
+			Title:    firstLine(o.GetTask().GetObjective()),
+			By:       display(latest.GetBy()),
+			DateTime: latest.GetDateTime(),
+			Issues:   latest.GetIssues(),
+		})
+	}
+	addIssues("", o)
+
+	var walk func(tree *Tree, number string, level int)
+	walk = func(tree *Tree, number string, level int) {
+		for i, suborder := range tree.GetSuborders() {
+			so := suborder.GetOrder()
+			n := fmt.Sprintf("%s.%v", number, i+1)
+			doc.Execution = append(doc.Execution, &DocumentTask{
+				Number:      n,
+				Level:       level,
+				Title:       firstLine(so.GetTask().GetObjective()),
+				Accountable: display(so.GetTask().GetAccountable()),
+				Deadline:    so.GetTask().GetDeadline(),
+				State:       so.GetTask().GetState().String(),
+				Overdue:     so.GetTask().IsOverdue(now),
+			})
+			addIssues(n, so)
+			walk(suborder, n, level+1)
+		}
+	}
+	walk(tree, "3", 0)
+	return doc
+}
+
+func formatDocumentTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.UTC().Format("2006-01-02 15:04 UTC")
+}
+
+// mdCell keeps the text on one table cell.
+func mdCell(text string) string {
+	text = strings.ReplaceAll(text, "|", `\|`)
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func (d *Document) Markdown() string {
+	var b strings.Builder
+	field := func(name string, value string) {
+		if len(strings.TrimSpace(value)) > 0 {
+			fmt.Fprintf(&b, "- **%s:** %s\n", name, strings.TrimSpace(value))
+		}
+	}
+
+	fmt.Fprintf(&b, "# ORDER: %s\n\n", d.Title)
+	fmt.Fprintf(&b, "Order %s, version %v. Generated %s.\n\n", d.OrderID, d.Version, formatDocumentTime(d.Generated))
+
+	b.WriteString("## 1. Situation\n\n")
+	r := d.Rollup
+	fmt.Fprintf(&b, "Delegated tasks: %v total, %v completed, %v blocked, %v overdue.\n\n", r.GetTotal(), r.GetCompleted(), r.GetBlocked(), r.GetOverdue())
+	if len(d.SitReps) == 0 {
+		b.WriteString("No sitreps reported.\n\n")
+	}
+	for i, sitrep := range d.SitReps {
+		fmt.Fprintf(&b, "### 1.%v Sitrep %s, %s\n\n", i+1, formatDocumentTime(sitrep.GetDateTime()), sitrep.GetBy())
+		field("Situation", sitrep.GetSituation())
+		field("Actions", sitrep.GetActions())
+		field("To do", sitrep.GetTODO())
+		field("Issues", sitrep.GetIssues())
+		b.WriteString("\n")
+	}
+
+	b.WriteString("## 2. Mission\n\n")
+	fmt.Fprintf(&b, "%s\n\n", strings.TrimSpace(d.Mission.GetObjective()))
+	field("Accountable", d.Accountable)
+	field("Deadline", formatDocumentTime(d.Mission.GetDeadline()))
+	field("State", d.Mission.GetState().String())
+	b.WriteString("\n")
+
+	b.WriteString("## 3. Execution\n\n")
+	if len(d.Execution) == 0 {
+		b.WriteString("No delegated tasks.\n\n")
+	} else {
+		b.WriteString("| No. | Task | Accountable | Deadline | State |\n")
+		b.WriteString("| --- | --- | --- | --- | --- |\n")
+		for _, task := range d.Execution {
+			state := task.State
+			if task.Overdue {
+				state += ", overdue"
+			}
+			fmt.Fprintf(&b, "| %s | %s%s | %s | %s | %s |\n", task.Number, strings.Repeat("&nbsp;&nbsp;", task.Level), mdCell(task.Title), mdCell(task.Accountable), formatDocumentTime(task.Deadline), state)
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("## 4. Sustainment\n\n")
+	if len(d.Issues) == 0 {
+		b.WriteString("No issues reported.\n\n")
+	}
+	for _, issue := range d.Issues {
+		number := issue.Number
+		if len(number) == 0 {
+			number = "2" // NOTE: the order itself, ie the mission
+		}
+		fmt.Fprintf(&b, "- **%s %s** (%s, %s): %s\n", number, issue.Title, issue.By, formatDocumentTime(issue.DateTime), mdCell(issue.Issues))
+	}
+	if len(d.Issues) > 0 {
+		b.WriteString("\n")
+	}
+
+	b.WriteString("## 5. Command and Signal\n\n")
+	field("Accountable", d.Accountable)
+	reportsTo := d.ReportsTo
+	if len(reportsTo) == 0 {
+		reportsTo = "-"
+	}
+	field("Reports to", reportsTo)
+	return b.String()
+}
+
+var templDocument = template.Must(template.New("document").Funcs(template.FuncMap{
+	"formatTime": formatDocumentTime,
+	"inc":        func(i int) int { return i + 1 },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>ORDER: {{.Title}}</title>
+<style>
+body { font-family: serif; max-width: 50em; margin: 2em auto; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #000; padding: 0.2em 0.5em; text-align: left; vertical-align: top; }
+.objective { white-space: pre-wrap; }
+@media print { body { margin: 0; } }
+</style>
+</head>
+<body>
+<h1>ORDER: {{.Title}}</h1>
+<p>Order {{.OrderID}}, version {{.Version}}. Generated {{formatTime .Generated}}.</p>
+
+<h2>1. Situation</h2>
+<p>Delegated tasks: {{.Rollup.GetTotal}} total, {{.Rollup.GetCompleted}} completed, {{.Rollup.GetBlocked}} blocked, {{.Rollup.GetOverdue}} overdue.</p>
+{{- range $i, $sitrep := .SitReps}}
+<h3>1.{{inc $i}} Sitrep {{formatTime $sitrep.GetDateTime}}, {{$sitrep.GetBy}}</h3>
+<ul>
+{{- with $sitrep.GetSituation}}
+<li><b>Situation:</b> {{.}}</li>
+{{- end}}
+{{- with $sitrep.GetActions}}
+<li><b>Actions:</b> {{.}}</li>
+{{- end}}
+{{- with $sitrep.GetTODO}}
+<li><b>To do:</b> {{.}}</li>
+{{- end}}
+{{- with $sitrep.GetIssues}}
+<li><b>Issues:</b> {{.}}</li>
+{{- end}}
+</ul>
+{{- else}}
+<p>No sitreps reported.</p>
+{{- end}}
+
+<h2>2. Mission</h2>
+<p class="objective">{{.Mission.GetObjective}}</p>
+<ul>
+<li><b>Accountable:</b> {{.Accountable}}</li>
+<li><b>Deadline:</b> {{formatTime .Mission.GetDeadline}}</li>
+<li><b>State:</b> {{.Mission.GetState}}</li>
+</ul>
+
+<h2>3. Execution</h2>
+{{- if .Execution}}
+<table>
+<tr><th>No.</th><th>Task</th><th>Accountable</th><th>Deadline</th><th>State</th></tr>
+{{- range .Execution}}
+<tr><td>{{.Number}}</td><td style="padding-left: {{inc .Level}}em">{{.Title}}</td><td>{{.Accountable}}</td><td>{{formatTime .Deadline}}</td><td>{{.State}}{{if .Overdue}}, overdue{{end}}</td></tr>
+{{- end}}
+</table>
+{{- else}}
+<p>No delegated tasks.</p>
+{{- end}}
+
+<h2>4. Sustainment</h2>
+{{- if .Issues}}
+<ul>
+{{- range .Issues}}
+<li><b>{{if .Number}}{{.Number}}{{else}}2{{end}} {{.Title}}</b> ({{.By}}, {{formatTime .DateTime}}): {{.Issues}}</li>
+{{- end}}
+</ul>
+{{- else}}
+<p>No issues reported.</p>
+{{- end}}
+
+<h2>5. Command and Signal</h2>
+<ul>
+<li><b>Accountable:</b> {{.Accountable}}</li>
+<li><b>Reports to:</b> {{or .ReportsTo "-"}}</li>
+</ul>
+</body>
+</html>
+`))
+
+func (d *Document) HTML() (string, error) {
+	var b bytes.Buffer
+	if err := templDocument.Execute(&b, d); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// Render writes the document in the format, unknown formats are an error.
+func (d *Document) Render(format DocumentFormat) (string, error) {
+	switch format {
+	case DocumentMarkdown:
+		return d.Markdown(), nil
+	case DocumentHTML:
+		return d.HTML()
+	default:
+		return "", fmt.Errorf("unknown document format '%s'", format)
+	}
+}
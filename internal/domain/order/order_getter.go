@@ -130,9 +130,298 @@ func (o *Order) GetSitReps() []*SitRep {
 	return o.SitReps
 }
 
+func (o *Order) GetRollupPolicy() *RollupPolicy {
+	if o == nil {
+		return nil
+	}
+	return o.RollupPolicy
+}
+
 func (o *Order) GetMeta() *meta.Meta {
 	if o == nil {
 		return nil
 	}
 	return o.Meta
 }
+
+////////////////
+
+func (fc *FieldChange) GetField() string {
+	if fc == nil {
+		return ""
+	}
+	return fc.Field
+}
+
+func (fc *FieldChange) GetKind() ChangeKind {
+	if fc == nil {
+		return ""
+	}
+	return fc.Kind
+}
+
+func (fc *FieldChange) GetOld() string {
+	if fc == nil {
+		return ""
+	}
+	return fc.Old
+}
+
+func (fc *FieldChange) GetNew() string {
+	if fc == nil {
+		return ""
+	}
+	return fc.New
+}
+
+////////////////
+
+func (d *Diff) GetFrom() uint {
+	if d == nil {
+		return 0
+	}
+	return d.From
+}
+
+func (d *Diff) GetTo() uint {
+	if d == nil {
+		return 0
+	}
+	return d.To
+}
+
+func (d *Diff) GetUpdated() time.Time {
+	if d == nil {
+		return time.Time{}
+	}
+	return d.Updated
+}
+
+func (d *Diff) GetUpdatedBy() string {
+	if d == nil {
+		return ""
+	}
+	return d.UpdatedBy
+}
+
+func (d *Diff) GetChanges() []*FieldChange {
+	if d == nil {
+		return nil
+	}
+	return d.Changes
+}
+
+////////////////
+
+func (r *Rollup) GetTotal() int {
+	if r == nil {
+		return 0
+	}
+	return r.Total
+}
+
+func (r *Rollup) GetCompleted() int {
+	if r == nil {
+		return 0
+	}
+	return r.Completed
+}
+
+func (r *Rollup) GetBlocked() int {
+	if r == nil {
+		return 0
+	}
+	return r.Blocked
+}
+
+func (r *Rollup) GetOverdue() int {
+	if r == nil {
+		return 0
+	}
+	return r.Overdue
+}
+
+func (t *Tree) GetOrder() *Order {
+	if t == nil {
+		return nil
+	}
+	return t.Order
+}
+
+func (t *Tree) GetRollup() *Rollup {
+	if t == nil {
+		return nil
+	}
+	return t.Rollup
+}
+
+func (t *Tree) GetSuborders() []*Tree {
+	if t == nil {
+		return nil
+	}
+	return t.Suborders
+}
+
+////////////
+
+func (rp *RollupPolicy) GetIssuesOnBlocked() bool {
+	if rp == nil {
+		return false
+	}
+	return rp.IssuesOnBlocked
+}
+
+func (rp *RollupPolicy) GetCompleteDelegated() bool {
+	if rp == nil {
+		return false
+	}
+	return rp.CompleteDelegated
+}
+
+func (rp *RollupPolicy) GetPreRollupState() State {
+	if rp == nil {
+		return 0
+	}
+	return rp.PreRollupState
+}
+
+////////////
+
+func (dc *DeadlineConflict) GetOrderID() meta.ID {
+	if dc == nil {
+		return meta.EmptyID()
+	}
+	return dc.OrderID
+}
+
+func (dc *DeadlineConflict) GetDeadline() time.Time {
+	if dc == nil {
+		return time.Time{}
+	}
+	return dc.Deadline
+}
+
+func (dc *DeadlineConflict) GetAncestorID() meta.ID {
+	if dc == nil {
+		return meta.EmptyID()
+	}
+	return dc.AncestorID
+}
+
+func (dc *DeadlineConflict) GetAncestorDeadline() time.Time {
+	if dc == nil {
+		return time.Time{}
+	}
+	return dc.AncestorDeadline
+}
+
+////////////
+
+func (rg *ReportGroup) GetAccountable() user.Email {
+	if rg == nil {
+		return ""
+	}
+	return rg.Accountable
+}
+
+func (rg *ReportGroup) GetOverdue() []*Task {
+	if rg == nil {
+		return nil
+	}
+	return rg.Overdue
+}
+
+func (rg *ReportGroup) GetAtRisk() []*Task {
+	if rg == nil {
+		return nil
+	}
+	return rg.AtRisk
+}
+
+////////////
+
+func (sh *SearchHit) GetOrderID() meta.ID {
+	if sh == nil {
+		return ""
+	}
+	return sh.OrderID
+}
+
+func (sh *SearchHit) GetObjective() string {
+	if sh == nil {
+		return ""
+	}
+	return sh.Objective
+}
+
+func (sh *SearchHit) GetScore() int {
+	if sh == nil {
+		return 0
+	}
+	return sh.Score
+}
+
+func (sh *SearchHit) GetSnippets() []*Snippet {
+	if sh == nil {
+		return nil
+	}
+	return sh.Snippets
+}
+
+func (sn *Snippet) GetField() string {
+	if sn == nil {
+		return ""
+	}
+	return sn.Field
+}
+
+func (sn *Snippet) GetSitRepID() meta.ID {
+	if sn == nil {
+		return ""
+	}
+	return sn.SitRepID
+}
+
+func (sn *Snippet) GetText() string {
+	if sn == nil {
+		return ""
+	}
+	return sn.Text
+}
+
+func (sn *Snippet) GetHighlights() []*Highlight {
+	if sn == nil {
+		return nil
+	}
+	return sn.Highlights
+}
+
+func (h *Highlight) GetStart() int {
+	if h == nil {
+		return 0
+	}
+	return h.Start
+}
+
+func (h *Highlight) GetEnd() int {
+	if h == nil {
+		return 0
+	}
+	return h.End
+}
+
+////////////
+
+func (ur *UserRef) GetField() string {
+	if ur == nil {
+		return ""
+	}
+	return ur.Field
+}
+
+func (ur *UserRef) GetEmail() user.Email {
+	if ur == nil {
+		return ""
+	}
+	return ur.Email
+}
@@ -0,0 +1,31 @@
+package order
+
+import (
+	"time"
+
+	"github.com/moledoc/orderly/internal/domain/user"
+)
+
+// ReportGroup is the late tasks of one accountable user.
+type ReportGroup struct {
+	Accountable user.Email `json:"accountable"`
+	Overdue     []*Task    `json:"overdue,omitempty"`
+	AtRisk      []*Task    `json:"at_risk,omitempty"`
+}
+
+// IsOverdue reports whether the deadline has passed and the task isn't completed.
+func (t *Task) IsOverdue(now time.Time) bool {
+	deadline := t.GetDeadline()
+	return t.GetState() != Completed && !deadline.IsZero() && deadline.Before(now)
+}
+
+// IsAtRisk reports whether the task is blocked or having issues and due within the given duration.
+// NOTE: overdue tasks aren't at risk, they're already late
+func (t *Task) IsAtRisk(now time.Time, within time.Duration) bool {
+	state := t.GetState()
+	deadline := t.GetDeadline()
+	if state != Blocked && state != HavingIssues || deadline.IsZero() || deadline.Before(now) {
+		return false
+	}
+	return !deadline.After(now.Add(within))
+}
@@ -0,0 +1,216 @@
+package order
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/moledoc/orderly/internal/domain/meta"
+)
+
+const (
+	snippetBefore = 40  // NOTE: bytes of context kept before the first match
+	snippetLength = 160 // NOTE: bytes a snippet is cut to
+)
+
+// SearchHit is an order matching the search query, Score ranks the hits, higher first.
+type SearchHit struct {
+	OrderID   meta.ID    `json:"order_id,omitempty"`
+	Objective string     `json:"objective,omitempty"`
+	Score     int        `json:"score"`
+	Snippets  []*Snippet `json:"snippets,omitempty"`
+}
+
+// Snippet is an excerpt of the searched field, Highlights are byte offsets of the matches in Text.
+type Snippet struct {
+	Field      string       `json:"field,omitempty"`     // NOTE: objective or sitrep.situation, sitrep.actions, sitrep.todo, sitrep.issues
+	SitRepID   meta.ID      `json:"sitrep_id,omitempty"` // NOTE: set for the sitrep fields
+	Text       string       `json:"text,omitempty"`
+	Highlights []*Highlight `json:"highlights,omitempty"`
+}
+
+type Highlight struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// SnippetPart is a piece of the snippet's text, either matched or not.
+type SnippetPart struct {
+	Text  string
+	Match bool
+}
+
+// token is a word of the text and its byte offsets.
+type token struct {
+	word       string
+	start, end int
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func tokens(text string) []token {
+	var tt []token
+	start := -1
+	for i, r := range text {
+		if isWordRune(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			tt = append(tt, token{word: strings.ToLower(text[start:i]), start: start, end: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tt = append(tt, token{word: strings.ToLower(text[start:]), start: start, end: len(text)})
+	}
+	return tt
+}
+
+// Tokenize splits the text into lower case words, letters and digits make up a word.
+// NOTE: there's no stemming, search terms match words by prefix
+func Tokenize(text string) []string {
+	var words []string
+	for _, t := range tokens(text) {
+		words = append(words, t.word)
+	}
+	return words
+}
+
+// searchField is a searchable text of the order.
+type searchField struct {
+	field    string
+	sitrepID meta.ID
+	text     string
+	weight   int
+}
+
+func (o *Order) searchFields() []searchField {
+	fields := []searchField{{field: "objective", text: o.GetTask().GetObjective(), weight: 3}}
+	for _, sitrep := range o.GetSitReps() {
+		fields = append(fields,
+			searchField{field: "sitrep.situation", sitrepID: sitrep.GetID(), text: sitrep.GetSituation(), weight: 1},
+			searchField{field: "sitrep.actions", sitrepID: sitrep.GetID(), text: sitrep.GetActions(), weight: 1},
+			searchField{field: "sitrep.todo", sitrepID: sitrep.GetID(), text: sitrep.GetTODO(), weight: 1},
+			searchField{field: "sitrep.issues", sitrepID: sitrep.GetID(), text: sitrep.GetIssues(), weight: 1},
+		)
+	}
+	return fields
+}
+
+// SearchWords returns the words the order is found by, every word once.
+func (o *Order) SearchWords() []string {
+	seen := make(map[string]bool)
+	var words []string
+	for _, field := range o.searchFields() {
+		for _, word := range Tokenize(field.text) {
+			if !seen[word] {
+				seen[word] = true
+				words = append(words, word)
+			}
+		}
+	}
+	return words
+}
+
+// Search matches the order against the search terms, nil when some term matches no word.
+// NOTE: a whole word match scores twice a prefix match, a match in the objective thrice a match in a sitrep
+func (o *Order) Search(terms []string) *SearchHit {
+	if o == nil || len(terms) == 0 {
+		return nil
+	}
+	hit := &SearchHit{
+		OrderID:   o.GetID(),
+		Objective: o.GetTask().GetObjective(),
+	}
+	found := make(map[string]bool)
+	for _, field := range o.searchFields() {
+		var highlights []*Highlight
+		for _, t := range tokens(field.text) {
+			matched := false
+			for _, term := range terms {
+				if !strings.HasPrefix(t.word, term) {
+					continue
+				}
+				found[term] = true
+				matched = true
+				if t.word == term {
+					hit.Score += 2 * field.weight
+				} else {
+					hit.Score += field.weight
+				}
+			}
+			if matched {
+				highlights = append(highlights, &Highlight{Start: t.start, End: t.end})
+			}
+		}
+		if len(highlights) > 0 {
+			hit.Snippets = append(hit.Snippets, snippet(field, highlights))
+		}
+	}
+	if len(found) < len(terms) {
+		return nil
+	}
+	return hit
+}
+
+// snippet cuts the field's text around the first highlight.
+func snippet(field searchField, highlights []*Highlight) *Snippet {
+	text := field.text
+	start := 0
+	if len(text) > snippetLength {
+		start = max(0, highlights[0].Start-snippetBefore)
+		for start > 0 && !utf8.RuneStart(text[start]) {
+			start--
+		}
+		end := min(len(text), start+snippetLength)
+		for end < len(text) && !utf8.RuneStart(text[end]) {
+			end--
+		}
+		text = text[start:end]
+	}
+
+	var hs []*Highlight
+	for _, h := range highlights {
+		if h.Start < start || h.End > start+len(text) {
+			continue
+		}
+		hs = append(hs, &Highlight{Start: h.Start - start, End: h.End - start})
+	}
+	return &Snippet{
+		Field:      field.field,
+		SitRepID:   field.sitrepID,
+		Text:       text,
+		Highlights: hs,
+	}
+}
+
+// Parts splits the snippet's text by its highlights, so it can be rendered.
+func (s *Snippet) Parts() []*SnippetPart {
+	var parts []*SnippetPart
+	at := 0
+	for _, h := range s.GetHighlights() {
+		if at < h.GetStart() {
+			parts = append(parts, &SnippetPart{Text: s.GetText()[at:h.GetStart()]})
+		}
+		parts = append(parts, &SnippetPart{Text: s.GetText()[h.GetStart():h.GetEnd()], Match: true})
+		at = h.GetEnd()
+	}
+	if at < len(s.GetText()) {
+		parts = append(parts, &SnippetPart{Text: s.GetText()[at:]})
+	}
+	return parts
+}
+
+// RankHits sorts the hits by score, higher first, ties by order id.
+func RankHits(hits []*SearchHit) {
+	slices.SortFunc(hits, func(a, b *SearchHit) int {
+		return cmp.Or(cmp.Compare(b.GetScore(), a.GetScore()), cmp.Compare(a.GetOrderID(), b.GetOrderID()))
+	})
+}
@@ -130,9 +130,25 @@ func (o *Order) SetSitReps(sitreps []*SitRep) {
 	o.SitReps = sitreps
 }
 
+func (o *Order) SetRollupPolicy(policy *RollupPolicy) {
+	if o == nil {
+		return
+	}
+	o.RollupPolicy = policy
+}
+
 func (o *Order) SetMeta(meta *meta.Meta) {
 	if o == nil {
 		return
 	}
 	o.Meta = meta
 }
+
+////////////
+
+func (rp *RollupPolicy) SetPreRollupState(state State) {
+	if rp == nil {
+		return
+	}
+	rp.PreRollupState = state
+}
@@ -0,0 +1,46 @@
+package order
+
+import "time"
+
+// Rollup counts the orders delegated below a tree node, at any depth.
+type Rollup struct {
+	Total     int `json:"total"`
+	Completed int `json:"completed"`
+	Blocked   int `json:"blocked"`
+	Overdue   int `json:"overdue"` // NOTE: deadline has passed and the order isn't completed
+}
+
+// Tree is an order together with the orders delegated below it.
+type Tree struct {
+	Order     *Order  `json:"order,omitempty"`
+	Rollup    *Rollup `json:"rollup,omitempty"`
+	Suborders []*Tree `json:"suborders,omitempty"`
+}
+
+// Add counts the order into the rollup.
+func (r *Rollup) Add(o *Order, now time.Time) {
+	if r == nil {
+		return
+	}
+	r.Total++
+	switch o.GetTask().GetState() {
+	case Completed:
+		r.Completed++
+	case Blocked:
+		r.Blocked++
+	}
+	if o.GetTask().IsOverdue(now) {
+		r.Overdue++
+	}
+}
+
+// Merge adds the counts of other into the rollup.
+func (r *Rollup) Merge(other *Rollup) {
+	if r == nil || other == nil {
+		return
+	}
+	r.Total += other.Total
+	r.Completed += other.Completed
+	r.Blocked += other.Blocked
+	r.Overdue += other.Overdue
+}
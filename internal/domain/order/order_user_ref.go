@@ -0,0 +1,38 @@
+package order
+
+import (
+	"fmt"
+
+	"github.com/moledoc/orderly/internal/domain/user"
+)
+
+// UserRef is a user the order refers to, by the field it's referred from.
+type UserRef struct {
+	Field string
+	Email user.Email
+}
+
+// TaskUserRefs returns the accountable users of the tasks, listed under the field.
+func TaskUserRefs(field string, tasks ...*Task) []*UserRef {
+	var refs []*UserRef
+	for i, task := range tasks {
+		refs = append(refs, &UserRef{Field: fmt.Sprintf("%s[%v].accountable", field, i), Email: task.GetAccountable()})
+	}
+	return refs
+}
+
+// SitRepUserRefs returns the users reporting the sitreps, listed under the field.
+func SitRepUserRefs(field string, sitreps ...*SitRep) []*UserRef {
+	var refs []*UserRef
+	for i, sitrep := range sitreps {
+		refs = append(refs, &UserRef{Field: fmt.Sprintf("%s[%v].by", field, i), Email: sitrep.GetBy()})
+	}
+	return refs
+}
+
+// OrderUserRefs returns every user the order refers to: its task's, delegated tasks' and sitreps'.
+func OrderUserRefs(field string, o *Order) []*UserRef {
+	refs := []*UserRef{{Field: field + ".task.accountable", Email: o.GetTask().GetAccountable()}}
+	refs = append(refs, TaskUserRefs(field+".delegated_tasks", o.GetDelegatedTasks()...)...)
+	return append(refs, SitRepUserRefs(field+".sitreps", o.GetSitReps()...)...)
+}
@@ -0,0 +1,12 @@
+package request
+
+import (
+	"github.com/moledoc/orderly/internal/domain/dump"
+)
+
+type ExportRequest struct{}
+
+type ImportRequest struct {
+	Mode     dump.Mode      `json:"mode,omitempty"` // NOTE: merge when empty
+	Document *dump.Document `json:"document,omitempty"`
+}
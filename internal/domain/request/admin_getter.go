@@ -0,0 +1,19 @@
+package request
+
+import (
+	"github.com/moledoc/orderly/internal/domain/dump"
+)
+
+func (r *ImportRequest) GetMode() dump.Mode {
+	if r == nil {
+		return ""
+	}
+	return r.Mode
+}
+
+func (r *ImportRequest) GetDocument() *dump.Document {
+	if r == nil {
+		return nil
+	}
+	return r.Document
+}
@@ -1,6 +1,8 @@
 package request
 
 import (
+	"time"
+
 	"github.com/moledoc/orderly/internal/domain/meta"
 	"github.com/moledoc/orderly/internal/domain/order"
 	"github.com/moledoc/orderly/internal/domain/user"
@@ -15,16 +17,38 @@ type GetOrderByIDRequest struct {
 }
 
 type GetOrdersRequest struct {
-	ParentOrderID meta.ID    `json:"parent_order_id,omitempty"`
-	Accountable   user.Email `json:"accountable,omitempty"`
+	ParentOrderID     meta.ID       `json:"parent_order_id,omitempty"`
+	Accountable       user.Email    `json:"accountable,omitempty"`
+	States            []order.State `json:"states,omitempty"`        // NOTE: matches any of the states
+	DeadlineFrom      time.Time     `json:"deadline_from,omitempty"` // NOTE: ranges are [from, to), zero time leaves the side open
+	DeadlineTo        time.Time     `json:"deadline_to,omitempty"`
+	CreatedFrom       time.Time     `json:"created_from,omitempty"`
+	CreatedTo         time.Time     `json:"created_to,omitempty"`
+	UpdatedFrom       time.Time     `json:"updated_from,omitempty"`
+	UpdatedTo         time.Time     `json:"updated_to,omitempty"`
+	Objective         string        `json:"objective,omitempty"` // NOTE: case-insensitive substring
+	SitRepBy          user.Email    `json:"sitrep_by,omitempty"`
+	HasDelegatedTasks *bool         `json:"has_delegated_tasks,omitempty"` // NOTE: nil matches either
+	Limit             uint          `json:"limit,omitempty"`               // NOTE: 0 lists every match
+	Cursor            string        `json:"cursor,omitempty"`
+	Sort              meta.Sort     `json:"sort,omitempty"` // NOTE: created, updated, deadline or state, '-' prefix for descending
 }
 
 type PatchOrderRequest struct {
-	Order *order.Order `json:"order,omitempty"`
+	Order           *order.Order `json:"order,omitempty"`
+	ExpectedVersion uint         `json:"expected_version,omitempty"` // NOTE: 0 skips the version check
 }
 
 type DeleteOrderRequest struct {
-	ID meta.ID `json:"id,omitempty"`
+	ID      meta.ID `json:"id,omitempty"`
+	Cascade bool    `json:"cascade,omitempty"` // NOTE: delete the whole subtree of delegated orders
+	DryRun  bool    `json:"dry_run,omitempty"` // NOTE: only report what would be deleted
+}
+
+type MoveOrderRequest struct {
+	ID              meta.ID `json:"id,omitempty"`
+	ParentOrderID   meta.ID `json:"parent_order_id,omitempty"`
+	ExpectedVersion uint    `json:"expected_version,omitempty"` // NOTE: 0 skips the version check
 }
 
 ////////////////
@@ -35,8 +59,9 @@ type PutDelegatedTasksRequest struct {
 }
 
 type PatchDelegatedTasksRequest struct {
-	OrderID meta.ID       `json:"order_id,omitempty"`
-	Tasks   []*order.Task `json:"tasks,omitempty"`
+	OrderID         meta.ID       `json:"order_id,omitempty"`
+	Tasks           []*order.Task `json:"tasks,omitempty"`
+	ExpectedVersion uint          `json:"expected_version,omitempty"` // NOTE: 0 skips the version check
 }
 
 type DeleteDelegatedTasksRequest struct {
@@ -52,11 +77,80 @@ type PutSitRepsRequest struct {
 }
 
 type PatchSitRepsRequest struct {
-	OrderID meta.ID         `json:"order_id,omitempty"`
-	SitReps []*order.SitRep `json:"sitreps,omitempty"`
+	OrderID         meta.ID         `json:"order_id,omitempty"`
+	SitReps         []*order.SitRep `json:"sitreps,omitempty"`
+	ExpectedVersion uint            `json:"expected_version,omitempty"` // NOTE: 0 skips the version check
 }
 
 type DeleteSitRepsRequest struct {
 	OrderID   meta.ID   `json:"order_id,omitempty"`
 	SitRepIDs []meta.ID `json:"sitrep_ids,omitempty"`
 }
+
+////////////////
+
+type GetOrderVersionsRequest struct {
+	ID meta.ID `json:"id,omitempty"`
+}
+
+type GetOrderVersionRequest struct {
+	ID      meta.ID `json:"id,omitempty"`
+	Version uint    `json:"version,omitempty"`
+}
+
+type GetOrderDiffRequest struct {
+	ID   meta.ID `json:"id,omitempty"`
+	From uint    `json:"from,omitempty"` // NOTE: defaults to the first version
+	To   uint    `json:"to,omitempty"`   // NOTE: defaults to the latest version
+}
+
+type GetOrderTreeRequest struct {
+	ID    meta.ID `json:"id,omitempty"`
+	Depth uint    `json:"depth,omitempty"` // NOTE: 0 returns the whole tree
+}
+
+type GetOrderDocumentRequest struct {
+	ID      meta.ID              `json:"id,omitempty"`
+	Format  order.DocumentFormat `json:"format,omitempty"`  // NOTE: defaults to markdown
+	SitReps uint                 `json:"sitreps,omitempty"` // NOTE: latest sitreps in the situation, 0 defaults to 3
+}
+
+type GetOverdueReportRequest struct {
+	RootOrderID meta.ID    `json:"root_order_id,omitempty"` // NOTE: empty reports on all orders
+	Supervisor  user.Email `json:"supervisor,omitempty"`    // NOTE: reports only the tasks of users reporting to the supervisor
+	Days        uint       `json:"days,omitempty"`          // NOTE: at risk window, 0 defaults to 7 days
+}
+
+type GetCalendarRequest struct {
+	Accountable user.Email `json:"accountable,omitempty"`
+	RootOrderID meta.ID    `json:"root_order_id,omitempty"` // NOTE: empty covers all orders
+}
+
+type SearchRequest struct {
+	Query string `json:"q,omitempty"`     // NOTE: every word has to match, as a word or a word's prefix
+	Limit uint   `json:"limit,omitempty"` // NOTE: 0 defaults to 20 hits
+}
+
+////////////////
+
+type GetDeletedOrdersRequest struct{}
+
+type RestoreOrderRequest struct {
+	ID meta.ID `json:"id,omitempty"`
+}
+
+type RestoreSitRepsRequest struct {
+	OrderID   meta.ID   `json:"order_id,omitempty"`
+	SitRepIDs []meta.ID `json:"sitrep_ids,omitempty"`
+}
+
+type PurgeOrdersRequest struct {
+	Before time.Time `json:"before,omitempty"` // NOTE: orders deleted before this time are removed for good
+}
+
+////////////////
+
+type ChangeUserEmailRequest struct {
+	From user.Email `json:"from,omitempty"`
+	To   user.Email `json:"to,omitempty"`
+}
@@ -1,6 +1,8 @@
 package request
 
 import (
+	"time"
+
 	"github.com/moledoc/orderly/internal/domain/meta"
 	"github.com/moledoc/orderly/internal/domain/order"
 	"github.com/moledoc/orderly/internal/domain/user"
@@ -38,6 +40,97 @@ func (r *GetOrdersRequest) GetAccountable() user.Email {
 	return r.Accountable
 }
 
+func (r *GetOrdersRequest) GetStates() []order.State {
+	if r == nil {
+		return nil
+	}
+	return r.States
+}
+
+func (r *GetOrdersRequest) GetDeadlineFrom() time.Time {
+	if r == nil {
+		return time.Time{}
+	}
+	return r.DeadlineFrom
+}
+
+func (r *GetOrdersRequest) GetDeadlineTo() time.Time {
+	if r == nil {
+		return time.Time{}
+	}
+	return r.DeadlineTo
+}
+
+func (r *GetOrdersRequest) GetCreatedFrom() time.Time {
+	if r == nil {
+		return time.Time{}
+	}
+	return r.CreatedFrom
+}
+
+func (r *GetOrdersRequest) GetCreatedTo() time.Time {
+	if r == nil {
+		return time.Time{}
+	}
+	return r.CreatedTo
+}
+
+func (r *GetOrdersRequest) GetUpdatedFrom() time.Time {
+	if r == nil {
+		return time.Time{}
+	}
+	return r.UpdatedFrom
+}
+
+func (r *GetOrdersRequest) GetUpdatedTo() time.Time {
+	if r == nil {
+		return time.Time{}
+	}
+	return r.UpdatedTo
+}
+
+func (r *GetOrdersRequest) GetObjective() string {
+	if r == nil {
+		return ""
+	}
+	return r.Objective
+}
+
+func (r *GetOrdersRequest) GetSitRepBy() user.Email {
+	if r == nil {
+		return ""
+	}
+	return r.SitRepBy
+}
+
+func (r *GetOrdersRequest) GetHasDelegatedTasks() *bool {
+	if r == nil {
+		return nil
+	}
+	return r.HasDelegatedTasks
+}
+
+func (r *GetOrdersRequest) GetLimit() uint {
+	if r == nil {
+		return 0
+	}
+	return r.Limit
+}
+
+func (r *GetOrdersRequest) GetCursor() string {
+	if r == nil {
+		return ""
+	}
+	return r.Cursor
+}
+
+func (r *GetOrdersRequest) GetSort() meta.Sort {
+	if r == nil {
+		return ""
+	}
+	return r.Sort
+}
+
 ////////////////
 
 func (r *PatchOrderRequest) GetOrder() *order.Order {
@@ -47,6 +140,13 @@ func (r *PatchOrderRequest) GetOrder() *order.Order {
 	return r.Order
 }
 
+func (r *PatchOrderRequest) GetExpectedVersion() uint {
+	if r == nil {
+		return 0
+	}
+	return r.ExpectedVersion
+}
+
 ////////////////
 
 func (r *DeleteOrderRequest) GetID() meta.ID {
@@ -56,6 +156,41 @@ func (r *DeleteOrderRequest) GetID() meta.ID {
 	return r.ID
 }
 
+func (r *DeleteOrderRequest) GetCascade() bool {
+	if r == nil {
+		return false
+	}
+	return r.Cascade
+}
+
+func (r *DeleteOrderRequest) GetDryRun() bool {
+	if r == nil {
+		return false
+	}
+	return r.DryRun
+}
+
+func (r *MoveOrderRequest) GetID() meta.ID {
+	if r == nil {
+		return ""
+	}
+	return r.ID
+}
+
+func (r *MoveOrderRequest) GetParentOrderID() meta.ID {
+	if r == nil {
+		return ""
+	}
+	return r.ParentOrderID
+}
+
+func (r *MoveOrderRequest) GetExpectedVersion() uint {
+	if r == nil {
+		return 0
+	}
+	return r.ExpectedVersion
+}
+
 ////////////////
 
 func (r *PutDelegatedTasksRequest) GetOrderID() meta.ID {
@@ -88,6 +223,13 @@ func (r *PatchDelegatedTasksRequest) GetTasks() []*order.Task {
 	return r.Tasks
 }
 
+func (r *PatchDelegatedTasksRequest) GetExpectedVersion() uint {
+	if r == nil {
+		return 0
+	}
+	return r.ExpectedVersion
+}
+
 ////////////////
 
 func (r *DeleteDelegatedTasksRequest) GetOrderID() meta.ID {
@@ -136,6 +278,13 @@ func (r *PatchSitRepsRequest) GetSitReps() []*order.SitRep {
 	return r.SitReps
 }
 
+func (r *PatchSitRepsRequest) GetExpectedVersion() uint {
+	if r == nil {
+		return 0
+	}
+	return r.ExpectedVersion
+}
+
 ////////////////
 
 func (r *DeleteSitRepsRequest) GetOrderID() meta.ID {
@@ -151,3 +300,181 @@ func (r *DeleteSitRepsRequest) GetSitRepIDs() []meta.ID {
 	}
 	return r.SitRepIDs
 }
+
+////////////////
+
+func (r *GetOrderVersionsRequest) GetID() meta.ID {
+	if r == nil {
+		return ""
+	}
+	return r.ID
+}
+
+////////////////
+
+func (r *GetOrderVersionRequest) GetID() meta.ID {
+	if r == nil {
+		return ""
+	}
+	return r.ID
+}
+
+func (r *GetOrderVersionRequest) GetVersion() uint {
+	if r == nil {
+		return 0
+	}
+	return r.Version
+}
+
+////////////////
+
+func (r *GetOrderDiffRequest) GetID() meta.ID {
+	if r == nil {
+		return ""
+	}
+	return r.ID
+}
+
+func (r *GetOrderDiffRequest) GetFrom() uint {
+	if r == nil {
+		return 0
+	}
+	return r.From
+}
+
+func (r *GetOrderDiffRequest) GetTo() uint {
+	if r == nil {
+		return 0
+	}
+	return r.To
+}
+
+func (r *GetOrderTreeRequest) GetID() meta.ID {
+	if r == nil {
+		return ""
+	}
+	return r.ID
+}
+
+func (r *GetOrderTreeRequest) GetDepth() uint {
+	if r == nil {
+		return 0
+	}
+	return r.Depth
+}
+
+func (r *GetOrderDocumentRequest) GetID() meta.ID {
+	if r == nil {
+		return ""
+	}
+	return r.ID
+}
+
+func (r *GetOrderDocumentRequest) GetFormat() order.DocumentFormat {
+	if r == nil {
+		return ""
+	}
+	return r.Format
+}
+
+func (r *GetOrderDocumentRequest) GetSitReps() uint {
+	if r == nil {
+		return 0
+	}
+	return r.SitReps
+}
+
+func (r *GetCalendarRequest) GetAccountable() user.Email {
+	if r == nil {
+		return ""
+	}
+	return r.Accountable
+}
+
+func (r *GetCalendarRequest) GetRootOrderID() meta.ID {
+	if r == nil {
+		return ""
+	}
+	return r.RootOrderID
+}
+
+func (r *GetOverdueReportRequest) GetRootOrderID() meta.ID {
+	if r == nil {
+		return ""
+	}
+	return r.RootOrderID
+}
+
+func (r *GetOverdueReportRequest) GetSupervisor() user.Email {
+	if r == nil {
+		return ""
+	}
+	return r.Supervisor
+}
+
+func (r *GetOverdueReportRequest) GetDays() uint {
+	if r == nil {
+		return 0
+	}
+	return r.Days
+}
+
+func (r *SearchRequest) GetQuery() string {
+	if r == nil {
+		return ""
+	}
+	return r.Query
+}
+
+func (r *SearchRequest) GetLimit() uint {
+	if r == nil {
+		return 0
+	}
+	return r.Limit
+}
+
+////////////////
+
+func (r *RestoreOrderRequest) GetID() meta.ID {
+	if r == nil {
+		return ""
+	}
+	return r.ID
+}
+
+func (r *RestoreSitRepsRequest) GetOrderID() meta.ID {
+	if r == nil {
+		return ""
+	}
+	return r.OrderID
+}
+
+func (r *RestoreSitRepsRequest) GetSitRepIDs() []meta.ID {
+	if r == nil {
+		return nil
+	}
+	return r.SitRepIDs
+}
+
+func (r *PurgeOrdersRequest) GetBefore() time.Time {
+	if r == nil {
+		return time.Time{}
+	}
+	return r.Before
+}
+
+////////////////
+
+func (r *ChangeUserEmailRequest) GetFrom() user.Email {
+	if r == nil {
+		return ""
+	}
+	return r.From
+}
+
+func (r *ChangeUserEmailRequest) GetTo() user.Email {
+	if r == nil {
+		return ""
+	}
+	return r.To
+}
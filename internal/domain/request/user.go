@@ -1,6 +1,8 @@
 package request
 
 import (
+	"time"
+
 	"github.com/moledoc/orderly/internal/domain/meta"
 	"github.com/moledoc/orderly/internal/domain/user"
 )
@@ -16,12 +18,44 @@ type GetUserByIDRequest struct {
 type GetUsersRequest struct {
 	Emails     []user.Email `json:"emails,omitempty"`
 	Supervisor user.Email   `json:"supervisor,omitempty"`
+	Limit      uint         `json:"limit,omitempty"` // NOTE: 0 lists every match
+	Cursor     string       `json:"cursor,omitempty"`
+	Sort       meta.Sort    `json:"sort,omitempty"` // NOTE: created or updated, '-' prefix for descending
 }
 
 type PatchUserRequest struct {
-	User *user.User `json:"user,omitempty"`
+	User            *user.User `json:"user,omitempty"`
+	ExpectedVersion uint       `json:"expected_version,omitempty"` // NOTE: 0 skips the version check
 }
 
 type DeleteUserRequest struct {
 	ID meta.ID `json:"id,omitempty"`
 }
+
+type GetUserVersionsRequest struct {
+	ID meta.ID `json:"id,omitempty"`
+}
+
+type GetUserVersionRequest struct {
+	ID      meta.ID `json:"id,omitempty"`
+	Version uint    `json:"version,omitempty"`
+}
+
+type GetUserChainRequest struct {
+	ID meta.ID `json:"id,omitempty"`
+}
+
+type GetUserSubordinatesRequest struct {
+	ID    meta.ID `json:"id,omitempty"`
+	Depth uint    `json:"depth,omitempty"` // NOTE: 0 returns the whole reporting tree
+}
+
+type GetDeletedUsersRequest struct{}
+
+type RestoreUserRequest struct {
+	ID meta.ID `json:"id,omitempty"`
+}
+
+type PurgeUsersRequest struct {
+	Before time.Time `json:"before,omitempty"` // NOTE: users deleted before this time are removed for good
+}
@@ -1,6 +1,8 @@
 package request
 
 import (
+	"time"
+
 	"github.com/moledoc/orderly/internal/domain/meta"
 	"github.com/moledoc/orderly/internal/domain/user"
 )
@@ -37,6 +39,27 @@ func (r *GetUsersRequest) GetSupervisor() user.Email {
 	return r.Supervisor
 }
 
+func (r *GetUsersRequest) GetLimit() uint {
+	if r == nil {
+		return 0
+	}
+	return r.Limit
+}
+
+func (r *GetUsersRequest) GetCursor() string {
+	if r == nil {
+		return ""
+	}
+	return r.Cursor
+}
+
+func (r *GetUsersRequest) GetSort() meta.Sort {
+	if r == nil {
+		return ""
+	}
+	return r.Sort
+}
+
 ////////////////
 
 func (r *PatchUserRequest) GetUser() *user.User {
@@ -46,6 +69,13 @@ func (r *PatchUserRequest) GetUser() *user.User {
 	return r.User
 }
 
+func (r *PatchUserRequest) GetExpectedVersion() uint {
+	if r == nil {
+		return 0
+	}
+	return r.ExpectedVersion
+}
+
 ////////////////
 
 func (r *DeleteUserRequest) GetID() meta.ID {
@@ -54,3 +84,67 @@ func (r *DeleteUserRequest) GetID() meta.ID {
 	}
 	return r.ID
 }
+
+////////////////
+
+func (r *GetUserVersionsRequest) GetID() meta.ID {
+	if r == nil {
+		return ""
+	}
+	return r.ID
+}
+
+////////////////
+
+func (r *GetUserVersionRequest) GetID() meta.ID {
+	if r == nil {
+		return ""
+	}
+	return r.ID
+}
+
+func (r *GetUserVersionRequest) GetVersion() uint {
+	if r == nil {
+		return 0
+	}
+	return r.Version
+}
+
+////////////////
+
+func (r *GetUserChainRequest) GetID() meta.ID {
+	if r == nil {
+		return ""
+	}
+	return r.ID
+}
+
+func (r *GetUserSubordinatesRequest) GetID() meta.ID {
+	if r == nil {
+		return ""
+	}
+	return r.ID
+}
+
+func (r *GetUserSubordinatesRequest) GetDepth() uint {
+	if r == nil {
+		return 0
+	}
+	return r.Depth
+}
+
+////////////////
+
+func (r *RestoreUserRequest) GetID() meta.ID {
+	if r == nil {
+		return ""
+	}
+	return r.ID
+}
+
+func (r *PurgeUsersRequest) GetBefore() time.Time {
+	if r == nil {
+		return time.Time{}
+	}
+	return r.Before
+}
@@ -0,0 +1,20 @@
+package response
+
+import (
+	"github.com/moledoc/orderly/internal/domain/dump"
+	"github.com/moledoc/orderly/internal/domain/meta"
+)
+
+type ExportResponse struct {
+	Document *dump.Document `json:"document"`
+}
+
+type ImportResponse struct {
+	Mode           dump.Mode `json:"mode"`
+	ImportedUsers  []meta.ID `json:"imported_users"`
+	SkippedUsers   []meta.ID `json:"skipped_users"` // NOTE: already stored or the root user
+	RemovedUsers   []meta.ID `json:"removed_users"` // NOTE: replace mode only
+	ImportedOrders []meta.ID `json:"imported_orders"`
+	SkippedOrders  []meta.ID `json:"skipped_orders"` // NOTE: already stored or the root order
+	RemovedOrders  []meta.ID `json:"removed_orders"` // NOTE: replace mode only
+}
@@ -0,0 +1,64 @@
+package response
+
+import (
+	"github.com/moledoc/orderly/internal/domain/dump"
+	"github.com/moledoc/orderly/internal/domain/meta"
+)
+
+func (r *ExportResponse) GetDocument() *dump.Document {
+	if r == nil {
+		return nil
+	}
+	return r.Document
+}
+
+////////////////
+
+func (r *ImportResponse) GetMode() dump.Mode {
+	if r == nil {
+		return ""
+	}
+	return r.Mode
+}
+
+func (r *ImportResponse) GetImportedUsers() []meta.ID {
+	if r == nil {
+		return nil
+	}
+	return r.ImportedUsers
+}
+
+func (r *ImportResponse) GetSkippedUsers() []meta.ID {
+	if r == nil {
+		return nil
+	}
+	return r.SkippedUsers
+}
+
+func (r *ImportResponse) GetRemovedUsers() []meta.ID {
+	if r == nil {
+		return nil
+	}
+	return r.RemovedUsers
+}
+
+func (r *ImportResponse) GetImportedOrders() []meta.ID {
+	if r == nil {
+		return nil
+	}
+	return r.ImportedOrders
+}
+
+func (r *ImportResponse) GetSkippedOrders() []meta.ID {
+	if r == nil {
+		return nil
+	}
+	return r.SkippedOrders
+}
+
+func (r *ImportResponse) GetRemovedOrders() []meta.ID {
+	if r == nil {
+		return nil
+	}
+	return r.RemovedOrders
+}
@@ -1,11 +1,14 @@
 package response
 
 import (
+	"github.com/moledoc/orderly/internal/domain/meta"
 	"github.com/moledoc/orderly/internal/domain/order"
 )
 
+// NOTE: deadline conflicts list the orders due after the orders they're delegated from, they're only set when the conflicts are warned about
 type PostOrderResponse struct {
-	Order *order.Order `json:"order"`
+	Order             *order.Order              `json:"order"`
+	DeadlineConflicts []*order.DeadlineConflict `json:"deadline_conflicts,omitempty"`
 }
 
 type GetOrderByIDResponse struct {
@@ -14,22 +17,36 @@ type GetOrderByIDResponse struct {
 
 type GetOrdersResponse struct {
 	Orders []*order.Order `json:"orders"`
+	Page   *meta.Page     `json:"page,omitempty"`
 }
 
 type PatchOrderResponse struct {
-	Order *order.Order `json:"order"`
+	Order             *order.Order              `json:"order"`
+	DeadlineConflicts []*order.DeadlineConflict `json:"deadline_conflicts,omitempty"`
+}
+
+// NOTE: ids are only set on dry run
+type DeleteOrderResponse struct {
+	OrderIDs  []meta.ID `json:"order_ids,omitempty"`
+	TaskIDs   []meta.ID `json:"task_ids,omitempty"`
+	SitRepIDs []meta.ID `json:"sitrep_ids,omitempty"`
 }
 
-type DeleteOrderResponse struct{}
+type MoveOrderResponse struct {
+	Order             *order.Order              `json:"order"`
+	DeadlineConflicts []*order.DeadlineConflict `json:"deadline_conflicts,omitempty"`
+}
 
 ////////////////
 
 type PutDelegatedTasksResponse struct {
-	Order *order.Order `json:"order"`
+	Order             *order.Order              `json:"order"`
+	DeadlineConflicts []*order.DeadlineConflict `json:"deadline_conflicts,omitempty"`
 }
 
 type PatchDelegatedTasksResponse struct {
-	Order *order.Order `json:"order"`
+	Order             *order.Order              `json:"order"`
+	DeadlineConflicts []*order.DeadlineConflict `json:"deadline_conflicts,omitempty"`
 }
 
 type DeleteDelegatedTasksResponse struct {
@@ -49,3 +66,62 @@ type PatchSitRepsResponse struct {
 type DeleteSitRepsResponse struct {
 	Order *order.Order `json:"order"`
 }
+
+////////////////
+
+type GetOrderVersionsResponse struct {
+	Versions []*order.Order `json:"versions"`
+}
+
+type GetOrderVersionResponse struct {
+	Order *order.Order `json:"order"`
+}
+
+type GetOrderDiffResponse struct {
+	Diffs []*order.Diff `json:"diffs"`
+}
+
+type GetOrderTreeResponse struct {
+	Tree *order.Tree `json:"tree"`
+}
+
+type GetOrderDocumentResponse struct {
+	ContentType string `json:"content_type"`
+	Document    string `json:"document"`
+}
+
+type GetOverdueReportResponse struct {
+	Groups []*order.ReportGroup `json:"groups"`
+}
+
+type GetCalendarResponse struct {
+	Calendar string `json:"calendar"`
+}
+
+type SearchResponse struct {
+	Hits []*order.SearchHit `json:"hits"`
+}
+
+////////////////
+
+type GetDeletedOrdersResponse struct {
+	Orders []*order.Order `json:"orders"`
+}
+
+type RestoreOrderResponse struct {
+	Order *order.Order `json:"order"`
+}
+
+type RestoreSitRepsResponse struct {
+	Order *order.Order `json:"order"`
+}
+
+type PurgeOrdersResponse struct {
+	IDs []meta.ID `json:"ids"`
+}
+
+////////////////
+
+type ChangeUserEmailResponse struct {
+	OrderIDs []meta.ID `json:"order_ids"`
+}
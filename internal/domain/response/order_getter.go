@@ -1,6 +1,7 @@
 package response
 
 import (
+	"github.com/moledoc/orderly/internal/domain/meta"
 	"github.com/moledoc/orderly/internal/domain/order"
 )
 
@@ -11,6 +12,13 @@ func (r *PostOrderResponse) GetOrder() *order.Order {
 	return r.Order
 }
 
+func (r *PostOrderResponse) GetDeadlineConflicts() []*order.DeadlineConflict {
+	if r == nil {
+		return nil
+	}
+	return r.DeadlineConflicts
+}
+
 ////////////////
 
 func (r *GetOrderByIDResponse) GetOrder() *order.Order {
@@ -29,6 +37,13 @@ func (r *GetOrdersResponse) GetOrders() []*order.Order {
 	return r.Orders
 }
 
+func (r *GetOrdersResponse) GetPage() *meta.Page {
+	if r == nil {
+		return nil
+	}
+	return r.Page
+}
+
 ////////////////
 
 func (r *PatchOrderResponse) GetOrder() *order.Order {
@@ -38,6 +53,50 @@ func (r *PatchOrderResponse) GetOrder() *order.Order {
 	return r.Order
 }
 
+func (r *PatchOrderResponse) GetDeadlineConflicts() []*order.DeadlineConflict {
+	if r == nil {
+		return nil
+	}
+	return r.DeadlineConflicts
+}
+
+////////////////
+
+func (r *DeleteOrderResponse) GetOrderIDs() []meta.ID {
+	if r == nil {
+		return nil
+	}
+	return r.OrderIDs
+}
+
+func (r *DeleteOrderResponse) GetTaskIDs() []meta.ID {
+	if r == nil {
+		return nil
+	}
+	return r.TaskIDs
+}
+
+func (r *DeleteOrderResponse) GetSitRepIDs() []meta.ID {
+	if r == nil {
+		return nil
+	}
+	return r.SitRepIDs
+}
+
+func (r *MoveOrderResponse) GetOrder() *order.Order {
+	if r == nil {
+		return nil
+	}
+	return r.Order
+}
+
+func (r *MoveOrderResponse) GetDeadlineConflicts() []*order.DeadlineConflict {
+	if r == nil {
+		return nil
+	}
+	return r.DeadlineConflicts
+}
+
 ////////////////
 
 func (r *PutDelegatedTasksResponse) GetOrder() *order.Order {
@@ -47,6 +106,13 @@ func (r *PutDelegatedTasksResponse) GetOrder() *order.Order {
 	return r.Order
 }
 
+func (r *PutDelegatedTasksResponse) GetDeadlineConflicts() []*order.DeadlineConflict {
+	if r == nil {
+		return nil
+	}
+	return r.DeadlineConflicts
+}
+
 ////////////////
 
 func (r *PatchDelegatedTasksResponse) GetOrder() *order.Order {
@@ -56,6 +122,13 @@ func (r *PatchDelegatedTasksResponse) GetOrder() *order.Order {
 	return r.Order
 }
 
+func (r *PatchDelegatedTasksResponse) GetDeadlineConflicts() []*order.DeadlineConflict {
+	if r == nil {
+		return nil
+	}
+	return r.DeadlineConflicts
+}
+
 ////////////////
 
 func (r *DeleteDelegatedTasksResponse) GetOrder() *order.Order {
@@ -91,3 +164,111 @@ func (r *DeleteSitRepsResponse) GetOrder() *order.Order {
 	}
 	return r.Order
 }
+
+////////////////
+
+func (r *GetOrderVersionsResponse) GetVersions() []*order.Order {
+	if r == nil {
+		return nil
+	}
+	return r.Versions
+}
+
+////////////////
+
+func (r *GetOrderVersionResponse) GetOrder() *order.Order {
+	if r == nil {
+		return nil
+	}
+	return r.Order
+}
+
+////////////////
+
+func (r *GetOrderDiffResponse) GetDiffs() []*order.Diff {
+	if r == nil {
+		return nil
+	}
+	return r.Diffs
+}
+
+func (r *GetOrderTreeResponse) GetTree() *order.Tree {
+	if r == nil {
+		return nil
+	}
+	return r.Tree
+}
+
+func (r *GetOrderDocumentResponse) GetContentType() string {
+	if r == nil {
+		return ""
+	}
+	return r.ContentType
+}
+
+func (r *GetOrderDocumentResponse) GetDocument() string {
+	if r == nil {
+		return ""
+	}
+	return r.Document
+}
+
+func (r *GetCalendarResponse) GetCalendar() string {
+	if r == nil {
+		return ""
+	}
+	return r.Calendar
+}
+
+func (r *GetOverdueReportResponse) GetGroups() []*order.ReportGroup {
+	if r == nil {
+		return nil
+	}
+	return r.Groups
+}
+
+func (r *SearchResponse) GetHits() []*order.SearchHit {
+	if r == nil {
+		return nil
+	}
+	return r.Hits
+}
+
+////////////////
+
+func (r *GetDeletedOrdersResponse) GetOrders() []*order.Order {
+	if r == nil {
+		return nil
+	}
+	return r.Orders
+}
+
+func (r *RestoreOrderResponse) GetOrder() *order.Order {
+	if r == nil {
+		return nil
+	}
+	return r.Order
+}
+
+func (r *RestoreSitRepsResponse) GetOrder() *order.Order {
+	if r == nil {
+		return nil
+	}
+	return r.Order
+}
+
+func (r *PurgeOrdersResponse) GetIDs() []meta.ID {
+	if r == nil {
+		return nil
+	}
+	return r.IDs
+}
+
+////////////////
+
+func (r *ChangeUserEmailResponse) GetOrderIDs() []meta.ID {
+	if r == nil {
+		return nil
+	}
+	return r.OrderIDs
+}
@@ -1,6 +1,9 @@
 package response
 
-import "github.com/moledoc/orderly/internal/domain/user"
+import (
+	"github.com/moledoc/orderly/internal/domain/meta"
+	"github.com/moledoc/orderly/internal/domain/user"
+)
 
 type PostUserResponse struct {
 	User *user.User `json:"user"`
@@ -12,6 +15,7 @@ type GetUserByIDResponse struct {
 
 type GetUsersResponse struct {
 	Users []*user.User `json:"users"`
+	Page  *meta.Page   `json:"page,omitempty"`
 }
 
 type PatchUserResponse struct {
@@ -19,3 +23,31 @@ type PatchUserResponse struct {
 }
 
 type DeleteUserResponse struct{}
+
+type GetUserVersionsResponse struct {
+	Versions []*user.User `json:"versions"`
+}
+
+type GetUserVersionResponse struct {
+	User *user.User `json:"user"`
+}
+
+type GetUserChainResponse struct {
+	Users []*user.User `json:"users"` // NOTE: from the direct supervisor up to root
+}
+
+type GetUserSubordinatesResponse struct {
+	Subordinates []*user.Subordinate `json:"subordinates"`
+}
+
+type GetDeletedUsersResponse struct {
+	Users []*user.User `json:"users"`
+}
+
+type RestoreUserResponse struct {
+	User *user.User `json:"user"`
+}
+
+type PurgeUsersResponse struct {
+	IDs []meta.ID `json:"ids"`
+}
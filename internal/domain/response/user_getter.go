@@ -1,6 +1,7 @@
 package response
 
 import (
+	"github.com/moledoc/orderly/internal/domain/meta"
 	"github.com/moledoc/orderly/internal/domain/user"
 )
 
@@ -29,6 +30,13 @@ func (r *GetUsersResponse) GetUsers() []*user.User {
 	return r.Users
 }
 
+func (r *GetUsersResponse) GetPage() *meta.Page {
+	if r == nil {
+		return nil
+	}
+	return r.Page
+}
+
 ////////////////
 
 func (r *PatchUserResponse) GetUser() *user.User {
@@ -37,3 +45,58 @@ func (r *PatchUserResponse) GetUser() *user.User {
 	}
 	return r.User
 }
+
+////////////////
+
+func (r *GetUserVersionsResponse) GetVersions() []*user.User {
+	if r == nil {
+		return nil
+	}
+	return r.Versions
+}
+
+////////////////
+
+func (r *GetUserVersionResponse) GetUser() *user.User {
+	if r == nil {
+		return nil
+	}
+	return r.User
+}
+
+////////////////
+
+func (r *GetUserChainResponse) GetUsers() []*user.User {
+	if r == nil {
+		return nil
+	}
+	return r.Users
+}
+
+func (r *GetUserSubordinatesResponse) GetSubordinates() []*user.Subordinate {
+	if r == nil {
+		return nil
+	}
+	return r.Subordinates
+}
+
+func (r *GetDeletedUsersResponse) GetUsers() []*user.User {
+	if r == nil {
+		return nil
+	}
+	return r.Users
+}
+
+func (r *RestoreUserResponse) GetUser() *user.User {
+	if r == nil {
+		return nil
+	}
+	return r.User
+}
+
+func (r *PurgeUsersResponse) GetIDs() []meta.ID {
+	if r == nil {
+		return nil
+	}
+	return r.IDs
+}
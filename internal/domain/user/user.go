@@ -29,3 +29,18 @@ func (u *User) Clone() *User {
 
 	return &clone
 }
+
+// SortKey is the user's key when listed by the sort field.
+// NOTE: users are listed by created or updated only
+func (u *User) SortKey(field meta.Sort) int64 {
+	if field == meta.SortUpdated {
+		return meta.TimeKey(u.GetMeta().GetUpdated())
+	}
+	return meta.TimeKey(u.GetMeta().GetCreated())
+}
+
+// Subordinate is a user together with the users reporting to them.
+type Subordinate struct {
+	User         *User          `json:"user,omitempty"`
+	Subordinates []*Subordinate `json:"subordinates,omitempty"`
+}
@@ -36,3 +36,19 @@ func (u *User) GetMeta() *meta.Meta {
 	}
 	return u.Meta
 }
+
+////////////////
+
+func (s *Subordinate) GetUser() *User {
+	if s == nil {
+		return nil
+	}
+	return s.User
+}
+
+func (s *Subordinate) GetSubordinates() []*Subordinate {
+	if s == nil {
+		return nil
+	}
+	return s.Subordinates
+}
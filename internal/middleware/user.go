@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/moledoc/orderly/pkg/consts"
+)
+
+// AddUserToCtxFromRequest stores the email of the user making the request, if the client provided it.
+// TODO: replace with authenticated user, once there is login
+func AddUserToCtxFromRequest(ctx context.Context, r *http.Request) context.Context {
+	if r == nil {
+		return ctx
+	}
+	if email := r.Header.Get(consts.UserEmail); len(email) > 0 {
+		ctx = context.WithValue(ctx, consts.CtxKeyUser, email)
+	}
+	return ctx
+}
+
+func GetUserFromCtx(ctx context.Context) string {
+	email, ok := ctx.Value(consts.CtxKeyUser).(string)
+	if !ok {
+		return ""
+	}
+	return email
+}
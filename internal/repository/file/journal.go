@@ -0,0 +1,279 @@
+package file
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Options tune the durability/performance trade-off of the file backed repositories.
+type Options struct {
+	SnapshotEvery uint // NOTE: nr of journal records after which a snapshot is taken, 0 disables periodic snapshots
+	SyncWrites    bool // NOTE: fsync journal after every record, otherwise only on snapshot and Close
+}
+
+var (
+	DefaultOptions = Options{
+		SnapshotEvery: 1000,
+		SyncWrites:    false,
+	}
+)
+
+const (
+	journalSuffix  = ".journal"
+	snapshotSuffix = ".snapshot"
+	tmpSuffix      = ".tmp"
+
+	recordHeaderLen = 8 // NOTE: uint32 payload length + uint32 crc32 of payload
+)
+
+type record struct {
+	Seq  uint64          `json:"seq"`
+	Op   string          `json:"op"`
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+type snapshot struct {
+	Seq   uint64          `json:"seq"`
+	State json.RawMessage `json:"state"`
+}
+
+// journal is an append-only log of repository operations together with the latest snapshot of the repository state.
+// Records are framed as [len][crc32][payload], so a torn record at the end of the log is detected and cut off on load.
+type journal struct {
+	opts Options
+
+	journalPath  string
+	snapshotPath string
+
+	f *os.File
+	w *bufio.Writer
+
+	seq           uint64
+	sinceSnapshot uint
+}
+
+func openJournal(dir string, name string, opts Options) (*journal, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("creating data dir failed: %w", err)
+	}
+	return &journal{
+		opts:         opts,
+		journalPath:  filepath.Join(dir, name+journalSuffix),
+		snapshotPath: filepath.Join(dir, name+snapshotSuffix),
+	}, nil
+}
+
+// load restores the latest snapshot, replays the journal on top of it and opens the journal for appending.
+func (j *journal) load(restore func(state []byte) error, apply func(op string, data []byte) error) error {
+	bs, err := os.ReadFile(j.snapshotPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("reading snapshot failed: %w", err)
+	}
+	if err == nil {
+		var snap snapshot
+		if err := json.Unmarshal(bs, &snap); err != nil {
+			return fmt.Errorf("decoding snapshot failed: %w", err)
+		}
+		if err := restore(snap.State); err != nil {
+			return fmt.Errorf("restoring snapshot failed: %w", err)
+		}
+		j.seq = snap.Seq
+	}
+
+	f, err := os.OpenFile(j.journalPath, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return fmt.Errorf("opening journal failed: %w", err)
+	}
+
+	offset, err := j.replay(f, apply)
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Truncate(offset); err != nil { // NOTE: drop torn tail, if there was one
+		f.Close()
+		return fmt.Errorf("truncating journal failed: %w", err)
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		f.Close()
+		return fmt.Errorf("seeking journal failed: %w", err)
+	}
+
+	j.f = f
+	j.w = bufio.NewWriter(f)
+	return nil
+}
+
+// replay applies every intact record and returns the offset after the last one.
+func (j *journal) replay(f *os.File, apply func(op string, data []byte) error) (int64, error) {
+	r := bufio.NewReader(f)
+	var offset int64
+	header := make([]byte, recordHeaderLen)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			if err != io.EOF {
+				fmt.Fprintf(os.Stderr, "[WARNING]: torn record header in '%s' at offset %v, discarding tail\n", j.journalPath, offset)
+			}
+			return offset, nil
+		}
+		length := binary.BigEndian.Uint32(header[0:4])
+		checksum := binary.BigEndian.Uint32(header[4:8])
+
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(r, payload); err != nil {
+			fmt.Fprintf(os.Stderr, "[WARNING]: torn record in '%s' at offset %v, discarding tail\n", j.journalPath, offset)
+			return offset, nil
+		}
+		if crc32.ChecksumIEEE(payload) != checksum {
+			fmt.Fprintf(os.Stderr, "[WARNING]: corrupt record in '%s' at offset %v, discarding tail\n", j.journalPath, offset)
+			return offset, nil
+		}
+
+		var rec record
+		if err := json.Unmarshal(payload, &rec); err != nil {
+			fmt.Fprintf(os.Stderr, "[WARNING]: undecodable record in '%s' at offset %v, discarding tail\n", j.journalPath, offset)
+			return offset, nil
+		}
+		offset += int64(recordHeaderLen) + int64(length)
+
+		if rec.Seq <= j.seq { // NOTE: already part of the snapshot
+			continue
+		}
+		if err := apply(rec.Op, rec.Data); err != nil {
+			return 0, fmt.Errorf("replaying record %v (%s) failed: %w", rec.Seq, rec.Op, err)
+		}
+		j.seq = rec.Seq
+		j.sinceSnapshot++
+	}
+}
+
+func (j *journal) append(op string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("encoding record data failed: %w", err)
+	}
+	payload, err := json.Marshal(&record{
+		Seq:  j.seq + 1,
+		Op:   op,
+		Data: data,
+	})
+	if err != nil {
+		return fmt.Errorf("encoding record failed: %w", err)
+	}
+
+	header := make([]byte, recordHeaderLen)
+	binary.BigEndian.PutUint32(header[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(header[4:8], crc32.ChecksumIEEE(payload))
+
+	if _, err := j.w.Write(header); err != nil {
+		return fmt.Errorf("writing record failed: %w", err)
+	}
+	if _, err := j.w.Write(payload); err != nil {
+		return fmt.Errorf("writing record failed: %w", err)
+	}
+	if err := j.w.Flush(); err != nil {
+		return fmt.Errorf("flushing journal failed: %w", err)
+	}
+	if j.opts.SyncWrites {
+		if err := j.f.Sync(); err != nil {
+			return fmt.Errorf("syncing journal failed: %w", err)
+		}
+	}
+
+	j.seq++
+	j.sinceSnapshot++
+	return nil
+}
+
+func (j *journal) needsSnapshot() bool {
+	return j.opts.SnapshotEvery > 0 && j.sinceSnapshot >= j.opts.SnapshotEvery
+}
+
+// snapshot atomically replaces the snapshot file with state and truncates the journal.
+// The snapshot records the last applied seq, so a crash before truncation doesn't replay records twice.
+func (j *journal) snapshot(state []byte) error {
+	bs, err := json.Marshal(&snapshot{
+		Seq:   j.seq,
+		State: state,
+	})
+	if err != nil {
+		return fmt.Errorf("encoding snapshot failed: %w", err)
+	}
+
+	tmpPath := j.snapshotPath + tmpSuffix
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("creating snapshot failed: %w", err)
+	}
+	if _, err := tmp.Write(bs); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing snapshot failed: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("syncing snapshot failed: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("closing snapshot failed: %w", err)
+	}
+	if err := os.Rename(tmpPath, j.snapshotPath); err != nil {
+		return fmt.Errorf("renaming snapshot failed: %w", err)
+	}
+	if err := syncDir(filepath.Dir(j.snapshotPath)); err != nil {
+		return err
+	}
+
+	if err := j.w.Flush(); err != nil {
+		return fmt.Errorf("flushing journal failed: %w", err)
+	}
+	if err := j.f.Truncate(0); err != nil {
+		return fmt.Errorf("truncating journal failed: %w", err)
+	}
+	if _, err := j.f.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("seeking journal failed: %w", err)
+	}
+	if err := j.f.Sync(); err != nil {
+		return fmt.Errorf("syncing journal failed: %w", err)
+	}
+	j.w.Reset(j.f)
+	j.sinceSnapshot = 0
+	return nil
+}
+
+func (j *journal) close() error {
+	if j.f == nil {
+		return nil
+	}
+	if err := j.w.Flush(); err != nil {
+		return fmt.Errorf("flushing journal failed: %w", err)
+	}
+	if err := j.f.Sync(); err != nil {
+		return fmt.Errorf("syncing journal failed: %w", err)
+	}
+	err := j.f.Close()
+	j.f = nil
+	if err != nil {
+		return fmt.Errorf("closing journal failed: %w", err)
+	}
+	return nil
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("opening data dir failed: %w", err)
+	}
+	defer d.Close()
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("syncing data dir failed: %w", err)
+	}
+	return nil
+}
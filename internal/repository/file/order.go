@@ -0,0 +1,541 @@
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/moledoc/orderly/internal/domain/errwrap"
+	"github.com/moledoc/orderly/internal/domain/meta"
+	"github.com/moledoc/orderly/internal/domain/order"
+	"github.com/moledoc/orderly/internal/domain/request"
+	"github.com/moledoc/orderly/internal/middleware"
+	"github.com/moledoc/orderly/internal/repository"
+	"github.com/moledoc/orderly/internal/repository/local"
+)
+
+const (
+	orderJournalName = "orders"
+
+	opCreateOrder          = "create_order"
+	opUpdateTask           = "update_task"
+	opAppendDelegatedTasks = "append_delegated_tasks"
+	opRemoveDelegatedTasks = "remove_delegated_tasks"
+	opAppendSitReps        = "append_sitreps"
+	opUpdateSitRep         = "update_sitrep"
+	opRemoveSitReps        = "remove_sitreps"
+	opReparent             = "reparent"
+	opUpdateRollupPolicy   = "update_rollup_policy"
+	opUpdateMeta           = "update_meta"
+	opDeleteOrder          = "delete_order"
+	opDeleteTasks          = "delete_tasks"
+	opDeleteSitReps        = "delete_sitreps"
+)
+
+// orderChange is the journaled data of an operation that changes a stored order.
+type orderChange struct {
+	ID       meta.ID             `json:"id"`
+	ParentID meta.ID             `json:"parent_id,omitempty"`
+	Task     *order.Task         `json:"task,omitempty"`
+	Tasks    []*order.Task       `json:"tasks,omitempty"`
+	SitRep   *order.SitRep       `json:"sitrep,omitempty"`
+	SitReps  []*order.SitRep     `json:"sitreps,omitempty"`
+	IDs      []meta.ID           `json:"ids,omitempty"`
+	Policy   *order.RollupPolicy `json:"policy,omitempty"`
+	Meta     *meta.Meta          `json:"meta,omitempty"`
+}
+
+// apply makes the journaled change through the repository.
+func (c *orderChange) apply(ctx context.Context, op string, repo repository.RepositoryOrderAPI) (*order.Order, errwrap.Error) {
+	switch op {
+	case opUpdateTask:
+		return repo.UpdateTask(ctx, c.ID, c.Task, c.Meta)
+	case opAppendDelegatedTasks:
+		return repo.AppendDelegatedTasks(ctx, c.ID, c.Tasks, c.Meta)
+	case opRemoveDelegatedTasks:
+		return repo.RemoveDelegatedTasks(ctx, c.ID, c.IDs, c.Meta)
+	case opAppendSitReps:
+		return repo.AppendSitReps(ctx, c.ID, c.SitReps, c.Meta)
+	case opUpdateSitRep:
+		return repo.UpdateSitRep(ctx, c.ID, c.SitRep, c.Meta)
+	case opRemoveSitReps:
+		return repo.RemoveSitReps(ctx, c.ID, c.IDs, c.Meta)
+	case opReparent:
+		return repo.Reparent(ctx, c.ID, c.ParentID, c.Meta)
+	case opUpdateRollupPolicy:
+		return repo.UpdateRollupPolicy(ctx, c.ID, c.Policy, c.Meta)
+	case opUpdateMeta:
+		return repo.UpdateMeta(ctx, c.ID, c.Meta)
+	}
+	return nil, errwrap.NewError(http.StatusInternalServerError, "unknown op '%s'", op)
+}
+
+// FileRepositoryOrder keeps orders in memory and persists every change to a journal in the data dir.
+type FileRepositoryOrder struct {
+	mu      sync.Mutex
+	mem     repository.RepositoryOrderAPI
+	journal *journal
+	ops     *txOps // NOTE: set on the repository bound to a transaction, see WithTx
+}
+
+var (
+	_ repository.RepositoryOrderAPI = (*FileRepositoryOrder)(nil)
+)
+
+func NewFileRepositoryOrder(dir string, opts Options) (*FileRepositoryOrder, errwrap.Error) {
+	j, err := openJournal(dir, orderJournalName, opts)
+	if err != nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "file repository order: %s", err)
+	}
+	mem := local.NewLocalRepositoryOrder()
+	r := &FileRepositoryOrder{
+		mu:      sync.Mutex{},
+		mem:     mem,
+		journal: j,
+	}
+	if err := j.load(mem.UnmarshalJSON, r.apply); err != nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "file repository order: %s", err)
+	}
+	return r, nil
+}
+
+func (r *FileRepositoryOrder) apply(op string, data []byte) error {
+	ctx := context.Background()
+	switch op {
+	case opCreateOrder:
+		var o order.Order
+		if err := json.Unmarshal(data, &o); err != nil {
+			return err
+		}
+		if _, err := r.mem.CreateOrder(ctx, &o); err != nil {
+			return err
+		}
+	case opUpdateTask, opAppendDelegatedTasks, opRemoveDelegatedTasks, opAppendSitReps, opUpdateSitRep, opRemoveSitReps, opReparent, opUpdateRollupPolicy, opUpdateMeta:
+		var c orderChange
+		if err := json.Unmarshal(data, &c); err != nil {
+			return err
+		}
+		if _, err := c.apply(ctx, op, r.mem); err != nil {
+			return err
+		}
+	case opDeleteOrder:
+		var id meta.ID
+		if err := json.Unmarshal(data, &id); err != nil {
+			return err
+		}
+		if err := r.mem.DeleteOrder(ctx, id); err != nil {
+			return err
+		}
+	case opDeleteTasks:
+		var ids []meta.ID
+		if err := json.Unmarshal(data, &ids); err != nil {
+			return err
+		}
+		if _, err := r.mem.DeleteTasks(ctx, ids); err != nil {
+			return err
+		}
+	case opDeleteSitReps:
+		var ids []meta.ID
+		if err := json.Unmarshal(data, &ids); err != nil {
+			return err
+		}
+		if _, err := r.mem.DeleteSitReps(ctx, ids); err != nil {
+			return err
+		}
+	case opTx:
+		if err := applyTx(data, r.apply); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown op '%s'", op)
+	}
+	return nil
+}
+
+// record journals the op, or keeps it for the transaction's journal entry.
+// Changes are applied in memory first and journaled after, see change; deletes are journaled before they're applied.
+// NOTE: caller must hold r.mu
+func (r *FileRepositoryOrder) record(op string, v any) errwrap.Error {
+	if r.ops != nil { // NOTE: journaled on commit, see WithTx
+		if err := r.ops.add(op, v); err != nil {
+			return errwrap.NewError(http.StatusInternalServerError, "file repository order: %s", err)
+		}
+		return nil
+	}
+	if err := r.journal.append(op, v); err != nil {
+		return errwrap.NewError(http.StatusInternalServerError, "file repository order: %s", err)
+	}
+	return nil
+}
+
+// change makes the change in memory and journals it.
+// NOTE: a change that fails, in memory or in the journal, is rolled back, so only changes that replay cleanly are journaled
+func (r *FileRepositoryOrder) change(ctx context.Context, op string, v any, fn func(mem repository.RepositoryOrderAPI) (*order.Order, errwrap.Error)) (*order.Order, errwrap.Error) {
+	r.lock()
+	defer r.unlock()
+
+	var resp *order.Order
+	err := r.mem.WithTx(ctx, func(mem repository.RepositoryOrderAPI) errwrap.Error {
+		var err errwrap.Error
+		resp, err = fn(mem)
+		if err != nil {
+			return err
+		}
+		return r.record(op, v)
+	})
+	if err != nil {
+		return nil, err
+	}
+	r.snapshotIfDue()
+	return resp, nil
+}
+
+// NOTE: caller must hold r.mu
+func (r *FileRepositoryOrder) snapshotIfDue() {
+	if r.ops != nil || !r.journal.needsSnapshot() {
+		return
+	}
+	if err := r.snapshot(); err != nil {
+		fmt.Printf("[WARNING]: %s\n", err) // NOTE: journal is still intact, so nothing is lost
+	}
+}
+
+// NOTE: caller must hold r.mu
+func (r *FileRepositoryOrder) snapshot() errwrap.Error {
+	state, err := json.Marshal(r.mem)
+	if err != nil {
+		return errwrap.NewError(http.StatusInternalServerError, "file repository order: encoding state failed: %s", err)
+	}
+	if err := r.journal.snapshot(state); err != nil {
+		return errwrap.NewError(http.StatusInternalServerError, "file repository order: %s", err)
+	}
+	return nil
+}
+
+// NOTE: repository bound to a transaction runs under the lock held by WithTx
+func (r *FileRepositoryOrder) lock() {
+	if r.ops == nil {
+		r.mu.Lock()
+	}
+}
+
+func (r *FileRepositoryOrder) unlock() {
+	if r.ops == nil {
+		r.mu.Unlock()
+	}
+}
+
+func (r *FileRepositoryOrder) WithTx(ctx context.Context, fn func(tx repository.RepositoryOrderAPI) errwrap.Error) errwrap.Error {
+	middleware.SpanStart(ctx, "FileRepositoryOrder:WithTx")
+	defer middleware.SpanStop(ctx, "FileRepositoryOrder:WithTx")
+
+	if r == nil {
+		return errwrap.NewError(http.StatusInternalServerError, "file repository order uninitialized")
+	}
+	if r.ops != nil { // NOTE: join ongoing transaction
+		return fn(r)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	err := r.mem.WithTx(ctx, func(memTx repository.RepositoryOrderAPI) errwrap.Error {
+		tx := &FileRepositoryOrder{
+			mem: memTx,
+			ops: &txOps{},
+		}
+		if err := fn(tx); err != nil {
+			return err
+		}
+		if len(*tx.ops) == 0 {
+			return nil
+		}
+		return r.record(opTx, tx.ops) // NOTE: failing to journal rolls back the in-memory changes
+	})
+	if err != nil {
+		return err
+	}
+	r.snapshotIfDue()
+	return nil
+}
+
+func (r *FileRepositoryOrder) Close(ctx context.Context) errwrap.Error {
+	middleware.SpanStart(ctx, "FileRepositoryOrder:Close")
+	defer middleware.SpanStop(ctx, "FileRepositoryOrder:Close")
+
+	if r == nil {
+		return errwrap.NewError(http.StatusInternalServerError, "file repository order uninitialized")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.snapshot(); err != nil {
+		return err
+	}
+	if err := r.journal.close(); err != nil {
+		return errwrap.NewError(http.StatusInternalServerError, "file repository order: %s", err)
+	}
+	return r.mem.Close(ctx)
+}
+
+func (r *FileRepositoryOrder) ReadByID(ctx context.Context, id meta.ID) (*order.Order, errwrap.Error) {
+	middleware.SpanStart(ctx, "FileRepositoryOrder:ReadByID")
+	defer middleware.SpanStop(ctx, "FileRepositoryOrder:ReadByID")
+
+	if r == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "file repository order uninitialized")
+	}
+	return r.mem.ReadByID(ctx, id)
+}
+
+func (r *FileRepositoryOrder) ReadBy(ctx context.Context, req *request.GetOrdersRequest) ([]*order.Order, *meta.Page, errwrap.Error) {
+	middleware.SpanStart(ctx, "FileRepositoryOrder:ReadBy")
+	defer middleware.SpanStop(ctx, "FileRepositoryOrder:ReadBy")
+
+	if r == nil {
+		return nil, nil, errwrap.NewError(http.StatusInternalServerError, "file repository order uninitialized")
+	}
+	return r.mem.ReadBy(ctx, req)
+}
+
+func (r *FileRepositoryOrder) Search(ctx context.Context, req *request.SearchRequest) ([]*order.SearchHit, errwrap.Error) {
+	middleware.SpanStart(ctx, "FileRepositoryOrder:Search")
+	defer middleware.SpanStop(ctx, "FileRepositoryOrder:Search")
+
+	if r == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "file repository order uninitialized")
+	}
+	return r.mem.Search(ctx, req)
+}
+
+func (r *FileRepositoryOrder) ReadDeletedByID(ctx context.Context, id meta.ID) (*order.Order, errwrap.Error) {
+	middleware.SpanStart(ctx, "FileRepositoryOrder:ReadDeletedByID")
+	defer middleware.SpanStop(ctx, "FileRepositoryOrder:ReadDeletedByID")
+
+	if r == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "file repository order uninitialized")
+	}
+	return r.mem.ReadDeletedByID(ctx, id)
+}
+
+func (r *FileRepositoryOrder) ReadDeleted(ctx context.Context) ([]*order.Order, errwrap.Error) {
+	middleware.SpanStart(ctx, "FileRepositoryOrder:ReadDeleted")
+	defer middleware.SpanStop(ctx, "FileRepositoryOrder:ReadDeleted")
+
+	if r == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "file repository order uninitialized")
+	}
+	return r.mem.ReadDeleted(ctx)
+}
+
+func (r *FileRepositoryOrder) ReadVersions(ctx context.Context, id meta.ID) ([]*order.Order, errwrap.Error) {
+	middleware.SpanStart(ctx, "FileRepositoryOrder:ReadVersions")
+	defer middleware.SpanStop(ctx, "FileRepositoryOrder:ReadVersions")
+
+	if r == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "file repository order uninitialized")
+	}
+	return r.mem.ReadVersions(ctx, id)
+}
+
+func (r *FileRepositoryOrder) ReadVersion(ctx context.Context, id meta.ID, version uint) (*order.Order, errwrap.Error) {
+	middleware.SpanStart(ctx, "FileRepositoryOrder:ReadVersion")
+	defer middleware.SpanStop(ctx, "FileRepositoryOrder:ReadVersion")
+
+	if r == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "file repository order uninitialized")
+	}
+	return r.mem.ReadVersion(ctx, id, version)
+}
+
+func (r *FileRepositoryOrder) CreateOrder(ctx context.Context, o *order.Order) (*order.Order, errwrap.Error) {
+	middleware.SpanStart(ctx, "FileRepositoryOrder:CreateOrder")
+	defer middleware.SpanStop(ctx, "FileRepositoryOrder:CreateOrder")
+
+	if r == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "file repository order uninitialized")
+	}
+	return r.change(ctx, opCreateOrder, o, func(mem repository.RepositoryOrderAPI) (*order.Order, errwrap.Error) {
+		return mem.CreateOrder(ctx, o)
+	})
+}
+
+func (r *FileRepositoryOrder) UpdateTask(ctx context.Context, id meta.ID, task *order.Task, m *meta.Meta) (*order.Order, errwrap.Error) {
+	middleware.SpanStart(ctx, "FileRepositoryOrder:UpdateTask")
+	defer middleware.SpanStop(ctx, "FileRepositoryOrder:UpdateTask")
+
+	if r == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "file repository order uninitialized")
+	}
+	c := &orderChange{ID: id, Task: task, Meta: m}
+	return r.change(ctx, opUpdateTask, c, func(mem repository.RepositoryOrderAPI) (*order.Order, errwrap.Error) {
+		return c.apply(ctx, opUpdateTask, mem)
+	})
+}
+
+func (r *FileRepositoryOrder) AppendDelegatedTasks(ctx context.Context, id meta.ID, tasks []*order.Task, m *meta.Meta) (*order.Order, errwrap.Error) {
+	middleware.SpanStart(ctx, "FileRepositoryOrder:AppendDelegatedTasks")
+	defer middleware.SpanStop(ctx, "FileRepositoryOrder:AppendDelegatedTasks")
+
+	if r == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "file repository order uninitialized")
+	}
+	c := &orderChange{ID: id, Tasks: tasks, Meta: m}
+	return r.change(ctx, opAppendDelegatedTasks, c, func(mem repository.RepositoryOrderAPI) (*order.Order, errwrap.Error) {
+		return c.apply(ctx, opAppendDelegatedTasks, mem)
+	})
+}
+
+func (r *FileRepositoryOrder) RemoveDelegatedTasks(ctx context.Context, id meta.ID, taskIDs []meta.ID, m *meta.Meta) (*order.Order, errwrap.Error) {
+	middleware.SpanStart(ctx, "FileRepositoryOrder:RemoveDelegatedTasks")
+	defer middleware.SpanStop(ctx, "FileRepositoryOrder:RemoveDelegatedTasks")
+
+	if r == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "file repository order uninitialized")
+	}
+	c := &orderChange{ID: id, IDs: taskIDs, Meta: m}
+	return r.change(ctx, opRemoveDelegatedTasks, c, func(mem repository.RepositoryOrderAPI) (*order.Order, errwrap.Error) {
+		return c.apply(ctx, opRemoveDelegatedTasks, mem)
+	})
+}
+
+func (r *FileRepositoryOrder) AppendSitReps(ctx context.Context, id meta.ID, sitreps []*order.SitRep, m *meta.Meta) (*order.Order, errwrap.Error) {
+	middleware.SpanStart(ctx, "FileRepositoryOrder:AppendSitReps")
+	defer middleware.SpanStop(ctx, "FileRepositoryOrder:AppendSitReps")
+
+	if r == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "file repository order uninitialized")
+	}
+	c := &orderChange{ID: id, SitReps: sitreps, Meta: m}
+	return r.change(ctx, opAppendSitReps, c, func(mem repository.RepositoryOrderAPI) (*order.Order, errwrap.Error) {
+		return c.apply(ctx, opAppendSitReps, mem)
+	})
+}
+
+func (r *FileRepositoryOrder) UpdateSitRep(ctx context.Context, id meta.ID, sitrep *order.SitRep, m *meta.Meta) (*order.Order, errwrap.Error) {
+	middleware.SpanStart(ctx, "FileRepositoryOrder:UpdateSitRep")
+	defer middleware.SpanStop(ctx, "FileRepositoryOrder:UpdateSitRep")
+
+	if r == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "file repository order uninitialized")
+	}
+	c := &orderChange{ID: id, SitRep: sitrep, Meta: m}
+	return r.change(ctx, opUpdateSitRep, c, func(mem repository.RepositoryOrderAPI) (*order.Order, errwrap.Error) {
+		return c.apply(ctx, opUpdateSitRep, mem)
+	})
+}
+
+func (r *FileRepositoryOrder) RemoveSitReps(ctx context.Context, id meta.ID, sitrepIDs []meta.ID, m *meta.Meta) (*order.Order, errwrap.Error) {
+	middleware.SpanStart(ctx, "FileRepositoryOrder:RemoveSitReps")
+	defer middleware.SpanStop(ctx, "FileRepositoryOrder:RemoveSitReps")
+
+	if r == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "file repository order uninitialized")
+	}
+	c := &orderChange{ID: id, IDs: sitrepIDs, Meta: m}
+	return r.change(ctx, opRemoveSitReps, c, func(mem repository.RepositoryOrderAPI) (*order.Order, errwrap.Error) {
+		return c.apply(ctx, opRemoveSitReps, mem)
+	})
+}
+
+func (r *FileRepositoryOrder) Reparent(ctx context.Context, id meta.ID, parentID meta.ID, m *meta.Meta) (*order.Order, errwrap.Error) {
+	middleware.SpanStart(ctx, "FileRepositoryOrder:Reparent")
+	defer middleware.SpanStop(ctx, "FileRepositoryOrder:Reparent")
+
+	if r == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "file repository order uninitialized")
+	}
+	c := &orderChange{ID: id, ParentID: parentID, Meta: m}
+	return r.change(ctx, opReparent, c, func(mem repository.RepositoryOrderAPI) (*order.Order, errwrap.Error) {
+		return c.apply(ctx, opReparent, mem)
+	})
+}
+
+func (r *FileRepositoryOrder) UpdateRollupPolicy(ctx context.Context, id meta.ID, policy *order.RollupPolicy, m *meta.Meta) (*order.Order, errwrap.Error) {
+	middleware.SpanStart(ctx, "FileRepositoryOrder:UpdateRollupPolicy")
+	defer middleware.SpanStop(ctx, "FileRepositoryOrder:UpdateRollupPolicy")
+
+	if r == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "file repository order uninitialized")
+	}
+	c := &orderChange{ID: id, Policy: policy, Meta: m}
+	return r.change(ctx, opUpdateRollupPolicy, c, func(mem repository.RepositoryOrderAPI) (*order.Order, errwrap.Error) {
+		return c.apply(ctx, opUpdateRollupPolicy, mem)
+	})
+}
+
+func (r *FileRepositoryOrder) UpdateMeta(ctx context.Context, id meta.ID, m *meta.Meta) (*order.Order, errwrap.Error) {
+	middleware.SpanStart(ctx, "FileRepositoryOrder:UpdateMeta")
+	defer middleware.SpanStop(ctx, "FileRepositoryOrder:UpdateMeta")
+
+	if r == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "file repository order uninitialized")
+	}
+	c := &orderChange{ID: id, Meta: m}
+	return r.change(ctx, opUpdateMeta, c, func(mem repository.RepositoryOrderAPI) (*order.Order, errwrap.Error) {
+		return c.apply(ctx, opUpdateMeta, mem)
+	})
+}
+
+func (r *FileRepositoryOrder) DeleteOrder(ctx context.Context, id meta.ID) errwrap.Error {
+	middleware.SpanStart(ctx, "FileRepositoryOrder:DeleteOrder")
+	defer middleware.SpanStop(ctx, "FileRepositoryOrder:DeleteOrder")
+
+	if r == nil {
+		return errwrap.NewError(http.StatusInternalServerError, "file repository order uninitialized")
+	}
+	r.lock()
+	defer r.unlock()
+
+	if err := r.record(opDeleteOrder, id); err != nil {
+		return err
+	}
+	if err := r.mem.DeleteOrder(ctx, id); err != nil {
+		return err
+	}
+	r.snapshotIfDue()
+	return nil
+}
+
+func (r *FileRepositoryOrder) DeleteTasks(ctx context.Context, ids []meta.ID) (bool, errwrap.Error) {
+	middleware.SpanStart(ctx, "FileRepositoryOrder:DeleteTasks")
+	defer middleware.SpanStop(ctx, "FileRepositoryOrder:DeleteTasks")
+
+	if r == nil {
+		return false, errwrap.NewError(http.StatusInternalServerError, "file repository order uninitialized")
+	}
+	r.lock()
+	defer r.unlock()
+
+	if err := r.record(opDeleteTasks, ids); err != nil {
+		return false, err
+	}
+	didDelete, err := r.mem.DeleteTasks(ctx, ids)
+	if err != nil {
+		return false, err
+	}
+	r.snapshotIfDue()
+	return didDelete, nil
+}
+
+func (r *FileRepositoryOrder) DeleteSitReps(ctx context.Context, ids []meta.ID) (bool, errwrap.Error) {
+	middleware.SpanStart(ctx, "FileRepositoryOrder:DeleteSitReps")
+	defer middleware.SpanStop(ctx, "FileRepositoryOrder:DeleteSitReps")
+
+	if r == nil {
+		return false, errwrap.NewError(http.StatusInternalServerError, "file repository order uninitialized")
+	}
+	r.lock()
+	defer r.unlock()
+
+	if err := r.record(opDeleteSitReps, ids); err != nil {
+		return false, err
+	}
+	didDelete, err := r.mem.DeleteSitReps(ctx, ids)
+	if err != nil {
+		return false, err
+	}
+	r.snapshotIfDue()
+	return didDelete, nil
+}
@@ -0,0 +1,42 @@
+package file
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	opTx = "tx"
+)
+
+// txOp is an op made within a transaction.
+// The ops of a transaction are journaled together as a single record, so a transaction torn by a crash is dropped as a whole on replay.
+type txOp struct {
+	Op   string          `json:"op"`
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+type txOps []txOp
+
+func (ops *txOps) add(op string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("encoding record data failed: %w", err)
+	}
+	*ops = append(*ops, txOp{Op: op, Data: data})
+	return nil
+}
+
+// applyTx replays the ops of a journaled transaction.
+func applyTx(data []byte, apply func(op string, data []byte) error) error {
+	var ops txOps
+	if err := json.Unmarshal(data, &ops); err != nil {
+		return err
+	}
+	for _, op := range ops {
+		if err := apply(op.Op, op.Data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
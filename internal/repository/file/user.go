@@ -0,0 +1,316 @@
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/moledoc/orderly/internal/domain/errwrap"
+	"github.com/moledoc/orderly/internal/domain/meta"
+	"github.com/moledoc/orderly/internal/domain/request"
+	"github.com/moledoc/orderly/internal/domain/user"
+	"github.com/moledoc/orderly/internal/middleware"
+	"github.com/moledoc/orderly/internal/repository"
+	"github.com/moledoc/orderly/internal/repository/local"
+)
+
+const (
+	userJournalName = "users"
+
+	opCreateUser = "create_user"
+	opUpdateUser = "update_user"
+	opDeleteUser = "delete_user"
+)
+
+// FileRepositoryUser keeps users in memory and persists every change to a journal in the data dir.
+type FileRepositoryUser struct {
+	mu      sync.Mutex
+	mem     repository.RepositoryUserAPI
+	journal *journal
+	ops     *txOps // NOTE: set on the repository bound to a transaction, see WithTx
+}
+
+var (
+	_ repository.RepositoryUserAPI = (*FileRepositoryUser)(nil)
+)
+
+func NewFileRepositoryUser(dir string, opts Options) (*FileRepositoryUser, errwrap.Error) {
+	j, err := openJournal(dir, userJournalName, opts)
+	if err != nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "file repository user: %s", err)
+	}
+	mem := local.NewLocalRepositoryUser()
+	r := &FileRepositoryUser{
+		mu:      sync.Mutex{},
+		mem:     mem,
+		journal: j,
+	}
+	if err := j.load(mem.UnmarshalJSON, r.apply); err != nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "file repository user: %s", err)
+	}
+	return r, nil
+}
+
+func (r *FileRepositoryUser) apply(op string, data []byte) error {
+	ctx := context.Background()
+	switch op {
+	case opCreateUser, opUpdateUser:
+		var u user.User
+		if err := json.Unmarshal(data, &u); err != nil {
+			return err
+		}
+		write := r.mem.CreateUser
+		if op == opUpdateUser {
+			write = r.mem.UpdateUser
+		}
+		if _, err := write(ctx, &u); err != nil {
+			return err
+		}
+	case opDeleteUser:
+		var id meta.ID
+		if err := json.Unmarshal(data, &id); err != nil {
+			return err
+		}
+		if err := r.mem.Delete(ctx, id); err != nil {
+			return err
+		}
+	case opTx:
+		if err := applyTx(data, r.apply); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown op '%s'", op)
+	}
+	return nil
+}
+
+// record journals the op, or keeps it for the transaction's journal entry.
+// Changes are applied in memory first and journaled after, see write; deletes are journaled before they're applied.
+// NOTE: caller must hold r.mu
+func (r *FileRepositoryUser) record(op string, v any) errwrap.Error {
+	if r.ops != nil { // NOTE: journaled on commit, see WithTx
+		if err := r.ops.add(op, v); err != nil {
+			return errwrap.NewError(http.StatusInternalServerError, "file repository user: %s", err)
+		}
+		return nil
+	}
+	if err := r.journal.append(op, v); err != nil {
+		return errwrap.NewError(http.StatusInternalServerError, "file repository user: %s", err)
+	}
+	return nil
+}
+
+// NOTE: caller must hold r.mu
+func (r *FileRepositoryUser) snapshotIfDue() {
+	if r.ops != nil || !r.journal.needsSnapshot() {
+		return
+	}
+	if err := r.snapshot(); err != nil {
+		fmt.Printf("[WARNING]: %s\n", err) // NOTE: journal is still intact, so nothing is lost
+	}
+}
+
+// NOTE: caller must hold r.mu
+func (r *FileRepositoryUser) snapshot() errwrap.Error {
+	state, err := json.Marshal(r.mem)
+	if err != nil {
+		return errwrap.NewError(http.StatusInternalServerError, "file repository user: encoding state failed: %s", err)
+	}
+	if err := r.journal.snapshot(state); err != nil {
+		return errwrap.NewError(http.StatusInternalServerError, "file repository user: %s", err)
+	}
+	return nil
+}
+
+// NOTE: repository bound to a transaction runs under the lock held by WithTx
+func (r *FileRepositoryUser) lock() {
+	if r.ops == nil {
+		r.mu.Lock()
+	}
+}
+
+func (r *FileRepositoryUser) unlock() {
+	if r.ops == nil {
+		r.mu.Unlock()
+	}
+}
+
+func (r *FileRepositoryUser) WithTx(ctx context.Context, fn func(tx repository.RepositoryUserAPI) errwrap.Error) errwrap.Error {
+	middleware.SpanStart(ctx, "FileRepositoryUser:WithTx")
+	defer middleware.SpanStop(ctx, "FileRepositoryUser:WithTx")
+
+	if r == nil {
+		return errwrap.NewError(http.StatusInternalServerError, "file repository user uninitialized")
+	}
+	if r.ops != nil { // NOTE: join ongoing transaction
+		return fn(r)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	err := r.mem.WithTx(ctx, func(memTx repository.RepositoryUserAPI) errwrap.Error {
+		tx := &FileRepositoryUser{
+			mem: memTx,
+			ops: &txOps{},
+		}
+		if err := fn(tx); err != nil {
+			return err
+		}
+		if len(*tx.ops) == 0 {
+			return nil
+		}
+		return r.record(opTx, tx.ops) // NOTE: failing to journal rolls back the in-memory changes
+	})
+	if err != nil {
+		return err
+	}
+	r.snapshotIfDue()
+	return nil
+}
+
+func (r *FileRepositoryUser) Close(ctx context.Context) errwrap.Error {
+	middleware.SpanStart(ctx, "FileRepositoryUser:Close")
+	defer middleware.SpanStop(ctx, "FileRepositoryUser:Close")
+
+	if r == nil {
+		return errwrap.NewError(http.StatusInternalServerError, "file repository user uninitialized")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.snapshot(); err != nil {
+		return err
+	}
+	if err := r.journal.close(); err != nil {
+		return errwrap.NewError(http.StatusInternalServerError, "file repository user: %s", err)
+	}
+	return r.mem.Close(ctx)
+}
+
+func (r *FileRepositoryUser) ReadByID(ctx context.Context, id meta.ID) (*user.User, errwrap.Error) {
+	middleware.SpanStart(ctx, "FileRepositoryUser:ReadByID")
+	defer middleware.SpanStop(ctx, "FileRepositoryUser:ReadByID")
+
+	if r == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "file repository user uninitialized")
+	}
+	return r.mem.ReadByID(ctx, id)
+}
+
+func (r *FileRepositoryUser) ReadBy(ctx context.Context, req *request.GetUsersRequest) ([]*user.User, *meta.Page, errwrap.Error) {
+	middleware.SpanStart(ctx, "FileRepositoryUser:ReadBy")
+	defer middleware.SpanStop(ctx, "FileRepositoryUser:ReadBy")
+
+	if r == nil {
+		return nil, nil, errwrap.NewError(http.StatusInternalServerError, "file repository user uninitialized")
+	}
+	return r.mem.ReadBy(ctx, req)
+}
+
+func (r *FileRepositoryUser) ReadDeletedByID(ctx context.Context, id meta.ID) (*user.User, errwrap.Error) {
+	middleware.SpanStart(ctx, "FileRepositoryUser:ReadDeletedByID")
+	defer middleware.SpanStop(ctx, "FileRepositoryUser:ReadDeletedByID")
+
+	if r == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "file repository user uninitialized")
+	}
+	return r.mem.ReadDeletedByID(ctx, id)
+}
+
+func (r *FileRepositoryUser) ReadDeleted(ctx context.Context) ([]*user.User, errwrap.Error) {
+	middleware.SpanStart(ctx, "FileRepositoryUser:ReadDeleted")
+	defer middleware.SpanStop(ctx, "FileRepositoryUser:ReadDeleted")
+
+	if r == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "file repository user uninitialized")
+	}
+	return r.mem.ReadDeleted(ctx)
+}
+
+func (r *FileRepositoryUser) ReadVersions(ctx context.Context, id meta.ID) ([]*user.User, errwrap.Error) {
+	middleware.SpanStart(ctx, "FileRepositoryUser:ReadVersions")
+	defer middleware.SpanStop(ctx, "FileRepositoryUser:ReadVersions")
+
+	if r == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "file repository user uninitialized")
+	}
+	return r.mem.ReadVersions(ctx, id)
+}
+
+func (r *FileRepositoryUser) ReadVersion(ctx context.Context, id meta.ID, version uint) (*user.User, errwrap.Error) {
+	middleware.SpanStart(ctx, "FileRepositoryUser:ReadVersion")
+	defer middleware.SpanStop(ctx, "FileRepositoryUser:ReadVersion")
+
+	if r == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "file repository user uninitialized")
+	}
+	return r.mem.ReadVersion(ctx, id, version)
+}
+
+// write makes the change in memory and journals it.
+// NOTE: a change that fails, in memory or in the journal, is rolled back, so only changes that replay cleanly are journaled
+func (r *FileRepositoryUser) write(ctx context.Context, op string, u *user.User, fn func(mem repository.RepositoryUserAPI) (*user.User, errwrap.Error)) (*user.User, errwrap.Error) {
+	r.lock()
+	defer r.unlock()
+
+	var resp *user.User
+	err := r.mem.WithTx(ctx, func(mem repository.RepositoryUserAPI) errwrap.Error {
+		var err errwrap.Error
+		resp, err = fn(mem)
+		if err != nil {
+			return err
+		}
+		return r.record(op, u)
+	})
+	if err != nil {
+		return nil, err
+	}
+	r.snapshotIfDue()
+	return resp, nil
+}
+
+func (r *FileRepositoryUser) CreateUser(ctx context.Context, u *user.User) (*user.User, errwrap.Error) {
+	middleware.SpanStart(ctx, "FileRepositoryUser:CreateUser")
+	defer middleware.SpanStop(ctx, "FileRepositoryUser:CreateUser")
+
+	if r == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "file repository user uninitialized")
+	}
+	return r.write(ctx, opCreateUser, u, func(mem repository.RepositoryUserAPI) (*user.User, errwrap.Error) {
+		return mem.CreateUser(ctx, u)
+	})
+}
+
+func (r *FileRepositoryUser) UpdateUser(ctx context.Context, u *user.User) (*user.User, errwrap.Error) {
+	middleware.SpanStart(ctx, "FileRepositoryUser:UpdateUser")
+	defer middleware.SpanStop(ctx, "FileRepositoryUser:UpdateUser")
+
+	if r == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "file repository user uninitialized")
+	}
+	return r.write(ctx, opUpdateUser, u, func(mem repository.RepositoryUserAPI) (*user.User, errwrap.Error) {
+		return mem.UpdateUser(ctx, u)
+	})
+}
+
+func (r *FileRepositoryUser) Delete(ctx context.Context, id meta.ID) errwrap.Error {
+	middleware.SpanStart(ctx, "FileRepositoryUser:Delete")
+	defer middleware.SpanStop(ctx, "FileRepositoryUser:Delete")
+
+	if r == nil {
+		return errwrap.NewError(http.StatusInternalServerError, "file repository user uninitialized")
+	}
+	r.lock()
+	defer r.unlock()
+
+	if err := r.record(opDeleteUser, id); err != nil {
+		return err
+	}
+	if err := r.mem.Delete(ctx, id); err != nil {
+		return err
+	}
+	r.snapshotIfDue()
+	return nil
+}
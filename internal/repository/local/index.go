@@ -0,0 +1,200 @@
+package local
+
+import (
+	"slices"
+	"time"
+
+	"github.com/moledoc/orderly/internal/domain/meta"
+	"github.com/moledoc/orderly/internal/domain/order"
+	"github.com/moledoc/orderly/internal/domain/user"
+)
+
+type idSet map[meta.ID]struct{}
+
+func addToSet[K comparable](sets map[K]idSet, key K, id meta.ID) {
+	set, ok := sets[key]
+	if !ok {
+		set = make(idSet)
+		sets[key] = set
+	}
+	set[id] = struct{}{}
+}
+
+func removeFromSet[K comparable](sets map[K]idSet, key K, id meta.ID) {
+	set, ok := sets[key]
+	if !ok {
+		return
+	}
+	delete(set, id)
+	if len(set) == 0 {
+		delete(sets, key)
+	}
+}
+
+type indexEntry struct {
+	parentOrderID meta.ID
+	accountable   user.Email
+	state         order.State
+	deadline      time.Time
+}
+
+// orderIndex keeps the stored order ids by the fields orders are looked up by, so lookups don't have to scan every order.
+// NOTE: orders in trash are indexed as well, lookups need to filter them out
+type orderIndex struct {
+	entries       map[meta.ID]indexEntry
+	byParent      map[meta.ID]idSet
+	byAccountable map[user.Email]idSet
+	byState       map[order.State]idSet
+	byDeadline    map[int64]idSet // NOTE: keyed by the deadline's day
+	deadlineDays  []int64         // NOTE: sorted keys of byDeadline
+}
+
+func newOrderIndex() *orderIndex {
+	return &orderIndex{
+		entries:       make(map[meta.ID]indexEntry),
+		byParent:      make(map[meta.ID]idSet),
+		byAccountable: make(map[user.Email]idSet),
+		byState:       make(map[order.State]idSet),
+		byDeadline:    make(map[int64]idSet),
+	}
+}
+
+func deadlineDay(deadline time.Time) int64 {
+	return deadline.Unix() / int64(24*time.Hour/time.Second)
+}
+
+func (idx *orderIndex) set(id meta.ID, entry indexEntry) {
+	if current, ok := idx.entries[id]; ok {
+		if current == entry {
+			return
+		}
+		idx.remove(id)
+	}
+	idx.entries[id] = entry
+	addToSet(idx.byParent, entry.parentOrderID, id)
+	addToSet(idx.byAccountable, entry.accountable, id)
+	addToSet(idx.byState, entry.state, id)
+
+	day := deadlineDay(entry.deadline)
+	if _, ok := idx.byDeadline[day]; !ok {
+		i, _ := slices.BinarySearch(idx.deadlineDays, day)
+		idx.deadlineDays = slices.Insert(idx.deadlineDays, i, day)
+	}
+	addToSet(idx.byDeadline, day, id)
+}
+
+func (idx *orderIndex) remove(id meta.ID) {
+	entry, ok := idx.entries[id]
+	if !ok {
+		return
+	}
+	delete(idx.entries, id)
+	removeFromSet(idx.byParent, entry.parentOrderID, id)
+	removeFromSet(idx.byAccountable, entry.accountable, id)
+	removeFromSet(idx.byState, entry.state, id)
+
+	day := deadlineDay(entry.deadline)
+	removeFromSet(idx.byDeadline, day, id)
+	if _, ok := idx.byDeadline[day]; !ok {
+		if i, found := slices.BinarySearch(idx.deadlineDays, day); found {
+			idx.deadlineDays = slices.Delete(idx.deadlineDays, i, i+1)
+		}
+	}
+}
+
+// lookup returns the ids of orders matching all the given non-empty filters, an order matches any of the states.
+// NOTE: returns false when no filter is given, meaning every order matches
+func (idx *orderIndex) lookup(parentOrderID meta.ID, accountable user.Email, states []order.State, deadlineFrom time.Time, deadlineTo time.Time) (idSet, bool) {
+	var candidates []idSet
+	if len(parentOrderID) > 0 {
+		candidates = append(candidates, idx.byParent[parentOrderID])
+	}
+	if len(accountable) > 0 {
+		candidates = append(candidates, idx.byAccountable[accountable])
+	}
+	if len(states) > 0 {
+		withStates := make(idSet)
+		for _, state := range states {
+			for id := range idx.withState(state) {
+				withStates[id] = struct{}{}
+			}
+		}
+		candidates = append(candidates, withStates)
+	}
+	if !deadlineFrom.IsZero() || !deadlineTo.IsZero() {
+		candidates = append(candidates, idx.withDeadlineBetween(deadlineFrom, deadlineTo))
+	}
+	if len(candidates) == 0 {
+		return nil, false
+	}
+	slices.SortFunc(candidates, func(a, b idSet) int { return len(a) - len(b) })
+
+	ids := make(idSet, len(candidates[0]))
+	for id := range candidates[0] {
+		matches := true
+		for _, other := range candidates[1:] {
+			if _, ok := other[id]; !ok {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			ids[id] = struct{}{}
+		}
+	}
+	return ids, true
+}
+
+// withState returns the ids of orders in given state.
+func (idx *orderIndex) withState(state order.State) idSet {
+	return idx.byState[state]
+}
+
+// withDeadlineBetween returns the ids of orders with deadline in [from, to).
+// NOTE: zero from or to leaves that end of the range open
+func (idx *orderIndex) withDeadlineBetween(from time.Time, to time.Time) idSet {
+	ids := make(idSet)
+	start := 0
+	if !from.IsZero() {
+		start, _ = slices.BinarySearch(idx.deadlineDays, deadlineDay(from))
+	}
+	for _, day := range idx.deadlineDays[start:] {
+		if !to.IsZero() && day > deadlineDay(to) {
+			break
+		}
+		for id := range idx.byDeadline[day] {
+			deadline := idx.entries[id].deadline
+			if (from.IsZero() || !deadline.Before(from)) && (to.IsZero() || deadline.Before(to)) {
+				ids[id] = struct{}{}
+			}
+		}
+	}
+	return ids
+}
+
+// reindex brings the index entries of given orders up to date with the stored state.
+func (r *LocalRepositoryOrder) reindex(ids ...meta.ID) {
+	for _, id := range ids {
+		r.reindexSearch(id)
+		storedOrder, ok := r.Orders[id]
+		if !ok {
+			r.index.remove(id)
+			continue
+		}
+		task := r.Tasks[storedOrder.TaskID]
+		r.index.set(id, indexEntry{
+			parentOrderID: storedOrder.ParentOrderID,
+			accountable:   task.GetAccountable(),
+			state:         task.GetState(),
+			deadline:      task.GetDeadline(),
+		})
+	}
+}
+
+func (r *LocalRepositoryOrder) rebuildIndex() {
+	r.index = newOrderIndex()
+	r.search = newSearchIndex()
+	for id := range r.Orders {
+		r.reindex(id)
+	}
+}
@@ -3,7 +3,10 @@ package local
 import (
 	"context"
 	"net/http"
+	"slices"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/moledoc/orderly/internal/domain/errwrap"
 	"github.com/moledoc/orderly/internal/domain/meta"
@@ -18,14 +21,19 @@ type orderInfo struct {
 	ParentOrderID    meta.ID
 	DelegatedTaskIDs []meta.ID
 	SitRepIDs        []meta.ID
+	RollupPolicy     *order.RollupPolicy
 	Meta             *meta.Meta
 }
 
 type LocalRepositoryOrder struct {
-	mu      sync.Mutex
-	Orders  map[meta.ID]*orderInfo
-	Tasks   map[meta.ID]*order.Task
-	SitReps map[meta.ID]*order.SitRep
+	mu       sync.Mutex
+	Orders   map[meta.ID]*orderInfo
+	Tasks    map[meta.ID]*order.Task
+	SitReps  map[meta.ID]*order.SitRep
+	Versions map[meta.ID][]*order.Order // NOTE: ordered by version, oldest first
+	index    *orderIndex
+	search   *searchIndex
+	undo     *orderUndo // NOTE: set on the repository bound to a transaction, see WithTx
 }
 
 var (
@@ -34,24 +42,31 @@ var (
 
 func NewLocalRepositoryOrder() *LocalRepositoryOrder {
 	return &LocalRepositoryOrder{
-		mu:      sync.Mutex{},
-		Orders:  make(map[meta.ID]*orderInfo),
-		Tasks:   make(map[meta.ID]*order.Task),
-		SitReps: make(map[meta.ID]*order.SitRep),
+		mu:       sync.Mutex{},
+		Orders:   make(map[meta.ID]*orderInfo),
+		Tasks:    make(map[meta.ID]*order.Task),
+		SitReps:  make(map[meta.ID]*order.SitRep),
+		Versions: make(map[meta.ID][]*order.Order),
+		index:    newOrderIndex(),
+		search:   newSearchIndex(),
 	}
 }
 
+// composeOrder builds the order from copies of the stored objects, so changes made to the order don't leak into the repository.
 func (r *LocalRepositoryOrder) composeOrder(storedOrder *orderInfo) *order.Order {
-	task := r.Tasks[storedOrder.TaskID]
+	task := r.Tasks[storedOrder.TaskID].Clone()
 
 	var delegatedTasks []*order.Task
 	for _, delegatedID := range storedOrder.DelegatedTaskIDs {
+		if delegatedOrder, ok := r.Orders[delegatedID]; ok && delegatedOrder.Meta.IsDeleted() { // NOTE: delegated order is in trash
+			continue
+		}
 		d, ok := r.Tasks[delegatedID]
 		if !ok {
 			// TODO: log warning
 			continue
 		}
-		delegatedTasks = append(delegatedTasks, d)
+		delegatedTasks = append(delegatedTasks, d.Clone())
 	}
 
 	var sitreps []*order.SitRep
@@ -61,7 +76,7 @@ func (r *LocalRepositoryOrder) composeOrder(storedOrder *orderInfo) *order.Order
 			// TODO: log warning
 			continue
 		}
-		sitreps = append(sitreps, sr)
+		sitreps = append(sitreps, sr.Clone())
 	}
 
 	if len(delegatedTasks) == 0 {
@@ -70,57 +85,118 @@ func (r *LocalRepositoryOrder) composeOrder(storedOrder *orderInfo) *order.Order
 	if len(sitreps) == 0 {
 		sitreps = nil
 	}
+	var policy *order.RollupPolicy
+	if storedOrder.RollupPolicy.IsEnabled() {
+		policy = storedOrder.RollupPolicy.Clone()
+	}
 	resp := &order.Order{
 		Task:           task,
 		ParentOrderID:  storedOrder.ParentOrderID,
 		DelegatedTasks: delegatedTasks,
 		SitReps:        sitreps,
-		Meta:           storedOrder.Meta,
+		RollupPolicy:   policy,
+		Meta:           storedOrder.Meta.Clone(),
 	}
 	return resp
 }
 
-func (r *LocalRepositoryOrder) storeOrder(o *order.Order) *orderInfo {
+// storedOrder returns the stored order regardless of whether it's in trash.
+func (r *LocalRepositoryOrder) storedOrder(id meta.ID) (*orderInfo, errwrap.Error) {
+	storedOrder, ok := r.Orders[id]
+	if !ok {
+		return nil, errwrap.NewError(http.StatusNotFound, "order '%s' not found", id)
+	}
+	r.saveOrder(id) // NOTE: stored order is changed in place by the caller
+	return storedOrder, nil
+}
+
+// linkDelegated adds the order to its parent's delegated tasks, unless it's already there.
+// NOTE: parent might not be stored locally
+func (r *LocalRepositoryOrder) linkDelegated(parentID meta.ID, id meta.ID) {
+	parent, ok := r.Orders[parentID]
+	if !ok || parentID == id || slices.Contains(parent.DelegatedTaskIDs, id) {
+		return
+	}
+	r.saveOrder(parentID)
+	parent.DelegatedTaskIDs = append(parent.DelegatedTaskIDs, id)
+}
 
-	r.Tasks[o.GetTask().GetID()] = o.GetTask()
+func (r *LocalRepositoryOrder) unlinkDelegated(parentID meta.ID, id meta.ID) {
+	parent, ok := r.Orders[parentID]
+	if !ok || !slices.Contains(parent.DelegatedTaskIDs, id) {
+		return
+	}
+	r.saveOrder(parentID)
+	parent.DelegatedTaskIDs = slices.DeleteFunc(parent.DelegatedTaskIDs, func(a meta.ID) bool { return a == id })
+}
 
-	var delegatedTaskIDs []meta.ID
-	var sitrepIDs []meta.ID
-	for _, delegated := range o.GetDelegatedTasks() {
-		delegatedTaskIDs = append(delegatedTaskIDs, delegated.GetID())
+func (r *LocalRepositoryOrder) storeDelegatedTasks(storedOrder *orderInfo, tasks []*order.Task, m *meta.Meta) {
+	for _, delegated := range tasks {
+		r.saveTask(delegated.GetID())
 		r.Tasks[delegated.GetID()] = delegated
-		r.Orders[delegated.GetID()] = &orderInfo{
-			TaskID:        delegated.GetID(),
-			ParentOrderID: o.GetID(),
-			Meta:          o.GetMeta(), // NOTE: set meta as order.meta, since they are created at the same time
+		if _, ok := r.Orders[delegated.GetID()]; !ok { // NOTE: delegated task is an order on its own
+			r.saveOrder(delegated.GetID())
+			r.Orders[delegated.GetID()] = &orderInfo{
+				TaskID:        delegated.GetID(),
+				ParentOrderID: storedOrder.TaskID,
+				Meta:          m.Clone(), // NOTE: set meta as order.meta, since they are created at the same time
+			}
 		}
+		r.linkDelegated(storedOrder.TaskID, delegated.GetID())
+		r.reindex(delegated.GetID())
 	}
-	for _, sitrep := range o.GetSitReps() {
-		sitrepIDs = append(sitrepIDs, sitrep.GetID())
+}
+
+func (r *LocalRepositoryOrder) storeSitReps(storedOrder *orderInfo, sitreps []*order.SitRep) {
+	for _, sitrep := range sitreps {
+		r.saveSitRep(sitrep.GetID())
 		r.SitReps[sitrep.GetID()] = sitrep
+		if !slices.Contains(storedOrder.SitRepIDs, sitrep.GetID()) {
+			storedOrder.SitRepIDs = append(storedOrder.SitRepIDs, sitrep.GetID())
+		}
 	}
-	info := &orderInfo{
-		TaskID:           o.GetID(),
-		ParentOrderID:    o.GetParentOrderID(),
-		DelegatedTaskIDs: delegatedTaskIDs,
-		SitRepIDs:        sitrepIDs,
-		Meta:             o.GetMeta(),
+}
+
+// commitOrder sets the order's meta and keeps the resulting version.
+func (r *LocalRepositoryOrder) commitOrder(storedOrder *orderInfo, m *meta.Meta) *order.Order {
+	storedOrder.Meta = m.Clone()
+	o := r.composeOrder(storedOrder)
+	r.storeVersion(o)
+	r.search.set(o.GetID(), o.SearchWords()) // NOTE: sitrep changes aren't reindexed otherwise
+	return o
+}
+
+// storeVersion keeps a copy of the order as it was at its current version.
+// NOTE: writes that don't bump the version replace the latest copy
+func (r *LocalRepositoryOrder) storeVersion(o *order.Order) {
+	r.saveVersions(o.GetID())
+	versions := r.Versions[o.GetID()]
+	if len(versions) > 0 && versions[len(versions)-1].GetMeta().GetVersion() == o.GetMeta().GetVersion() {
+		versions = versions[:len(versions)-1]
 	}
-	r.Orders[o.GetID()] = info
-	parent := r.Orders[o.ParentOrderID]
-	parent.DelegatedTaskIDs = append(r.Orders[o.ParentOrderID].DelegatedTaskIDs, o.GetID())
-	return info
+	r.Versions[o.GetID()] = append(versions, o.Clone())
 }
 
 func (r *LocalRepositoryOrder) deleteOrder(storedOrder *orderInfo) {
+	r.saveTask(storedOrder.TaskID)
 	delete(r.Tasks, storedOrder.TaskID)
 	for _, id := range storedOrder.DelegatedTaskIDs {
+		if delegatedOrder, ok := r.Orders[id]; ok && storedOrder.Meta.IsDeleted() && !delegatedOrder.Meta.IsDeleted() { // NOTE: live delegated order of a trashed order keeps its task
+			continue
+		}
+		r.saveTask(id)
 		delete(r.Tasks, id)
 	}
 	for _, id := range storedOrder.SitRepIDs {
+		r.saveSitRep(id)
 		delete(r.SitReps, id)
 	}
+	r.saveOrder(storedOrder.TaskID)
 	delete(r.Orders, storedOrder.TaskID)
+	r.saveVersions(storedOrder.TaskID)
+	delete(r.Versions, storedOrder.TaskID)
+	r.reindex(storedOrder.TaskID)
+	r.reindex(storedOrder.DelegatedTaskIDs...)
 	return
 }
 
@@ -132,8 +208,8 @@ func (r *LocalRepositoryOrder) Close(ctx context.Context) errwrap.Error {
 		return errwrap.NewError(http.StatusInternalServerError, "local repository uninitialized")
 	}
 
-	r.mu.Lock()
-	defer r.mu.Unlock()
+	r.lock()
+	defer r.unlock()
 
 	r = nil
 	return nil
@@ -146,11 +222,11 @@ func (r *LocalRepositoryOrder) ReadByID(ctx context.Context, id meta.ID) (*order
 	if r == nil {
 		return nil, errwrap.NewError(http.StatusInternalServerError, "local repository uninitialized")
 	}
-	r.mu.Lock()
-	defer r.mu.Unlock()
+	r.lock()
+	defer r.unlock()
 
 	storedOrder, ok := r.Orders[id]
-	if !ok {
+	if !ok || storedOrder.Meta.IsDeleted() {
 		return nil, errwrap.NewError(http.StatusNotFound, "not found")
 	}
 
@@ -159,22 +235,109 @@ func (r *LocalRepositoryOrder) ReadByID(ctx context.Context, id meta.ID) (*order
 	return resp, nil
 }
 
-func (r *LocalRepositoryOrder) ReadBy(ctx context.Context, req *request.GetOrdersRequest) ([]*order.Order, errwrap.Error) {
+// inRange reports whether t is in [from, to), zero time leaves the side open.
+func inRange(t time.Time, from time.Time, to time.Time) bool {
+	return (from.IsZero() || !t.Before(from)) && (to.IsZero() || t.Before(to))
+}
+
+// matchesFilters reports whether the order passes the filters the index can't look up.
+func matchesFilters(o *order.Order, req *request.GetOrdersRequest) bool {
+	task := o.GetTask()
+	if len(req.GetStates()) > 0 && !slices.Contains(req.GetStates(), task.GetState()) {
+		return false
+	}
+	if !inRange(task.GetDeadline(), req.GetDeadlineFrom(), req.GetDeadlineTo()) ||
+		!inRange(o.GetMeta().GetCreated(), req.GetCreatedFrom(), req.GetCreatedTo()) ||
+		!inRange(o.GetMeta().GetUpdated(), req.GetUpdatedFrom(), req.GetUpdatedTo()) {
+		return false
+	}
+	if len(req.GetObjective()) > 0 && !strings.Contains(strings.ToLower(task.GetObjective()), strings.ToLower(req.GetObjective())) {
+		return false
+	}
+	if len(req.GetSitRepBy()) > 0 && !slices.ContainsFunc(o.GetSitReps(), func(sitrep *order.SitRep) bool {
+		return sitrep.GetBy() == req.GetSitRepBy()
+	}) {
+		return false
+	}
+	if has := req.GetHasDelegatedTasks(); has != nil && *has != (len(o.GetDelegatedTasks()) > 0) {
+		return false
+	}
+	return true
+}
+
+func (r *LocalRepositoryOrder) ReadBy(ctx context.Context, req *request.GetOrdersRequest) ([]*order.Order, *meta.Page, errwrap.Error) {
 	middleware.SpanStart(ctx, "LocalStorageOrder:ReadBy")
 	defer middleware.SpanStop(ctx, "LocalStorageOrder:ReadBy")
 
 	if r == nil {
-		return nil, errwrap.NewError(http.StatusInternalServerError, "local repository uninitialized")
+		return nil, nil, errwrap.NewError(http.StatusInternalServerError, "local repository uninitialized")
 	}
-	r.mu.Lock()
-	defer r.mu.Unlock()
+	r.lock()
+	defer r.unlock()
 
-	var orders []*order.Order
 	parentOrderID := req.GetParentOrderID()
 	accountable := req.GetAccountable()
-	for _, storedOrder := range r.Orders {
+
+	var orders []*order.Order
+	collect := func(storedOrder *orderInfo) {
+		if storedOrder.Meta.IsDeleted() {
+			return
+		}
 		if (len(parentOrderID) == 0 || parentOrderID == storedOrder.ParentOrderID) &&
 			(len(accountable) == 0 || accountable == r.Tasks[storedOrder.TaskID].GetAccountable()) {
+			if o := r.composeOrder(storedOrder); matchesFilters(o, req) {
+				orders = append(orders, o)
+			}
+		}
+	}
+
+	ids, ok := r.index.lookup(parentOrderID, accountable, req.GetStates(), req.GetDeadlineFrom(), req.GetDeadlineTo())
+	if !ok { // NOTE: no filters, every order matches
+		for _, storedOrder := range r.Orders {
+			collect(storedOrder)
+		}
+		return page(orders, req.GetSort(), req.GetCursor(), req.GetLimit())
+	}
+	for id := range ids {
+		if storedOrder, ok := r.Orders[id]; ok {
+			collect(storedOrder)
+		}
+	}
+
+	return page(orders, req.GetSort(), req.GetCursor(), req.GetLimit())
+}
+
+func (r *LocalRepositoryOrder) ReadDeletedByID(ctx context.Context, id meta.ID) (*order.Order, errwrap.Error) {
+	middleware.SpanStart(ctx, "LocalStorageOrder:ReadDeletedByID")
+	defer middleware.SpanStop(ctx, "LocalStorageOrder:ReadDeletedByID")
+
+	if r == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "local repository uninitialized")
+	}
+	r.lock()
+	defer r.unlock()
+
+	storedOrder, ok := r.Orders[id]
+	if !ok || !storedOrder.Meta.IsDeleted() {
+		return nil, errwrap.NewError(http.StatusNotFound, "not found")
+	}
+
+	return r.composeOrder(storedOrder), nil
+}
+
+func (r *LocalRepositoryOrder) ReadDeleted(ctx context.Context) ([]*order.Order, errwrap.Error) {
+	middleware.SpanStart(ctx, "LocalStorageOrder:ReadDeleted")
+	defer middleware.SpanStop(ctx, "LocalStorageOrder:ReadDeleted")
+
+	if r == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "local repository uninitialized")
+	}
+	r.lock()
+	defer r.unlock()
+
+	var orders []*order.Order
+	for _, storedOrder := range r.Orders {
+		if storedOrder.Meta.IsDeleted() {
 			orders = append(orders, r.composeOrder(storedOrder))
 		}
 	}
@@ -182,20 +345,272 @@ func (r *LocalRepositoryOrder) ReadBy(ctx context.Context, req *request.GetOrder
 	return orders, nil
 }
 
-func (r *LocalRepositoryOrder) Write(ctx context.Context, order *order.Order) (*order.Order, errwrap.Error) {
-	middleware.SpanStart(ctx, "LocalStorageOrder:Write")
-	defer middleware.SpanStop(ctx, "LocalStorageOrder:Write")
+func (r *LocalRepositoryOrder) ReadVersions(ctx context.Context, id meta.ID) ([]*order.Order, errwrap.Error) {
+	middleware.SpanStart(ctx, "LocalStorageOrder:ReadVersions")
+	defer middleware.SpanStop(ctx, "LocalStorageOrder:ReadVersions")
 
 	if r == nil {
 		return nil, errwrap.NewError(http.StatusInternalServerError, "local repository uninitialized")
 	}
-	r.mu.Lock()
-	defer r.mu.Unlock()
+	r.lock()
+	defer r.unlock()
 
-	info := r.storeOrder(order)
-	order = r.composeOrder(info)
+	versions, ok := r.Versions[id]
+	if !ok {
+		return nil, errwrap.NewError(http.StatusNotFound, "not found")
+	}
+
+	resp := make([]*order.Order, len(versions))
+	for i, version := range versions {
+		resp[i] = version.Clone()
+	}
+	return resp, nil
+}
+
+func (r *LocalRepositoryOrder) ReadVersion(ctx context.Context, id meta.ID, version uint) (*order.Order, errwrap.Error) {
+	middleware.SpanStart(ctx, "LocalStorageOrder:ReadVersion")
+	defer middleware.SpanStop(ctx, "LocalStorageOrder:ReadVersion")
+
+	if r == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "local repository uninitialized")
+	}
+	r.lock()
+	defer r.unlock()
+
+	for _, v := range r.Versions[id] {
+		if v.GetMeta().GetVersion() == version {
+			return v.Clone(), nil
+		}
+	}
+	return nil, errwrap.NewError(http.StatusNotFound, "not found")
+}
+
+func (r *LocalRepositoryOrder) CreateOrder(ctx context.Context, o *order.Order) (*order.Order, errwrap.Error) {
+	middleware.SpanStart(ctx, "LocalStorageOrder:CreateOrder")
+	defer middleware.SpanStop(ctx, "LocalStorageOrder:CreateOrder")
+
+	if r == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "local repository uninitialized")
+	}
+	r.lock()
+	defer r.unlock()
+
+	if _, ok := r.Orders[o.GetID()]; ok {
+		return nil, errwrap.NewError(http.StatusConflict, "order '%s' already exists", o.GetID())
+	}
+
+	r.saveTask(o.GetID())
+	r.Tasks[o.GetID()] = o.GetTask()
+	storedOrder := &orderInfo{
+		TaskID:        o.GetID(),
+		ParentOrderID: o.GetParentOrderID(),
+		RollupPolicy:  o.GetRollupPolicy().Clone(),
+		Meta:          o.GetMeta().Clone(),
+	}
+	r.saveOrder(o.GetID())
+	r.Orders[o.GetID()] = storedOrder
+
+	r.storeDelegatedTasks(storedOrder, o.GetDelegatedTasks(), o.GetMeta())
+	r.storeSitReps(storedOrder, o.GetSitReps())
+	r.linkDelegated(o.GetParentOrderID(), o.GetID())
+	r.reindex(o.GetID())
+
+	return r.commitOrder(storedOrder, o.GetMeta()), nil
+}
+
+func (r *LocalRepositoryOrder) UpdateTask(ctx context.Context, id meta.ID, task *order.Task, m *meta.Meta) (*order.Order, errwrap.Error) {
+	middleware.SpanStart(ctx, "LocalStorageOrder:UpdateTask")
+	defer middleware.SpanStop(ctx, "LocalStorageOrder:UpdateTask")
+
+	if r == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "local repository uninitialized")
+	}
+	r.lock()
+	defer r.unlock()
+
+	storedOrder, err := r.storedOrder(id)
+	if err != nil {
+		return nil, err
+	}
+	if task.GetID() != storedOrder.TaskID && !slices.Contains(storedOrder.DelegatedTaskIDs, task.GetID()) {
+		return nil, errwrap.NewError(http.StatusNotFound, "task '%s' not found in order '%s'", task.GetID(), id)
+	}
+
+	r.saveTask(task.GetID())
+	r.Tasks[task.GetID()] = task
+	r.reindex(task.GetID())
+
+	return r.commitOrder(storedOrder, m), nil
+}
+
+func (r *LocalRepositoryOrder) AppendDelegatedTasks(ctx context.Context, id meta.ID, tasks []*order.Task, m *meta.Meta) (*order.Order, errwrap.Error) {
+	middleware.SpanStart(ctx, "LocalStorageOrder:AppendDelegatedTasks")
+	defer middleware.SpanStop(ctx, "LocalStorageOrder:AppendDelegatedTasks")
+
+	if r == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "local repository uninitialized")
+	}
+	r.lock()
+	defer r.unlock()
+
+	storedOrder, err := r.storedOrder(id)
+	if err != nil {
+		return nil, err
+	}
+	r.storeDelegatedTasks(storedOrder, tasks, m)
+
+	return r.commitOrder(storedOrder, m), nil
+}
+
+func (r *LocalRepositoryOrder) RemoveDelegatedTasks(ctx context.Context, id meta.ID, taskIDs []meta.ID, m *meta.Meta) (*order.Order, errwrap.Error) {
+	middleware.SpanStart(ctx, "LocalStorageOrder:RemoveDelegatedTasks")
+	defer middleware.SpanStop(ctx, "LocalStorageOrder:RemoveDelegatedTasks")
+
+	if r == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "local repository uninitialized")
+	}
+	r.lock()
+	defer r.unlock()
+
+	storedOrder, err := r.storedOrder(id)
+	if err != nil {
+		return nil, err
+	}
+	for _, taskID := range taskIDs {
+		r.unlinkDelegated(id, taskID)
+	}
+
+	return r.commitOrder(storedOrder, m), nil
+}
+
+func (r *LocalRepositoryOrder) AppendSitReps(ctx context.Context, id meta.ID, sitreps []*order.SitRep, m *meta.Meta) (*order.Order, errwrap.Error) {
+	middleware.SpanStart(ctx, "LocalStorageOrder:AppendSitReps")
+	defer middleware.SpanStop(ctx, "LocalStorageOrder:AppendSitReps")
+
+	if r == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "local repository uninitialized")
+	}
+	r.lock()
+	defer r.unlock()
+
+	storedOrder, err := r.storedOrder(id)
+	if err != nil {
+		return nil, err
+	}
+	r.storeSitReps(storedOrder, sitreps)
+
+	return r.commitOrder(storedOrder, m), nil
+}
+
+func (r *LocalRepositoryOrder) UpdateSitRep(ctx context.Context, id meta.ID, sitrep *order.SitRep, m *meta.Meta) (*order.Order, errwrap.Error) {
+	middleware.SpanStart(ctx, "LocalStorageOrder:UpdateSitRep")
+	defer middleware.SpanStop(ctx, "LocalStorageOrder:UpdateSitRep")
+
+	if r == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "local repository uninitialized")
+	}
+	r.lock()
+	defer r.unlock()
+
+	storedOrder, err := r.storedOrder(id)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(storedOrder.SitRepIDs, sitrep.GetID()) {
+		return nil, errwrap.NewError(http.StatusNotFound, "sitrep '%s' not found in order '%s'", sitrep.GetID(), id)
+	}
+
+	r.saveSitRep(sitrep.GetID())
+	r.SitReps[sitrep.GetID()] = sitrep
+
+	return r.commitOrder(storedOrder, m), nil
+}
+
+func (r *LocalRepositoryOrder) RemoveSitReps(ctx context.Context, id meta.ID, sitrepIDs []meta.ID, m *meta.Meta) (*order.Order, errwrap.Error) {
+	middleware.SpanStart(ctx, "LocalStorageOrder:RemoveSitReps")
+	defer middleware.SpanStop(ctx, "LocalStorageOrder:RemoveSitReps")
+
+	if r == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "local repository uninitialized")
+	}
+	r.lock()
+	defer r.unlock()
+
+	storedOrder, err := r.storedOrder(id)
+	if err != nil {
+		return nil, err
+	}
+	storedOrder.SitRepIDs = slices.DeleteFunc(storedOrder.SitRepIDs, func(a meta.ID) bool {
+		if !slices.Contains(sitrepIDs, a) {
+			return false
+		}
+		r.saveSitRep(a)
+		delete(r.SitReps, a)
+		return true
+	})
+
+	return r.commitOrder(storedOrder, m), nil
+}
+
+func (r *LocalRepositoryOrder) Reparent(ctx context.Context, id meta.ID, parentID meta.ID, m *meta.Meta) (*order.Order, errwrap.Error) {
+	middleware.SpanStart(ctx, "LocalStorageOrder:Reparent")
+	defer middleware.SpanStop(ctx, "LocalStorageOrder:Reparent")
+
+	if r == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "local repository uninitialized")
+	}
+	r.lock()
+	defer r.unlock()
+
+	storedOrder, err := r.storedOrder(id)
+	if err != nil {
+		return nil, err
+	}
+	if storedOrder.ParentOrderID != parentID {
+		r.unlinkDelegated(storedOrder.ParentOrderID, id)
+		storedOrder.ParentOrderID = parentID
+	}
+	r.linkDelegated(parentID, id)
+	r.reindex(id)
+
+	return r.commitOrder(storedOrder, m), nil
+}
+
+func (r *LocalRepositoryOrder) UpdateRollupPolicy(ctx context.Context, id meta.ID, policy *order.RollupPolicy, m *meta.Meta) (*order.Order, errwrap.Error) {
+	middleware.SpanStart(ctx, "LocalStorageOrder:UpdateRollupPolicy")
+	defer middleware.SpanStop(ctx, "LocalStorageOrder:UpdateRollupPolicy")
+
+	if r == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "local repository uninitialized")
+	}
+	r.lock()
+	defer r.unlock()
+
+	storedOrder, err := r.storedOrder(id)
+	if err != nil {
+		return nil, err
+	}
+	storedOrder.RollupPolicy = policy.Clone()
+
+	return r.commitOrder(storedOrder, m), nil
+}
+
+func (r *LocalRepositoryOrder) UpdateMeta(ctx context.Context, id meta.ID, m *meta.Meta) (*order.Order, errwrap.Error) {
+	middleware.SpanStart(ctx, "LocalStorageOrder:UpdateMeta")
+	defer middleware.SpanStop(ctx, "LocalStorageOrder:UpdateMeta")
+
+	if r == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "local repository uninitialized")
+	}
+	r.lock()
+	defer r.unlock()
+
+	storedOrder, err := r.storedOrder(id)
+	if err != nil {
+		return nil, err
+	}
 
-	return order, nil
+	return r.commitOrder(storedOrder, m), nil
 }
 
 func (r *LocalRepositoryOrder) DeleteOrder(ctx context.Context, id meta.ID) errwrap.Error {
@@ -205,8 +620,8 @@ func (r *LocalRepositoryOrder) DeleteOrder(ctx context.Context, id meta.ID) errw
 	if r == nil {
 		return errwrap.NewError(http.StatusInternalServerError, "local repository uninitialized")
 	}
-	r.mu.Lock()
-	defer r.mu.Unlock()
+	r.lock()
+	defer r.unlock()
 
 	storedOrder, ok := r.Orders[id]
 	if !ok {
@@ -224,15 +639,17 @@ func (r *LocalRepositoryOrder) DeleteTasks(ctx context.Context, ids []meta.ID) (
 	if r == nil {
 		return false, errwrap.NewError(http.StatusInternalServerError, "local repository uninitialized")
 	}
-	r.mu.Lock()
-	defer r.mu.Unlock()
+	r.lock()
+	defer r.unlock()
 
 	didDelete := false
 	for _, id := range ids {
 		_, ok := r.Tasks[id]
 		didDelete = ok || didDelete
+		r.saveTask(id)
 		delete(r.Tasks, id)
 	}
+	r.reindex(ids...)
 
 	return didDelete, nil
 }
@@ -244,13 +661,14 @@ func (r *LocalRepositoryOrder) DeleteSitReps(ctx context.Context, ids []meta.ID)
 	if r == nil {
 		return false, errwrap.NewError(http.StatusInternalServerError, "local repository uninitialized")
 	}
-	r.mu.Lock()
-	defer r.mu.Unlock()
+	r.lock()
+	defer r.unlock()
 
 	didDelete := false
 	for _, id := range ids {
 		_, ok := r.SitReps[id]
 		didDelete = ok || didDelete
+		r.saveSitRep(id)
 		delete(r.SitReps, id)
 	}
 
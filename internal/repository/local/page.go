@@ -0,0 +1,54 @@
+package local
+
+import (
+	"cmp"
+	"net/http"
+	"slices"
+
+	"github.com/moledoc/orderly/internal/domain/errwrap"
+	"github.com/moledoc/orderly/internal/domain/meta"
+)
+
+// sortable is an object that can be listed page by page, orders and users alike.
+type sortable interface {
+	GetID() meta.ID
+	SortKey(field meta.Sort) int64
+}
+
+// page sorts the matches by the sort key and id, then cuts the page following the cursor.
+// NOTE: the total counts every match, also the ones before the cursor
+func page[T sortable](items []T, sort meta.Sort, cursor string, limit uint) ([]T, *meta.Page, errwrap.Error) {
+	sort = sort.Normalized()
+	field := sort.Field()
+	slices.SortFunc(items, func(a, b T) int {
+		keys := cmp.Compare(a.SortKey(field), b.SortKey(field))
+		if sort.IsDesc() {
+			keys = -keys
+		}
+		return cmp.Or(keys, cmp.Compare(a.GetID(), b.GetID()))
+	})
+
+	pg := &meta.Page{Total: len(items)}
+	if len(cursor) > 0 {
+		c, err := meta.DecodeCursor(cursor, sort)
+		if err != nil {
+			return nil, nil, errwrap.NewError(http.StatusBadRequest, "invalid cursor: %s", err)
+		}
+		i := slices.IndexFunc(items, func(item T) bool {
+			return c.IsAfter(item.SortKey(field), item.GetID())
+		})
+		if i < 0 {
+			i = len(items)
+		}
+		items = items[i:]
+	}
+	if limit > 0 && uint(len(items)) > limit {
+		items = items[:limit]
+		last := items[len(items)-1]
+		pg.NextCursor = (&meta.Cursor{Sort: sort, Key: last.SortKey(field), ID: last.GetID()}).Encode()
+	}
+	if len(items) == 0 {
+		return nil, pg, nil
+	}
+	return items, pg, nil
+}
@@ -0,0 +1,128 @@
+package local
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/moledoc/orderly/internal/domain/errwrap"
+	"github.com/moledoc/orderly/internal/domain/meta"
+	"github.com/moledoc/orderly/internal/domain/order"
+	"github.com/moledoc/orderly/internal/domain/request"
+	"github.com/moledoc/orderly/internal/middleware"
+)
+
+// searchIndex is the inverted index of the words orders are searched by.
+// NOTE: words are kept sorted, so the words a search term prefixes are found by binary search
+type searchIndex struct {
+	byWord map[string]idSet
+	words  []string             // NOTE: sorted keys of byWord
+	docs   map[meta.ID][]string // NOTE: words indexed for the order, so they can be removed
+}
+
+func newSearchIndex() *searchIndex {
+	return &searchIndex{
+		byWord: make(map[string]idSet),
+		docs:   make(map[meta.ID][]string),
+	}
+}
+
+func (idx *searchIndex) set(id meta.ID, words []string) {
+	idx.remove(id)
+	if len(words) == 0 {
+		return
+	}
+	idx.docs[id] = words
+	for _, word := range words {
+		if _, ok := idx.byWord[word]; !ok {
+			i, _ := slices.BinarySearch(idx.words, word)
+			idx.words = slices.Insert(idx.words, i, word)
+		}
+		addToSet(idx.byWord, word, id)
+	}
+}
+
+func (idx *searchIndex) remove(id meta.ID) {
+	for _, word := range idx.docs[id] {
+		removeFromSet(idx.byWord, word, id)
+		if _, ok := idx.byWord[word]; !ok {
+			if i, found := slices.BinarySearch(idx.words, word); found {
+				idx.words = slices.Delete(idx.words, i, i+1)
+			}
+		}
+	}
+	delete(idx.docs, id)
+}
+
+// withPrefix returns the ids of orders having a word starting with the term.
+func (idx *searchIndex) withPrefix(term string) idSet {
+	ids := make(idSet)
+	start, _ := slices.BinarySearch(idx.words, term)
+	for _, word := range idx.words[start:] {
+		if !strings.HasPrefix(word, term) {
+			break
+		}
+		for id := range idx.byWord[word] {
+			ids[id] = struct{}{}
+		}
+	}
+	return ids
+}
+
+// lookup returns the ids of orders matching every term.
+func (idx *searchIndex) lookup(terms []string) idSet {
+	var ids idSet
+	for _, term := range terms {
+		withTerm := idx.withPrefix(term)
+		if ids == nil {
+			ids = withTerm
+			continue
+		}
+		for id := range ids {
+			if _, ok := withTerm[id]; !ok {
+				delete(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+// reindexSearch brings the search index entry of the order up to date with the stored state.
+func (r *LocalRepositoryOrder) reindexSearch(id meta.ID) {
+	storedOrder, ok := r.Orders[id]
+	if !ok {
+		r.search.remove(id)
+		return
+	}
+	r.search.set(id, r.composeOrder(storedOrder).SearchWords())
+}
+
+func (r *LocalRepositoryOrder) Search(ctx context.Context, req *request.SearchRequest) ([]*order.SearchHit, errwrap.Error) {
+	middleware.SpanStart(ctx, "LocalStorageOrder:Search")
+	defer middleware.SpanStop(ctx, "LocalStorageOrder:Search")
+
+	if r == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "local repository uninitialized")
+	}
+	r.lock()
+	defer r.unlock()
+
+	terms := order.Tokenize(req.GetQuery())
+	var hits []*order.SearchHit
+	for id := range r.search.lookup(terms) {
+		storedOrder, ok := r.Orders[id]
+		if !ok || storedOrder.Meta.IsDeleted() {
+			continue
+		}
+		if hit := r.composeOrder(storedOrder).Search(terms); hit != nil { // NOTE: index might list words no longer stored, eg purged sitreps
+			hits = append(hits, hit)
+		}
+	}
+	order.RankHits(hits)
+
+	if req.GetLimit() > 0 && uint(len(hits)) > req.GetLimit() {
+		hits = hits[:req.GetLimit()]
+	}
+	return hits, nil
+}
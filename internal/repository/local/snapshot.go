@@ -0,0 +1,96 @@
+package local
+
+import (
+	"encoding/json"
+
+	"github.com/moledoc/orderly/internal/domain/meta"
+	"github.com/moledoc/orderly/internal/domain/order"
+	"github.com/moledoc/orderly/internal/domain/user"
+)
+
+type orderSnapshot struct {
+	Orders   map[meta.ID]*orderInfo     `json:"orders"`
+	Tasks    map[meta.ID]*order.Task    `json:"tasks"`
+	SitReps  map[meta.ID]*order.SitRep  `json:"sitreps"`
+	Versions map[meta.ID][]*order.Order `json:"versions"`
+}
+
+type userSnapshot struct {
+	Users    map[meta.ID]*user.User   `json:"users"`
+	Versions map[meta.ID][]*user.User `json:"versions"`
+}
+
+// MarshalJSON dumps the whole repository state, used for snapshotting.
+func (r *LocalRepositoryOrder) MarshalJSON() ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return json.Marshal(&orderSnapshot{
+		Orders:   r.Orders,
+		Tasks:    r.Tasks,
+		SitReps:  r.SitReps,
+		Versions: r.Versions,
+	})
+}
+
+// UnmarshalJSON replaces the repository state with a snapshot made by MarshalJSON.
+func (r *LocalRepositoryOrder) UnmarshalJSON(data []byte) error {
+	var snapshot orderSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.Orders = snapshot.Orders
+	r.Tasks = snapshot.Tasks
+	r.SitReps = snapshot.SitReps
+	r.Versions = snapshot.Versions
+	if r.Orders == nil {
+		r.Orders = make(map[meta.ID]*orderInfo)
+	}
+	if r.Tasks == nil {
+		r.Tasks = make(map[meta.ID]*order.Task)
+	}
+	if r.SitReps == nil {
+		r.SitReps = make(map[meta.ID]*order.SitRep)
+	}
+	if r.Versions == nil {
+		r.Versions = make(map[meta.ID][]*order.Order)
+	}
+	r.rebuildIndex()
+	return nil
+}
+
+// MarshalJSON dumps the whole repository state, used for snapshotting.
+func (r *LocalRepositoryUser) MarshalJSON() ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return json.Marshal(&userSnapshot{
+		Users:    r.db,
+		Versions: r.versions,
+	})
+}
+
+// UnmarshalJSON replaces the repository state with a snapshot made by MarshalJSON.
+func (r *LocalRepositoryUser) UnmarshalJSON(data []byte) error {
+	var snapshot userSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.db = snapshot.Users
+	r.versions = snapshot.Versions
+	if r.db == nil {
+		r.db = make(map[meta.ID]*user.User)
+	}
+	if r.versions == nil {
+		r.versions = make(map[meta.ID][]*user.User)
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package local
+
+import (
+	"context"
+	"net/http"
+	"slices"
+
+	"github.com/moledoc/orderly/internal/domain/errwrap"
+	"github.com/moledoc/orderly/internal/domain/meta"
+	"github.com/moledoc/orderly/internal/domain/order"
+	"github.com/moledoc/orderly/internal/domain/user"
+	"github.com/moledoc/orderly/internal/middleware"
+	"github.com/moledoc/orderly/internal/repository"
+)
+
+type undoEntry[T any] struct {
+	value  T
+	exists bool
+}
+
+// undoLog keeps map entries as they were before the transaction first changed them.
+type undoLog[T any] map[meta.ID]undoEntry[T]
+
+func (u undoLog[T]) save(current map[meta.ID]T, id meta.ID, clone func(T) T) {
+	if _, ok := u[id]; ok { // NOTE: keep the state from before the transaction
+		return
+	}
+	value, exists := current[id]
+	if exists && clone != nil {
+		value = clone(value)
+	}
+	u[id] = undoEntry[T]{value: value, exists: exists}
+}
+
+func (u undoLog[T]) restore(current map[meta.ID]T) {
+	for id, entry := range u {
+		if entry.exists {
+			current[id] = entry.value
+		} else {
+			delete(current, id)
+		}
+	}
+}
+
+////////////////
+
+type orderUndo struct {
+	orders   undoLog[*orderInfo]
+	tasks    undoLog[*order.Task]
+	sitreps  undoLog[*order.SitRep]
+	versions undoLog[[]*order.Order]
+}
+
+func cloneOrderInfo(info *orderInfo) *orderInfo {
+	clone := *info
+	clone.DelegatedTaskIDs = slices.Clone(info.DelegatedTaskIDs)
+	clone.SitRepIDs = slices.Clone(info.SitRepIDs)
+	return &clone
+}
+
+// NOTE: repository bound to a transaction runs under the lock held by WithTx
+func (r *LocalRepositoryOrder) lock() {
+	if r.undo == nil {
+		r.mu.Lock()
+	}
+}
+
+func (r *LocalRepositoryOrder) unlock() {
+	if r.undo == nil {
+		r.mu.Unlock()
+	}
+}
+
+// NOTE: save* are no-ops outside of a transaction
+func (r *LocalRepositoryOrder) saveOrder(id meta.ID) {
+	if r.undo != nil {
+		r.undo.orders.save(r.Orders, id, cloneOrderInfo)
+	}
+}
+
+func (r *LocalRepositoryOrder) saveTask(id meta.ID) {
+	if r.undo != nil {
+		r.undo.tasks.save(r.Tasks, id, nil)
+	}
+}
+
+func (r *LocalRepositoryOrder) saveSitRep(id meta.ID) {
+	if r.undo != nil {
+		r.undo.sitreps.save(r.SitReps, id, nil)
+	}
+}
+
+func (r *LocalRepositoryOrder) saveVersions(id meta.ID) {
+	if r.undo != nil {
+		r.undo.versions.save(r.Versions, id, slices.Clone)
+	}
+}
+
+func (r *LocalRepositoryOrder) rollback() {
+	r.undo.orders.restore(r.Orders)
+	r.undo.tasks.restore(r.Tasks)
+	r.undo.sitreps.restore(r.SitReps)
+	r.undo.versions.restore(r.Versions)
+	for id := range r.undo.orders {
+		r.reindex(id)
+	}
+	for id := range r.undo.tasks {
+		r.reindex(id)
+	}
+}
+
+func (r *LocalRepositoryOrder) WithTx(ctx context.Context, fn func(tx repository.RepositoryOrderAPI) errwrap.Error) errwrap.Error {
+	middleware.SpanStart(ctx, "LocalStorageOrder:WithTx")
+	defer middleware.SpanStop(ctx, "LocalStorageOrder:WithTx")
+
+	if r == nil {
+		return errwrap.NewError(http.StatusInternalServerError, "local repository uninitialized")
+	}
+	if r.undo != nil { // NOTE: join ongoing transaction
+		return fn(r)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tx := &LocalRepositoryOrder{
+		Orders:   r.Orders,
+		Tasks:    r.Tasks,
+		SitReps:  r.SitReps,
+		Versions: r.Versions,
+		index:    r.index,
+		search:   r.search,
+		undo: &orderUndo{
+			orders:   make(undoLog[*orderInfo]),
+			tasks:    make(undoLog[*order.Task]),
+			sitreps:  make(undoLog[*order.SitRep]),
+			versions: make(undoLog[[]*order.Order]),
+		},
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		tx.rollback()
+		return err
+	}
+	return nil
+}
+
+////////////////
+
+type userUndo struct {
+	db       undoLog[*user.User]
+	versions undoLog[[]*user.User]
+}
+
+// NOTE: repository bound to a transaction runs under the lock held by WithTx
+func (r *LocalRepositoryUser) lock() {
+	if r.undo == nil {
+		r.mu.Lock()
+	}
+}
+
+func (r *LocalRepositoryUser) unlock() {
+	if r.undo == nil {
+		r.mu.Unlock()
+	}
+}
+
+// NOTE: no-op outside of a transaction
+func (r *LocalRepositoryUser) saveUser(id meta.ID) {
+	if r.undo != nil {
+		r.undo.db.save(r.db, id, nil)
+		r.undo.versions.save(r.versions, id, slices.Clone)
+	}
+}
+
+func (r *LocalRepositoryUser) rollback() {
+	r.undo.db.restore(r.db)
+	r.undo.versions.restore(r.versions)
+}
+
+func (r *LocalRepositoryUser) WithTx(ctx context.Context, fn func(tx repository.RepositoryUserAPI) errwrap.Error) errwrap.Error {
+	middleware.SpanStart(ctx, "LocalRepositoryUser:WithTx")
+	defer middleware.SpanStop(ctx, "LocalRepositoryUser:WithTx")
+
+	if r == nil {
+		return errwrap.NewError(http.StatusInternalServerError, "local repository user uninitialized")
+	}
+	if r.undo != nil { // NOTE: join ongoing transaction
+		return fn(r)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tx := &LocalRepositoryUser{
+		db:       r.db,
+		versions: r.versions,
+		undo: &userUndo{
+			db:       make(undoLog[*user.User]),
+			versions: make(undoLog[[]*user.User]),
+		},
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		tx.rollback()
+		return err
+	}
+	return nil
+}
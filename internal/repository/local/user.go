@@ -23,8 +23,10 @@ type userInfo struct {
 }
 
 type LocalRepositoryUser struct {
-	mu sync.Mutex
-	db map[meta.ID]*user.User
+	mu       sync.Mutex
+	db       map[meta.ID]*user.User
+	versions map[meta.ID][]*user.User // NOTE: ordered by version, oldest first
+	undo     *userUndo                // NOTE: set on the repository bound to a transaction, see WithTx
 }
 
 var (
@@ -33,8 +35,9 @@ var (
 
 func NewLocalRepositoryUser() *LocalRepositoryUser {
 	return &LocalRepositoryUser{
-		mu: sync.Mutex{},
-		db: make(map[meta.ID]*user.User),
+		mu:       sync.Mutex{},
+		db:       make(map[meta.ID]*user.User),
+		versions: make(map[meta.ID][]*user.User),
 	}
 }
 
@@ -57,52 +60,170 @@ func (r *LocalRepositoryUser) ReadByID(ctx context.Context, id meta.ID) (*user.U
 		return nil, errwrap.NewError(http.StatusInternalServerError, "local repository user uninitialized")
 	}
 
-	r.mu.Lock()
-	defer r.mu.Unlock()
+	r.lock()
+	defer r.unlock()
 
 	u, ok := r.db[id]
-	if !ok {
+	if !ok || u.GetMeta().IsDeleted() {
 		return nil, errwrap.NewError(http.StatusNotFound, "not found")
 	}
 	return u, nil
 }
 
-func (r *LocalRepositoryUser) ReadBy(ctx context.Context, req *request.GetUsersRequest) ([]*user.User, errwrap.Error) {
+func (r *LocalRepositoryUser) ReadBy(ctx context.Context, req *request.GetUsersRequest) ([]*user.User, *meta.Page, errwrap.Error) {
 	middleware.SpanStart(ctx, "LocalRepositoryUser:ReadBy")
 	defer middleware.SpanStop(ctx, "LocalRepositoryUser:ReadBy")
 
 	if r == nil {
-		return nil, errwrap.NewError(http.StatusInternalServerError, "local repository user uninitialized")
+		return nil, nil, errwrap.NewError(http.StatusInternalServerError, "local repository user uninitialized")
 	}
 
-	r.mu.Lock()
-	defer r.mu.Unlock()
+	r.lock()
+	defer r.unlock()
 
 	var users []*user.User
 	emails := req.GetEmails()
 	supervisor := req.GetSupervisor()
 	for _, u := range r.db {
+		if u.GetMeta().IsDeleted() {
+			continue
+		}
 		if (len(emails) == 0 || slices.Contains(emails, u.GetEmail())) &&
 			(len(supervisor) == 0 || supervisor == u.GetSupervisor()) {
 			users = append(users, u)
 		}
 	}
 
+	return page(users, req.GetSort(), req.GetCursor(), req.GetLimit())
+}
+
+func (r *LocalRepositoryUser) ReadDeletedByID(ctx context.Context, id meta.ID) (*user.User, errwrap.Error) {
+	middleware.SpanStart(ctx, "LocalRepositoryUser:ReadDeletedByID")
+	defer middleware.SpanStop(ctx, "LocalRepositoryUser:ReadDeletedByID")
+
+	if r == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "local repository user uninitialized")
+	}
+
+	r.lock()
+	defer r.unlock()
+
+	u, ok := r.db[id]
+	if !ok || !u.GetMeta().IsDeleted() {
+		return nil, errwrap.NewError(http.StatusNotFound, "not found")
+	}
+	return u, nil
+}
+
+func (r *LocalRepositoryUser) ReadDeleted(ctx context.Context) ([]*user.User, errwrap.Error) {
+	middleware.SpanStart(ctx, "LocalRepositoryUser:ReadDeleted")
+	defer middleware.SpanStop(ctx, "LocalRepositoryUser:ReadDeleted")
+
+	if r == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "local repository user uninitialized")
+	}
+
+	r.lock()
+	defer r.unlock()
+
+	var users []*user.User
+	for _, u := range r.db {
+		if u.GetMeta().IsDeleted() {
+			users = append(users, u)
+		}
+	}
+
 	return users, nil
 }
 
-func (r *LocalRepositoryUser) Write(ctx context.Context, user *user.User) (*user.User, errwrap.Error) {
-	middleware.SpanStart(ctx, "LocalRepositoryUser:Write")
-	defer middleware.SpanStop(ctx, "LocalRepositoryUser:Write")
+func (r *LocalRepositoryUser) ReadVersions(ctx context.Context, id meta.ID) ([]*user.User, errwrap.Error) {
+	middleware.SpanStart(ctx, "LocalRepositoryUser:ReadVersions")
+	defer middleware.SpanStop(ctx, "LocalRepositoryUser:ReadVersions")
 
 	if r == nil {
 		return nil, errwrap.NewError(http.StatusInternalServerError, "local repository user uninitialized")
 	}
-	r.mu.Lock()
-	defer r.mu.Unlock()
 
-	id := user.GetID()
-	r.db[id] = user
+	r.lock()
+	defer r.unlock()
+
+	versions, ok := r.versions[id]
+	if !ok {
+		return nil, errwrap.NewError(http.StatusNotFound, "not found")
+	}
+
+	resp := make([]*user.User, len(versions))
+	for i, version := range versions {
+		resp[i] = version.Clone()
+	}
+	return resp, nil
+}
+
+func (r *LocalRepositoryUser) ReadVersion(ctx context.Context, id meta.ID, version uint) (*user.User, errwrap.Error) {
+	middleware.SpanStart(ctx, "LocalRepositoryUser:ReadVersion")
+	defer middleware.SpanStop(ctx, "LocalRepositoryUser:ReadVersion")
+
+	if r == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "local repository user uninitialized")
+	}
+
+	r.lock()
+	defer r.unlock()
+
+	for _, u := range r.versions[id] {
+		if u.GetMeta().GetVersion() == version {
+			return u.Clone(), nil
+		}
+	}
+	return nil, errwrap.NewError(http.StatusNotFound, "not found")
+}
+
+// storeUser keeps the user and a copy of it as it is at its current version.
+// NOTE: writes that don't bump the version replace the latest copy
+func (r *LocalRepositoryUser) storeUser(u *user.User) {
+	id := u.GetID()
+	r.saveUser(id)
+	r.db[id] = u
+
+	versions := r.versions[id]
+	if len(versions) > 0 && versions[len(versions)-1].GetMeta().GetVersion() == u.GetMeta().GetVersion() {
+		versions = versions[:len(versions)-1]
+	}
+	r.versions[id] = append(versions, u.Clone())
+}
+
+func (r *LocalRepositoryUser) CreateUser(ctx context.Context, user *user.User) (*user.User, errwrap.Error) {
+	middleware.SpanStart(ctx, "LocalRepositoryUser:CreateUser")
+	defer middleware.SpanStop(ctx, "LocalRepositoryUser:CreateUser")
+
+	if r == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "local repository user uninitialized")
+	}
+	r.lock()
+	defer r.unlock()
+
+	if _, ok := r.db[user.GetID()]; ok {
+		return nil, errwrap.NewError(http.StatusConflict, "user '%s' already exists", user.GetID())
+	}
+	r.storeUser(user)
+
+	return user, nil
+}
+
+func (r *LocalRepositoryUser) UpdateUser(ctx context.Context, user *user.User) (*user.User, errwrap.Error) {
+	middleware.SpanStart(ctx, "LocalRepositoryUser:UpdateUser")
+	defer middleware.SpanStop(ctx, "LocalRepositoryUser:UpdateUser")
+
+	if r == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "local repository user uninitialized")
+	}
+	r.lock()
+	defer r.unlock()
+
+	if _, ok := r.db[user.GetID()]; !ok { // NOTE: users in trash are updated as well
+		return nil, errwrap.NewError(http.StatusNotFound, "user '%s' not found", user.GetID())
+	}
+	r.storeUser(user)
 
 	return user, nil
 }
@@ -114,10 +235,12 @@ func (r *LocalRepositoryUser) Delete(ctx context.Context, id meta.ID) errwrap.Er
 	if r == nil {
 		return errwrap.NewError(http.StatusInternalServerError, "local repository uninitialized")
 	}
-	r.mu.Lock()
-	defer r.mu.Unlock()
+	r.lock()
+	defer r.unlock()
 
+	r.saveUser(id)
 	delete(r.db, id)
+	delete(r.versions, id)
 
 	return nil
 }
@@ -10,22 +10,65 @@ import (
 	"github.com/moledoc/orderly/internal/domain/user"
 )
 
+// NOTE: soft deleted objects (meta.deleted set) are hidden from ReadByID and ReadBy, they're only reachable through ReadDeleted*.
+// Delete* remove the objects for good.
+
+// NOTE: ReadBy lists in a stable order: by the requested sort and then by id.
+// The page tells the total count of matches and the cursor to the next page, limit 0 lists every match.
+
+// NOTE: Search finds orders by the words of their objective and sitreps, orders in trash aren't found.
+// Hits are ranked best first, limit 0 returns every hit.
+
+// NOTE: WithTx runs fn as a single unit of work: fn gets a repository bound to the transaction,
+// when fn returns an error every change made through it is rolled back.
+// WithTx called on the transaction's repository joins the ongoing transaction.
+
+// NOTE: order changes are made through operations that touch only what changed.
+// Each operation sets the order's meta to m and keeps the resulting version, operations made with the same version replace the latest copy.
+// Orders in trash can be changed as well, see UpdateMeta.
+
 type RepositoryOrderAPI interface {
 	Close(ctx context.Context) errwrap.Error
+	WithTx(ctx context.Context, fn func(tx RepositoryOrderAPI) errwrap.Error) errwrap.Error
 	ReadByID(ctx context.Context, id meta.ID) (*order.Order, errwrap.Error)
-	ReadBy(ctx context.Context, req *request.GetOrdersRequest) ([]*order.Order, errwrap.Error)
-	// TODO: split Write to specific funcs
-	Write(ctx context.Context, order *order.Order) (*order.Order, errwrap.Error)
+	ReadBy(ctx context.Context, req *request.GetOrdersRequest) ([]*order.Order, *meta.Page, errwrap.Error)
+	Search(ctx context.Context, req *request.SearchRequest) ([]*order.SearchHit, errwrap.Error)
+	ReadDeletedByID(ctx context.Context, id meta.ID) (*order.Order, errwrap.Error)
+	ReadDeleted(ctx context.Context) ([]*order.Order, errwrap.Error)
+	ReadVersions(ctx context.Context, id meta.ID) ([]*order.Order, errwrap.Error)
+	ReadVersion(ctx context.Context, id meta.ID, version uint) (*order.Order, errwrap.Error)
+	CreateOrder(ctx context.Context, order *order.Order) (*order.Order, errwrap.Error)
+	UpdateTask(ctx context.Context, id meta.ID, task *order.Task, m *meta.Meta) (*order.Order, errwrap.Error)
+	AppendDelegatedTasks(ctx context.Context, id meta.ID, tasks []*order.Task, m *meta.Meta) (*order.Order, errwrap.Error)
+	RemoveDelegatedTasks(ctx context.Context, id meta.ID, taskIDs []meta.ID, m *meta.Meta) (*order.Order, errwrap.Error)
+	AppendSitReps(ctx context.Context, id meta.ID, sitreps []*order.SitRep, m *meta.Meta) (*order.Order, errwrap.Error)
+	UpdateSitRep(ctx context.Context, id meta.ID, sitrep *order.SitRep, m *meta.Meta) (*order.Order, errwrap.Error)
+	RemoveSitReps(ctx context.Context, id meta.ID, sitrepIDs []meta.ID, m *meta.Meta) (*order.Order, errwrap.Error)
+	Reparent(ctx context.Context, id meta.ID, parentID meta.ID, m *meta.Meta) (*order.Order, errwrap.Error)
+	UpdateRollupPolicy(ctx context.Context, id meta.ID, policy *order.RollupPolicy, m *meta.Meta) (*order.Order, errwrap.Error)
+	UpdateMeta(ctx context.Context, id meta.ID, m *meta.Meta) (*order.Order, errwrap.Error)
 	DeleteOrder(ctx context.Context, id meta.ID) errwrap.Error
 	DeleteTasks(ctx context.Context, ids []meta.ID) (bool, errwrap.Error)
 	DeleteSitReps(ctx context.Context, ids []meta.ID) (bool, errwrap.Error)
 }
 
+// RepositoryTxContext is implemented by the repositories whose storage can be shared, e.g sql repositories on the same database.
+// NOTE: WithTxContext runs fn within a transaction passed along in the context,
+// repositories on the same storage join it in WithTx when given that context.
+type RepositoryTxContext interface {
+	WithTxContext(ctx context.Context, fn func(ctx context.Context) errwrap.Error) errwrap.Error
+}
+
 type RepositoryUserAPI interface {
 	Close(ctx context.Context) errwrap.Error
+	WithTx(ctx context.Context, fn func(tx RepositoryUserAPI) errwrap.Error) errwrap.Error
 	ReadByID(ctx context.Context, id meta.ID) (*user.User, errwrap.Error)
-	ReadBy(ctx context.Context, req *request.GetUsersRequest) ([]*user.User, errwrap.Error)
-	// TODO: split Write to specific funcs
-	Write(ctx context.Context, user *user.User) (*user.User, errwrap.Error)
+	ReadBy(ctx context.Context, req *request.GetUsersRequest) ([]*user.User, *meta.Page, errwrap.Error)
+	ReadDeletedByID(ctx context.Context, id meta.ID) (*user.User, errwrap.Error)
+	ReadDeleted(ctx context.Context) ([]*user.User, errwrap.Error)
+	ReadVersions(ctx context.Context, id meta.ID) ([]*user.User, errwrap.Error)
+	ReadVersion(ctx context.Context, id meta.ID, version uint) (*user.User, errwrap.Error)
+	CreateUser(ctx context.Context, user *user.User) (*user.User, errwrap.Error)
+	UpdateUser(ctx context.Context, user *user.User) (*user.User, errwrap.Error)
 	Delete(ctx context.Context, id meta.ID) errwrap.Error
 }
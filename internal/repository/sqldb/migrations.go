@@ -0,0 +1,214 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/moledoc/orderly/internal/domain/meta"
+)
+
+type migration struct {
+	Version    uint
+	Desc       string
+	Statements []string
+	Fill       func(ctx context.Context, tx *sql.Tx) error // NOTE: fills in the data statements can't, run after them in the same transaction
+}
+
+// NOTE: append only; applied migrations must never change.
+// Timestamps are stored as unix nanoseconds, so they survive the round-trip with full precision regardless of the driver.
+var migrations = []migration{
+	{
+		Version: 1,
+		Desc:    "initial schema",
+		Statements: []string{
+			`CREATE TABLE users (
+				id         TEXT PRIMARY KEY,
+				name       TEXT NOT NULL,
+				email      TEXT NOT NULL,
+				supervisor TEXT NOT NULL,
+				version    BIGINT NOT NULL,
+				created    BIGINT NOT NULL,
+				updated    BIGINT NOT NULL
+			)`,
+			`CREATE INDEX users_email_idx ON users (email)`,
+			`CREATE INDEX users_supervisor_idx ON users (supervisor)`,
+			`CREATE TABLE tasks (
+				id          TEXT PRIMARY KEY,
+				state       INTEGER NOT NULL,
+				accountable TEXT NOT NULL,
+				objective   TEXT NOT NULL,
+				deadline    BIGINT NOT NULL
+			)`,
+			`CREATE INDEX tasks_accountable_idx ON tasks (accountable)`,
+			`CREATE TABLE orders (
+				task_id         TEXT PRIMARY KEY,
+				parent_order_id TEXT NOT NULL,
+				version         BIGINT NOT NULL,
+				created         BIGINT NOT NULL,
+				updated         BIGINT NOT NULL
+			)`,
+			`CREATE INDEX orders_parent_order_id_idx ON orders (parent_order_id)`,
+			`CREATE TABLE delegated_tasks (
+				order_id TEXT NOT NULL,
+				task_id  TEXT NOT NULL,
+				position INTEGER NOT NULL,
+				PRIMARY KEY (order_id, task_id)
+			)`,
+			`CREATE TABLE sitreps (
+				id        TEXT PRIMARY KEY,
+				order_id  TEXT NOT NULL,
+				position  INTEGER NOT NULL,
+				datetime  BIGINT NOT NULL,
+				by_email  TEXT NOT NULL,
+				situation TEXT NOT NULL,
+				actions   TEXT NOT NULL,
+				todo      TEXT NOT NULL,
+				issues    TEXT NOT NULL
+			)`,
+			`CREATE INDEX sitreps_order_id_idx ON sitreps (order_id)`,
+		},
+	},
+	{
+		Version: 2,
+		Desc:    "version history",
+		Statements: []string{
+			`CREATE TABLE order_versions (
+				order_id TEXT NOT NULL,
+				version  BIGINT NOT NULL,
+				data     TEXT NOT NULL,
+				PRIMARY KEY (order_id, version)
+			)`,
+			`CREATE TABLE user_versions (
+				user_id TEXT NOT NULL,
+				version BIGINT NOT NULL,
+				data    TEXT NOT NULL,
+				PRIMARY KEY (user_id, version)
+			)`,
+		},
+	},
+	{
+		Version: 3,
+		Desc:    "updated by",
+		Statements: []string{
+			`ALTER TABLE orders ADD COLUMN updated_by TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE users ADD COLUMN updated_by TEXT NOT NULL DEFAULT ''`,
+		},
+	},
+	{
+		Version: 4,
+		Desc:    "soft delete",
+		Statements: []string{
+			`ALTER TABLE orders ADD COLUMN deleted BIGINT NOT NULL DEFAULT 0`,
+			`ALTER TABLE users ADD COLUMN deleted BIGINT NOT NULL DEFAULT 0`,
+			`CREATE INDEX orders_deleted_idx ON orders (deleted)`,
+			`CREATE INDEX users_deleted_idx ON users (deleted)`,
+		},
+	},
+	{
+		Version: 5,
+		Desc:    "rollup policy",
+		Statements: []string{
+			`ALTER TABLE orders ADD COLUMN issues_on_blocked INTEGER NOT NULL DEFAULT 0`,
+			`ALTER TABLE orders ADD COLUMN complete_delegated INTEGER NOT NULL DEFAULT 0`,
+		},
+	},
+	{
+		Version: 6,
+		Desc:    "search",
+		Statements: []string{
+			`CREATE TABLE search_words (
+				word     TEXT NOT NULL,
+				order_id TEXT NOT NULL,
+				PRIMARY KEY (word, order_id)
+			)`,
+			`CREATE INDEX search_words_order_id_idx ON search_words (order_id)`,
+		},
+		Fill: fillSearchWords,
+	},
+	{
+		Version: 7,
+		Desc:    "pre-rollup state",
+		Statements: []string{
+			`ALTER TABLE orders ADD COLUMN pre_rollup_state INTEGER NOT NULL DEFAULT 0`,
+		},
+	},
+}
+
+// Migrate brings the schema up to the latest version, each migration is applied in its own transaction.
+func Migrate(ctx context.Context, db *sql.DB) error {
+	_, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version BIGINT PRIMARY KEY,
+		applied BIGINT NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("creating schema_migrations failed: %w", err)
+	}
+
+	var current uint
+	if err := db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current); err != nil {
+		return fmt.Errorf("reading schema version failed: %w", err)
+	}
+
+	for _, m := range migrations {
+		if m.Version <= current {
+			continue
+		}
+		if err := applyMigration(ctx, db, m); err != nil {
+			return fmt.Errorf("migration %v (%s) failed: %w", m.Version, m.Desc, err)
+		}
+	}
+	return nil
+}
+
+func applyMigration(ctx context.Context, db *sql.DB, m migration) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, stmt := range m.Statements {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	if m.Fill != nil {
+		if err := m.Fill(ctx, tx); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, applied) VALUES ($1, $2)`, m.Version, time.Now().UTC().UnixNano()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// fillSearchWords indexes the orders stored before search was added.
+func fillSearchWords(ctx context.Context, tx *sql.Tx) error {
+	rows, err := tx.QueryContext(ctx, `SELECT task_id FROM orders`)
+	if err != nil {
+		return err
+	}
+	var ids []meta.ID
+	for rows.Next() {
+		var id meta.ID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if err := reindexSearch(ctx, tx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
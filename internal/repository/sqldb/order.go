@@ -0,0 +1,955 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/moledoc/orderly/internal/domain/errwrap"
+	"github.com/moledoc/orderly/internal/domain/meta"
+	"github.com/moledoc/orderly/internal/domain/order"
+	"github.com/moledoc/orderly/internal/domain/request"
+	"github.com/moledoc/orderly/internal/middleware"
+	"github.com/moledoc/orderly/internal/repository"
+	"github.com/moledoc/orderly/pkg/utils"
+)
+
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type SQLRepositoryOrder struct {
+	db *sql.DB
+	tx *sql.Tx // NOTE: set on the repository bound to a transaction, see WithTx
+}
+
+var (
+	_ repository.RepositoryOrderAPI = (*SQLRepositoryOrder)(nil)
+)
+
+func NewSQLRepositoryOrder(db *sql.DB) *SQLRepositoryOrder {
+	return &SQLRepositoryOrder{
+		db: db,
+	}
+}
+
+func readTasks(ctx context.Context, q querier, query string, args ...any) ([]*order.Task, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tasks []*order.Task
+	for rows.Next() {
+		var task order.Task
+		var state int
+		var deadline int64
+		if err := rows.Scan(&task.ID, &state, &task.Accountable, &task.Objective, &deadline); err != nil {
+			return nil, err
+		}
+		task.State = utils.Ptr(order.State(state))
+		task.Deadline = fromUnix(deadline)
+		tasks = append(tasks, &task)
+	}
+	return tasks, rows.Err()
+}
+
+func readSitReps(ctx context.Context, q querier, orderID meta.ID) ([]*order.SitRep, error) {
+	rows, err := q.QueryContext(ctx, `SELECT id, datetime, by_email, situation, actions, todo, issues
+		FROM sitreps WHERE order_id = $1 ORDER BY position`, string(orderID))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sitreps []*order.SitRep
+	for rows.Next() {
+		var sitrep order.SitRep
+		var datetime int64
+		if err := rows.Scan(&sitrep.ID, &datetime, &sitrep.By, &sitrep.Situation, &sitrep.Actions, &sitrep.TODO, &sitrep.Issues); err != nil {
+			return nil, err
+		}
+		sitrep.DateTime = fromUnix(datetime)
+		sitreps = append(sitreps, &sitrep)
+	}
+	return sitreps, rows.Err()
+}
+
+type orderRow struct {
+	TaskID        meta.ID
+	ParentOrderID meta.ID
+	RollupPolicy  *order.RollupPolicy
+	Meta          *meta.Meta
+}
+
+func readOrderRows(ctx context.Context, q querier, query string, args ...any) ([]*orderRow, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orderRows []*orderRow
+	for rows.Next() {
+		var row orderRow
+		var version uint
+		var created, updated, deleted int64
+		var updatedBy string
+		var issuesOnBlocked, completeDelegated, preRollupState int
+		if err := rows.Scan(&row.TaskID, &row.ParentOrderID, &version, &created, &updated, &updatedBy, &deleted, &issuesOnBlocked, &completeDelegated, &preRollupState); err != nil {
+			return nil, err
+		}
+		if issuesOnBlocked != 0 || completeDelegated != 0 {
+			row.RollupPolicy = &order.RollupPolicy{
+				IssuesOnBlocked:   issuesOnBlocked != 0,
+				CompleteDelegated: completeDelegated != 0,
+				PreRollupState:    order.State(preRollupState),
+			}
+		}
+		row.Meta = &meta.Meta{
+			Version:   version,
+			Created:   fromUnix(created),
+			Updated:   fromUnix(updated),
+			UpdatedBy: updatedBy,
+		}
+		row.Meta.SetDeleted(fromUnix(deleted))
+		orderRows = append(orderRows, &row)
+	}
+	return orderRows, rows.Err()
+}
+
+func composeOrder(ctx context.Context, q querier, row *orderRow) (*order.Order, error) {
+	tasks, err := readTasks(ctx, q, `SELECT id, state, accountable, objective, deadline FROM tasks WHERE id = $1`, string(row.TaskID))
+	if err != nil {
+		return nil, err
+	}
+	var task *order.Task
+	if len(tasks) > 0 {
+		task = tasks[0]
+	}
+
+	// NOTE: delegated orders in trash are hidden
+	delegatedTasks, err := readTasks(ctx, q, `SELECT t.id, t.state, t.accountable, t.objective, t.deadline
+		FROM delegated_tasks d JOIN tasks t ON t.id = d.task_id LEFT JOIN orders c ON c.task_id = d.task_id
+		WHERE d.order_id = $1 AND COALESCE(c.deleted, 0) = 0 ORDER BY d.position`, string(row.TaskID))
+	if err != nil {
+		return nil, err
+	}
+
+	sitreps, err := readSitReps(ctx, q, row.TaskID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &order.Order{
+		Task:           task,
+		ParentOrderID:  row.ParentOrderID,
+		DelegatedTasks: delegatedTasks,
+		SitReps:        sitreps,
+		RollupPolicy:   row.RollupPolicy,
+		Meta:           row.Meta,
+	}, nil
+}
+
+const selectOrders = `SELECT o.task_id, o.parent_order_id, o.version, o.created, o.updated, o.updated_by, o.deleted, o.issues_on_blocked, o.complete_delegated, o.pre_rollup_state
+	FROM orders o JOIN tasks t ON t.id = o.task_id`
+
+// readOrder reads the order regardless of whether it's in trash.
+func readOrder(ctx context.Context, q querier, id meta.ID) (*order.Order, error) {
+	rows, err := readOrderRows(ctx, q, selectOrders+` WHERE o.task_id = $1`, string(id))
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return composeOrder(ctx, q, rows[0])
+}
+
+func storeTask(ctx context.Context, q querier, task *order.Task) error {
+	_, err := q.ExecContext(ctx, `INSERT INTO tasks (id, state, accountable, objective, deadline)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (id) DO UPDATE SET
+			state = excluded.state,
+			accountable = excluded.accountable,
+			objective = excluded.objective,
+			deadline = excluded.deadline`,
+		string(task.GetID()), int(task.GetState()), string(task.GetAccountable()), task.GetObjective(), toUnix(task.GetDeadline()))
+	return err
+}
+
+func orderExists(ctx context.Context, q querier, id meta.ID) (bool, error) {
+	var exists int
+	err := q.QueryRowContext(ctx, `SELECT 1 FROM orders WHERE task_id = $1`, string(id)).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// linkDelegated adds the order to its parent's delegated tasks, unless it's already there.
+func linkDelegated(ctx context.Context, q querier, parentID meta.ID, id meta.ID) error {
+	if parentID == id { // NOTE: root order
+		return nil
+	}
+	// NOTE: parent might not be stored
+	_, err := q.ExecContext(ctx, `INSERT INTO delegated_tasks (order_id, task_id, position)
+		SELECT $1, $2, (SELECT COALESCE(MAX(position), -1) + 1 FROM delegated_tasks WHERE order_id = $1)
+		WHERE EXISTS (SELECT 1 FROM orders WHERE task_id = $1)
+		ON CONFLICT (order_id, task_id) DO NOTHING`,
+		string(parentID), string(id))
+	return err
+}
+
+func storeDelegatedTasks(ctx context.Context, q querier, id meta.ID, tasks []*order.Task, m *meta.Meta) error {
+	for _, delegated := range tasks {
+		if err := storeTask(ctx, q, delegated); err != nil {
+			return err
+		}
+		// NOTE: delegated task is an order on its own, created with the same meta as the delegating order
+		_, err := q.ExecContext(ctx, `INSERT INTO orders (task_id, parent_order_id, version, created, updated, updated_by)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (task_id) DO NOTHING`,
+			string(delegated.GetID()), string(id), m.GetVersion(), toUnix(m.GetCreated()), toUnix(m.GetUpdated()), m.GetUpdatedBy())
+		if err != nil {
+			return err
+		}
+		if err := linkDelegated(ctx, q, id, delegated.GetID()); err != nil {
+			return err
+		}
+		if err := reindexSearch(ctx, q, delegated.GetID()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func storeSitReps(ctx context.Context, q querier, id meta.ID, sitreps []*order.SitRep) error {
+	for _, sitrep := range sitreps {
+		_, err := q.ExecContext(ctx, `INSERT INTO sitreps (id, order_id, position, datetime, by_email, situation, actions, todo, issues)
+			VALUES ($1, $2, (SELECT COALESCE(MAX(position), -1) + 1 FROM sitreps WHERE order_id = $2), $3, $4, $5, $6, $7, $8)
+			ON CONFLICT (id) DO UPDATE SET
+				datetime = excluded.datetime,
+				by_email = excluded.by_email,
+				situation = excluded.situation,
+				actions = excluded.actions,
+				todo = excluded.todo,
+				issues = excluded.issues`,
+			string(sitrep.GetID()), string(id), toUnix(sitrep.GetDateTime()), string(sitrep.GetBy()),
+			sitrep.GetSituation(), sitrep.GetActions(), sitrep.GetTODO(), sitrep.GetIssues())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// storeSearchWords replaces the words the order is searched by.
+func storeSearchWords(ctx context.Context, q querier, id meta.ID, words []string) error {
+	if _, err := q.ExecContext(ctx, `DELETE FROM search_words WHERE order_id = $1`, string(id)); err != nil {
+		return err
+	}
+	for _, word := range words {
+		if _, err := q.ExecContext(ctx, `INSERT INTO search_words (word, order_id) VALUES ($1, $2)`, word, string(id)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reindexSearch brings the search words of the stored order up to date, e.g. after its task was changed through the delegating order.
+func reindexSearch(ctx context.Context, q querier, id meta.ID) error {
+	o, err := readOrder(ctx, q, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return storeSearchWords(ctx, q, id, o.SearchWords())
+}
+
+// commitOrder sets the order's meta and keeps the resulting version.
+func commitOrder(ctx context.Context, q querier, id meta.ID, m *meta.Meta) (*order.Order, error) {
+	_, err := q.ExecContext(ctx, `UPDATE orders SET version = $2, created = $3, updated = $4, updated_by = $5, deleted = $6 WHERE task_id = $1`,
+		string(id), m.GetVersion(), toUnix(m.GetCreated()), toUnix(m.GetUpdated()), m.GetUpdatedBy(), toUnix(m.GetDeleted()))
+	if err != nil {
+		return nil, err
+	}
+	o, err := readOrder(ctx, q, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := storeVersion(ctx, q, "order_versions", "order_id", string(id), o.GetMeta().GetVersion(), o); err != nil {
+		return nil, err
+	}
+	if err := storeSearchWords(ctx, q, id, o.SearchWords()); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// change runs fn on the stored order within a transaction, then commits the order with meta m.
+// NOTE: orders in trash can be changed as well
+func (r *SQLRepositoryOrder) change(ctx context.Context, desc string, id meta.ID, m *meta.Meta, fn func(q querier) errwrap.Error) (*order.Order, errwrap.Error) {
+	tx, err := begin(ctx, r.db, r.tx)
+	if err != nil {
+		return nil, internalError("%s failed: %s", desc, err)
+	}
+	defer tx.Rollback()
+
+	exists, err := orderExists(ctx, tx, id)
+	if err != nil {
+		return nil, internalError("%s failed: %s", desc, err)
+	}
+	if !exists {
+		return nil, errwrap.NewError(http.StatusNotFound, "order '%s' not found", id)
+	}
+	if err := fn(tx); err != nil {
+		return nil, err
+	}
+	o, err := commitOrder(ctx, tx, id, m)
+	if err != nil {
+		return nil, internalError("%s failed: %s", desc, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, internalError("%s failed: %s", desc, err)
+	}
+	return o, nil
+}
+
+// conn returns the transaction the repository is bound to, the database otherwise.
+func (r *SQLRepositoryOrder) conn() querier {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db
+}
+
+func (r *SQLRepositoryOrder) WithTx(ctx context.Context, fn func(tx repository.RepositoryOrderAPI) errwrap.Error) errwrap.Error {
+	middleware.SpanStart(ctx, "SQLRepositoryOrder:WithTx")
+	defer middleware.SpanStop(ctx, "SQLRepositoryOrder:WithTx")
+
+	if r == nil || r.db == nil {
+		return errwrap.NewError(http.StatusInternalServerError, "sql repository order uninitialized")
+	}
+	if r.tx != nil { // NOTE: join ongoing transaction
+		return fn(r)
+	}
+	if tx := txFromCtx(ctx, r.db); tx != nil { // NOTE: join transaction passed along in the context, see WithTxContext
+		return fn(&SQLRepositoryOrder{
+			db: r.db,
+			tx: tx,
+		})
+	}
+	return withTx(ctx, r.db, func(tx *sql.Tx) errwrap.Error {
+		return fn(&SQLRepositoryOrder{
+			db: r.db,
+			tx: tx,
+		})
+	})
+}
+
+func (r *SQLRepositoryOrder) Close(ctx context.Context) errwrap.Error {
+	middleware.SpanStart(ctx, "SQLRepositoryOrder:Close")
+	defer middleware.SpanStop(ctx, "SQLRepositoryOrder:Close")
+
+	if r == nil || r.db == nil {
+		return errwrap.NewError(http.StatusInternalServerError, "sql repository order uninitialized")
+	}
+	if err := r.db.Close(); err != nil {
+		return internalError("closing database failed: %s", err)
+	}
+	return nil
+}
+
+func (r *SQLRepositoryOrder) ReadByID(ctx context.Context, id meta.ID) (*order.Order, errwrap.Error) {
+	middleware.SpanStart(ctx, "SQLRepositoryOrder:ReadByID")
+	defer middleware.SpanStop(ctx, "SQLRepositoryOrder:ReadByID")
+
+	if r == nil || r.db == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "sql repository order uninitialized")
+	}
+
+	o, err := readOrder(ctx, r.conn(), id)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && o.GetMeta().IsDeleted()) {
+		return nil, errwrap.NewError(http.StatusNotFound, "not found")
+	}
+	if err != nil {
+		return nil, internalError("reading order failed: %s", err)
+	}
+	return o, nil
+}
+
+// orderSortColumns are the columns orders are listed by.
+var orderSortColumns = map[meta.Sort]string{
+	meta.SortCreated:  "o.created",
+	meta.SortUpdated:  "o.updated",
+	meta.SortDeadline: "t.deadline",
+	meta.SortState:    "t.state",
+}
+
+func (r *SQLRepositoryOrder) ReadBy(ctx context.Context, req *request.GetOrdersRequest) ([]*order.Order, *meta.Page, errwrap.Error) {
+	middleware.SpanStart(ctx, "SQLRepositoryOrder:ReadBy")
+	defer middleware.SpanStop(ctx, "SQLRepositoryOrder:ReadBy")
+
+	if r == nil || r.db == nil {
+		return nil, nil, errwrap.NewError(http.StatusInternalServerError, "sql repository order uninitialized")
+	}
+
+	where := `
+		WHERE o.deleted = 0 AND ($1 = '' OR o.parent_order_id = $1) AND ($2 = '' OR t.accountable = $2)`
+	args := []any{string(req.GetParentOrderID()), string(req.GetAccountable())}
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%v", len(args))
+	}
+
+	if len(req.GetStates()) > 0 {
+		ps := make([]string, len(req.GetStates()))
+		for i, state := range req.GetStates() {
+			ps[i] = arg(int(state))
+		}
+		where += ` AND t.state IN (` + strings.Join(ps, ", ") + `)`
+	}
+	for _, rng := range []struct {
+		column   string
+		from, to time.Time
+	}{
+		{"t.deadline", req.GetDeadlineFrom(), req.GetDeadlineTo()},
+		{"o.created", req.GetCreatedFrom(), req.GetCreatedTo()},
+		{"o.updated", req.GetUpdatedFrom(), req.GetUpdatedTo()},
+	} {
+		if !rng.from.IsZero() {
+			where += ` AND ` + rng.column + ` >= ` + arg(toUnix(rng.from))
+		}
+		if !rng.to.IsZero() {
+			where += ` AND ` + rng.column + ` < ` + arg(toUnix(rng.to))
+		}
+	}
+	if len(req.GetObjective()) > 0 {
+		where += ` AND LOWER(t.objective) LIKE LOWER(` + arg("%"+escapeLike(req.GetObjective())+"%") + `) ESCAPE '\'`
+	}
+	if len(req.GetSitRepBy()) > 0 {
+		where += ` AND EXISTS (SELECT 1 FROM sitreps s WHERE s.order_id = o.task_id AND s.by_email = ` + arg(string(req.GetSitRepBy())) + `)`
+	}
+	if has := req.GetHasDelegatedTasks(); has != nil {
+		// NOTE: delegated orders in trash are hidden, like when composing the order
+		exists := `EXISTS (SELECT 1 FROM delegated_tasks d LEFT JOIN orders c ON c.task_id = d.task_id
+			WHERE d.order_id = o.task_id AND COALESCE(c.deleted, 0) = 0)`
+		if !*has {
+			exists = `NOT ` + exists
+		}
+		where += ` AND ` + exists
+	}
+
+	page := &meta.Page{}
+	if err := r.conn().QueryRowContext(ctx, `SELECT COUNT(*) FROM orders o JOIN tasks t ON t.id = o.task_id`+where, args...).Scan(&page.Total); err != nil {
+		return nil, nil, internalError("counting orders failed: %s", err)
+	}
+
+	column, ok := orderSortColumns[req.GetSort().Field()]
+	if !ok {
+		return nil, nil, errwrap.NewError(http.StatusBadRequest, "invalid sort '%s'", req.GetSort())
+	}
+	query, args, errw := pageQuery(selectOrders+where, args, column, "o.task_id", req.GetSort(), req.GetCursor(), req.GetLimit())
+	if errw != nil {
+		return nil, nil, errw
+	}
+	rows, err := readOrderRows(ctx, r.conn(), query, args...)
+	if err != nil {
+		return nil, nil, internalError("reading orders failed: %s", err)
+	}
+
+	var orders []*order.Order
+	for _, row := range rows {
+		o, err := composeOrder(ctx, r.conn(), row)
+		if err != nil {
+			return nil, nil, internalError("reading orders failed: %s", err)
+		}
+		orders = append(orders, o)
+	}
+	orders, page.NextCursor = cutPage(orders, req.GetSort(), req.GetLimit())
+	return orders, page, nil
+}
+
+func (r *SQLRepositoryOrder) Search(ctx context.Context, req *request.SearchRequest) ([]*order.SearchHit, errwrap.Error) {
+	middleware.SpanStart(ctx, "SQLRepositoryOrder:Search")
+	defer middleware.SpanStop(ctx, "SQLRepositoryOrder:Search")
+
+	if r == nil || r.db == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "sql repository order uninitialized")
+	}
+
+	terms := order.Tokenize(req.GetQuery())
+	if len(terms) == 0 {
+		return nil, nil
+	}
+	query := `SELECT DISTINCT w.order_id FROM search_words w JOIN orders o ON o.task_id = w.order_id
+		WHERE o.deleted = 0 AND w.word LIKE $1 ESCAPE '\'`
+	args := []any{escapeLike(terms[0]) + "%"}
+	for _, term := range terms[1:] {
+		args = append(args, escapeLike(term)+"%")
+		query += fmt.Sprintf(` AND w.order_id IN (SELECT order_id FROM search_words WHERE word LIKE $%v ESCAPE '\')`, len(args))
+	}
+
+	rows, err := r.conn().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, internalError("searching orders failed: %s", err)
+	}
+	var ids []meta.ID
+	for rows.Next() {
+		var id meta.ID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, internalError("searching orders failed: %s", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, internalError("searching orders failed: %s", err)
+	}
+
+	var hits []*order.SearchHit
+	for _, id := range ids {
+		o, err := readOrder(ctx, r.conn(), id)
+		if err != nil {
+			return nil, internalError("searching orders failed: %s", err)
+		}
+		if hit := o.Search(terms); hit != nil {
+			hits = append(hits, hit)
+		}
+	}
+	order.RankHits(hits)
+
+	if req.GetLimit() > 0 && uint(len(hits)) > req.GetLimit() {
+		hits = hits[:req.GetLimit()]
+	}
+	return hits, nil
+}
+
+func (r *SQLRepositoryOrder) ReadDeletedByID(ctx context.Context, id meta.ID) (*order.Order, errwrap.Error) {
+	middleware.SpanStart(ctx, "SQLRepositoryOrder:ReadDeletedByID")
+	defer middleware.SpanStop(ctx, "SQLRepositoryOrder:ReadDeletedByID")
+
+	if r == nil || r.db == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "sql repository order uninitialized")
+	}
+
+	o, err := readOrder(ctx, r.conn(), id)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !o.GetMeta().IsDeleted()) {
+		return nil, errwrap.NewError(http.StatusNotFound, "not found")
+	}
+	if err != nil {
+		return nil, internalError("reading deleted order failed: %s", err)
+	}
+	return o, nil
+}
+
+func (r *SQLRepositoryOrder) ReadDeleted(ctx context.Context) ([]*order.Order, errwrap.Error) {
+	middleware.SpanStart(ctx, "SQLRepositoryOrder:ReadDeleted")
+	defer middleware.SpanStop(ctx, "SQLRepositoryOrder:ReadDeleted")
+
+	if r == nil || r.db == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "sql repository order uninitialized")
+	}
+
+	rows, err := readOrderRows(ctx, r.conn(), selectOrders+` WHERE o.deleted <> 0 ORDER BY o.deleted`)
+	if err != nil {
+		return nil, internalError("reading deleted orders failed: %s", err)
+	}
+
+	var orders []*order.Order
+	for _, row := range rows {
+		o, err := composeOrder(ctx, r.conn(), row)
+		if err != nil {
+			return nil, internalError("reading deleted orders failed: %s", err)
+		}
+		orders = append(orders, o)
+	}
+	return orders, nil
+}
+
+func (r *SQLRepositoryOrder) ReadVersions(ctx context.Context, id meta.ID) ([]*order.Order, errwrap.Error) {
+	middleware.SpanStart(ctx, "SQLRepositoryOrder:ReadVersions")
+	defer middleware.SpanStop(ctx, "SQLRepositoryOrder:ReadVersions")
+
+	if r == nil || r.db == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "sql repository order uninitialized")
+	}
+
+	versions, err := readVersions[order.Order](ctx, r.conn(), "order_versions", "order_id", string(id))
+	if err != nil {
+		return nil, internalError("reading order versions failed: %s", err)
+	}
+	if len(versions) == 0 {
+		return nil, errwrap.NewError(http.StatusNotFound, "not found")
+	}
+	return versions, nil
+}
+
+func (r *SQLRepositoryOrder) ReadVersion(ctx context.Context, id meta.ID, version uint) (*order.Order, errwrap.Error) {
+	middleware.SpanStart(ctx, "SQLRepositoryOrder:ReadVersion")
+	defer middleware.SpanStop(ctx, "SQLRepositoryOrder:ReadVersion")
+
+	if r == nil || r.db == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "sql repository order uninitialized")
+	}
+
+	o, err := readVersion[order.Order](ctx, r.conn(), "order_versions", "order_id", string(id), version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errwrap.NewError(http.StatusNotFound, "not found")
+	}
+	if err != nil {
+		return nil, internalError("reading order version failed: %s", err)
+	}
+	return o, nil
+}
+
+func (r *SQLRepositoryOrder) CreateOrder(ctx context.Context, o *order.Order) (*order.Order, errwrap.Error) {
+	middleware.SpanStart(ctx, "SQLRepositoryOrder:CreateOrder")
+	defer middleware.SpanStop(ctx, "SQLRepositoryOrder:CreateOrder")
+
+	if r == nil || r.db == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "sql repository order uninitialized")
+	}
+
+	tx, err := begin(ctx, r.db, r.tx)
+	if err != nil {
+		return nil, internalError("creating order failed: %s", err)
+	}
+	defer tx.Rollback()
+
+	exists, err := orderExists(ctx, tx, o.GetID())
+	if err != nil {
+		return nil, internalError("creating order failed: %s", err)
+	}
+	if exists {
+		return nil, errwrap.NewError(http.StatusConflict, "order '%s' already exists", o.GetID())
+	}
+
+	if err := storeTask(ctx, tx, o.GetTask()); err != nil {
+		return nil, internalError("creating order failed: %s", err)
+	}
+	_, err = tx.ExecContext(ctx, `INSERT INTO orders (task_id, parent_order_id, version, created, updated, updated_by, deleted, issues_on_blocked, complete_delegated, pre_rollup_state)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		string(o.GetID()), string(o.GetParentOrderID()), o.GetMeta().GetVersion(), toUnix(o.GetMeta().GetCreated()), toUnix(o.GetMeta().GetUpdated()), o.GetMeta().GetUpdatedBy(),
+		toUnix(o.GetMeta().GetDeleted()), toFlag(o.GetRollupPolicy().GetIssuesOnBlocked()), toFlag(o.GetRollupPolicy().GetCompleteDelegated()), int(o.GetRollupPolicy().GetPreRollupState()))
+	if err != nil {
+		return nil, internalError("creating order failed: %s", err)
+	}
+	if err := storeDelegatedTasks(ctx, tx, o.GetID(), o.GetDelegatedTasks(), o.GetMeta()); err != nil {
+		return nil, internalError("creating order failed: %s", err)
+	}
+	if err := storeSitReps(ctx, tx, o.GetID(), o.GetSitReps()); err != nil {
+		return nil, internalError("creating order failed: %s", err)
+	}
+	if err := linkDelegated(ctx, tx, o.GetParentOrderID(), o.GetID()); err != nil {
+		return nil, internalError("creating order failed: %s", err)
+	}
+
+	created, err := commitOrder(ctx, tx, o.GetID(), o.GetMeta())
+	if err != nil {
+		return nil, internalError("creating order failed: %s", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, internalError("creating order failed: %s", err)
+	}
+	return created, nil
+}
+
+func (r *SQLRepositoryOrder) UpdateTask(ctx context.Context, id meta.ID, task *order.Task, m *meta.Meta) (*order.Order, errwrap.Error) {
+	middleware.SpanStart(ctx, "SQLRepositoryOrder:UpdateTask")
+	defer middleware.SpanStop(ctx, "SQLRepositoryOrder:UpdateTask")
+
+	if r == nil || r.db == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "sql repository order uninitialized")
+	}
+
+	return r.change(ctx, "updating task", id, m, func(q querier) errwrap.Error {
+		if task.GetID() != id {
+			var delegated int
+			err := q.QueryRowContext(ctx, `SELECT 1 FROM delegated_tasks WHERE order_id = $1 AND task_id = $2`, string(id), string(task.GetID())).Scan(&delegated)
+			if errors.Is(err, sql.ErrNoRows) {
+				return errwrap.NewError(http.StatusNotFound, "task '%s' not found in order '%s'", task.GetID(), id)
+			}
+			if err != nil {
+				return internalError("updating task failed: %s", err)
+			}
+		}
+		if err := storeTask(ctx, q, task); err != nil {
+			return internalError("updating task failed: %s", err)
+		}
+		if task.GetID() != id {
+			if err := reindexSearch(ctx, q, task.GetID()); err != nil {
+				return internalError("updating task failed: %s", err)
+			}
+		}
+		return nil
+	})
+}
+
+func (r *SQLRepositoryOrder) AppendDelegatedTasks(ctx context.Context, id meta.ID, tasks []*order.Task, m *meta.Meta) (*order.Order, errwrap.Error) {
+	middleware.SpanStart(ctx, "SQLRepositoryOrder:AppendDelegatedTasks")
+	defer middleware.SpanStop(ctx, "SQLRepositoryOrder:AppendDelegatedTasks")
+
+	if r == nil || r.db == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "sql repository order uninitialized")
+	}
+
+	return r.change(ctx, "appending delegated tasks", id, m, func(q querier) errwrap.Error {
+		if err := storeDelegatedTasks(ctx, q, id, tasks, m); err != nil {
+			return internalError("appending delegated tasks failed: %s", err)
+		}
+		return nil
+	})
+}
+
+func (r *SQLRepositoryOrder) RemoveDelegatedTasks(ctx context.Context, id meta.ID, taskIDs []meta.ID, m *meta.Meta) (*order.Order, errwrap.Error) {
+	middleware.SpanStart(ctx, "SQLRepositoryOrder:RemoveDelegatedTasks")
+	defer middleware.SpanStop(ctx, "SQLRepositoryOrder:RemoveDelegatedTasks")
+
+	if r == nil || r.db == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "sql repository order uninitialized")
+	}
+
+	return r.change(ctx, "removing delegated tasks", id, m, func(q querier) errwrap.Error {
+		if len(taskIDs) == 0 {
+			return nil
+		}
+		ps, args := placeholders(2, taskIDs)
+		if _, err := q.ExecContext(ctx, `DELETE FROM delegated_tasks WHERE order_id = $1 AND task_id IN (`+ps+`)`, append([]any{string(id)}, args...)...); err != nil {
+			return internalError("removing delegated tasks failed: %s", err)
+		}
+		return nil
+	})
+}
+
+func (r *SQLRepositoryOrder) AppendSitReps(ctx context.Context, id meta.ID, sitreps []*order.SitRep, m *meta.Meta) (*order.Order, errwrap.Error) {
+	middleware.SpanStart(ctx, "SQLRepositoryOrder:AppendSitReps")
+	defer middleware.SpanStop(ctx, "SQLRepositoryOrder:AppendSitReps")
+
+	if r == nil || r.db == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "sql repository order uninitialized")
+	}
+
+	return r.change(ctx, "appending sitreps", id, m, func(q querier) errwrap.Error {
+		if err := storeSitReps(ctx, q, id, sitreps); err != nil {
+			return internalError("appending sitreps failed: %s", err)
+		}
+		return nil
+	})
+}
+
+func (r *SQLRepositoryOrder) UpdateSitRep(ctx context.Context, id meta.ID, sitrep *order.SitRep, m *meta.Meta) (*order.Order, errwrap.Error) {
+	middleware.SpanStart(ctx, "SQLRepositoryOrder:UpdateSitRep")
+	defer middleware.SpanStop(ctx, "SQLRepositoryOrder:UpdateSitRep")
+
+	if r == nil || r.db == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "sql repository order uninitialized")
+	}
+
+	return r.change(ctx, "updating sitrep", id, m, func(q querier) errwrap.Error {
+		res, err := q.ExecContext(ctx, `UPDATE sitreps SET datetime = $3, by_email = $4, situation = $5, actions = $6, todo = $7, issues = $8
+			WHERE id = $1 AND order_id = $2`,
+			string(sitrep.GetID()), string(id), toUnix(sitrep.GetDateTime()), string(sitrep.GetBy()),
+			sitrep.GetSituation(), sitrep.GetActions(), sitrep.GetTODO(), sitrep.GetIssues())
+		if err != nil {
+			return internalError("updating sitrep failed: %s", err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return internalError("updating sitrep failed: %s", err)
+		}
+		if n == 0 {
+			return errwrap.NewError(http.StatusNotFound, "sitrep '%s' not found in order '%s'", sitrep.GetID(), id)
+		}
+		return nil
+	})
+}
+
+func (r *SQLRepositoryOrder) RemoveSitReps(ctx context.Context, id meta.ID, sitrepIDs []meta.ID, m *meta.Meta) (*order.Order, errwrap.Error) {
+	middleware.SpanStart(ctx, "SQLRepositoryOrder:RemoveSitReps")
+	defer middleware.SpanStop(ctx, "SQLRepositoryOrder:RemoveSitReps")
+
+	if r == nil || r.db == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "sql repository order uninitialized")
+	}
+
+	return r.change(ctx, "removing sitreps", id, m, func(q querier) errwrap.Error {
+		if len(sitrepIDs) == 0 {
+			return nil
+		}
+		ps, args := placeholders(2, sitrepIDs)
+		if _, err := q.ExecContext(ctx, `DELETE FROM sitreps WHERE order_id = $1 AND id IN (`+ps+`)`, append([]any{string(id)}, args...)...); err != nil {
+			return internalError("removing sitreps failed: %s", err)
+		}
+		return nil
+	})
+}
+
+func (r *SQLRepositoryOrder) Reparent(ctx context.Context, id meta.ID, parentID meta.ID, m *meta.Meta) (*order.Order, errwrap.Error) {
+	middleware.SpanStart(ctx, "SQLRepositoryOrder:Reparent")
+	defer middleware.SpanStop(ctx, "SQLRepositoryOrder:Reparent")
+
+	if r == nil || r.db == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "sql repository order uninitialized")
+	}
+
+	return r.change(ctx, "reparenting order", id, m, func(q querier) errwrap.Error {
+		var currentParentID string
+		if err := q.QueryRowContext(ctx, `SELECT parent_order_id FROM orders WHERE task_id = $1`, string(id)).Scan(&currentParentID); err != nil {
+			return internalError("reparenting order failed: %s", err)
+		}
+		if meta.ID(currentParentID) != parentID {
+			if _, err := q.ExecContext(ctx, `DELETE FROM delegated_tasks WHERE order_id = $1 AND task_id = $2`, currentParentID, string(id)); err != nil {
+				return internalError("reparenting order failed: %s", err)
+			}
+			if _, err := q.ExecContext(ctx, `UPDATE orders SET parent_order_id = $2 WHERE task_id = $1`, string(id), string(parentID)); err != nil {
+				return internalError("reparenting order failed: %s", err)
+			}
+		}
+		if err := linkDelegated(ctx, q, parentID, id); err != nil {
+			return internalError("reparenting order failed: %s", err)
+		}
+		return nil
+	})
+}
+
+func (r *SQLRepositoryOrder) UpdateRollupPolicy(ctx context.Context, id meta.ID, policy *order.RollupPolicy, m *meta.Meta) (*order.Order, errwrap.Error) {
+	middleware.SpanStart(ctx, "SQLRepositoryOrder:UpdateRollupPolicy")
+	defer middleware.SpanStop(ctx, "SQLRepositoryOrder:UpdateRollupPolicy")
+
+	if r == nil || r.db == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "sql repository order uninitialized")
+	}
+
+	return r.change(ctx, "updating rollup policy", id, m, func(q querier) errwrap.Error {
+		if _, err := q.ExecContext(ctx, `UPDATE orders SET issues_on_blocked = $2, complete_delegated = $3, pre_rollup_state = $4 WHERE task_id = $1`,
+			string(id), toFlag(policy.GetIssuesOnBlocked()), toFlag(policy.GetCompleteDelegated()), int(policy.GetPreRollupState())); err != nil {
+			return internalError("updating rollup policy failed: %s", err)
+		}
+		return nil
+	})
+}
+
+func (r *SQLRepositoryOrder) UpdateMeta(ctx context.Context, id meta.ID, m *meta.Meta) (*order.Order, errwrap.Error) {
+	middleware.SpanStart(ctx, "SQLRepositoryOrder:UpdateMeta")
+	defer middleware.SpanStop(ctx, "SQLRepositoryOrder:UpdateMeta")
+
+	if r == nil || r.db == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "sql repository order uninitialized")
+	}
+
+	return r.change(ctx, "updating order meta", id, m, func(q querier) errwrap.Error {
+		return nil
+	})
+}
+
+func (r *SQLRepositoryOrder) DeleteOrder(ctx context.Context, id meta.ID) errwrap.Error {
+	middleware.SpanStart(ctx, "SQLRepositoryOrder:DeleteOrder")
+	defer middleware.SpanStop(ctx, "SQLRepositoryOrder:DeleteOrder")
+
+	if r == nil || r.db == nil {
+		return errwrap.NewError(http.StatusInternalServerError, "sql repository order uninitialized")
+	}
+
+	tx, err := begin(ctx, r.db, r.tx)
+	if err != nil {
+		return internalError("deleting order failed: %s", err)
+	}
+	defer tx.Rollback()
+
+	delegated := `SELECT d.task_id FROM delegated_tasks d LEFT JOIN orders c ON c.task_id = d.task_id
+		WHERE d.order_id = $1 AND (COALESCE(c.deleted, 1) <> 0 OR (SELECT deleted FROM orders WHERE task_id = $1) = 0)` // NOTE: live delegated orders of a trashed order are kept
+	stmts := []string{
+		`DELETE FROM tasks WHERE id IN (` + delegated + `)`,
+		`DELETE FROM orders WHERE task_id IN (` + delegated + `)`,
+		`DELETE FROM search_words WHERE order_id IN (` + delegated + `)`,
+		`DELETE FROM delegated_tasks WHERE order_id = $1 OR task_id = $1`,
+		`DELETE FROM sitreps WHERE order_id = $1`,
+		`DELETE FROM tasks WHERE id = $1`,
+		`DELETE FROM orders WHERE task_id = $1`,
+		`DELETE FROM order_versions WHERE order_id = $1`,
+		`DELETE FROM search_words WHERE order_id = $1`,
+	}
+	for _, stmt := range stmts {
+		if _, err := tx.ExecContext(ctx, stmt, string(id)); err != nil {
+			return internalError("deleting order failed: %s", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return internalError("deleting order failed: %s", err)
+	}
+	return nil
+}
+
+func (r *SQLRepositoryOrder) DeleteTasks(ctx context.Context, ids []meta.ID) (bool, errwrap.Error) {
+	middleware.SpanStart(ctx, "SQLRepositoryOrder:DeleteTasks")
+	defer middleware.SpanStop(ctx, "SQLRepositoryOrder:DeleteTasks")
+
+	if r == nil || r.db == nil {
+		return false, errwrap.NewError(http.StatusInternalServerError, "sql repository order uninitialized")
+	}
+	if len(ids) == 0 {
+		return false, nil
+	}
+
+	tx, err := begin(ctx, r.db, r.tx)
+	if err != nil {
+		return false, internalError("deleting tasks failed: %s", err)
+	}
+	defer tx.Rollback()
+
+	ps, args := placeholders(1, ids)
+	res, err := tx.ExecContext(ctx, `DELETE FROM tasks WHERE id IN (`+ps+`)`, args...)
+	if err != nil {
+		return false, internalError("deleting tasks failed: %s", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM delegated_tasks WHERE task_id IN (`+ps+`)`, args...); err != nil {
+		return false, internalError("deleting tasks failed: %s", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM search_words WHERE order_id IN (`+ps+`)`, args...); err != nil {
+		return false, internalError("deleting tasks failed: %s", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return false, internalError("deleting tasks failed: %s", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, internalError("deleting tasks failed: %s", err)
+	}
+	return n > 0, nil
+}
+
+func (r *SQLRepositoryOrder) DeleteSitReps(ctx context.Context, ids []meta.ID) (bool, errwrap.Error) {
+	middleware.SpanStart(ctx, "SQLRepositoryOrder:DeleteSitReps")
+	defer middleware.SpanStop(ctx, "SQLRepositoryOrder:DeleteSitReps")
+
+	if r == nil || r.db == nil {
+		return false, errwrap.NewError(http.StatusInternalServerError, "sql repository order uninitialized")
+	}
+	if len(ids) == 0 {
+		return false, nil
+	}
+
+	ps, args := placeholders(1, ids)
+	res, err := r.conn().ExecContext(ctx, `DELETE FROM sitreps WHERE id IN (`+ps+`)`, args...)
+	if err != nil {
+		return false, internalError("deleting sitreps failed: %s", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, internalError("deleting sitreps failed: %s", err)
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,253 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/moledoc/orderly/internal/domain/errwrap"
+	"github.com/moledoc/orderly/internal/domain/meta"
+)
+
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// Open connects to the database and migrates the schema to the latest version.
+// NOTE: the driver must be registered by the caller, see internal/repository/storage
+func Open(ctx context.Context, driver string, dsn string) (*sql.DB, errwrap.Error) {
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "opening database failed: %s", err)
+	}
+	if driver == DriverSQLite {
+		db.SetMaxOpenConns(1) // NOTE: sqlite allows a single writer
+	}
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, errwrap.NewError(http.StatusInternalServerError, "connecting to database failed: %s", err)
+	}
+	if err := Migrate(ctx, db); err != nil {
+		db.Close()
+		return nil, errwrap.NewError(http.StatusInternalServerError, "migrating database failed: %s", err)
+	}
+	return db, nil
+}
+
+type txer interface {
+	querier
+	Commit() error
+	Rollback() error
+}
+
+// joinedTx is a transaction started by WithTx, it's committed or rolled back by WithTx only.
+type joinedTx struct {
+	*sql.Tx
+}
+
+func (joinedTx) Commit() error {
+	return nil
+}
+
+func (joinedTx) Rollback() error {
+	return nil
+}
+
+// ctxTx is the transaction passed along in the context, see withTxContext.
+type ctxTx struct {
+	db *sql.DB
+	tx *sql.Tx
+}
+
+type ctxTxKey struct{}
+
+// txFromCtx returns the transaction on db passed along in the context, nil when there's none.
+func txFromCtx(ctx context.Context, db *sql.DB) *sql.Tx {
+	shared, ok := ctx.Value(ctxTxKey{}).(*ctxTx)
+	if !ok || shared.db != db {
+		return nil
+	}
+	return shared.tx
+}
+
+// withTxContext runs fn within a transaction passed along in the context, joining the one already there.
+func withTxContext(ctx context.Context, db *sql.DB, fn func(ctx context.Context) errwrap.Error) errwrap.Error {
+	if txFromCtx(ctx, db) != nil {
+		return fn(ctx)
+	}
+	return withTx(ctx, db, func(tx *sql.Tx) errwrap.Error {
+		return fn(context.WithValue(ctx, ctxTxKey{}, &ctxTx{db: db, tx: tx}))
+	})
+}
+
+// begin starts a transaction, or joins tx when the repository is bound to one or it's passed along in the context.
+func begin(ctx context.Context, db *sql.DB, tx *sql.Tx) (txer, error) {
+	if tx == nil {
+		tx = txFromCtx(ctx, db)
+	}
+	if tx != nil {
+		return joinedTx{Tx: tx}, nil
+	}
+	newTx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return newTx, nil
+}
+
+// withTx runs fn within a new transaction, committed when fn succeeds.
+func withTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) errwrap.Error) errwrap.Error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return internalError("starting transaction failed: %s", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return internalError("committing transaction failed: %s", err)
+	}
+	return nil
+}
+
+func toUnix(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func fromUnix(nsec int64) time.Time {
+	if nsec == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nsec).UTC()
+}
+
+// NOTE: flags are stored as integers, since not every driver maps bool onto an integer column
+func toFlag(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// placeholders returns "$from, $from+1, ..." for the values and the matching args.
+func placeholders[T ~string](from int, vals []T) (string, []any) {
+	ps := make([]string, len(vals))
+	args := make([]any, len(vals))
+	for i, val := range vals {
+		ps[i] = fmt.Sprintf("$%v", from+i)
+		args[i] = string(val)
+	}
+	return strings.Join(ps, ", "), args
+}
+
+// escapeLike escapes the LIKE wildcards in s, the query has to use ESCAPE '\'.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// pageQuery orders the query of the matches by the sort column and id column, continuing after the cursor.
+// NOTE: one row past the limit is read, it tells whether there's a next page, see cutPage
+func pageQuery(query string, args []any, column string, id string, sort meta.Sort, cursor string, limit uint) (string, []any, errwrap.Error) {
+	sort = sort.Normalized()
+	if len(cursor) > 0 {
+		c, err := meta.DecodeCursor(cursor, sort)
+		if err != nil {
+			return "", nil, errwrap.NewError(http.StatusBadRequest, "invalid cursor: %s", err)
+		}
+		op := ">"
+		if sort.IsDesc() {
+			op = "<"
+		}
+		n := len(args) + 1
+		query += fmt.Sprintf(" AND (%[1]s %[2]s $%[3]v OR (%[1]s = $%[3]v AND %[4]s > $%[5]v))", column, op, n, id, n+1)
+		args = append(args, c.Key, string(c.ID))
+	}
+
+	order := "ASC"
+	if sort.IsDesc() {
+		order = "DESC"
+	}
+	query += fmt.Sprintf(" ORDER BY %s %s, %s ASC", column, order, id)
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%v", len(args)+1)
+		args = append(args, limit+1)
+	}
+	return query, args, nil
+}
+
+// cutPage drops the row read past the limit and returns the cursor to the next page, empty on the last page.
+func cutPage[T interface {
+	GetID() meta.ID
+	SortKey(field meta.Sort) int64
+}](items []T, sort meta.Sort, limit uint) ([]T, string) {
+	if limit == 0 || uint(len(items)) <= limit {
+		return items, ""
+	}
+	sort = sort.Normalized()
+	items = items[:limit]
+	last := items[len(items)-1]
+	return items, (&meta.Cursor{Sort: sort, Key: last.SortKey(sort.Field()), ID: last.GetID()}).Encode()
+}
+
+// storeVersion keeps the JSON encoded copy of the entity at its current version.
+// NOTE: writes that don't bump the version replace the stored copy
+func storeVersion(ctx context.Context, q querier, table string, column string, id string, version uint, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = q.ExecContext(ctx, `INSERT INTO `+table+` (`+column+`, version, data) VALUES ($1, $2, $3)
+		ON CONFLICT (`+column+`, version) DO UPDATE SET data = excluded.data`,
+		id, version, string(data))
+	return err
+}
+
+// readVersions decodes the stored copies of the entity, oldest first.
+func readVersions[T any](ctx context.Context, q querier, table string, column string, id string) ([]*T, error) {
+	rows, err := q.QueryContext(ctx, `SELECT data FROM `+table+` WHERE `+column+` = $1 ORDER BY version`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var versions []*T
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var v T
+		if err := json.Unmarshal([]byte(data), &v); err != nil {
+			return nil, err
+		}
+		versions = append(versions, &v)
+	}
+	return versions, rows.Err()
+}
+
+func readVersion[T any](ctx context.Context, q querier, table string, column string, id string, version uint) (*T, error) {
+	var data string
+	err := q.QueryRowContext(ctx, `SELECT data FROM `+table+` WHERE `+column+` = $1 AND version = $2`, id, version).Scan(&data)
+	if err != nil {
+		return nil, err
+	}
+	var v T
+	if err := json.Unmarshal([]byte(data), &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func internalError(format string, a ...any) errwrap.Error {
+	return errwrap.NewError(http.StatusInternalServerError, format, a...)
+}
@@ -0,0 +1,350 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/moledoc/orderly/internal/domain/errwrap"
+	"github.com/moledoc/orderly/internal/domain/meta"
+	"github.com/moledoc/orderly/internal/domain/request"
+	"github.com/moledoc/orderly/internal/domain/user"
+	"github.com/moledoc/orderly/internal/middleware"
+	"github.com/moledoc/orderly/internal/repository"
+)
+
+type SQLRepositoryUser struct {
+	db *sql.DB
+	tx *sql.Tx // NOTE: set on the repository bound to a transaction, see WithTx
+}
+
+var (
+	_ repository.RepositoryUserAPI   = (*SQLRepositoryUser)(nil)
+	_ repository.RepositoryTxContext = (*SQLRepositoryUser)(nil)
+)
+
+func NewSQLRepositoryUser(db *sql.DB) *SQLRepositoryUser {
+	return &SQLRepositoryUser{
+		db: db,
+	}
+}
+
+const selectUsers = `SELECT id, name, email, supervisor, version, created, updated, updated_by, deleted FROM users`
+
+func readUsers(ctx context.Context, q querier, query string, args ...any) ([]*user.User, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []*user.User
+	for rows.Next() {
+		var u user.User
+		var version uint
+		var created, updated, deleted int64
+		var updatedBy string
+		if err := rows.Scan(&u.ID, &u.Name, &u.Email, &u.Supervisor, &version, &created, &updated, &updatedBy, &deleted); err != nil {
+			return nil, err
+		}
+		u.Meta = &meta.Meta{
+			Version:   version,
+			Created:   fromUnix(created),
+			Updated:   fromUnix(updated),
+			UpdatedBy: updatedBy,
+		}
+		u.Meta.SetDeleted(fromUnix(deleted))
+		users = append(users, &u)
+	}
+	return users, rows.Err()
+}
+
+// conn returns the transaction the repository is bound to, the database otherwise.
+func (r *SQLRepositoryUser) conn() querier {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db
+}
+
+func (r *SQLRepositoryUser) WithTx(ctx context.Context, fn func(tx repository.RepositoryUserAPI) errwrap.Error) errwrap.Error {
+	middleware.SpanStart(ctx, "SQLRepositoryUser:WithTx")
+	defer middleware.SpanStop(ctx, "SQLRepositoryUser:WithTx")
+
+	if r == nil || r.db == nil {
+		return errwrap.NewError(http.StatusInternalServerError, "sql repository user uninitialized")
+	}
+	if r.tx != nil { // NOTE: join ongoing transaction
+		return fn(r)
+	}
+	if tx := txFromCtx(ctx, r.db); tx != nil { // NOTE: join transaction passed along in the context, see WithTxContext
+		return fn(&SQLRepositoryUser{
+			db: r.db,
+			tx: tx,
+		})
+	}
+	return withTx(ctx, r.db, func(tx *sql.Tx) errwrap.Error {
+		return fn(&SQLRepositoryUser{
+			db: r.db,
+			tx: tx,
+		})
+	})
+}
+
+func (r *SQLRepositoryUser) WithTxContext(ctx context.Context, fn func(ctx context.Context) errwrap.Error) errwrap.Error {
+	middleware.SpanStart(ctx, "SQLRepositoryUser:WithTxContext")
+	defer middleware.SpanStop(ctx, "SQLRepositoryUser:WithTxContext")
+
+	if r == nil || r.db == nil {
+		return errwrap.NewError(http.StatusInternalServerError, "sql repository user uninitialized")
+	}
+	return withTxContext(ctx, r.db, fn)
+}
+
+func (r *SQLRepositoryUser) Close(ctx context.Context) errwrap.Error {
+	middleware.SpanStart(ctx, "SQLRepositoryUser:Close")
+	defer middleware.SpanStop(ctx, "SQLRepositoryUser:Close")
+
+	if r == nil || r.db == nil {
+		return errwrap.NewError(http.StatusInternalServerError, "sql repository user uninitialized")
+	}
+	if err := r.db.Close(); err != nil {
+		return internalError("closing database failed: %s", err)
+	}
+	return nil
+}
+
+func (r *SQLRepositoryUser) ReadByID(ctx context.Context, id meta.ID) (*user.User, errwrap.Error) {
+	middleware.SpanStart(ctx, "SQLRepositoryUser:ReadByID")
+	defer middleware.SpanStop(ctx, "SQLRepositoryUser:ReadByID")
+
+	if r == nil || r.db == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "sql repository user uninitialized")
+	}
+
+	users, err := readUsers(ctx, r.conn(), selectUsers+` WHERE deleted = 0 AND id = $1`, string(id))
+	if err != nil {
+		return nil, internalError("reading user failed: %s", err)
+	}
+	if len(users) == 0 {
+		return nil, errwrap.NewError(http.StatusNotFound, "not found")
+	}
+	return users[0], nil
+}
+
+// userSortColumns are the columns users are listed by.
+var userSortColumns = map[meta.Sort]string{
+	meta.SortCreated: "created",
+	meta.SortUpdated: "updated",
+}
+
+func (r *SQLRepositoryUser) ReadBy(ctx context.Context, req *request.GetUsersRequest) ([]*user.User, *meta.Page, errwrap.Error) {
+	middleware.SpanStart(ctx, "SQLRepositoryUser:ReadBy")
+	defer middleware.SpanStop(ctx, "SQLRepositoryUser:ReadBy")
+
+	if r == nil || r.db == nil {
+		return nil, nil, errwrap.NewError(http.StatusInternalServerError, "sql repository user uninitialized")
+	}
+
+	where := ` WHERE deleted = 0 AND ($1 = '' OR supervisor = $1)`
+	args := []any{string(req.GetSupervisor())}
+	if len(req.GetEmails()) > 0 {
+		ps, emailArgs := placeholders(2, req.GetEmails())
+		where += ` AND email IN (` + ps + `)`
+		args = append(args, emailArgs...)
+	}
+
+	page := &meta.Page{}
+	if err := r.conn().QueryRowContext(ctx, `SELECT COUNT(*) FROM users`+where, args...).Scan(&page.Total); err != nil {
+		return nil, nil, internalError("counting users failed: %s", err)
+	}
+
+	column, ok := userSortColumns[req.GetSort().Field()]
+	if !ok {
+		return nil, nil, errwrap.NewError(http.StatusBadRequest, "invalid sort '%s'", req.GetSort())
+	}
+	query, args, errw := pageQuery(selectUsers+where, args, column, "id", req.GetSort(), req.GetCursor(), req.GetLimit())
+	if errw != nil {
+		return nil, nil, errw
+	}
+	users, err := readUsers(ctx, r.conn(), query, args...)
+	if err != nil {
+		return nil, nil, internalError("reading users failed: %s", err)
+	}
+	users, page.NextCursor = cutPage(users, req.GetSort(), req.GetLimit())
+	return users, page, nil
+}
+
+func (r *SQLRepositoryUser) ReadDeletedByID(ctx context.Context, id meta.ID) (*user.User, errwrap.Error) {
+	middleware.SpanStart(ctx, "SQLRepositoryUser:ReadDeletedByID")
+	defer middleware.SpanStop(ctx, "SQLRepositoryUser:ReadDeletedByID")
+
+	if r == nil || r.db == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "sql repository user uninitialized")
+	}
+
+	users, err := readUsers(ctx, r.conn(), selectUsers+` WHERE deleted <> 0 AND id = $1`, string(id))
+	if err != nil {
+		return nil, internalError("reading deleted user failed: %s", err)
+	}
+	if len(users) == 0 {
+		return nil, errwrap.NewError(http.StatusNotFound, "not found")
+	}
+	return users[0], nil
+}
+
+func (r *SQLRepositoryUser) ReadDeleted(ctx context.Context) ([]*user.User, errwrap.Error) {
+	middleware.SpanStart(ctx, "SQLRepositoryUser:ReadDeleted")
+	defer middleware.SpanStop(ctx, "SQLRepositoryUser:ReadDeleted")
+
+	if r == nil || r.db == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "sql repository user uninitialized")
+	}
+
+	users, err := readUsers(ctx, r.conn(), selectUsers+` WHERE deleted <> 0 ORDER BY deleted`)
+	if err != nil {
+		return nil, internalError("reading deleted users failed: %s", err)
+	}
+	return users, nil
+}
+
+func (r *SQLRepositoryUser) ReadVersions(ctx context.Context, id meta.ID) ([]*user.User, errwrap.Error) {
+	middleware.SpanStart(ctx, "SQLRepositoryUser:ReadVersions")
+	defer middleware.SpanStop(ctx, "SQLRepositoryUser:ReadVersions")
+
+	if r == nil || r.db == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "sql repository user uninitialized")
+	}
+
+	versions, err := readVersions[user.User](ctx, r.conn(), "user_versions", "user_id", string(id))
+	if err != nil {
+		return nil, internalError("reading user versions failed: %s", err)
+	}
+	if len(versions) == 0 {
+		return nil, errwrap.NewError(http.StatusNotFound, "not found")
+	}
+	return versions, nil
+}
+
+func (r *SQLRepositoryUser) ReadVersion(ctx context.Context, id meta.ID, version uint) (*user.User, errwrap.Error) {
+	middleware.SpanStart(ctx, "SQLRepositoryUser:ReadVersion")
+	defer middleware.SpanStop(ctx, "SQLRepositoryUser:ReadVersion")
+
+	if r == nil || r.db == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "sql repository user uninitialized")
+	}
+
+	u, err := readVersion[user.User](ctx, r.conn(), "user_versions", "user_id", string(id), version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errwrap.NewError(http.StatusNotFound, "not found")
+	}
+	if err != nil {
+		return nil, internalError("reading user version failed: %s", err)
+	}
+	return u, nil
+}
+
+func (r *SQLRepositoryUser) CreateUser(ctx context.Context, u *user.User) (*user.User, errwrap.Error) {
+	middleware.SpanStart(ctx, "SQLRepositoryUser:CreateUser")
+	defer middleware.SpanStop(ctx, "SQLRepositoryUser:CreateUser")
+
+	if r == nil || r.db == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "sql repository user uninitialized")
+	}
+
+	tx, err := begin(ctx, r.db, r.tx)
+	if err != nil {
+		return nil, internalError("creating user failed: %s", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `INSERT INTO users (id, name, email, supervisor, version, created, updated, updated_by, deleted)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (id) DO NOTHING`,
+		string(u.GetID()), u.GetName(), string(u.GetEmail()), string(u.GetSupervisor()),
+		u.GetMeta().GetVersion(), toUnix(u.GetMeta().GetCreated()), toUnix(u.GetMeta().GetUpdated()), u.GetMeta().GetUpdatedBy(),
+		toUnix(u.GetMeta().GetDeleted()))
+	if err != nil {
+		return nil, internalError("creating user failed: %s", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return nil, internalError("creating user failed: %s", err)
+	}
+	if n == 0 {
+		return nil, errwrap.NewError(http.StatusConflict, "user '%s' already exists", u.GetID())
+	}
+	if err := storeVersion(ctx, tx, "user_versions", "user_id", string(u.GetID()), u.GetMeta().GetVersion(), u); err != nil {
+		return nil, internalError("creating user version failed: %s", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, internalError("creating user failed: %s", err)
+	}
+	return u, nil
+}
+
+func (r *SQLRepositoryUser) UpdateUser(ctx context.Context, u *user.User) (*user.User, errwrap.Error) {
+	middleware.SpanStart(ctx, "SQLRepositoryUser:UpdateUser")
+	defer middleware.SpanStop(ctx, "SQLRepositoryUser:UpdateUser")
+
+	if r == nil || r.db == nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "sql repository user uninitialized")
+	}
+
+	tx, err := begin(ctx, r.db, r.tx)
+	if err != nil {
+		return nil, internalError("updating user failed: %s", err)
+	}
+	defer tx.Rollback()
+
+	// NOTE: users in trash are updated as well
+	res, err := tx.ExecContext(ctx, `UPDATE users SET name = $2, email = $3, supervisor = $4, version = $5, created = $6, updated = $7, updated_by = $8, deleted = $9
+		WHERE id = $1`,
+		string(u.GetID()), u.GetName(), string(u.GetEmail()), string(u.GetSupervisor()),
+		u.GetMeta().GetVersion(), toUnix(u.GetMeta().GetCreated()), toUnix(u.GetMeta().GetUpdated()), u.GetMeta().GetUpdatedBy(),
+		toUnix(u.GetMeta().GetDeleted()))
+	if err != nil {
+		return nil, internalError("updating user failed: %s", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return nil, internalError("updating user failed: %s", err)
+	}
+	if n == 0 {
+		return nil, errwrap.NewError(http.StatusNotFound, "user '%s' not found", u.GetID())
+	}
+	if err := storeVersion(ctx, tx, "user_versions", "user_id", string(u.GetID()), u.GetMeta().GetVersion(), u); err != nil {
+		return nil, internalError("updating user version failed: %s", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, internalError("updating user failed: %s", err)
+	}
+	return u, nil
+}
+
+func (r *SQLRepositoryUser) Delete(ctx context.Context, id meta.ID) errwrap.Error {
+	middleware.SpanStart(ctx, "SQLRepositoryUser:Delete")
+	defer middleware.SpanStop(ctx, "SQLRepositoryUser:Delete")
+
+	if r == nil || r.db == nil {
+		return errwrap.NewError(http.StatusInternalServerError, "sql repository user uninitialized")
+	}
+
+	tx, err := begin(ctx, r.db, r.tx)
+	if err != nil {
+		return internalError("deleting user failed: %s", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM users WHERE id = $1`, string(id)); err != nil {
+		return internalError("deleting user failed: %s", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_versions WHERE user_id = $1`, string(id)); err != nil {
+		return internalError("deleting user failed: %s", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return internalError("deleting user failed: %s", err)
+	}
+	return nil
+}
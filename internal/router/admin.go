@@ -0,0 +1,46 @@
+package router
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/moledoc/orderly/internal/domain/dump"
+	"github.com/moledoc/orderly/internal/domain/request"
+	"github.com/moledoc/orderly/internal/domain/response"
+	"github.com/moledoc/orderly/internal/middleware"
+)
+
+// export responds with the document itself, so that it can be imported as is
+func export(w http.ResponseWriter, r *http.Request) {
+	ctx := middleware.AddTraceToCtxFromWriter(context.Background(), w)
+	defer func() { go middleware.SpanFlushTrace(ctx) }()
+
+	middleware.SpanStart(ctx, "export")
+	defer middleware.SpanStop(ctx, "export")
+
+	resp, err := adminsvc.Export(ctx, &request.ExportRequest{})
+	writeResponse(ctx, w, resp.GetDocument(), err, http.StatusOK)
+}
+
+// importDocument reads the document from the body and the mode from the query
+func importDocument(w http.ResponseWriter, r *http.Request) {
+	ctx := middleware.AddTraceToCtxFromWriter(context.Background(), w)
+	ctx = middleware.AddUserToCtxFromRequest(ctx, r)
+	defer func() { go middleware.SpanFlushTrace(ctx) }()
+
+	middleware.SpanStart(ctx, "importDocument")
+	defer middleware.SpanStop(ctx, "importDocument")
+
+	req := &request.ImportRequest{
+		Mode: dump.Mode(r.URL.Query().Get("mode")),
+	}
+	var resp *response.ImportResponse
+	var doc dump.Document
+	err := decodeBody(ctx, r, &doc)
+	if err == nil {
+		req.Document = &doc
+		middleware.SpanLog(ctx, "ImportRequestMode", req.GetMode()) // NOTE: document is too big to be logged
+		resp, err = adminsvc.Import(ctx, req)
+	}
+	writeResponse(ctx, w, resp, err, http.StatusOK)
+}
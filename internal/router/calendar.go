@@ -0,0 +1,52 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/moledoc/orderly/internal/domain/errwrap"
+	"github.com/moledoc/orderly/internal/domain/meta"
+	"github.com/moledoc/orderly/internal/domain/order"
+	"github.com/moledoc/orderly/internal/domain/request"
+	"github.com/moledoc/orderly/internal/middleware"
+)
+
+// getCalendar responds with the deadline feed of the user, so that calendar apps can subscribe to it.
+// NOTE: path patterns can't match a wildcard with a suffix, so '.ics' is trimmed here
+func getCalendar(w http.ResponseWriter, r *http.Request) {
+	ctx := middleware.AddTraceToCtxFromWriter(context.Background(), w)
+	defer func() { go middleware.SpanFlushTrace(ctx) }()
+
+	middleware.SpanStart(ctx, "getCalendar")
+	defer middleware.SpanStop(ctx, "getCalendar")
+
+	id, ok := strings.CutSuffix(r.PathValue(calendarFile), ".ics")
+	if !ok {
+		writeResponse(ctx, w, nil, errwrap.NewError(http.StatusNotFound, "calendar '%s' not found, expected '<user_id>.ics'", r.PathValue(calendarFile)), http.StatusOK)
+		return
+	}
+
+	respUser, err := mgmtusersvc.GetUserByID(ctx, &request.GetUserByIDRequest{
+		ID: meta.ID(id),
+	})
+	if err != nil {
+		writeResponse(ctx, w, nil, err, http.StatusOK)
+		return
+	}
+
+	req := &request.GetCalendarRequest{
+		Accountable: respUser.GetUser().GetEmail(),
+		RootOrderID: meta.ID(r.URL.Query().Get("root_order_id")),
+	}
+	middleware.SpanLog(ctx, "GetCalendarRequest", req)
+	resp, err := mgmtordersvc.GetCalendar(ctx, req)
+	if err != nil {
+		writeResponse(ctx, w, nil, err, http.StatusOK)
+		return
+	}
+	w.Header().Add("Content-Type", order.CalendarContentType)
+	w.Header().Add("Content-Disposition", `inline; filename="`+id+`.ics"`)
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(resp.GetCalendar()))
+}
@@ -4,9 +4,12 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/moledoc/orderly/internal/domain/errwrap"
 	"github.com/moledoc/orderly/internal/domain/meta"
+	"github.com/moledoc/orderly/internal/domain/order"
 	"github.com/moledoc/orderly/internal/domain/request"
 	"github.com/moledoc/orderly/internal/domain/response"
 	"github.com/moledoc/orderly/internal/domain/user"
@@ -15,6 +18,7 @@ import (
 
 func postOrder(w http.ResponseWriter, r *http.Request) {
 	ctx := middleware.AddTraceToCtxFromWriter(context.Background(), w)
+	ctx = middleware.AddUserToCtxFromRequest(ctx, r)
 	defer func() { go middleware.SpanFlushTrace(ctx) }()
 
 	middleware.SpanStart(ctx, "postOrder")
@@ -48,9 +52,65 @@ func getOrderByID(w http.ResponseWriter, r *http.Request) {
 	}
 	middleware.SpanLog(ctx, "GetOrderByIDRequest", req)
 	resp, err := mgmtordersvc.GetOrderByID(ctx, req)
+	if err == nil {
+		setETag(w, resp.GetOrder().GetMeta().GetVersion())
+	}
 	writeResponse(ctx, w, resp, err, http.StatusOK)
 }
 
+// parseGetOrdersRequest reads the filters from query parameters, state can be given multiple times
+func parseGetOrdersRequest(r *http.Request) (*request.GetOrdersRequest, errwrap.Error) {
+	query := r.URL.Query()
+
+	limit, errp := strconv.ParseUint(query.Get("limit"), 10, 0)
+	if errp != nil && len(query.Get("limit")) > 0 {
+		return nil, errwrap.NewError(http.StatusBadRequest, "invalid limit: %s", errp)
+	}
+
+	req := &request.GetOrdersRequest{
+		ParentOrderID: meta.ID(query.Get("parent_order_id")),
+		Accountable:   user.Email(query.Get("accountable")),
+		Objective:     query.Get("objective"),
+		SitRepBy:      user.Email(query.Get("sitrep_by")),
+		Limit:         uint(limit),
+		Cursor:        query.Get("cursor"),
+		Sort:          meta.Sort(query.Get("sort")),
+	}
+
+	for _, value := range query["state"] {
+		state, errp := order.ParseState(value)
+		if errp != nil {
+			return nil, errwrap.NewError(http.StatusBadRequest, "invalid state: %s", errp)
+		}
+		req.States = append(req.States, state)
+	}
+
+	for name, field := range map[string]*time.Time{
+		"deadline_from": &req.DeadlineFrom,
+		"deadline_to":   &req.DeadlineTo,
+		"created_from":  &req.CreatedFrom,
+		"created_to":    &req.CreatedTo,
+		"updated_from":  &req.UpdatedFrom,
+		"updated_to":    &req.UpdatedTo,
+	} {
+		t, err := queryTime(r, name)
+		if err != nil {
+			return nil, err
+		}
+		*field = t
+	}
+
+	if value := query.Get("has_delegated_tasks"); len(value) > 0 {
+		has, errp := strconv.ParseBool(value)
+		if errp != nil {
+			return nil, errwrap.NewError(http.StatusBadRequest, "invalid has_delegated_tasks: %s", errp)
+		}
+		req.HasDelegatedTasks = &has
+	}
+
+	return req, nil
+}
+
 func getOrders(w http.ResponseWriter, r *http.Request) {
 	ctx := middleware.AddTraceToCtxFromWriter(context.Background(), w)
 	defer func() { go middleware.SpanFlushTrace(ctx) }()
@@ -58,9 +118,10 @@ func getOrders(w http.ResponseWriter, r *http.Request) {
 	middleware.SpanStart(ctx, "getOrders")
 	defer middleware.SpanStop(ctx, "getOrders")
 
-	req := &request.GetOrdersRequest{
-		ParentOrderID: meta.ID(r.URL.Query().Get("parent_order_id")),
-		Accountable:   user.Email(r.URL.Query().Get("accountable")),
+	req, err := parseGetOrdersRequest(r)
+	if err != nil {
+		writeResponse(ctx, w, nil, err, http.StatusOK)
+		return
 	}
 
 	middleware.SpanLog(ctx, "GetOrdersRequest", req)
@@ -70,6 +131,7 @@ func getOrders(w http.ResponseWriter, r *http.Request) {
 
 func patchOrder(w http.ResponseWriter, r *http.Request) {
 	ctx := middleware.AddTraceToCtxFromWriter(context.Background(), w)
+	ctx = middleware.AddUserToCtxFromRequest(ctx, r)
 	defer func() { go middleware.SpanFlushTrace(ctx) }()
 
 	middleware.SpanStart(ctx, "patchOrder")
@@ -80,25 +142,76 @@ func patchOrder(w http.ResponseWriter, r *http.Request) {
 	var err errwrap.Error
 
 	err = decodeBody(ctx, r, &req)
+	if err == nil {
+		req.ExpectedVersion, err = ifMatchVersion(r, req.GetExpectedVersion())
+	}
 	if err == nil {
 		middleware.SpanLog(ctx, "PatchOrderRequest", &req)
 		resp, err = mgmtordersvc.PatchOrder(ctx, &req)
 	}
+	if err == nil {
+		setETag(w, resp.GetOrder().GetMeta().GetVersion())
+	}
+	writeResponse(ctx, w, resp, err, http.StatusOK)
+}
+
+func moveOrder(w http.ResponseWriter, r *http.Request) {
+	ctx := middleware.AddTraceToCtxFromWriter(context.Background(), w)
+	ctx = middleware.AddUserToCtxFromRequest(ctx, r)
+	defer func() { go middleware.SpanFlushTrace(ctx) }()
+
+	middleware.SpanStart(ctx, "moveOrder")
+	defer middleware.SpanStop(ctx, "moveOrder")
+
+	req := &request.MoveOrderRequest{}
+	var resp *response.MoveOrderResponse
+	var err errwrap.Error
+
+	err = decodeBody(ctx, r, req)
+	req.ID = meta.ID(r.PathValue(orderID))
+	if err == nil {
+		req.ExpectedVersion, err = ifMatchVersion(r, req.GetExpectedVersion())
+	}
+	if err == nil {
+		middleware.SpanLog(ctx, "MoveOrderRequest", req)
+		resp, err = mgmtordersvc.MoveOrder(ctx, req)
+	}
+	if err == nil {
+		setETag(w, resp.GetOrder().GetMeta().GetVersion())
+	}
 	writeResponse(ctx, w, resp, err, http.StatusOK)
 }
 
 func deleteOrder(w http.ResponseWriter, r *http.Request) {
 	ctx := middleware.AddTraceToCtxFromWriter(context.Background(), w)
+	ctx = middleware.AddUserToCtxFromRequest(ctx, r)
 	defer func() { go middleware.SpanFlushTrace(ctx) }()
 
 	middleware.SpanStart(ctx, "deleteOrder")
 	defer middleware.SpanStop(ctx, "deleteOrder")
 
+	cascade, errp := strconv.ParseBool(r.URL.Query().Get("cascade"))
+	if errp != nil && len(r.URL.Query().Get("cascade")) > 0 {
+		writeResponse(ctx, w, nil, errwrap.NewError(http.StatusBadRequest, "invalid cascade: %s", errp), http.StatusOK)
+		return
+	}
+	dryRun, errp := strconv.ParseBool(r.URL.Query().Get("dry_run"))
+	if errp != nil && len(r.URL.Query().Get("dry_run")) > 0 {
+		writeResponse(ctx, w, nil, errwrap.NewError(http.StatusBadRequest, "invalid dry_run: %s", errp), http.StatusOK)
+		return
+	}
+
 	req := &request.DeleteOrderRequest{
-		ID: meta.ID(r.PathValue(orderID)),
+		ID:      meta.ID(r.PathValue(orderID)),
+		Cascade: cascade,
+		DryRun:  dryRun,
 	}
 	middleware.SpanLog(ctx, "DeleteOrderRequest", req)
 	resp, err := mgmtordersvc.DeleteOrder(ctx, req)
+	if req.GetDryRun() {
+		writeResponse(ctx, w, resp, err, http.StatusOK)
+		return
+	}
 	writeResponse(ctx, w, resp, err, http.StatusNoContent)
 }
 
@@ -106,6 +219,7 @@ func deleteOrder(w http.ResponseWriter, r *http.Request) {
 
 func putDelegatedTasks(w http.ResponseWriter, r *http.Request) {
 	ctx := middleware.AddTraceToCtxFromWriter(context.Background(), w)
+	ctx = middleware.AddUserToCtxFromRequest(ctx, r)
 	defer func() { go middleware.SpanFlushTrace(ctx) }()
 
 	middleware.SpanStart(ctx, "putDelegatedTask")
@@ -127,6 +241,7 @@ func putDelegatedTasks(w http.ResponseWriter, r *http.Request) {
 
 func patchDelegatedTasks(w http.ResponseWriter, r *http.Request) {
 	ctx := middleware.AddTraceToCtxFromWriter(context.Background(), w)
+	ctx = middleware.AddUserToCtxFromRequest(ctx, r)
 	defer func() { go middleware.SpanFlushTrace(ctx) }()
 
 	middleware.SpanStart(ctx, "patchDelegatedTask")
@@ -139,15 +254,22 @@ func patchDelegatedTasks(w http.ResponseWriter, r *http.Request) {
 	var err errwrap.Error
 
 	err = decodeBody(ctx, r, &req)
+	if err == nil {
+		req.ExpectedVersion, err = ifMatchVersion(r, req.GetExpectedVersion())
+	}
 	if err == nil {
 		middleware.SpanLog(ctx, "PatchDelegatedTaskRequest", &req)
 		resp, err = mgmtordersvc.PatchDelegatedTasks(ctx, &req)
 	}
+	if err == nil {
+		setETag(w, resp.GetOrder().GetMeta().GetVersion())
+	}
 	writeResponse(ctx, w, resp, err, http.StatusOK)
 }
 
 func deleteDelegatedTasks(w http.ResponseWriter, r *http.Request) {
 	ctx := middleware.AddTraceToCtxFromWriter(context.Background(), w)
+	ctx = middleware.AddUserToCtxFromRequest(ctx, r)
 	defer func() { go middleware.SpanFlushTrace(ctx) }()
 
 	middleware.SpanStart(ctx, "deleteDelegatedTask")
@@ -171,6 +293,7 @@ func deleteDelegatedTasks(w http.ResponseWriter, r *http.Request) {
 
 func putSitReps(w http.ResponseWriter, r *http.Request) {
 	ctx := middleware.AddTraceToCtxFromWriter(context.Background(), w)
+	ctx = middleware.AddUserToCtxFromRequest(ctx, r)
 	defer func() { go middleware.SpanFlushTrace(ctx) }()
 
 	middleware.SpanStart(ctx, "putSitRep")
@@ -192,6 +315,7 @@ func putSitReps(w http.ResponseWriter, r *http.Request) {
 
 func patchSitReps(w http.ResponseWriter, r *http.Request) {
 	ctx := middleware.AddTraceToCtxFromWriter(context.Background(), w)
+	ctx = middleware.AddUserToCtxFromRequest(ctx, r)
 	defer func() { go middleware.SpanFlushTrace(ctx) }()
 
 	middleware.SpanStart(ctx, "patchSitRep")
@@ -204,15 +328,22 @@ func patchSitReps(w http.ResponseWriter, r *http.Request) {
 	var err errwrap.Error
 
 	err = decodeBody(ctx, r, &req)
+	if err == nil {
+		req.ExpectedVersion, err = ifMatchVersion(r, req.GetExpectedVersion())
+	}
 	if err == nil {
 		middleware.SpanLog(ctx, "PatchSitRepRequest", &req)
 		resp, err = mgmtordersvc.PatchSitReps(ctx, &req)
 	}
+	if err == nil {
+		setETag(w, resp.GetOrder().GetMeta().GetVersion())
+	}
 	writeResponse(ctx, w, resp, err, http.StatusOK)
 }
 
 func deleteSitReps(w http.ResponseWriter, r *http.Request) {
 	ctx := middleware.AddTraceToCtxFromWriter(context.Background(), w)
+	ctx = middleware.AddUserToCtxFromRequest(ctx, r)
 	defer func() { go middleware.SpanFlushTrace(ctx) }()
 
 	middleware.SpanStart(ctx, "deleteSitRep")
@@ -231,3 +362,233 @@ func deleteSitReps(w http.ResponseWriter, r *http.Request) {
 
 	writeResponse(ctx, w, resp, err, http.StatusOK)
 }
+
+func getOrderVersions(w http.ResponseWriter, r *http.Request) {
+	ctx := middleware.AddTraceToCtxFromWriter(context.Background(), w)
+	defer func() { go middleware.SpanFlushTrace(ctx) }()
+
+	middleware.SpanStart(ctx, "getOrderVersions")
+	defer middleware.SpanStop(ctx, "getOrderVersions")
+
+	req := &request.GetOrderVersionsRequest{
+		ID: meta.ID(r.PathValue(orderID)),
+	}
+	middleware.SpanLog(ctx, "GetOrderVersionsRequest", req)
+	resp, err := mgmtordersvc.GetOrderVersions(ctx, req)
+	writeResponse(ctx, w, resp, err, http.StatusOK)
+}
+
+func getOrderVersion(w http.ResponseWriter, r *http.Request) {
+	ctx := middleware.AddTraceToCtxFromWriter(context.Background(), w)
+	defer func() { go middleware.SpanFlushTrace(ctx) }()
+
+	middleware.SpanStart(ctx, "getOrderVersion")
+	defer middleware.SpanStop(ctx, "getOrderVersion")
+
+	v, _ := strconv.ParseUint(r.PathValue(version), 10, 0) // NOTE: invalid version is caught by validation
+	req := &request.GetOrderVersionRequest{
+		ID:      meta.ID(r.PathValue(orderID)),
+		Version: uint(v),
+	}
+	middleware.SpanLog(ctx, "GetOrderVersionRequest", req)
+	resp, err := mgmtordersvc.GetOrderVersion(ctx, req)
+	writeResponse(ctx, w, resp, err, http.StatusOK)
+}
+
+func getOrderDiff(w http.ResponseWriter, r *http.Request) {
+	ctx := middleware.AddTraceToCtxFromWriter(context.Background(), w)
+	defer func() { go middleware.SpanFlushTrace(ctx) }()
+
+	middleware.SpanStart(ctx, "getOrderDiff")
+	defer middleware.SpanStop(ctx, "getOrderDiff")
+
+	from, errp := strconv.ParseUint(r.URL.Query().Get("from"), 10, 0)
+	if errp != nil && len(r.URL.Query().Get("from")) > 0 {
+		writeResponse(ctx, w, nil, errwrap.NewError(http.StatusBadRequest, "invalid from: %s", errp), http.StatusOK)
+		return
+	}
+	to, errp := strconv.ParseUint(r.URL.Query().Get("to"), 10, 0)
+	if errp != nil && len(r.URL.Query().Get("to")) > 0 {
+		writeResponse(ctx, w, nil, errwrap.NewError(http.StatusBadRequest, "invalid to: %s", errp), http.StatusOK)
+		return
+	}
+
+	req := &request.GetOrderDiffRequest{
+		ID:   meta.ID(r.PathValue(orderID)),
+		From: uint(from),
+		To:   uint(to),
+	}
+	middleware.SpanLog(ctx, "GetOrderDiffRequest", req)
+	resp, err := mgmtordersvc.GetOrderDiff(ctx, req)
+	writeResponse(ctx, w, resp, err, http.StatusOK)
+}
+
+func getOrderTree(w http.ResponseWriter, r *http.Request) {
+	ctx := middleware.AddTraceToCtxFromWriter(context.Background(), w)
+	defer func() { go middleware.SpanFlushTrace(ctx) }()
+
+	middleware.SpanStart(ctx, "getOrderTree")
+	defer middleware.SpanStop(ctx, "getOrderTree")
+
+	depth, errp := strconv.ParseUint(r.URL.Query().Get("depth"), 10, 0)
+	if errp != nil && len(r.URL.Query().Get("depth")) > 0 {
+		writeResponse(ctx, w, nil, errwrap.NewError(http.StatusBadRequest, "invalid depth: %s", errp), http.StatusOK)
+		return
+	}
+
+	req := &request.GetOrderTreeRequest{
+		ID:    meta.ID(r.PathValue(orderID)),
+		Depth: uint(depth),
+	}
+	middleware.SpanLog(ctx, "GetOrderTreeRequest", req)
+	resp, err := mgmtordersvc.GetOrderTree(ctx, req)
+	writeResponse(ctx, w, resp, err, http.StatusOK)
+}
+
+// getOrderDocument responds with the document itself, so that it can be printed or saved as is
+func getOrderDocument(w http.ResponseWriter, r *http.Request) {
+	ctx := middleware.AddTraceToCtxFromWriter(context.Background(), w)
+	defer func() { go middleware.SpanFlushTrace(ctx) }()
+
+	middleware.SpanStart(ctx, "getOrderDocument")
+	defer middleware.SpanStop(ctx, "getOrderDocument")
+
+	sitreps, errp := strconv.ParseUint(r.URL.Query().Get("sitreps"), 10, 0)
+	if errp != nil && len(r.URL.Query().Get("sitreps")) > 0 {
+		writeResponse(ctx, w, nil, errwrap.NewError(http.StatusBadRequest, "invalid sitreps: %s", errp), http.StatusOK)
+		return
+	}
+
+	req := &request.GetOrderDocumentRequest{
+		ID:      meta.ID(r.PathValue(orderID)),
+		Format:  order.DocumentFormat(r.URL.Query().Get("format")),
+		SitReps: uint(sitreps),
+	}
+	middleware.SpanLog(ctx, "GetOrderDocumentRequest", req)
+	resp, err := mgmtordersvc.GetOrderDocument(ctx, req)
+	if err != nil {
+		writeResponse(ctx, w, nil, err, http.StatusOK)
+		return
+	}
+	w.Header().Add("Content-Type", resp.GetContentType())
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(resp.GetDocument()))
+}
+
+func getOverdueReport(w http.ResponseWriter, r *http.Request) {
+	ctx := middleware.AddTraceToCtxFromWriter(context.Background(), w)
+	defer func() { go middleware.SpanFlushTrace(ctx) }()
+
+	middleware.SpanStart(ctx, "getOverdueReport")
+	defer middleware.SpanStop(ctx, "getOverdueReport")
+
+	days, errp := strconv.ParseUint(r.URL.Query().Get("days"), 10, 0)
+	if errp != nil && len(r.URL.Query().Get("days")) > 0 {
+		writeResponse(ctx, w, nil, errwrap.NewError(http.StatusBadRequest, "invalid days: %s", errp), http.StatusOK)
+		return
+	}
+
+	req := &request.GetOverdueReportRequest{
+		RootOrderID: meta.ID(r.URL.Query().Get("root_order_id")),
+		Supervisor:  user.Email(r.URL.Query().Get("supervisor")),
+		Days:        uint(days),
+	}
+	middleware.SpanLog(ctx, "GetOverdueReportRequest", req)
+	resp, err := mgmtordersvc.GetOverdueReport(ctx, req)
+	writeResponse(ctx, w, resp, err, http.StatusOK)
+}
+
+func search(w http.ResponseWriter, r *http.Request) {
+	ctx := middleware.AddTraceToCtxFromWriter(context.Background(), w)
+	defer func() { go middleware.SpanFlushTrace(ctx) }()
+
+	middleware.SpanStart(ctx, "search")
+	defer middleware.SpanStop(ctx, "search")
+
+	limit, errp := strconv.ParseUint(r.URL.Query().Get("limit"), 10, 0)
+	if errp != nil && len(r.URL.Query().Get("limit")) > 0 {
+		writeResponse(ctx, w, nil, errwrap.NewError(http.StatusBadRequest, "invalid limit: %s", errp), http.StatusOK)
+		return
+	}
+
+	req := &request.SearchRequest{
+		Query: r.URL.Query().Get("q"),
+		Limit: uint(limit),
+	}
+	middleware.SpanLog(ctx, "SearchRequest", req)
+	resp, err := mgmtordersvc.Search(ctx, req)
+	writeResponse(ctx, w, resp, err, http.StatusOK)
+}
+
+func getDeletedOrders(w http.ResponseWriter, r *http.Request) {
+	ctx := middleware.AddTraceToCtxFromWriter(context.Background(), w)
+	defer func() { go middleware.SpanFlushTrace(ctx) }()
+
+	middleware.SpanStart(ctx, "getDeletedOrders")
+	defer middleware.SpanStop(ctx, "getDeletedOrders")
+
+	req := &request.GetDeletedOrdersRequest{}
+	middleware.SpanLog(ctx, "GetDeletedOrdersRequest", req)
+	resp, err := mgmtordersvc.GetDeletedOrders(ctx, req)
+	writeResponse(ctx, w, resp, err, http.StatusOK)
+}
+
+func restoreOrder(w http.ResponseWriter, r *http.Request) {
+	ctx := middleware.AddTraceToCtxFromWriter(context.Background(), w)
+	ctx = middleware.AddUserToCtxFromRequest(ctx, r)
+	defer func() { go middleware.SpanFlushTrace(ctx) }()
+
+	middleware.SpanStart(ctx, "restoreOrder")
+	defer middleware.SpanStop(ctx, "restoreOrder")
+
+	req := &request.RestoreOrderRequest{
+		ID: meta.ID(r.PathValue(orderID)),
+	}
+	middleware.SpanLog(ctx, "RestoreOrderRequest", req)
+	resp, err := mgmtordersvc.RestoreOrder(ctx, req)
+	writeResponse(ctx, w, resp, err, http.StatusOK)
+}
+
+func restoreSitReps(w http.ResponseWriter, r *http.Request) {
+	ctx := middleware.AddTraceToCtxFromWriter(context.Background(), w)
+	ctx = middleware.AddUserToCtxFromRequest(ctx, r)
+	defer func() { go middleware.SpanFlushTrace(ctx) }()
+
+	middleware.SpanStart(ctx, "restoreSitReps")
+	defer middleware.SpanStop(ctx, "restoreSitReps")
+
+	req := &request.RestoreSitRepsRequest{
+		OrderID: meta.ID(r.PathValue(orderID)),
+	}
+	var resp *response.RestoreSitRepsResponse
+	var err errwrap.Error
+	err = decodeBody(ctx, r, req)
+	if err == nil {
+		middleware.SpanLog(ctx, "RestoreSitRepsRequest", req)
+		resp, err = mgmtordersvc.RestoreSitReps(ctx, req)
+	}
+
+	writeResponse(ctx, w, resp, err, http.StatusOK)
+}
+
+func purgeOrders(w http.ResponseWriter, r *http.Request) {
+	ctx := middleware.AddTraceToCtxFromWriter(context.Background(), w)
+	ctx = middleware.AddUserToCtxFromRequest(ctx, r)
+	defer func() { go middleware.SpanFlushTrace(ctx) }()
+
+	middleware.SpanStart(ctx, "purgeOrders")
+	defer middleware.SpanStop(ctx, "purgeOrders")
+
+	before, errp := time.Parse(time.RFC3339, r.URL.Query().Get("before"))
+	if errp != nil && len(r.URL.Query().Get("before")) > 0 {
+		writeResponse(ctx, w, nil, errwrap.NewError(http.StatusBadRequest, "invalid before: %s", errp), http.StatusOK)
+		return
+	}
+
+	req := &request.PurgeOrdersRequest{
+		Before: before,
+	}
+	middleware.SpanLog(ctx, "PurgeOrdersRequest", req)
+	resp, err := mgmtordersvc.PurgeOrders(ctx, req)
+	writeResponse(ctx, w, resp, err, http.StatusOK)
+}
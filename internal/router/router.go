@@ -5,10 +5,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/moledoc/orderly/internal/domain/errwrap"
 	"github.com/moledoc/orderly/internal/middleware"
+	"github.com/moledoc/orderly/internal/service/admin"
 	"github.com/moledoc/orderly/internal/service/mgmtorder"
 	"github.com/moledoc/orderly/internal/service/mgmtuser"
 	"github.com/moledoc/orderly/pkg/consts"
@@ -20,11 +24,15 @@ var (
 	sitrepID        = "sitrep_id"
 	userID          = "user_id"
 	userEmail       = "user_email"
+	version         = "version"
+	calendarFile    = "calendar_file"
 )
 
 var (
-	onceRouteUser  sync.Once
-	onceRouteOrder sync.Once
+	onceRouteUser     sync.Once
+	onceRouteOrder    sync.Once
+	onceRouteAdmin    sync.Once
+	onceRouteCalendar sync.Once
 )
 
 func decodeBody(ctx context.Context, r *http.Request, req any) errwrap.Error {
@@ -74,14 +82,48 @@ func writeResponse(ctx context.Context, w http.ResponseWriter, resp any, err err
 	w.Write(bs)
 }
 
+// setETag exposes the object version, so that clients can send it back with If-Match
+func setETag(w http.ResponseWriter, version uint) {
+	w.Header().Set("ETag", fmt.Sprintf("\"%v\"", version))
+}
+
+// ifMatchVersion returns the version expected by the If-Match header;
+// request field value is kept when the header is missing or '*'
+func ifMatchVersion(r *http.Request, expectedVersion uint) (uint, errwrap.Error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if len(header) == 0 || header == "*" {
+		return expectedVersion, nil
+	}
+	v, err := strconv.ParseUint(strings.Trim(strings.TrimPrefix(header, "W/"), "\""), 10, 0)
+	if err != nil || v == 0 {
+		return 0, errwrap.NewError(http.StatusBadRequest, "invalid If-Match header: %s", header)
+	}
+	return uint(v), nil
+}
+
+// queryTime parses the RFC3339 time query parameter, zero time when it's missing
+func queryTime(r *http.Request, name string) (time.Time, errwrap.Error) {
+	value := r.URL.Query().Get(name)
+	if len(value) == 0 {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, errwrap.NewError(http.StatusBadRequest, "invalid %s: %s", name, err)
+	}
+	return t, nil
+}
+
 type Service struct {
 	MgmtOrder mgmtorder.ServiceMgmtOrderAPI
 	MgmtUser  mgmtuser.ServiceMgmtUserAPI
+	Admin     admin.ServiceAdminAPI
 }
 
 var (
 	mgmtordersvc mgmtorder.ServiceMgmtOrderAPI = nil
 	mgmtusersvc  mgmtuser.ServiceMgmtUserAPI   = nil
+	adminsvc     admin.ServiceAdminAPI         = nil
 )
 
 func RouteOrder(svc mgmtorder.ServiceMgmtOrderAPI) *http.ServeMux {
@@ -97,6 +139,7 @@ func RouteOrder(svc mgmtorder.ServiceMgmtOrderAPI) *http.ServeMux {
 		http.HandleFunc("GET /v1/mgmt/orders", getOrders)
 		http.HandleFunc("PATCH /v1/mgmt/order", patchOrder)
 		http.HandleFunc(fmt.Sprintf("DELETE /v1/mgmt/order/{%v}", orderID), deleteOrder)
+		http.HandleFunc(fmt.Sprintf("POST /v1/mgmt/order/{%v}/move", orderID), moveOrder)
 
 		http.HandleFunc(fmt.Sprintf("PUT /v1/mgmt/order/{%v}/delegated_task", orderID), putDelegatedTasks)
 		http.HandleFunc(fmt.Sprintf("PATCH /v1/mgmt/order/{%v}/delegated_task", orderID), patchDelegatedTasks)
@@ -106,6 +149,19 @@ func RouteOrder(svc mgmtorder.ServiceMgmtOrderAPI) *http.ServeMux {
 		http.HandleFunc(fmt.Sprintf("PATCH /v1/mgmt/order/{%v}/sitrep", orderID), patchSitReps)
 		http.HandleFunc(fmt.Sprintf("DELETE /v1/mgmt/order/{%v}/sitrep", orderID), deleteSitReps)
 
+		http.HandleFunc(fmt.Sprintf("GET /v1/mgmt/order/{%v}/versions", orderID), getOrderVersions)
+		http.HandleFunc(fmt.Sprintf("GET /v1/mgmt/order/{%v}/version/{%v}", orderID, version), getOrderVersion)
+		http.HandleFunc(fmt.Sprintf("GET /v1/mgmt/order/{%v}/diff", orderID), getOrderDiff)
+		http.HandleFunc(fmt.Sprintf("GET /v1/mgmt/order/{%v}/tree", orderID), getOrderTree)
+		http.HandleFunc(fmt.Sprintf("GET /v1/mgmt/order/{%v}/document", orderID), getOrderDocument)
+		http.HandleFunc("GET /v1/mgmt/reports/overdue", getOverdueReport)
+		http.HandleFunc("GET /v1/mgmt/search", search)
+
+		http.HandleFunc("GET /v1/mgmt/trash/orders", getDeletedOrders)
+		http.HandleFunc("DELETE /v1/mgmt/trash/orders", purgeOrders)
+		http.HandleFunc(fmt.Sprintf("POST /v1/mgmt/order/{%v}/restore", orderID), restoreOrder)
+		http.HandleFunc(fmt.Sprintf("POST /v1/mgmt/order/{%v}/sitrep/restore", orderID), restoreSitReps)
+
 		// NOTE: handle empty ids
 		http.HandleFunc("GET /v1/mgmt/order/", getOrderByID)
 		http.HandleFunc("DELETE /v1/mgmt/order/", deleteOrder)
@@ -136,6 +192,16 @@ func RouteUser(svc mgmtuser.ServiceMgmtUserAPI) *http.ServeMux {
 		http.HandleFunc("PATCH /v1/mgmt/user", handlePatchUser)
 		http.HandleFunc(fmt.Sprintf("DELETE /v1/mgmt/user/{%v}", userID), handleDeleteUser)
 
+		http.HandleFunc(fmt.Sprintf("GET /v1/mgmt/user/{%v}/versions", userID), handleGetUserVersions)
+		http.HandleFunc(fmt.Sprintf("GET /v1/mgmt/user/{%v}/version/{%v}", userID, version), handleGetUserVersion)
+
+		http.HandleFunc(fmt.Sprintf("GET /v1/mgmt/user/{%v}/chain", userID), handleGetUserChain)
+		http.HandleFunc(fmt.Sprintf("GET /v1/mgmt/user/{%v}/subordinates", userID), handleGetUserSubordinates)
+
+		http.HandleFunc("GET /v1/mgmt/trash/users", handleGetDeletedUsers)
+		http.HandleFunc("DELETE /v1/mgmt/trash/users", handlePurgeUsers)
+		http.HandleFunc(fmt.Sprintf("POST /v1/mgmt/user/{%v}/restore", userID), handleRestoreUser)
+
 		// NOTE: handle empty ids
 		http.HandleFunc("GET /v1/mgmt/user/", handleGetUserByID)
 		http.HandleFunc("DELETE /v1/mgmt/user/", handleDeleteUser)
@@ -144,7 +210,37 @@ func RouteUser(svc mgmtuser.ServiceMgmtUserAPI) *http.ServeMux {
 	return http.DefaultServeMux
 }
 
+func RouteAdmin(svc admin.ServiceAdminAPI) *http.ServeMux {
+	adminsvc = svc
+
+	if adminsvc == nil {
+		panic("router: admin service is not initialized")
+	}
+
+	onceRouteAdmin.Do(func() {
+		http.HandleFunc("GET /v1/admin/export", export)
+		http.HandleFunc("POST /v1/admin/import", importDocument)
+	})
+
+	return http.DefaultServeMux
+}
+
+// NOTE: calendar feeds are read by the user's id, so they need both the order and the user service
+func RouteCalendar(orders mgmtorder.ServiceMgmtOrderAPI, users mgmtuser.ServiceMgmtUserAPI) *http.ServeMux {
+	RouteOrder(orders)
+	RouteUser(users)
+
+	onceRouteCalendar.Do(func() {
+		http.HandleFunc(fmt.Sprintf("GET /calendar/{%v}", calendarFile), getCalendar)
+	})
+
+	return http.DefaultServeMux
+}
+
+// NOTE: admin routes are registered only with the admin service
 func Route(svcs *Service) {
-	RouteOrder(svcs.MgmtOrder)
-	RouteUser(svcs.MgmtUser)
+	RouteCalendar(svcs.MgmtOrder, svcs.MgmtUser)
+	if svcs.Admin != nil {
+		RouteAdmin(svcs.Admin)
+	}
 }
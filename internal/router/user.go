@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/moledoc/orderly/internal/domain/errwrap"
 	"github.com/moledoc/orderly/internal/domain/meta"
@@ -20,6 +22,7 @@ func handlePostUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := middleware.AddTraceToCtxFromWriter(context.Background(), w)
+	ctx = middleware.AddUserToCtxFromRequest(ctx, r)
 	defer func() { go middleware.SpanFlushTrace(ctx) }()
 
 	middleware.SpanStart(ctx, "postUser")
@@ -58,6 +61,9 @@ func handleGetUserByID(w http.ResponseWriter, r *http.Request) {
 	}
 	middleware.SpanLog(ctx, "GetUserByIDRequest", req)
 	resp, err := mgmtusersvc.GetUserByID(ctx, req)
+	if err == nil {
+		setETag(w, resp.GetUser().GetMeta().GetVersion())
+	}
 
 	writeResponse(ctx, w, resp, err, http.StatusOK)
 }
@@ -69,6 +75,12 @@ func handleGetUsers(w http.ResponseWriter, r *http.Request) {
 	middleware.SpanStart(ctx, "getUsers")
 	defer middleware.SpanStop(ctx, "getUsers")
 
+	limit, errp := strconv.ParseUint(r.URL.Query().Get("limit"), 10, 0)
+	if errp != nil && len(r.URL.Query().Get("limit")) > 0 {
+		writeResponse(ctx, w, nil, errwrap.NewError(http.StatusBadRequest, "invalid limit: %s", errp), http.StatusOK)
+		return
+	}
+
 	queryEmails := r.URL.Query()["emails"]
 	emails := make([]user.Email, len(queryEmails))
 	for i, em := range queryEmails {
@@ -77,6 +89,9 @@ func handleGetUsers(w http.ResponseWriter, r *http.Request) {
 	req := &request.GetUsersRequest{
 		Emails:     emails,
 		Supervisor: user.Email(r.URL.Query().Get("supervisor")),
+		Limit:      uint(limit),
+		Cursor:     r.URL.Query().Get("cursor"),
+		Sort:       meta.Sort(r.URL.Query().Get("sort")),
 	}
 	middleware.SpanLog(ctx, "GetUsersRequest", req)
 	resp, err := mgmtusersvc.GetUsers(ctx, req)
@@ -90,6 +105,7 @@ func handlePatchUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := middleware.AddTraceToCtxFromWriter(context.Background(), w)
+	ctx = middleware.AddUserToCtxFromRequest(ctx, r)
 	defer func() { go middleware.SpanFlushTrace(ctx) }()
 
 	middleware.SpanStart(ctx, "patchUser")
@@ -100,10 +116,16 @@ func handlePatchUser(w http.ResponseWriter, r *http.Request) {
 	var err errwrap.Error
 
 	err = decodeBody(ctx, r, &req)
+	if err == nil {
+		req.ExpectedVersion, err = ifMatchVersion(r, req.GetExpectedVersion())
+	}
 	if err == nil {
 		middleware.SpanLog(ctx, "PatchUserRequest", &req)
 		resp, err = mgmtusersvc.PatchUser(ctx, &req)
 	}
+	if err == nil {
+		setETag(w, resp.GetUser().GetMeta().GetVersion())
+	}
 
 	writeResponse(ctx, w, resp, err, http.StatusOK)
 }
@@ -115,6 +137,7 @@ func handleDeleteUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := middleware.AddTraceToCtxFromWriter(context.Background(), w)
+	ctx = middleware.AddUserToCtxFromRequest(ctx, r)
 	defer func() { go middleware.SpanFlushTrace(ctx) }()
 
 	middleware.SpanStart(ctx, "deleteUser")
@@ -127,3 +150,123 @@ func handleDeleteUser(w http.ResponseWriter, r *http.Request) {
 	resp, err := mgmtusersvc.DeleteUser(ctx, req)
 	writeResponse(ctx, w, resp, err, http.StatusNoContent)
 }
+
+func handleGetUserVersions(w http.ResponseWriter, r *http.Request) {
+	ctx := middleware.AddTraceToCtxFromWriter(context.Background(), w)
+	defer func() { go middleware.SpanFlushTrace(ctx) }()
+
+	middleware.SpanStart(ctx, "getUserVersions")
+	defer middleware.SpanStop(ctx, "getUserVersions")
+
+	req := &request.GetUserVersionsRequest{
+		ID: meta.ID(r.PathValue(userID)),
+	}
+	middleware.SpanLog(ctx, "GetUserVersionsRequest", req)
+	resp, err := mgmtusersvc.GetUserVersions(ctx, req)
+	writeResponse(ctx, w, resp, err, http.StatusOK)
+}
+
+func handleGetUserVersion(w http.ResponseWriter, r *http.Request) {
+	ctx := middleware.AddTraceToCtxFromWriter(context.Background(), w)
+	defer func() { go middleware.SpanFlushTrace(ctx) }()
+
+	middleware.SpanStart(ctx, "getUserVersion")
+	defer middleware.SpanStop(ctx, "getUserVersion")
+
+	v, _ := strconv.ParseUint(r.PathValue(version), 10, 0) // NOTE: invalid version is caught by validation
+	req := &request.GetUserVersionRequest{
+		ID:      meta.ID(r.PathValue(userID)),
+		Version: uint(v),
+	}
+	middleware.SpanLog(ctx, "GetUserVersionRequest", req)
+	resp, err := mgmtusersvc.GetUserVersion(ctx, req)
+	writeResponse(ctx, w, resp, err, http.StatusOK)
+}
+
+func handleGetUserChain(w http.ResponseWriter, r *http.Request) {
+	ctx := middleware.AddTraceToCtxFromWriter(context.Background(), w)
+	defer func() { go middleware.SpanFlushTrace(ctx) }()
+
+	middleware.SpanStart(ctx, "getUserChain")
+	defer middleware.SpanStop(ctx, "getUserChain")
+
+	req := &request.GetUserChainRequest{
+		ID: meta.ID(r.PathValue(userID)),
+	}
+	middleware.SpanLog(ctx, "GetUserChainRequest", req)
+	resp, err := mgmtusersvc.GetUserChain(ctx, req)
+	writeResponse(ctx, w, resp, err, http.StatusOK)
+}
+
+func handleGetUserSubordinates(w http.ResponseWriter, r *http.Request) {
+	ctx := middleware.AddTraceToCtxFromWriter(context.Background(), w)
+	defer func() { go middleware.SpanFlushTrace(ctx) }()
+
+	middleware.SpanStart(ctx, "getUserSubordinates")
+	defer middleware.SpanStop(ctx, "getUserSubordinates")
+
+	depth, errp := strconv.ParseUint(r.URL.Query().Get("depth"), 10, 0)
+	if errp != nil && len(r.URL.Query().Get("depth")) > 0 {
+		writeResponse(ctx, w, nil, errwrap.NewError(http.StatusBadRequest, "invalid depth: %s", errp), http.StatusOK)
+		return
+	}
+
+	req := &request.GetUserSubordinatesRequest{
+		ID:    meta.ID(r.PathValue(userID)),
+		Depth: uint(depth),
+	}
+	middleware.SpanLog(ctx, "GetUserSubordinatesRequest", req)
+	resp, err := mgmtusersvc.GetUserSubordinates(ctx, req)
+	writeResponse(ctx, w, resp, err, http.StatusOK)
+}
+
+func handleGetDeletedUsers(w http.ResponseWriter, r *http.Request) {
+	ctx := middleware.AddTraceToCtxFromWriter(context.Background(), w)
+	defer func() { go middleware.SpanFlushTrace(ctx) }()
+
+	middleware.SpanStart(ctx, "getDeletedUsers")
+	defer middleware.SpanStop(ctx, "getDeletedUsers")
+
+	req := &request.GetDeletedUsersRequest{}
+	middleware.SpanLog(ctx, "GetDeletedUsersRequest", req)
+	resp, err := mgmtusersvc.GetDeletedUsers(ctx, req)
+	writeResponse(ctx, w, resp, err, http.StatusOK)
+}
+
+func handleRestoreUser(w http.ResponseWriter, r *http.Request) {
+	ctx := middleware.AddTraceToCtxFromWriter(context.Background(), w)
+	ctx = middleware.AddUserToCtxFromRequest(ctx, r)
+	defer func() { go middleware.SpanFlushTrace(ctx) }()
+
+	middleware.SpanStart(ctx, "restoreUser")
+	defer middleware.SpanStop(ctx, "restoreUser")
+
+	req := &request.RestoreUserRequest{
+		ID: meta.ID(r.PathValue(userID)),
+	}
+	middleware.SpanLog(ctx, "RestoreUserRequest", req)
+	resp, err := mgmtusersvc.RestoreUser(ctx, req)
+	writeResponse(ctx, w, resp, err, http.StatusOK)
+}
+
+func handlePurgeUsers(w http.ResponseWriter, r *http.Request) {
+	ctx := middleware.AddTraceToCtxFromWriter(context.Background(), w)
+	ctx = middleware.AddUserToCtxFromRequest(ctx, r)
+	defer func() { go middleware.SpanFlushTrace(ctx) }()
+
+	middleware.SpanStart(ctx, "purgeUsers")
+	defer middleware.SpanStop(ctx, "purgeUsers")
+
+	before, errp := time.Parse(time.RFC3339, r.URL.Query().Get("before"))
+	if errp != nil && len(r.URL.Query().Get("before")) > 0 {
+		writeResponse(ctx, w, nil, errwrap.NewError(http.StatusBadRequest, "invalid before: %s", errp), http.StatusOK)
+		return
+	}
+
+	req := &request.PurgeUsersRequest{
+		Before: before,
+	}
+	middleware.SpanLog(ctx, "PurgeUsersRequest", req)
+	resp, err := mgmtusersvc.PurgeUsers(ctx, req)
+	writeResponse(ctx, w, resp, err, http.StatusOK)
+}
@@ -0,0 +1,468 @@
+package admin
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/moledoc/orderly/internal/domain/dump"
+	"github.com/moledoc/orderly/internal/domain/errwrap"
+	"github.com/moledoc/orderly/internal/domain/meta"
+	"github.com/moledoc/orderly/internal/domain/order"
+	"github.com/moledoc/orderly/internal/domain/request"
+	"github.com/moledoc/orderly/internal/domain/response"
+	"github.com/moledoc/orderly/internal/domain/user"
+	"github.com/moledoc/orderly/internal/middleware"
+	"github.com/moledoc/orderly/internal/repository"
+)
+
+// readUsers returns every stored user, the ones in trash included.
+func readUsers(ctx context.Context, repo repository.RepositoryUserAPI) ([]*user.User, errwrap.Error) {
+	users, _, err := repo.ReadBy(ctx, &request.GetUsersRequest{})
+	if err != nil {
+		return nil, err
+	}
+	deleted, err := repo.ReadDeleted(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return append(users, deleted...), nil
+}
+
+// readOrders returns every stored order, the ones in trash included.
+func readOrders(ctx context.Context, repo repository.RepositoryOrderAPI) ([]*order.Order, errwrap.Error) {
+	orders, _, err := repo.ReadBy(ctx, &request.GetOrdersRequest{})
+	if err != nil {
+		return nil, err
+	}
+	deleted, err := repo.ReadDeleted(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return append(orders, deleted...), nil
+}
+
+func (s *serviceAdmin) Export(ctx context.Context, req *request.ExportRequest) (*response.ExportResponse, errwrap.Error) {
+	ctx = middleware.AddTraceToCtx(ctx)
+	middleware.SpanStart(ctx, "Export")
+	defer middleware.SpanStop(ctx, "Export")
+
+	if err := ValidateExportRequest(req); err != nil {
+		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
+	}
+
+	doc := &dump.Document{
+		Version:  dump.Version,
+		Exported: time.Now().UTC(),
+	}
+	// NOTE: users and orders are read in transactions of their own, repositories might share a database allowing a single writer
+	err := s.Users.WithTx(ctx, func(tx repository.RepositoryUserAPI) errwrap.Error {
+		var err errwrap.Error
+		doc.Users, err = readUsers(ctx, tx)
+		return err
+	})
+	if err != nil {
+		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
+	}
+	err = s.Orders.WithTx(ctx, func(tx repository.RepositoryOrderAPI) errwrap.Error {
+		var err errwrap.Error
+		doc.Orders, err = readOrders(ctx, tx)
+		return err
+	})
+	if err != nil {
+		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
+	}
+
+	return &response.ExportResponse{
+		Document: doc,
+	}, nil
+}
+
+// importPlan tells what the import changes, orders are listed parents first.
+type importPlan struct {
+	users         []*user.User
+	orders        []*order.Order
+	removedUsers  []*user.User
+	removedOrders []meta.ID
+	skippedUsers  []meta.ID
+	skippedOrders []meta.ID
+	emails        map[user.Email]meta.ID // NOTE: users by email once the users are imported
+}
+
+// planUsers checks the document's users against the stored ones, every broken reference is reported on its own field.
+func (s *serviceAdmin) planUsers(ctx context.Context, repo repository.RepositoryUserAPI, mode dump.Mode, doc *dump.Document, p *importPlan) ([]errwrap.FieldError, errwrap.Error) {
+	storedUsers, err := readUsers(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields []errwrap.FieldError
+	users := make(map[meta.ID]bool)
+	emails := make(map[user.Email]meta.ID)
+	for _, u := range storedUsers {
+		if mode == dump.ModeReplace && u.GetID() != s.RootUser.GetID() {
+			p.removedUsers = append(p.removedUsers, u)
+			continue
+		}
+		users[u.GetID()] = true
+		emails[u.GetEmail()] = u.GetID()
+	}
+	for i, u := range doc.GetUsers() {
+		if users[u.GetID()] || u.GetEmail() == s.RootUser.GetEmail() {
+			p.skippedUsers = append(p.skippedUsers, u.GetID())
+			continue
+		}
+		if id, ok := emails[u.GetEmail()]; ok {
+			fields = append(fields, errwrap.FieldError{
+				Field:   fmt.Sprintf("document.users[%v].email", i),
+				Message: fmt.Sprintf("email '%s' already belongs to user '%s'", u.GetEmail(), id),
+			})
+			continue
+		}
+		emails[u.GetEmail()] = u.GetID()
+		p.users = append(p.users, u)
+	}
+	for i, u := range doc.GetUsers() {
+		if !slices.Contains(p.users, u) {
+			continue
+		}
+		if _, ok := emails[u.GetSupervisor()]; !ok {
+			fields = append(fields, errwrap.FieldError{
+				Field:   fmt.Sprintf("document.users[%v].supervisor", i),
+				Message: fmt.Sprintf("user '%s' not found", u.GetSupervisor()),
+			})
+		}
+	}
+	p.emails = emails
+
+	if len(fields) > 0 {
+		return fields, nil
+	}
+	for i, u := range p.users {
+		p.users[i] = u.Clone()
+	}
+	return nil, nil
+}
+
+// planOrders checks the document's orders against the stored ones and the users planned by planUsers, every broken reference is reported on its own field.
+// NOTE: document's root order stands for the root order already stored, orders delegated by it are delegated by the stored root order instead
+func (s *serviceAdmin) planOrders(ctx context.Context, repo repository.RepositoryOrderAPI, mode dump.Mode, doc *dump.Document, p *importPlan) ([]errwrap.FieldError, errwrap.Error) {
+	storedOrders, err := readOrders(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields []errwrap.FieldError
+	orders := make(map[meta.ID]*order.Order)
+	sitreps := make(map[meta.ID]meta.ID) // NOTE: order of the sitrep
+	for _, o := range storedOrders {
+		if mode == dump.ModeReplace && o.GetID() != s.RootOrder.GetID() {
+			p.removedOrders = append(p.removedOrders, o.GetID())
+			continue
+		}
+		orders[o.GetID()] = o
+		for _, sitrep := range o.GetSitReps() {
+			sitreps[sitrep.GetID()] = o.GetID()
+		}
+	}
+
+	var docRootID meta.ID
+	for i, o := range doc.GetOrders() {
+		if o.GetID() != o.GetParentOrderID() {
+			continue
+		}
+		if len(docRootID) > 0 {
+			fields = append(fields, errwrap.FieldError{
+				Field:   fmt.Sprintf("document.orders[%v].parent_order_id", i),
+				Message: fmt.Sprintf("root order is '%s' already", docRootID),
+			})
+			continue
+		}
+		docRootID = o.GetID()
+	}
+	parentOf := func(o *order.Order) meta.ID {
+		if len(docRootID) > 0 && o.GetParentOrderID() == docRootID {
+			return s.RootOrder.GetID()
+		}
+		return o.GetParentOrderID()
+	}
+
+	imported := make(map[meta.ID]*order.Order)
+	position := make(map[meta.ID]int) // NOTE: position of the order among the parent's delegated tasks
+	for _, o := range doc.GetOrders() {
+		for j, task := range o.GetDelegatedTasks() {
+			position[task.GetID()] = j
+		}
+		if o.GetID() == docRootID || orders[o.GetID()] != nil {
+			p.skippedOrders = append(p.skippedOrders, o.GetID())
+			continue
+		}
+		imported[o.GetID()] = o
+	}
+
+	for i, o := range doc.GetOrders() {
+		if imported[o.GetID()] == nil {
+			continue
+		}
+		field := fmt.Sprintf("document.orders[%v]", i)
+
+		if parentID := parentOf(o); orders[parentID] == nil && imported[parentID] == nil {
+			fields = append(fields, errwrap.FieldError{
+				Field:   field + ".parent_order_id",
+				Message: fmt.Sprintf("order '%s' not found", parentID),
+			})
+		}
+		for j, task := range o.GetDelegatedTasks() {
+			if delegated := imported[task.GetID()]; delegated == nil || parentOf(delegated) != o.GetID() {
+				fields = append(fields, errwrap.FieldError{
+					Field:   fmt.Sprintf("%s.delegated_tasks[%v].id", field, j),
+					Message: fmt.Sprintf("order '%s' delegated by the order not found", task.GetID()),
+				})
+			}
+		}
+		for j, sitrep := range o.GetSitReps() {
+			if orderID, ok := sitreps[sitrep.GetID()]; ok {
+				fields = append(fields, errwrap.FieldError{
+					Field:   fmt.Sprintf("%s.sitreps[%v].id", field, j),
+					Message: fmt.Sprintf("sitrep '%s' already belongs to order '%s'", sitrep.GetID(), orderID),
+				})
+			}
+		}
+		for _, ref := range order.OrderUserRefs(field, o) {
+			if _, ok := p.emails[ref.GetEmail()]; !ok {
+				fields = append(fields, errwrap.FieldError{
+					Field:   ref.GetField(),
+					Message: fmt.Sprintf("user '%s' not found", ref.GetEmail()),
+				})
+			}
+		}
+	}
+
+	byPosition := func(a, b *order.Order) int {
+		pos := func(o *order.Order) int {
+			if j, ok := position[o.GetID()]; ok {
+				return j
+			}
+			return math.MaxInt // NOTE: orders in trash aren't listed by their parent
+		}
+		return cmp.Or(cmp.Compare(pos(a), pos(b)), cmp.Compare(a.GetID(), b.GetID()))
+	}
+	children := make(map[meta.ID][]*order.Order)
+	var next []*order.Order
+	for _, o := range imported {
+		if imported[parentOf(o)] == nil {
+			next = append(next, o)
+			continue
+		}
+		children[parentOf(o)] = append(children[parentOf(o)], o)
+	}
+	for len(next) > 0 { // NOTE: parents are created first, so creating an order links it to its parent
+		slices.SortFunc(next, byPosition)
+		var delegated []*order.Order
+		for _, o := range next {
+			p.orders = append(p.orders, o)
+			delegated = append(delegated, children[o.GetID()]...)
+		}
+		next = delegated
+	}
+	if len(p.orders) < len(imported) {
+		for i, o := range doc.GetOrders() {
+			if imported[o.GetID()] == nil || slices.Contains(p.orders, o) {
+				continue
+			}
+			fields = append(fields, errwrap.FieldError{
+				Field:   fmt.Sprintf("document.orders[%v].parent_order_id", i),
+				Message: "order isn't delegated from the root order",
+			})
+		}
+	}
+
+	if len(fields) > 0 {
+		return fields, nil
+	}
+	for i, o := range p.orders {
+		clone := o.Clone()
+		clone.SetParentOrderID(parentOf(o))
+		p.orders[i] = clone
+	}
+	return nil, nil
+}
+
+// removeUsers removes the users for good, returning the versions of each user, oldest first, so they can be put back.
+func removeUsers(ctx context.Context, tx repository.RepositoryUserAPI, users []*user.User) ([][]*user.User, errwrap.Error) {
+	var removed [][]*user.User
+	for _, u := range users {
+		versions, err := tx.ReadVersions(ctx, u.GetID())
+		if err != nil && err.GetStatusCode() != http.StatusNotFound {
+			return nil, err
+		}
+		if len(versions) == 0 || versions[len(versions)-1].GetMeta().GetVersion() != u.GetMeta().GetVersion() {
+			versions = append(versions, u)
+		}
+		if err := tx.Delete(ctx, u.GetID()); err != nil {
+			return nil, err
+		}
+		removed = append(removed, versions)
+	}
+	return removed, nil
+}
+
+// undoUserChanges puts the users back as they were before the import, removed users get their version history back.
+// NOTE: used when the orders couldn't be imported, since orders and users don't share a transaction
+func (s *serviceAdmin) undoUserChanges(ctx context.Context, imported []*user.User, removed [][]*user.User) errwrap.Error {
+	return s.Users.WithTx(ctx, func(tx repository.RepositoryUserAPI) errwrap.Error {
+		for _, u := range imported {
+			if err := tx.Delete(ctx, u.GetID()); err != nil {
+				return err
+			}
+		}
+		for _, versions := range removed {
+			if _, err := tx.CreateUser(ctx, versions[0]); err != nil {
+				return err
+			}
+			for _, version := range versions[1:] {
+				if _, err := tx.UpdateUser(ctx, version); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// Import loads the document, objects keep their ids and meta.
+// NOTE: the document is checked as a whole before anything is changed, users and orders are checked in the transactions changing them.
+// Users and orders don't share a transaction, so the user changes are undone when the orders fail.
+func (s *serviceAdmin) Import(ctx context.Context, req *request.ImportRequest) (*response.ImportResponse, errwrap.Error) {
+	ctx = middleware.AddTraceToCtx(ctx)
+	middleware.SpanStart(ctx, "Import")
+	defer middleware.SpanStop(ctx, "Import")
+
+	if err := ValidateImportRequest(req); err != nil {
+		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
+	}
+
+	mode := req.GetMode()
+	if len(mode) == 0 {
+		mode = dump.ModeMerge
+	}
+
+	p := &importPlan{}
+	var fields []errwrap.FieldError
+	var removedUsers [][]*user.User
+	err := s.Users.WithTx(ctx, func(tx repository.RepositoryUserAPI) errwrap.Error {
+		var err errwrap.Error
+		if fields, err = s.planUsers(ctx, tx, mode, req.GetDocument(), p); err != nil || len(fields) > 0 { // NOTE: orders are checked still, to report every broken reference at once
+			return err
+		}
+		if removedUsers, err = removeUsers(ctx, tx, p.removedUsers); err != nil {
+			return err
+		}
+		for _, u := range p.users {
+			if _, err := tx.CreateUser(ctx, u); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
+	}
+	usersChanged := len(fields) == 0
+
+	err = s.Orders.WithTx(ctx, func(tx repository.RepositoryOrderAPI) errwrap.Error {
+		orderFields, err := s.planOrders(ctx, tx, mode, req.GetDocument(), p)
+		if err != nil {
+			return err
+		}
+		if fields = append(fields, orderFields...); len(fields) > 0 {
+			return errwrap.NewFieldError(http.StatusUnprocessableEntity, fields...)
+		}
+
+		if err := s.removeOrders(ctx, tx, p.removedOrders); err != nil {
+			return err
+		}
+		for _, o := range p.orders {
+			stored := o.Clone()
+			stored.SetDelegatedTasks(nil) // NOTE: delegated orders link themselves to the order once they're created
+			if _, err := tx.CreateOrder(ctx, stored); err != nil {
+				return err
+			}
+		}
+		for _, o := range p.orders {
+			if len(o.GetDelegatedTasks()) == 0 {
+				continue
+			}
+			if _, err := tx.UpdateMeta(ctx, o.GetID(), o.GetMeta()); err != nil { // NOTE: keeps the delegated tasks in the order's stored version as well
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil && usersChanged {
+		if errUndo := s.undoUserChanges(ctx, p.users, removedUsers); errUndo != nil {
+			err = errwrap.NewError(http.StatusInternalServerError, "importing orders failed: %s; undoing user changes failed: %s", err.GetStatusMessage(), errUndo.GetStatusMessage())
+		}
+	}
+	if err != nil {
+		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
+	}
+
+	resp := &response.ImportResponse{
+		Mode:          mode,
+		SkippedUsers:  p.skippedUsers,
+		SkippedOrders: p.skippedOrders,
+		RemovedOrders: p.removedOrders,
+	}
+	for _, u := range p.users {
+		resp.ImportedUsers = append(resp.ImportedUsers, u.GetID())
+	}
+	for _, u := range p.removedUsers {
+		resp.RemovedUsers = append(resp.RemovedUsers, u.GetID())
+	}
+	for _, o := range p.orders {
+		resp.ImportedOrders = append(resp.ImportedOrders, o.GetID())
+	}
+	return resp, nil
+}
+
+// removeOrders removes the orders for good, sitreps kept only in their version history included.
+func (s *serviceAdmin) removeOrders(ctx context.Context, tx repository.RepositoryOrderAPI, ids []meta.ID) errwrap.Error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	root, err := tx.ReadByID(ctx, s.RootOrder.GetID())
+	if err != nil {
+		return err
+	}
+	if _, err := tx.RemoveDelegatedTasks(ctx, root.GetID(), ids, root.GetMeta()); err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		var sitrepIDs []meta.ID
+		versions, err := tx.ReadVersions(ctx, id)
+		if err != nil && err.GetStatusCode() != http.StatusNotFound {
+			return err
+		}
+		for _, version := range versions {
+			for _, sitrep := range version.GetSitReps() {
+				if !slices.Contains(sitrepIDs, sitrep.GetID()) {
+					sitrepIDs = append(sitrepIDs, sitrep.GetID())
+				}
+			}
+		}
+		if _, err := tx.DeleteSitReps(ctx, sitrepIDs); err != nil {
+			return err
+		}
+		if err := tx.DeleteOrder(ctx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
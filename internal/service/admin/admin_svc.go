@@ -0,0 +1,48 @@
+package admin
+
+import (
+	"context"
+
+	"github.com/moledoc/orderly/internal/domain/errwrap"
+	"github.com/moledoc/orderly/internal/domain/order"
+	"github.com/moledoc/orderly/internal/domain/request"
+	"github.com/moledoc/orderly/internal/domain/response"
+	"github.com/moledoc/orderly/internal/domain/user"
+	"github.com/moledoc/orderly/internal/repository"
+)
+
+type ServiceAdminAPI interface {
+	Export(ctx context.Context, req *request.ExportRequest) (*response.ExportResponse, errwrap.Error)
+	Import(ctx context.Context, req *request.ImportRequest) (*response.ImportResponse, errwrap.Error)
+}
+
+// serviceAdmin works on the repositories directly, since objects are moved as stored, ids and meta included.
+// NOTE: root order and root user belong to the running services, they're never replaced by an import
+type serviceAdmin struct {
+	Orders    repository.RepositoryOrderAPI
+	Users     repository.RepositoryUserAPI
+	RootOrder *order.Order
+	RootUser  *user.User
+}
+
+var (
+	_   ServiceAdminAPI = (*serviceAdmin)(nil)
+	svc ServiceAdminAPI = nil
+)
+
+func GetServiceAdmin() ServiceAdminAPI {
+	return svc
+}
+
+func NewServiceAdmin(orders repository.RepositoryOrderAPI, users repository.RepositoryUserAPI, rootOrder *order.Order, rootUser *user.User) ServiceAdminAPI {
+	if orders == nil || users == nil {
+		panic("admin: order and user repositories are required")
+	}
+	svc = &serviceAdmin{
+		Orders:    orders,
+		Users:     users,
+		RootOrder: rootOrder,
+		RootUser:  rootUser,
+	}
+	return svc
+}
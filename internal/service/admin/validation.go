@@ -0,0 +1,75 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/moledoc/orderly/internal/domain/dump"
+	"github.com/moledoc/orderly/internal/domain/errwrap"
+	"github.com/moledoc/orderly/internal/domain/meta"
+	"github.com/moledoc/orderly/internal/domain/request"
+	"github.com/moledoc/orderly/internal/service/common/validation"
+	"github.com/moledoc/orderly/internal/service/mgmtorder"
+	"github.com/moledoc/orderly/internal/service/mgmtuser"
+)
+
+func ValidateExportRequest(req *request.ExportRequest) errwrap.Error {
+	if req == nil {
+		return errwrap.NewError(http.StatusBadRequest, "empty request")
+	}
+	return nil
+}
+
+// ValidateImportRequest checks the document on its own, references between the objects are checked when importing.
+func ValidateImportRequest(req *request.ImportRequest) errwrap.Error {
+	if req == nil || req.GetDocument() == nil {
+		return errwrap.NewError(http.StatusBadRequest, "empty request")
+	}
+
+	switch req.GetMode() {
+	case "", dump.ModeMerge, dump.ModeReplace:
+	default:
+		return errwrap.NewError(http.StatusBadRequest, "invalid mode: expected '%s' or '%s'", dump.ModeMerge, dump.ModeReplace)
+	}
+
+	doc := req.GetDocument()
+	if doc.GetVersion() != dump.Version {
+		return errwrap.NewError(http.StatusBadRequest, "invalid document.version: expected %v, got %v", dump.Version, doc.GetVersion())
+	}
+
+	userIDs := make(map[meta.ID]bool)
+	for i, u := range doc.GetUsers() {
+		if u == nil || u.GetMeta() == nil {
+			return errwrap.NewError(http.StatusBadRequest, "invalid document.users[%v]: user and its meta are required", i)
+		}
+		if err := mgmtuser.ValidateUser(u, validation.IgnoreNothing); err != nil {
+			return errwrap.NewError(http.StatusBadRequest, "invalid document.users[%v]: %s", i, err.GetStatusMessage())
+		}
+		if userIDs[u.GetID()] {
+			return errwrap.NewError(http.StatusBadRequest, "invalid document.users[%v].id: duplicate id '%s'", i, u.GetID())
+		}
+		userIDs[u.GetID()] = true
+	}
+
+	orderIDs := make(map[meta.ID]bool)
+	sitrepIDs := make(map[meta.ID]bool)
+	for i, o := range doc.GetOrders() {
+		if o == nil || o.GetMeta() == nil {
+			return errwrap.NewError(http.StatusBadRequest, "invalid document.orders[%v]: order and its meta are required", i)
+		}
+		if err := mgmtorder.ValidateOrder(o, validation.IgnoreNothing); err != nil {
+			return errwrap.NewError(http.StatusBadRequest, "invalid document.orders[%v]: %s", i, err.GetStatusMessage())
+		}
+		if orderIDs[o.GetID()] {
+			return errwrap.NewError(http.StatusBadRequest, "invalid document.orders[%v].task.id: duplicate id '%s'", i, o.GetID())
+		}
+		orderIDs[o.GetID()] = true
+		for j, sitrep := range o.GetSitReps() {
+			if sitrepIDs[sitrep.GetID()] {
+				return errwrap.NewError(http.StatusBadRequest, "invalid document.orders[%v].sitreps[%v].id: duplicate id '%s'", i, j, sitrep.GetID())
+			}
+			sitrepIDs[sitrep.GetID()] = true
+		}
+	}
+
+	return nil
+}
@@ -3,6 +3,8 @@ package validation
 import (
 	"net/http"
 	"net/mail"
+	"slices"
+	"time"
 
 	"github.com/moledoc/orderly/internal/domain/errwrap"
 	"github.com/moledoc/orderly/internal/domain/meta"
@@ -48,3 +50,33 @@ func ValidateEmail(email user.Email) errwrap.Error {
 	}
 	return nil
 }
+
+// ValidateVersion rejects changes made against a stale copy of an object; zero expected version skips the check
+func ValidateVersion(expected uint, current uint) errwrap.Error {
+	if expected == 0 || expected == current {
+		return nil
+	}
+	return errwrap.NewError(http.StatusConflict, "version mismatch: expected %v, current %v", expected, current)
+}
+
+// ValidatePage checks the listing options: the sort field has to be one of allowed and the cursor made with the same sort
+func ValidatePage(sort meta.Sort, cursor string, allowed ...meta.Sort) errwrap.Error {
+	if !slices.Contains(allowed, sort.Field()) {
+		return errwrap.NewError(http.StatusBadRequest, "invalid sort '%s'", sort)
+	}
+	if len(cursor) == 0 {
+		return nil
+	}
+	if _, err := meta.DecodeCursor(cursor, sort); err != nil {
+		return errwrap.NewError(http.StatusBadRequest, "invalid cursor: %s", err)
+	}
+	return nil
+}
+
+// ValidateRange checks the time range [from, to) isn't backwards; zero time leaves the side open
+func ValidateRange(name string, from time.Time, to time.Time) errwrap.Error {
+	if from.IsZero() || to.IsZero() || from.Before(to) {
+		return nil
+	}
+	return errwrap.NewError(http.StatusBadRequest, "invalid %s range: from has to be before to", name)
+}
@@ -0,0 +1,332 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"unicode"
+
+	"github.com/moledoc/orderly/internal/domain/errwrap"
+	"github.com/moledoc/orderly/internal/domain/meta"
+	"github.com/moledoc/orderly/internal/domain/order"
+	"github.com/moledoc/orderly/internal/domain/request"
+	"github.com/moledoc/orderly/internal/domain/user"
+	"github.com/moledoc/orderly/internal/service/mgmtorder"
+	"github.com/moledoc/orderly/internal/service/mgmtuser"
+	"github.com/moledoc/orderly/pkg/utils"
+)
+
+// Config sets how the issues missing some of the order's fields are imported.
+type Config struct {
+	EmailDomain   string
+	Deadline      time.Duration
+	ParentOrderID string
+}
+
+func RegisterFlags(fs *flag.FlagSet) *Config {
+	cfg := &Config{}
+	fs.StringVar(&cfg.EmailDomain, "jira-email-domain", "jira.invalid", "domain of the emails given to Jira users the export has no email for")
+	fs.DurationVar(&cfg.Deadline, "jira-deadline", 30*24*time.Hour, "deadline, from now, of top level issues without a due date")
+	fs.StringVar(&cfg.ParentOrderID, "jira-parent-order", "", "order the top level issues are delegated from, root order when empty")
+	return cfg
+}
+
+// Report maps the Jira issues and users onto the orders and users they were imported as.
+type Report struct {
+	Users    []*UserMapping  `json:"users"`
+	Issues   []*IssueMapping `json:"issues"`
+	Warnings []string        `json:"warnings,omitempty"`
+}
+
+type UserMapping struct {
+	Jira    string     `json:"jira"`
+	Email   user.Email `json:"email"`
+	UserID  meta.ID    `json:"user_id"`
+	Created bool       `json:"created"` // NOTE: false when the user existed already
+}
+
+type IssueMapping struct {
+	Key           string     `json:"key"`
+	Type          string     `json:"type,omitempty"`
+	Parent        string     `json:"parent,omitempty"`
+	Status        string     `json:"status,omitempty"`
+	OrderID       meta.ID    `json:"order_id"`
+	ParentOrderID meta.ID    `json:"parent_order_id"`
+	State         string     `json:"state"`
+	Accountable   user.Email `json:"accountable"`
+	SitReps       int        `json:"sitreps"`
+}
+
+func (r *Report) warn(format string, a ...any) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, a...))
+}
+
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+func (r *Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "JIRA USER\tEMAIL\tUSER ID\tCREATED")
+	for _, u := range r.Users {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%v\n", u.Jira, u.Email, u.UserID, u.Created)
+	}
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "ISSUE\tTYPE\tPARENT\tSTATUS\tORDER ID\tPARENT ORDER ID\tSTATE\tACCOUNTABLE\tSITREPS")
+	for _, i := range r.Issues {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%v\n", i.Key, i.Type, i.Parent, i.Status, i.OrderID, i.ParentOrderID, i.State, i.Accountable, i.SitReps)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	for _, warning := range r.Warnings {
+		if _, err := fmt.Fprintf(w, "[WARNING]: %s\n", warning); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// email returns the person's email, made up from their name when the export doesn't have it.
+func (cfg *Config) email(p *Person) user.Email {
+	if len(p.Email) > 0 {
+		return user.Email(strings.ToLower(p.Email))
+	}
+	name := p.Name
+	if len(name) == 0 {
+		name = p.ID
+	}
+	var local strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			local.WriteRune(r)
+		case unicode.IsSpace(r) || r == '.' || r == '-' || r == '_':
+			if local.Len() > 0 && !strings.HasSuffix(local.String(), ".") {
+				local.WriteRune('.')
+			}
+		}
+	}
+	return user.Email(strings.Trim(local.String(), ".") + "@" + cfg.EmailDomain)
+}
+
+// importUsers makes sure every assignee and comment author is a user, missing users are created as the root user's subordinates.
+func (cfg *Config) importUsers(ctx context.Context, issues []*Issue, users mgmtuser.ServiceMgmtUserAPI, report *Report) (map[*Person]user.Email, errwrap.Error) {
+	var people []*Person
+	for _, issue := range issues {
+		if issue.Assignee != nil {
+			people = append(people, issue.Assignee)
+		}
+		for _, comment := range issue.Comments {
+			if comment.Author != nil {
+				people = append(people, comment.Author)
+			}
+		}
+	}
+
+	emails := make(map[*Person]user.Email)
+	mapped := make(map[user.Email]bool)
+	root := users.GetRootUser(ctx)
+	for _, p := range people {
+		email := cfg.email(p)
+		emails[p] = email
+		if mapped[email] {
+			continue
+		}
+		mapped[email] = true
+
+		jira := p.Name
+		if len(jira) == 0 {
+			jira = p.ID
+		}
+		resp, err := users.GetUsers(ctx, &request.GetUsersRequest{Emails: []user.Email{email}})
+		if err != nil {
+			return nil, err
+		}
+		if len(resp.GetUsers()) > 0 {
+			report.Users = append(report.Users, &UserMapping{Jira: jira, Email: email, UserID: resp.GetUsers()[0].GetID()})
+			continue
+		}
+
+		name := p.Name
+		if len(name) == 0 {
+			name = strings.Split(string(email), "@")[0]
+		}
+		created, err := users.PostUser(ctx, &request.PostUserRequest{
+			User: &user.User{
+				Name:       name,
+				Email:      email,
+				Supervisor: root.GetEmail(),
+			},
+		})
+		if err != nil {
+			return nil, errwrap.NewError(uint(err.GetStatusCode()), "creating user '%s' failed: %s", email, err.GetStatusMessage())
+		}
+		report.Users = append(report.Users, &UserMapping{Jira: jira, Email: email, UserID: created.GetUser().GetID(), Created: true})
+	}
+	return emails, nil
+}
+
+// Import creates an order for every issue, sub-tasks and issues of an epic are delegated from their parent's order.
+// NOTE: issues are imported parents first, the report tells what was imported until the first failure
+func (cfg *Config) Import(ctx context.Context, issues []*Issue, orders mgmtorder.ServiceMgmtOrderAPI, users mgmtuser.ServiceMgmtUserAPI) (*Report, errwrap.Error) {
+	report := &Report{}
+	if orders == nil || users == nil {
+		return report, errwrap.NewError(http.StatusInternalServerError, "jira import needs the order and user services")
+	}
+
+	byRef := make(map[string]*Issue)
+	for _, issue := range issues {
+		if len(issue.Key) == 0 {
+			return report, errwrap.NewError(http.StatusBadRequest, "issue without a key")
+		}
+		if _, ok := byRef[issue.Key]; ok {
+			return report, errwrap.NewError(http.StatusBadRequest, "duplicate issue '%s'", issue.Key)
+		}
+		byRef[issue.Key] = issue
+		if len(issue.ID) > 0 {
+			byRef[issue.ID] = issue
+		}
+	}
+
+	children := make(map[*Issue][]*Issue)
+	var next []*Issue
+	for _, issue := range issues {
+		parent, ok := byRef[issue.Parent]
+		if len(issue.Parent) > 0 && !ok {
+			report.warn("issue '%s': parent '%s' not in the export, imported as a top level issue", issue.Key, issue.Parent)
+		}
+		if !ok || parent == issue {
+			next = append(next, issue)
+			continue
+		}
+		children[parent] = append(children[parent], issue)
+	}
+
+	emails, err := cfg.importUsers(ctx, issues, users, report)
+	if err != nil {
+		return report, err
+	}
+
+	parentOrderID := meta.ID(cfg.ParentOrderID)
+	if len(parentOrderID) == 0 {
+		parentOrderID = orders.GetRootOrder(ctx).GetID()
+	}
+	parentOrder, err := orders.GetOrderByID(ctx, &request.GetOrderByIDRequest{ID: parentOrderID})
+	if err != nil {
+		return report, errwrap.NewError(uint(err.GetStatusCode()), "reading parent order failed: %s", err.GetStatusMessage())
+	}
+
+	type queued struct {
+		issue    *Issue
+		parent   *order.Order
+		deadline time.Time // NOTE: deadline when the issue has no due date
+	}
+	var queue []queued
+	for _, issue := range next {
+		queue = append(queue, queued{issue: issue, parent: parentOrder.GetOrder(), deadline: time.Now().UTC().Add(cfg.Deadline)})
+	}
+	imported := 0
+	for len(queue) > 0 {
+		q := queue[0]
+		queue = queue[1:]
+
+		o, err := cfg.importIssue(ctx, q.issue, q.parent, q.deadline, emails, users.GetRootUser(ctx).GetEmail(), orders, report)
+		if err != nil {
+			return report, errwrap.NewError(uint(err.GetStatusCode()), "importing issue '%s' failed: %s", q.issue.Key, err.GetStatusMessage())
+		}
+		imported++
+		for _, child := range children[q.issue] {
+			queue = append(queue, queued{issue: child, parent: o, deadline: o.GetTask().GetDeadline()}) // NOTE: due with the parent
+		}
+	}
+	if imported < len(issues) {
+		report.warn("%v issues not imported, their parents form a cycle", len(issues)-imported)
+	}
+	return report, nil
+}
+
+func (cfg *Config) importIssue(ctx context.Context, issue *Issue, parent *order.Order, deadline time.Time, emails map[*Person]user.Email, rootEmail user.Email, orders mgmtorder.ServiceMgmtOrderAPI, report *Report) (*order.Order, errwrap.Error) {
+	state, ok := State(issue.Status, issue.StatusCategory)
+	if !ok {
+		report.warn("issue '%s': unknown status '%s', imported as '%s'", issue.Key, issue.Status, state)
+	}
+
+	accountable := rootEmail // NOTE: unassigned issues are the root user's
+	if issue.Assignee != nil {
+		accountable = emails[issue.Assignee]
+	}
+
+	if !issue.DueDate.IsZero() {
+		deadline = issue.DueDate
+	}
+
+	objective := fmt.Sprintf("[%s] %s", issue.Key, issue.Summary)
+	if len(strings.TrimSpace(issue.Description)) > 0 {
+		objective += "\n\n" + issue.Description // NOTE: first line of the objective is the order's title
+	}
+
+	var sitreps []*order.SitRep
+	for _, comment := range issue.Comments {
+		if len(strings.TrimSpace(comment.Body)) == 0 {
+			continue
+		}
+		by := rootEmail
+		if comment.Author != nil {
+			by = emails[comment.Author]
+		}
+		datetime := comment.Created
+		if datetime.IsZero() {
+			datetime = issue.Created
+		}
+		if datetime.IsZero() {
+			datetime = time.Now().UTC()
+		}
+		sitreps = append(sitreps, &order.SitRep{
+			DateTime:  datetime,
+			By:        by,
+			Situation: comment.Body,
+		})
+	}
+
+	resp, err := orders.PostOrder(ctx, &request.PostOrderRequest{
+		Order: &order.Order{
+			Task: &order.Task{
+				State:       utils.Ptr(state),
+				Accountable: accountable,
+				Objective:   objective,
+				Deadline:    deadline,
+			},
+			ParentOrderID: parent.GetID(),
+			SitReps:       sitreps,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, conflict := range resp.GetDeadlineConflicts() {
+		report.warn("issue '%s': %s", issue.Key, conflict)
+	}
+
+	o := resp.GetOrder()
+	report.Issues = append(report.Issues, &IssueMapping{
+		Key:           issue.Key,
+		Type:          issue.Type,
+		Parent:        issue.Parent,
+		Status:        issue.Status,
+		OrderID:       o.GetID(),
+		ParentOrderID: o.GetParentOrderID(),
+		State:         state.String(),
+		Accountable:   accountable,
+		SitReps:       len(o.GetSitReps()),
+	})
+	return o, nil
+}
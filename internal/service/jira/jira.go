@@ -0,0 +1,332 @@
+package jira
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/moledoc/orderly/internal/domain/order"
+)
+
+// Issue is a Jira issue as read from an export, JSON and CSV exports alike.
+type Issue struct {
+	Key            string
+	ID             string // NOTE: numeric issue id, CSV exports refer to parents by it
+	Type           string
+	Summary        string
+	Description    string
+	Status         string
+	StatusCategory string
+	Assignee       *Person
+	Created        time.Time
+	DueDate        time.Time
+	Parent         string // NOTE: key or id of the parent issue, epic link included
+	Comments       []*Comment
+}
+
+// Person is a Jira user, exports don't always tell the email.
+type Person struct {
+	ID    string // NOTE: account id or user name
+	Name  string
+	Email string
+}
+
+type Comment struct {
+	Author  *Person
+	Created time.Time
+	Body    string
+}
+
+var timeLayouts = []string{
+	"2006-01-02T15:04:05.000-0700",
+	time.RFC3339,
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"02/Jan/06 3:04 PM",
+	"02/Jan/06",
+}
+
+func parseTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if len(value) == 0 {
+		return time.Time{}, nil
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unknown time format '%s'", value)
+}
+
+// State maps the Jira status onto the order state, by status name first and then by status category.
+// NOTE: unknown statuses map to 'Not Started', ok is false then
+func State(status string, category string) (state order.State, ok bool) {
+	switch strings.ToLower(strings.TrimSpace(status)) {
+	case "to do", "open", "new", "backlog", "selected for development", "reopened":
+		return order.NotStarted, true
+	case "in progress", "in development", "in review", "review", "testing", "qa":
+		return order.InProgress, true
+	case "blocked", "on hold", "impeded":
+		return order.Blocked, true
+	case "done", "closed", "resolved", "complete", "completed":
+		return order.Completed, true
+	}
+	switch strings.ToLower(strings.TrimSpace(category)) {
+	case "new", "to do":
+		return order.NotStarted, true
+	case "indeterminate", "in progress":
+		return order.InProgress, true
+	case "done":
+		return order.Completed, true
+	}
+	return order.NotStarted, false
+}
+
+// Parse reads a JSON or CSV export, the format is told apart by the first character.
+func Parse(r io.Reader) ([]*Issue, error) {
+	br := bufio.NewReader(r)
+	for {
+		b, err := br.Peek(1)
+		if err != nil {
+			return nil, fmt.Errorf("reading export failed: %w", err)
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			br.ReadByte()
+			continue
+		case '{', '[':
+			return ParseJSON(br)
+		default:
+			return ParseCSV(br)
+		}
+	}
+}
+
+type jsonPerson struct {
+	AccountID    string `json:"accountId"`
+	Name         string `json:"name"`
+	DisplayName  string `json:"displayName"`
+	EmailAddress string `json:"emailAddress"`
+}
+
+func (p *jsonPerson) person() *Person {
+	if p == nil {
+		return nil
+	}
+	id := p.AccountID
+	if len(id) == 0 {
+		id = p.Name
+	}
+	return &Person{ID: id, Name: p.DisplayName, Email: p.EmailAddress}
+}
+
+type jsonIssue struct {
+	ID     string `json:"id"`
+	Key    string `json:"key"`
+	Fields struct {
+		Summary     string          `json:"summary"`
+		Description json.RawMessage `json:"description"`
+		IssueType   struct {
+			Name string `json:"name"`
+		} `json:"issuetype"`
+		Status struct {
+			Name           string `json:"name"`
+			StatusCategory struct {
+				Key string `json:"key"`
+			} `json:"statusCategory"`
+		} `json:"status"`
+		Assignee *jsonPerson `json:"assignee"`
+		Created  string      `json:"created"`
+		DueDate  string      `json:"duedate"`
+		Parent   *struct {
+			ID  string `json:"id"`
+			Key string `json:"key"`
+		} `json:"parent"`
+		Comment struct {
+			Comments []struct {
+				Author  *jsonPerson     `json:"author"`
+				Body    json.RawMessage `json:"body"`
+				Created string          `json:"created"`
+			} `json:"comments"`
+		} `json:"comment"`
+	} `json:"fields"`
+}
+
+// text reads the text of a field, either a plain string or a document in Atlassian Document Format.
+func text(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var str string
+	if err := json.Unmarshal(raw, &str); err == nil {
+		return str
+	}
+	var node struct {
+		Type    string            `json:"type"`
+		Text    string            `json:"text"`
+		Content []json.RawMessage `json:"content"`
+	}
+	if err := json.Unmarshal(raw, &node); err != nil {
+		return ""
+	}
+	var parts []string
+	for _, child := range node.Content {
+		parts = append(parts, text(child))
+	}
+	sep := ""
+	if node.Type == "doc" {
+		sep = "\n" // NOTE: paragraphs are on lines of their own
+	}
+	return node.Text + strings.Join(parts, sep)
+}
+
+// ParseJSON reads the issues of a search result, or a list of issues.
+func ParseJSON(r io.Reader) ([]*Issue, error) {
+	bs, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading export failed: %w", err)
+	}
+	var issues []*jsonIssue
+	if trimmed := bytes.TrimSpace(bs); len(trimmed) > 0 && trimmed[0] == '[' {
+		err = json.Unmarshal(trimmed, &issues)
+	} else {
+		var result struct {
+			Issues []*jsonIssue `json:"issues"`
+		}
+		err = json.Unmarshal(trimmed, &result)
+		issues = result.Issues
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing json export failed: %w", err)
+	}
+
+	var parsed []*Issue
+	for _, ji := range issues {
+		issue := &Issue{
+			Key:            ji.Key,
+			ID:             ji.ID,
+			Type:           ji.Fields.IssueType.Name,
+			Summary:        ji.Fields.Summary,
+			Description:    text(ji.Fields.Description),
+			Status:         ji.Fields.Status.Name,
+			StatusCategory: ji.Fields.Status.StatusCategory.Key,
+			Assignee:       ji.Fields.Assignee.person(),
+		}
+		if ji.Fields.Parent != nil {
+			issue.Parent = ji.Fields.Parent.Key
+			if len(issue.Parent) == 0 {
+				issue.Parent = ji.Fields.Parent.ID
+			}
+		}
+		if issue.Created, err = parseTime(ji.Fields.Created); err != nil {
+			return nil, fmt.Errorf("issue '%s': invalid created: %w", ji.Key, err)
+		}
+		if issue.DueDate, err = parseTime(ji.Fields.DueDate); err != nil {
+			return nil, fmt.Errorf("issue '%s': invalid duedate: %w", ji.Key, err)
+		}
+		for i, jc := range ji.Fields.Comment.Comments {
+			created, err := parseTime(jc.Created)
+			if err != nil {
+				return nil, fmt.Errorf("issue '%s': invalid comment.%v.created: %w", ji.Key, i, err)
+			}
+			issue.Comments = append(issue.Comments, &Comment{
+				Author:  jc.Author.person(),
+				Created: created,
+				Body:    text(jc.Body),
+			})
+		}
+		parsed = append(parsed, issue)
+	}
+	return parsed, nil
+}
+
+// ParseCSV reads the 'all fields' CSV export, comments are the repeated Comment columns.
+// NOTE: a comment is 'created;author id;body', authors are known by name only when they're an assignee of some issue
+func ParseCSV(r io.Reader) ([]*Issue, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("parsing csv export failed: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	columns := make(map[string][]int)
+	for i, name := range records[0] {
+		name = strings.ToLower(strings.TrimSpace(name))
+		columns[name] = append(columns[name], i)
+	}
+	get := func(record []string, names ...string) string {
+		for _, name := range names {
+			for _, i := range columns[name] {
+				if i < len(record) && len(strings.TrimSpace(record[i])) > 0 {
+					return strings.TrimSpace(record[i])
+				}
+			}
+		}
+		return ""
+	}
+
+	people := make(map[string]*Person)
+	for _, record := range records[1:] {
+		if id := get(record, "assignee id"); len(id) > 0 {
+			people[id] = &Person{ID: id, Name: get(record, "assignee")}
+		}
+	}
+
+	var parsed []*Issue
+	for _, record := range records[1:] {
+		issue := &Issue{
+			Key:            get(record, "issue key"),
+			ID:             get(record, "issue id"),
+			Type:           get(record, "issue type"),
+			Summary:        get(record, "summary"),
+			Description:    get(record, "description"),
+			Status:         get(record, "status"),
+			StatusCategory: get(record, "status category"),
+			Parent:         get(record, "parent", "parent id", "parent key", "custom field (epic link)"),
+		}
+		if name := get(record, "assignee"); len(name) > 0 {
+			issue.Assignee = &Person{ID: get(record, "assignee id"), Name: name}
+			if len(issue.Assignee.ID) == 0 {
+				issue.Assignee.ID = name
+			}
+		}
+		if issue.Created, err = parseTime(get(record, "created")); err != nil {
+			return nil, fmt.Errorf("issue '%s': invalid created: %w", issue.Key, err)
+		}
+		if issue.DueDate, err = parseTime(get(record, "due date", "due")); err != nil {
+			return nil, fmt.Errorf("issue '%s': invalid due date: %w", issue.Key, err)
+		}
+		for _, i := range columns["comment"] {
+			if i >= len(record) || len(strings.TrimSpace(record[i])) == 0 {
+				continue
+			}
+			parts := strings.SplitN(record[i], ";", 3)
+			if len(parts) < 3 {
+				issue.Comments = append(issue.Comments, &Comment{Body: record[i]})
+				continue
+			}
+			created, err := parseTime(parts[0])
+			if err != nil {
+				return nil, fmt.Errorf("issue '%s': invalid comment created: %w", issue.Key, err)
+			}
+			author, ok := people[parts[1]]
+			if !ok {
+				author = &Person{ID: parts[1], Name: parts[1]}
+			}
+			issue.Comments = append(issue.Comments, &Comment{Author: author, Created: created, Body: parts[2]})
+		}
+		parsed = append(parsed, issue)
+	}
+	return parsed, nil
+}
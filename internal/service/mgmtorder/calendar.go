@@ -0,0 +1,97 @@
+package mgmtorder
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/moledoc/orderly/internal/domain/errwrap"
+	"github.com/moledoc/orderly/internal/domain/meta"
+	"github.com/moledoc/orderly/internal/domain/order"
+	"github.com/moledoc/orderly/internal/domain/request"
+	"github.com/moledoc/orderly/internal/domain/response"
+	"github.com/moledoc/orderly/internal/domain/user"
+	"github.com/moledoc/orderly/internal/middleware"
+	"github.com/moledoc/orderly/internal/repository"
+)
+
+// accountableTasks returns the tasks and delegated tasks of the orders the user is accountable for, sorted by deadline.
+// NOTE: a delegated task is the task of the order it was delegated to, the order's own copy is used when both are read.
+// Delegated tasks of orders in trash are left out
+func accountableTasks(orders []*order.Order, deleted []*order.Order, accountable user.Email, rootOrderID meta.ID) []*order.Task {
+	trashed := make(map[meta.ID]bool, len(deleted))
+	for _, o := range deleted {
+		trashed[o.GetID()] = true
+	}
+
+	byID := make(map[meta.ID]*order.Task)
+	for _, o := range orders {
+		if o.GetID() == rootOrderID { // NOTE: root order stands for the whole organisation, it has no deadline of its own
+			continue
+		}
+		if o.GetTask().GetAccountable() == accountable {
+			byID[o.GetTask().GetID()] = o.GetTask()
+		}
+	}
+	for _, o := range orders {
+		for _, delegated := range o.GetDelegatedTasks() {
+			if _, ok := byID[delegated.GetID()]; ok || trashed[delegated.GetID()] || delegated.GetAccountable() != accountable {
+				continue
+			}
+			byID[delegated.GetID()] = delegated
+		}
+	}
+
+	tasks := make([]*order.Task, 0, len(byID))
+	for _, task := range byID {
+		tasks = append(tasks, task)
+	}
+	slices.SortFunc(tasks, func(a, b *order.Task) int {
+		return cmp.Or(a.GetDeadline().Compare(b.GetDeadline()), cmp.Compare(a.GetID(), b.GetID()))
+	})
+	return tasks
+}
+
+func (s *serviceMgmtOrder) GetCalendar(ctx context.Context, req *request.GetCalendarRequest) (*response.GetCalendarResponse, errwrap.Error) {
+	ctx = middleware.AddTraceToCtx(ctx)
+	middleware.SpanStart(ctx, "GetCalendar")
+	defer middleware.SpanStop(ctx, "GetCalendar")
+
+	if err := ValidateGetCalendarRequest(req); err != nil {
+		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
+	}
+
+	var tasks []*order.Task
+	err := s.Repository.WithTx(ctx, func(tx repository.RepositoryOrderAPI) errwrap.Error {
+		var orders []*order.Order
+		var err errwrap.Error
+		if len(req.GetRootOrderID()) > 0 {
+			var root *order.Order
+			root, err = tx.ReadByID(ctx, req.GetRootOrderID())
+			if err != nil {
+				return err
+			}
+			orders, err = readSubtree(ctx, tx, root)
+		} else {
+			orders, _, err = tx.ReadBy(ctx, &request.GetOrdersRequest{})
+		}
+		if err != nil {
+			return err
+		}
+		deleted, err := tx.ReadDeleted(ctx)
+		if err != nil {
+			return err
+		}
+		tasks = accountableTasks(orders, deleted, req.GetAccountable(), s.RootOrder.GetID())
+		return nil
+	})
+	if err != nil {
+		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
+	}
+
+	return &response.GetCalendarResponse{
+		Calendar: order.Calendar(fmt.Sprintf("Deadlines of %s", req.GetAccountable()), tasks, time.Now().UTC()),
+	}, nil
+}
@@ -0,0 +1,101 @@
+package mgmtorder
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+
+	"github.com/moledoc/orderly/internal/domain/errwrap"
+	"github.com/moledoc/orderly/internal/domain/meta"
+	"github.com/moledoc/orderly/internal/domain/order"
+	"github.com/moledoc/orderly/internal/repository"
+)
+
+const (
+	DeadlinesWarn    = "warn"
+	DeadlinesEnforce = "enforce"
+)
+
+// Config sets how the order service treats orders due after the orders they're delegated from.
+type Config struct {
+	Deadlines string
+}
+
+var DefaultConfig = Config{
+	Deadlines: DeadlinesWarn,
+}
+
+func RegisterFlags(fs *flag.FlagSet) *Config {
+	cfg := &Config{}
+	fs.StringVar(&cfg.Deadlines, "order-deadlines", DeadlinesWarn, "orders due after the orders they're delegated from: warn (listed in the response) or enforce (rejected)")
+	return cfg
+}
+
+func (cfg *Config) validate() errwrap.Error {
+	switch cfg.Deadlines {
+	case DeadlinesWarn, DeadlinesEnforce:
+		return nil
+	default:
+		return errwrap.NewError(http.StatusInternalServerError, "unknown order deadlines '%s'", cfg.Deadlines)
+	}
+}
+
+// deadlineConflicts lists the conflicts around the stored orders: each order against its parent and,
+// with subtree, the orders delegated below it against the order.
+// NOTE: conflicts are listed once, even when several of the orders share them
+func deadlineConflicts(ctx context.Context, repo repository.RepositoryOrderAPI, subtree bool, ids ...meta.ID) ([]*order.DeadlineConflict, errwrap.Error) {
+	var conflicts []*order.DeadlineConflict
+	seen := make(map[[2]meta.ID]bool)
+	add := func(conflict *order.DeadlineConflict) {
+		if conflict == nil || seen[[2]meta.ID{conflict.GetOrderID(), conflict.GetAncestorID()}] {
+			return
+		}
+		seen[[2]meta.ID{conflict.GetOrderID(), conflict.GetAncestorID()}] = true
+		conflicts = append(conflicts, conflict)
+	}
+
+	for _, id := range ids {
+		o, err := repo.ReadByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if o.GetParentOrderID() != o.GetID() { // NOTE: root order is its own parent
+			parent, err := repo.ReadByID(ctx, o.GetParentOrderID())
+			if err != nil && err.GetStatusCode() != http.StatusNotFound {
+				return nil, err
+			}
+			if err == nil {
+				add(order.NewDeadlineConflict(o.GetTask(), parent.GetTask()))
+			}
+		}
+		if !subtree {
+			continue
+		}
+
+		orders, err := readSubtree(ctx, repo, o)
+		if err != nil {
+			return nil, err
+		}
+		for _, delegated := range orders[1:] {
+			add(order.NewDeadlineConflict(delegated.GetTask(), o.GetTask()))
+		}
+	}
+	return conflicts, nil
+}
+
+// checkDeadlines returns the conflicts to list in the response, or rejects them when deadlines are enforced.
+func (s *serviceMgmtOrder) checkDeadlines(conflicts []*order.DeadlineConflict, field string) ([]*order.DeadlineConflict, errwrap.Error) {
+	if len(conflicts) == 0 || s.Config.Deadlines != DeadlinesEnforce {
+		return conflicts, nil
+	}
+
+	var fields []errwrap.FieldError
+	for _, conflict := range conflicts {
+		fields = append(fields, errwrap.FieldError{
+			Field:   field,
+			Message: fmt.Sprintf("order '%s' is due after order '%s'", conflict.GetOrderID(), conflict.GetAncestorID()),
+		})
+	}
+	return nil, errwrap.NewFieldError(http.StatusUnprocessableEntity, fields...)
+}
@@ -0,0 +1,113 @@
+package mgmtorder
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/moledoc/orderly/internal/domain/errwrap"
+	"github.com/moledoc/orderly/internal/domain/order"
+	"github.com/moledoc/orderly/internal/domain/request"
+	"github.com/moledoc/orderly/internal/domain/response"
+	"github.com/moledoc/orderly/internal/domain/user"
+	"github.com/moledoc/orderly/internal/middleware"
+	"github.com/moledoc/orderly/internal/repository"
+)
+
+const (
+	defaultDocumentSitReps = 3
+	maxDocumentSitReps     = 100
+)
+
+// userNames returns the names of the users by email, no names without the user lookup.
+func (s *serviceMgmtOrder) userNames(ctx context.Context, tree *order.Tree, parent *order.Order) (map[user.Email]string, errwrap.Error) {
+	names := make(map[user.Email]string)
+	if s.Users == nil {
+		return names, nil
+	}
+
+	var emails []user.Email
+	seen := make(map[user.Email]bool)
+	add := func(email user.Email) {
+		if len(email) > 0 && !seen[email] {
+			seen[email] = true
+			emails = append(emails, email)
+		}
+	}
+	add(parent.GetTask().GetAccountable())
+	var walk func(tree *order.Tree)
+	walk = func(tree *order.Tree) {
+		add(tree.GetOrder().GetTask().GetAccountable())
+		for _, sitrep := range tree.GetOrder().GetSitReps() {
+			add(sitrep.GetBy())
+		}
+		for _, suborder := range tree.GetSuborders() {
+			walk(suborder)
+		}
+	}
+	walk(tree)
+
+	resp, err := s.Users.GetUsers(ctx, &request.GetUsersRequest{Emails: emails})
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range resp.GetUsers() {
+		names[u.GetEmail()] = u.GetName()
+	}
+	return names, nil
+}
+
+// GetOrderDocument writes the order and the orders delegated below it up as a five-paragraph order.
+func (s *serviceMgmtOrder) GetOrderDocument(ctx context.Context, req *request.GetOrderDocumentRequest) (*response.GetOrderDocumentResponse, errwrap.Error) {
+	ctx = middleware.AddTraceToCtx(ctx)
+	middleware.SpanStart(ctx, "GetOrderDocument")
+	defer middleware.SpanStop(ctx, "GetOrderDocument")
+
+	if err := ValidateGetOrderDocumentRequest(req); err != nil {
+		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
+	}
+
+	format := req.GetFormat()
+	if len(format) == 0 {
+		format = order.DocumentMarkdown
+	}
+	sitreps := req.GetSitReps()
+	if sitreps == 0 {
+		sitreps = defaultDocumentSitReps
+	}
+
+	now := time.Now().UTC()
+	var tree *order.Tree
+	var parent *order.Order
+	err := s.Repository.WithTx(ctx, func(tx repository.RepositoryOrderAPI) errwrap.Error {
+		o, err := tx.ReadByID(ctx, req.GetID())
+		if err != nil {
+			return err
+		}
+		if o.GetID() != o.GetParentOrderID() { // NOTE: root order doesn't report to anyone
+			parent, err = tx.ReadByID(ctx, o.GetParentOrderID())
+			if err != nil && err.GetStatusCode() != http.StatusNotFound {
+				return err
+			}
+		}
+		tree, err = readOrderTree(ctx, tx, o, 0, now)
+		return err
+	})
+	if err != nil {
+		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
+	}
+
+	names, err := s.userNames(ctx, tree, parent)
+	if err != nil {
+		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
+	}
+
+	doc, errr := order.NewDocument(tree, parent, names, int(sitreps), now).Render(format)
+	if errr != nil {
+		return nil, middleware.AddTraceToErrFromCtx(errwrap.NewError(http.StatusInternalServerError, "rendering document failed: %s", errr), ctx)
+	}
+	return &response.GetOrderDocumentResponse{
+		ContentType: format.ContentType(),
+		Document:    doc,
+	}, nil
+}
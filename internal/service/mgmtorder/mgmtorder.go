@@ -12,6 +12,8 @@ import (
 	"github.com/moledoc/orderly/internal/domain/request"
 	"github.com/moledoc/orderly/internal/domain/response"
 	"github.com/moledoc/orderly/internal/middleware"
+	"github.com/moledoc/orderly/internal/repository"
+	"github.com/moledoc/orderly/internal/service/common/validation"
 	"github.com/moledoc/orderly/pkg/utils"
 )
 
@@ -28,9 +30,13 @@ func (s *serviceMgmtOrder) PostOrder(ctx context.Context, req *request.PostOrder
 	if err := ValidatePostOrderRequest(req); err != nil {
 		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
 	}
+	if err := s.checkUsers(ctx, order.OrderUserRefs("order", req.GetOrder())); err != nil {
+		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
+	}
 
 	o := req.GetOrder().Clone()
 	o.SetID(meta.NewID())
+	o.GetRollupPolicy().SetPreRollupState(0) // NOTE: set by the rollup only
 
 	for _, delegated := range o.GetDelegatedTasks() {
 		delegated.SetID(meta.NewID())
@@ -41,17 +47,31 @@ func (s *serviceMgmtOrder) PostOrder(ctx context.Context, req *request.PostOrder
 
 	now := time.Now().UTC()
 	o.SetMeta(&meta.Meta{
-		Version: 1,
-		Created: now,
-		Updated: now,
+		Version:   1,
+		Created:   now,
+		Updated:   now,
+		UpdatedBy: middleware.GetUserFromCtx(ctx),
 	})
 
-	resp, err := s.Repository.Write(ctx, o)
+	var resp *order.Order
+	var conflicts []*order.DeadlineConflict
+	err := s.Repository.WithTx(ctx, func(tx repository.RepositoryOrderAPI) errwrap.Error {
+		var err errwrap.Error
+		if resp, err = tx.CreateOrder(ctx, o); err != nil {
+			return err
+		}
+		if conflicts, err = deadlineConflicts(ctx, tx, true, o.GetID()); err != nil {
+			return err
+		}
+		conflicts, err = s.checkDeadlines(conflicts, "order.task.deadline")
+		return err
+	})
 	if err != nil {
 		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
 	}
 	return &response.PostOrderResponse{
-		Order: resp,
+		Order:             resp,
+		DeadlineConflicts: conflicts,
 	}, nil
 }
 
@@ -82,12 +102,13 @@ func (s *serviceMgmtOrder) GetOrders(ctx context.Context, req *request.GetOrders
 		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
 	}
 
-	resp, err := s.Repository.ReadBy(ctx, req)
+	resp, page, err := s.Repository.ReadBy(ctx, req)
 	if err != nil {
 		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
 	}
 	return &response.GetOrdersResponse{
 		Orders: resp,
+		Page:   page,
 	}, nil
 }
 
@@ -99,57 +120,159 @@ func (s *serviceMgmtOrder) PatchOrder(ctx context.Context, req *request.PatchOrd
 	if err := ValidatePatchOrderRequest(req); err != nil {
 		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
 	}
-
-	order, err := s.Repository.ReadByID(ctx, req.GetOrder().GetID())
-	if err != nil {
+	if err := s.checkUsers(ctx, order.OrderUserRefs("order", req.GetOrder())); err != nil {
 		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
 	}
 
-	now := time.Now().UTC()
-	hasChanges := false
-	patchedOrder := order.Clone()
+	var resp *order.Order
+	var conflicts []*order.DeadlineConflict
+	err := s.Repository.WithTx(ctx, func(tx repository.RepositoryOrderAPI) errwrap.Error {
+		o, err := tx.ReadByID(ctx, req.GetOrder().GetID())
+		if err != nil {
+			return err
+		}
+		if err := validation.ValidateVersion(req.GetExpectedVersion(), o.GetMeta().GetVersion()); err != nil {
+			return err
+		}
 
-	hasChanges = patchTask(req.GetOrder().GetTask(), patchedOrder.GetTask()) || hasChanges
+		now := time.Now().UTC()
+		patchedOrder := o.Clone()
 
-	// TODO: optimize
-	for _, reqDelegatedTask := range req.GetOrder().GetDelegatedTasks() {
-		for _, patchedDelegatedTask := range patchedOrder.GetDelegatedTasks() {
-			if reqDelegatedTask.GetID() == patchedDelegatedTask.GetID() {
-				hasChanges = patchTask(reqDelegatedTask, patchedDelegatedTask) || hasChanges
-				break
+		var patchedTasks []*order.Task
+		if patchTask(req.GetOrder().GetTask(), patchedOrder.GetTask()) {
+			patchedTasks = append(patchedTasks, patchedOrder.GetTask())
+		}
+
+		// TODO: optimize
+		for _, reqDelegatedTask := range req.GetOrder().GetDelegatedTasks() {
+			for _, patchedDelegatedTask := range patchedOrder.GetDelegatedTasks() {
+				if reqDelegatedTask.GetID() == patchedDelegatedTask.GetID() {
+					if patchTask(reqDelegatedTask, patchedDelegatedTask) {
+						patchedTasks = append(patchedTasks, patchedDelegatedTask)
+					}
+					break
+				}
 			}
 		}
-	}
 
-	// TODO: optimize
-	for _, reqSitRep := range req.GetOrder().GetSitReps() {
-		for _, patchedSitRep := range patchedOrder.GetSitReps() {
-			if reqSitRep.GetID() == patchedSitRep.GetID() {
-				hasChanges = patchSitReps(reqSitRep, patchedSitRep) || hasChanges
-				break
+		var patchedSitReps []*order.SitRep
+		// TODO: optimize
+		for _, reqSitRep := range req.GetOrder().GetSitReps() {
+			for _, patchedSitRep := range patchedOrder.GetSitReps() {
+				if reqSitRep.GetID() == patchedSitRep.GetID() {
+					if patchSitReps(reqSitRep, patchedSitRep) {
+						patchedSitReps = append(patchedSitReps, patchedSitRep)
+					}
+					break
+				}
 			}
 		}
-	}
-	if !utils.IsZeroValue(req.GetOrder().GetParentOrderID()) && req.GetOrder().GetParentOrderID() != patchedOrder.GetParentOrderID() {
-		patchedOrder.SetParentOrderID(req.GetOrder().GetParentOrderID())
-		hasChanges = true
-	}
+		reparent := !utils.IsZeroValue(req.GetOrder().GetParentOrderID()) && req.GetOrder().GetParentOrderID() != patchedOrder.GetParentOrderID()
+		repolicy := req.GetOrder().GetRollupPolicy() != nil && !req.GetOrder().GetRollupPolicy().Equal(patchedOrder.GetRollupPolicy())
 
-	if !hasChanges { // NOTE: no changes, return current
-		return &response.PatchOrderResponse{
-			Order: order,
-		}, nil
-	}
+		if len(patchedTasks) == 0 && len(patchedSitReps) == 0 && !reparent && !repolicy { // NOTE: no changes, return current
+			resp = o
+			return nil
+		}
+		if reparent {
+			if err := checkMoveTarget(ctx, tx, o, req.GetOrder().GetParentOrderID(), "order.parent_order_id"); err != nil {
+				return err
+			}
+		}
+		if repolicy {
+			policy := req.GetOrder().GetRollupPolicy().Clone()
+			policy.SetPreRollupState(0) // NOTE: set by the rollup only
+			if policy.GetIssuesOnBlocked() {
+				policy.SetPreRollupState(patchedOrder.GetRollupPolicy().GetPreRollupState())
+			}
+			patchedOrder.SetRollupPolicy(policy)
+		}
+
+		// NOTE: states rolled up from the changed delegated tasks, children before their parents
+		var rollupIDs, deadlineIDs []meta.ID
+		if patchedOrder.GetTask().GetDeadline() != o.GetTask().GetDeadline() {
+			deadlineIDs = append(deadlineIDs, o.GetID())
+		}
+		for _, task := range patchedTasks {
+			i := slices.IndexFunc(o.GetDelegatedTasks(), func(a *order.Task) bool { return a.GetID() == task.GetID() })
+			if i >= 0 && o.GetDelegatedTasks()[i].GetState() != task.GetState() {
+				rollupIDs = append(rollupIDs, task.GetID())
+			}
+			if i >= 0 && o.GetDelegatedTasks()[i].GetDeadline() != task.GetDeadline() {
+				deadlineIDs = append(deadlineIDs, task.GetID())
+			}
+		}
+		if len(rollupIDs) > 0 || repolicy || patchedOrder.GetTask().GetState() != o.GetTask().GetState() {
+			rollupIDs = append(rollupIDs, o.GetID())
+		}
+		if patchedOrder.GetTask().GetState() != o.GetTask().GetState() && o.GetParentOrderID() != o.GetID() {
+			rollupIDs = append(rollupIDs, o.GetParentOrderID())
+		}
+
+		if o.GetTask().GetState() != order.Completed {
+			if err := checkCompleted(patchedOrder, "order.task.state"); err != nil {
+				return err
+			}
+		}
+		if err := checkDelegatedCompleted(ctx, tx, o.GetID(), req.GetOrder().GetDelegatedTasks(), "order.delegated_tasks"); err != nil {
+			return err
+		}
+
+		m := patchedOrder.GetMeta()
+		m.SetUpdated(now)
+		m.SetUpdatedBy(middleware.GetUserFromCtx(ctx))
+		m.VersionIncr()
 
-	patchedOrder.GetMeta().SetUpdated(now)
-	patchedOrder.GetMeta().VersionIncr()
+		for _, task := range patchedTasks {
+			if resp, err = tx.UpdateTask(ctx, o.GetID(), task, m); err != nil {
+				return err
+			}
+		}
+		for _, sitrep := range patchedSitReps {
+			if resp, err = tx.UpdateSitRep(ctx, o.GetID(), sitrep, m); err != nil {
+				return err
+			}
+		}
+		if repolicy {
+			if resp, err = tx.UpdateRollupPolicy(ctx, o.GetID(), patchedOrder.GetRollupPolicy(), m); err != nil {
+				return err
+			}
+		}
+		if reparent {
+			if resp, err = moveOrder(ctx, tx, o, req.GetOrder().GetParentOrderID(), m, now); err != nil {
+				return err
+			}
+		}
 
-	resp, err := s.Repository.Write(ctx, patchedOrder)
+		if conflicts, err = deadlineConflicts(ctx, tx, true, deadlineIDs...); err != nil {
+			return err
+		}
+		if reparent && !slices.Contains(deadlineIDs, o.GetID()) {
+			moved, err := deadlineConflicts(ctx, tx, false, o.GetID())
+			if err != nil {
+				return err
+			}
+			conflicts = append(conflicts, moved...)
+		}
+		if conflicts, err = s.checkDeadlines(conflicts, "order.task.deadline"); err != nil {
+			return err
+		}
+
+		if len(rollupIDs) == 0 {
+			return nil
+		}
+		if err := rollup(ctx, tx, now, rollupIDs...); err != nil {
+			return err
+		}
+		resp, err = tx.ReadByID(ctx, o.GetID())
+		return err
+	})
 	if err != nil {
 		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
 	}
 	return &response.PatchOrderResponse{
-		Order: resp,
+		Order:             resp,
+		DeadlineConflicts: conflicts,
 	}, nil
 }
 
@@ -162,23 +285,93 @@ func (s *serviceMgmtOrder) DeleteOrder(ctx context.Context, req *request.DeleteO
 		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
 	}
 
-	patchedOrder, err := s.Repository.ReadByID(ctx, req.GetID())
-	if err != nil && err.GetStatusCode() != http.StatusNotFound {
-		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
-	}
-	if err != nil && err.GetStatusCode() == http.StatusNotFound {
-		return &response.DeleteOrderResponse{}, nil
-	}
+	resp := &response.DeleteOrderResponse{}
+	err := s.Repository.WithTx(ctx, func(tx repository.RepositoryOrderAPI) errwrap.Error {
+		o, err := tx.ReadByID(ctx, req.GetID())
+		if err != nil && err.GetStatusCode() == http.StatusNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		orders, err := readSubtree(ctx, tx, o)
+		if err != nil {
+			return err
+		}
+		// NOTE: delegated orders go to trash together with the order, orders delegated further down only with cascade
+		if nested := slices.IndexFunc(orders, func(a *order.Order) bool {
+			return a.GetID() != o.GetID() && a.GetParentOrderID() != o.GetID()
+		}); nested >= 0 && !req.GetCascade() {
+			return errwrap.NewError(http.StatusConflict, "order '%s' has orders delegated below its delegated tasks, delete with cascade=true to delete the whole subtree", o.GetID())
+		}
 
-	err = s.Repository.DeleteOrder(ctx, req.GetID())
+		if req.GetDryRun() {
+			for _, subOrder := range orders {
+				resp.OrderIDs = append(resp.OrderIDs, subOrder.GetID())
+				if !slices.Contains(resp.TaskIDs, subOrder.GetID()) {
+					resp.TaskIDs = append(resp.TaskIDs, subOrder.GetID())
+				}
+				for _, delegated := range subOrder.GetDelegatedTasks() {
+					if !slices.Contains(resp.TaskIDs, delegated.GetID()) {
+						resp.TaskIDs = append(resp.TaskIDs, delegated.GetID())
+					}
+				}
+				for _, sitrep := range subOrder.GetSitReps() {
+					resp.SitRepIDs = append(resp.SitRepIDs, sitrep.GetID())
+				}
+			}
+			return nil
+		}
+
+		now := time.Now().UTC()
+		for _, subOrder := range orders {
+			if _, err := softDeleteOrder(ctx, tx, subOrder, now); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
 	}
 
-	patchedOrder.GetMeta().VersionIncr()
-	patchedOrder.GetMeta().SetUpdated(time.Now().UTC())
+	return resp, nil
+}
+
+// readSubtree reads the order and every order delegated below it, parents before their delegated orders.
+func readSubtree(ctx context.Context, repo repository.RepositoryOrderAPI, o *order.Order) ([]*order.Order, errwrap.Error) {
+	orders := []*order.Order{o}
+	seen := map[meta.ID]bool{o.GetID(): true}
+	for i := 0; i < len(orders); i++ {
+		for _, delegated := range orders[i].GetDelegatedTasks() {
+			if seen[delegated.GetID()] {
+				continue
+			}
+			seen[delegated.GetID()] = true
+
+			delegatedOrder, err := repo.ReadByID(ctx, delegated.GetID())
+			if err != nil && err.GetStatusCode() == http.StatusNotFound {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			orders = append(orders, delegatedOrder)
+		}
+	}
+	return orders, nil
+}
+
+// softDeleteOrder moves the order to trash, it's purged for good once the retention period has passed.
+func softDeleteOrder(ctx context.Context, repo repository.RepositoryOrderAPI, o *order.Order, now time.Time) (*order.Order, errwrap.Error) {
+	m := o.GetMeta().Clone()
+	m.VersionIncr()
+	m.SetUpdated(now)
+	m.SetUpdatedBy(middleware.GetUserFromCtx(ctx))
+	m.SetDeleted(now)
 
-	return &response.DeleteOrderResponse{}, nil
+	return repo.UpdateMeta(ctx, o.GetID(), m)
 }
 
 func (s *serviceMgmtOrder) PutDelegatedTasks(ctx context.Context, req *request.PutDelegatedTasksRequest) (*response.PutDelegatedTasksResponse, errwrap.Error) {
@@ -189,37 +382,57 @@ func (s *serviceMgmtOrder) PutDelegatedTasks(ctx context.Context, req *request.P
 	if err := ValidatePutDelegatedTaskRequest(req); err != nil {
 		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
 	}
-
-	order, err := s.Repository.ReadByID(ctx, req.GetOrderID())
-	if err != nil {
+	if err := s.checkUsers(ctx, order.TaskUserRefs("tasks", req.GetTasks()...)); err != nil {
 		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
 	}
 
-	patchedOrder := order.Clone()
+	var resp *order.Order
+	var conflicts []*order.DeadlineConflict
+	err := s.Repository.WithTx(ctx, func(tx repository.RepositoryOrderAPI) errwrap.Error {
+		order, err := tx.ReadByID(ctx, req.GetOrderID())
+		if err != nil {
+			return err
+		}
 
-	tasks := req.GetTasks()
-	now := time.Now().UTC()
-	for _, task := range tasks {
-		task.SetID(meta.ID(utils.RandAlphanum()))
-		patchedOrder.GetMeta().SetCreated(now)
-		patchedOrder.GetMeta().SetUpdated(now)
-		patchedOrder.GetMeta().VersionIncr()
-		patchedOrder.SetDelegatedTasks(append(patchedOrder.GetDelegatedTasks(), task))
-	}
+		m := order.GetMeta().Clone()
+
+		tasks := req.GetTasks()
+		now := time.Now().UTC()
+		for _, task := range tasks {
+			task.SetID(meta.ID(utils.RandAlphanum()))
+			m.SetCreated(now)
+			m.SetUpdated(now)
+			m.SetUpdatedBy(middleware.GetUserFromCtx(ctx))
+			m.VersionIncr()
+		}
 
-	resp, err := s.Repository.Write(ctx, patchedOrder)
+		if resp, err = tx.AppendDelegatedTasks(ctx, order.GetID(), tasks, m); err != nil {
+			return err
+		}
+
+		var ids []meta.ID
+		for _, task := range tasks {
+			ids = append(ids, task.GetID())
+		}
+		if conflicts, err = deadlineConflicts(ctx, tx, false, ids...); err != nil {
+			return err
+		}
+		conflicts, err = s.checkDeadlines(conflicts, "tasks.deadline")
+		return err
+	})
 	if err != nil {
 		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
 	}
 	return &response.PutDelegatedTasksResponse{
-		Order: resp,
+		Order:             resp,
+		DeadlineConflicts: conflicts,
 	}, nil
 }
 
 func patchTask(reqTask *order.Task, patchedTask *order.Task) bool {
 	hasChanges := false
 
-	if !utils.IsZeroValue(reqTask.GetState()) && reqTask.GetState() != patchedTask.GetState() {
+	if reqTask.State != nil && reqTask.GetState() != patchedTask.GetState() { // NOTE: GetState defaults to 'Not Started', unset state is left as is
 		patchedTask.SetState(reqTask.GetState())
 		hasChanges = true
 	}
@@ -246,44 +459,89 @@ func (s *serviceMgmtOrder) PatchDelegatedTasks(ctx context.Context, req *request
 	if err := ValidatePatchDelegatedTaskRequest(req); err != nil {
 		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
 	}
-
-	ordr, err := s.Repository.ReadByID(ctx, req.GetOrderID())
-	if err != nil {
+	if err := s.checkUsers(ctx, order.TaskUserRefs("tasks", req.GetTasks()...)); err != nil {
 		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
 	}
 
-	patchedOrder := ordr.Clone()
+	var resp *order.Order
+	var conflicts []*order.DeadlineConflict
+	err := s.Repository.WithTx(ctx, func(tx repository.RepositoryOrderAPI) errwrap.Error {
+		ordr, err := tx.ReadByID(ctx, req.GetOrderID())
+		if err != nil {
+			return err
+		}
+		if err := validation.ValidateVersion(req.GetExpectedVersion(), ordr.GetMeta().GetVersion()); err != nil {
+			return err
+		}
+
+		patchedOrder := ordr.Clone()
 
-	patchedOrderDelegatedTasks := make(map[meta.ID]*order.Task)
-	for _, delegated := range patchedOrder.GetDelegatedTasks() {
-		patchedOrderDelegatedTasks[delegated.GetID()] = delegated
-	}
+		patchedOrderDelegatedTasks := make(map[meta.ID]*order.Task)
+		for _, delegated := range patchedOrder.GetDelegatedTasks() {
+			patchedOrderDelegatedTasks[delegated.GetID()] = delegated
+		}
 
-	now := time.Now().UTC()
-	hasChanges := false
-	for _, delegated := range req.GetTasks() {
-		patchedDelegatedTask, ok := patchedOrderDelegatedTasks[delegated.GetID()]
-		if !ok {
-			continue
+		now := time.Now().UTC()
+		var patchedTasks []*order.Task
+		for _, delegated := range req.GetTasks() {
+			patchedDelegatedTask, ok := patchedOrderDelegatedTasks[delegated.GetID()]
+			if !ok {
+				continue
+			}
+			if patchTask(delegated, patchedDelegatedTask) {
+				patchedTasks = append(patchedTasks, patchedDelegatedTask)
+			}
+		}
+		if len(patchedTasks) == 0 { // NOTE: no changes, return existing order
+			resp = ordr
+			return nil
+		}
+		if err := checkDelegatedCompleted(ctx, tx, ordr.GetID(), req.GetTasks(), "tasks"); err != nil {
+			return err
 		}
-		hasChanges = patchTask(delegated, patchedDelegatedTask) || hasChanges
 
-	}
-	if !hasChanges { // NOTE: no changes, return existing order
-		return &response.PatchDelegatedTasksResponse{
-			Order: ordr,
-		}, nil
-	}
+		m := patchedOrder.GetMeta()
+		m.VersionIncr()
+		m.SetUpdated(now)
+		m.SetUpdatedBy(middleware.GetUserFromCtx(ctx))
 
-	patchedOrder.GetMeta().VersionIncr()
-	patchedOrder.GetMeta().SetUpdated(now)
+		// NOTE: states rolled up from the changed delegated tasks, children before their parents
+		var rollupIDs, deadlineIDs []meta.ID
+		for _, task := range patchedTasks {
+			if resp, err = tx.UpdateTask(ctx, ordr.GetID(), task, m); err != nil {
+				return err
+			}
+			i := slices.IndexFunc(ordr.GetDelegatedTasks(), func(a *order.Task) bool { return a.GetID() == task.GetID() })
+			if i >= 0 && ordr.GetDelegatedTasks()[i].GetState() != task.GetState() {
+				rollupIDs = append(rollupIDs, task.GetID())
+			}
+			if i >= 0 && ordr.GetDelegatedTasks()[i].GetDeadline() != task.GetDeadline() {
+				deadlineIDs = append(deadlineIDs, task.GetID())
+			}
+		}
+
+		if conflicts, err = deadlineConflicts(ctx, tx, true, deadlineIDs...); err != nil {
+			return err
+		}
+		if conflicts, err = s.checkDeadlines(conflicts, "tasks.deadline"); err != nil {
+			return err
+		}
 
-	resp, err := s.Repository.Write(ctx, patchedOrder)
+		if len(rollupIDs) == 0 {
+			return nil
+		}
+		if err := rollup(ctx, tx, now, append(rollupIDs, ordr.GetID())...); err != nil {
+			return err
+		}
+		resp, err = tx.ReadByID(ctx, ordr.GetID())
+		return err
+	})
 	if err != nil {
 		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
 	}
 	return &response.PatchDelegatedTasksResponse{
-		Order: resp,
+		Order:             resp,
+		DeadlineConflicts: conflicts,
 	}, nil
 }
 
@@ -296,33 +554,53 @@ func (s *serviceMgmtOrder) DeleteDelegatedTasks(ctx context.Context, req *reques
 		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
 	}
 
-	o, err := s.Repository.ReadByID(ctx, req.GetOrderID())
-	if err != nil {
-		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
-	}
-
-	patchedOrder := o.Clone()
-
-	patchedOrder.DelegatedTasks = slices.DeleteFunc(patchedOrder.DelegatedTasks, func(a *order.Task) bool {
-		return slices.Contains(req.GetDelegatedTaskIDs(), a.GetID())
-	})
+	var resp *order.Order
+	err := s.Repository.WithTx(ctx, func(tx repository.RepositoryOrderAPI) errwrap.Error {
+		o, err := tx.ReadByID(ctx, req.GetOrderID())
+		if err != nil {
+			return err
+		}
 
-	didDelete, err := s.Repository.DeleteTasks(ctx, req.GetDelegatedTaskIDs()) // NOTE: delete tasks in db.tasks
-	if err != nil {
-		// TODO: log warning
-	}
+		now := time.Now().UTC()
+		patchedOrder := o.Clone()
+		var deletedIDs []meta.ID
+		patchedOrder.DelegatedTasks = slices.DeleteFunc(patchedOrder.DelegatedTasks, func(a *order.Task) bool {
+			if !slices.Contains(req.GetDelegatedTaskIDs(), a.GetID()) {
+				return false
+			}
+			deletedIDs = append(deletedIDs, a.GetID())
+			return true
+		})
+		if len(deletedIDs) == 0 { // NOTE: nothing to delete, return existing order
+			resp = o
+			return nil
+		}
 
-	if didDelete {
-		patchedOrder.GetMeta().VersionIncr()
-		patchedOrder.GetMeta().SetUpdated(time.Now().UTC())
-		patchedOrder, err = s.Repository.Write(ctx, patchedOrder) // NOTE: update order obj in db.order
-	}
+		for _, id := range deletedIDs { // NOTE: delegated task is an order on its own, move it to trash
+			delegatedOrder, err := tx.ReadByID(ctx, id)
+			if err != nil && err.GetStatusCode() == http.StatusNotFound {
+				continue
+			}
+			if err == nil {
+				_, err = softDeleteOrder(ctx, tx, delegatedOrder, now)
+			}
+			if err != nil {
+				return err
+			}
+		}
 
+		m := patchedOrder.GetMeta()
+		m.VersionIncr()
+		m.SetUpdated(now)
+		m.SetUpdatedBy(middleware.GetUserFromCtx(ctx))
+		resp, err = tx.RemoveDelegatedTasks(ctx, o.GetID(), deletedIDs, m)
+		return err
+	})
 	if err != nil {
 		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
 	}
 	return &response.DeleteDelegatedTasksResponse{
-		Order: patchedOrder,
+		Order: resp,
 	}, nil
 }
 
@@ -334,24 +612,31 @@ func (s *serviceMgmtOrder) PutSitReps(ctx context.Context, req *request.PutSitRe
 	if err := ValidatePutSitRepRequest(req); err != nil {
 		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
 	}
-
-	order, err := s.Repository.ReadByID(ctx, req.GetOrderID())
-	if err != nil {
+	if err := s.checkUsers(ctx, order.SitRepUserRefs("sitreps", req.GetSitReps()...)); err != nil {
 		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
 	}
 
-	patchedOrder := order.Clone()
+	var resp *order.Order
+	err := s.Repository.WithTx(ctx, func(tx repository.RepositoryOrderAPI) errwrap.Error {
+		order, err := tx.ReadByID(ctx, req.GetOrderID())
+		if err != nil {
+			return err
+		}
 
-	sitreps := req.GetSitReps()
-	now := time.Now().UTC()
-	for _, sitrep := range sitreps {
-		sitrep.SetID(meta.ID(utils.RandAlphanum()))
-		patchedOrder.GetMeta().SetUpdated(now)
-		patchedOrder.GetMeta().VersionIncr()
-		patchedOrder.SetSitReps(append(patchedOrder.GetSitReps(), sitrep))
-	}
+		m := order.GetMeta().Clone()
 
-	resp, err := s.Repository.Write(ctx, patchedOrder)
+		sitreps := req.GetSitReps()
+		now := time.Now().UTC()
+		for _, sitrep := range sitreps {
+			sitrep.SetID(meta.ID(utils.RandAlphanum()))
+			m.SetUpdated(now)
+			m.SetUpdatedBy(middleware.GetUserFromCtx(ctx))
+			m.VersionIncr()
+		}
+
+		resp, err = tx.AppendSitReps(ctx, order.GetID(), sitreps, m)
+		return err
+	})
 	if err != nil {
 		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
 	}
@@ -397,40 +682,56 @@ func (s *serviceMgmtOrder) PatchSitReps(ctx context.Context, req *request.PatchS
 	if err := ValidatePatchSitRepRequest(req); err != nil {
 		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
 	}
-
-	o, err := s.Repository.ReadByID(ctx, req.GetOrderID())
-	if err != nil {
+	if err := s.checkUsers(ctx, order.SitRepUserRefs("sitreps", req.GetSitReps()...)); err != nil {
 		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
 	}
 
-	patchedOrder := o.Clone()
+	var resp *order.Order
+	err := s.Repository.WithTx(ctx, func(tx repository.RepositoryOrderAPI) errwrap.Error {
+		o, err := tx.ReadByID(ctx, req.GetOrderID())
+		if err != nil {
+			return err
+		}
+		if err := validation.ValidateVersion(req.GetExpectedVersion(), o.GetMeta().GetVersion()); err != nil {
+			return err
+		}
 
-	patchedOrderSitReps := make(map[meta.ID]*order.SitRep)
-	for _, sitrep := range patchedOrder.GetSitReps() {
-		patchedOrderSitReps[sitrep.GetID()] = sitrep
-	}
+		patchedOrder := o.Clone()
 
-	now := time.Now().UTC()
-	hasChanges := false
-	for _, sitrep := range req.GetSitReps() {
-		patchedSitRep, ok := patchedOrderSitReps[sitrep.GetID()]
-		if !ok {
-			continue
+		patchedOrderSitReps := make(map[meta.ID]*order.SitRep)
+		for _, sitrep := range patchedOrder.GetSitReps() {
+			patchedOrderSitReps[sitrep.GetID()] = sitrep
 		}
-		hasChanges = patchSitReps(sitrep, patchedSitRep) || hasChanges
 
-	}
+		now := time.Now().UTC()
+		var patchedSitReps []*order.SitRep
+		for _, sitrep := range req.GetSitReps() {
+			patchedSitRep, ok := patchedOrderSitReps[sitrep.GetID()]
+			if !ok {
+				continue
+			}
+			if patchSitReps(sitrep, patchedSitRep) {
+				patchedSitReps = append(patchedSitReps, patchedSitRep)
+			}
+		}
 
-	if !hasChanges { // NOTE: no changes, return current order
-		return &response.PatchSitRepsResponse{
-			Order: o,
-		}, nil
-	}
+		if len(patchedSitReps) == 0 { // NOTE: no changes, return current order
+			resp = o
+			return nil
+		}
 
-	patchedOrder.GetMeta().SetUpdated(now)
-	patchedOrder.GetMeta().VersionIncr()
+		m := patchedOrder.GetMeta()
+		m.SetUpdated(now)
+		m.SetUpdatedBy(middleware.GetUserFromCtx(ctx))
+		m.VersionIncr()
 
-	resp, err := s.Repository.Write(ctx, patchedOrder)
+		for _, sitrep := range patchedSitReps {
+			if resp, err = tx.UpdateSitRep(ctx, o.GetID(), sitrep, m); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
 	}
@@ -448,32 +749,366 @@ func (s *serviceMgmtOrder) DeleteSitReps(ctx context.Context, req *request.Delet
 		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
 	}
 
-	o, err := s.Repository.ReadByID(ctx, req.GetOrderID())
+	var resp *order.Order
+	err := s.Repository.WithTx(ctx, func(tx repository.RepositoryOrderAPI) errwrap.Error {
+		o, err := tx.ReadByID(ctx, req.GetOrderID())
+		if err != nil {
+			return err
+		}
+
+		// NOTE: sitreps stay in the order's version history, they are restored from there and purged together with the order
+		var deletedIDs []meta.ID
+		for _, sitrep := range o.GetSitReps() {
+			if slices.Contains(req.GetSitRepIDs(), sitrep.GetID()) {
+				deletedIDs = append(deletedIDs, sitrep.GetID())
+			}
+		}
+		if len(deletedIDs) == 0 { // NOTE: nothing to delete, return existing order
+			resp = o
+			return nil
+		}
+
+		m := o.GetMeta().Clone()
+		m.VersionIncr()
+		m.SetUpdated(time.Now().UTC())
+		m.SetUpdatedBy(middleware.GetUserFromCtx(ctx))
+		resp, err = tx.RemoveSitReps(ctx, o.GetID(), deletedIDs, m)
+		return err
+	})
+	if err != nil {
+		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
+	}
+	return &response.DeleteSitRepsResponse{
+		Order: resp,
+	}, nil
+}
+
+func (s *serviceMgmtOrder) GetOrderVersions(ctx context.Context, req *request.GetOrderVersionsRequest) (*response.GetOrderVersionsResponse, errwrap.Error) {
+	ctx = middleware.AddTraceToCtx(ctx)
+	middleware.SpanStart(ctx, "GetOrderVersions")
+	defer middleware.SpanStop(ctx, "GetOrderVersions")
+
+	if err := ValidateGetOrderVersionsRequest(req); err != nil {
+		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
+	}
+
+	resp, err := s.Repository.ReadVersions(ctx, req.GetID())
+	if err != nil {
+		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
+	}
+	return &response.GetOrderVersionsResponse{
+		Versions: resp,
+	}, nil
+}
+
+func (s *serviceMgmtOrder) GetOrderVersion(ctx context.Context, req *request.GetOrderVersionRequest) (*response.GetOrderVersionResponse, errwrap.Error) {
+	ctx = middleware.AddTraceToCtx(ctx)
+	middleware.SpanStart(ctx, "GetOrderVersion")
+	defer middleware.SpanStop(ctx, "GetOrderVersion")
+
+	if err := ValidateGetOrderVersionRequest(req); err != nil {
+		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
+	}
+
+	resp, err := s.Repository.ReadVersion(ctx, req.GetID(), req.GetVersion())
 	if err != nil {
 		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
 	}
+	return &response.GetOrderVersionResponse{
+		Order: resp,
+	}, nil
+}
+
+func (s *serviceMgmtOrder) GetOrderDiff(ctx context.Context, req *request.GetOrderDiffRequest) (*response.GetOrderDiffResponse, errwrap.Error) {
+	ctx = middleware.AddTraceToCtx(ctx)
+	middleware.SpanStart(ctx, "GetOrderDiff")
+	defer middleware.SpanStop(ctx, "GetOrderDiff")
 
-	patchedOrder := o.Clone()
+	if err := ValidateGetOrderDiffRequest(req); err != nil {
+		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
+	}
 
-	patchedOrder.SitReps = slices.DeleteFunc(patchedOrder.SitReps, func(a *order.SitRep) bool {
-		return slices.Contains(req.GetSitRepIDs(), a.GetID())
+	versions, err := s.Repository.ReadVersions(ctx, req.GetID())
+	if err != nil {
+		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
+	}
+
+	versions = slices.DeleteFunc(versions, func(o *order.Order) bool {
+		v := o.GetMeta().GetVersion()
+		return v < req.GetFrom() || (req.GetTo() > 0 && v > req.GetTo())
 	})
+	if len(versions) == 0 {
+		err := errwrap.NewError(http.StatusNotFound, "no versions between %v and %v", req.GetFrom(), req.GetTo())
+		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
+	}
+
+	// NOTE: versions are not necessarily consecutive, some changes bump the version more than once
+	diffs := make([]*order.Diff, 0, len(versions)-1)
+	for i := 1; i < len(versions); i++ {
+		diffs = append(diffs, order.NewDiff(versions[i-1], versions[i]))
+	}
+	return &response.GetOrderDiffResponse{
+		Diffs: diffs,
+	}, nil
+}
+
+func (s *serviceMgmtOrder) GetDeletedOrders(ctx context.Context, req *request.GetDeletedOrdersRequest) (*response.GetDeletedOrdersResponse, errwrap.Error) {
+	ctx = middleware.AddTraceToCtx(ctx)
+	middleware.SpanStart(ctx, "GetDeletedOrders")
+	defer middleware.SpanStop(ctx, "GetDeletedOrders")
 
-	didDelete, err := s.Repository.DeleteSitReps(ctx, req.GetSitRepIDs()) // NOTE: delete sitrep in db.sitrep
+	orders, err := s.Repository.ReadDeleted(ctx)
 	if err != nil {
-		// TODO: log warning
+		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
 	}
+	slices.SortFunc(orders, func(a *order.Order, b *order.Order) int { // NOTE: most recently deleted first
+		return b.GetMeta().GetDeleted().Compare(a.GetMeta().GetDeleted())
+	})
+
+	return &response.GetDeletedOrdersResponse{
+		Orders: orders,
+	}, nil
+}
+
+func (s *serviceMgmtOrder) RestoreOrder(ctx context.Context, req *request.RestoreOrderRequest) (*response.RestoreOrderResponse, errwrap.Error) {
+	ctx = middleware.AddTraceToCtx(ctx)
+	middleware.SpanStart(ctx, "RestoreOrder")
+	defer middleware.SpanStop(ctx, "RestoreOrder")
 
-	if didDelete {
-		patchedOrder.GetMeta().VersionIncr()
-		patchedOrder.GetMeta().SetUpdated(time.Now().UTC())
-		patchedOrder, err = s.Repository.Write(ctx, patchedOrder) // NOTE: update order obj in db.order
+	if err := ValidateRestoreOrderRequest(req); err != nil {
+		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
 	}
 
+	var resp *order.Order
+	err := s.Repository.WithTx(ctx, func(tx repository.RepositoryOrderAPI) errwrap.Error {
+		o, err := tx.ReadDeletedByID(ctx, req.GetID())
+		if err != nil {
+			return err
+		}
+		if len(o.GetParentOrderID()) > 0 { // NOTE: order restored under a trashed parent would be purged together with it
+			_, err := tx.ReadDeletedByID(ctx, o.GetParentOrderID())
+			if err == nil {
+				return errwrap.NewError(http.StatusConflict, "parent order '%s' is in trash, restore it first", o.GetParentOrderID())
+			}
+			if err.GetStatusCode() != http.StatusNotFound {
+				return err
+			}
+		}
+		deleted, err := tx.ReadDeleted(ctx)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		if _, err := restoreOrder(ctx, tx, o, now); err != nil {
+			return err
+		}
+		restored := []meta.ID{o.GetID()}
+		for i := 0; i < len(restored); i++ {
+			for _, delegatedOrder := range deleted { // NOTE: delegated orders that went to trash together with the order, down the whole subtree
+				if delegatedOrder.GetParentOrderID() != restored[i] || slices.Contains(restored, delegatedOrder.GetID()) ||
+					!delegatedOrder.GetMeta().GetDeleted().Equal(o.GetMeta().GetDeleted()) {
+					continue
+				}
+				if _, err := restoreOrder(ctx, tx, delegatedOrder, now); err != nil {
+					return err
+				}
+				restored = append(restored, delegatedOrder.GetID())
+			}
+		}
+
+		resp, err = tx.ReadByID(ctx, o.GetID())
+		return err
+	})
 	if err != nil {
 		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
 	}
-	return &response.DeleteSitRepsResponse{
-		Order: patchedOrder,
+	return &response.RestoreOrderResponse{
+		Order: resp,
+	}, nil
+}
+
+// restoreOrder takes the order out of trash and links it back to its parent as a delegated task.
+func restoreOrder(ctx context.Context, repo repository.RepositoryOrderAPI, o *order.Order, now time.Time) (*order.Order, errwrap.Error) {
+	m := o.GetMeta().Clone()
+	m.VersionIncr()
+	m.SetUpdated(now)
+	m.SetUpdatedBy(middleware.GetUserFromCtx(ctx))
+	m.SetDeleted(time.Time{})
+
+	if _, err := repo.UpdateMeta(ctx, o.GetID(), m); err != nil {
+		return nil, err
+	}
+	return repo.Reparent(ctx, o.GetID(), o.GetParentOrderID(), m)
+}
+
+func (s *serviceMgmtOrder) RestoreSitReps(ctx context.Context, req *request.RestoreSitRepsRequest) (*response.RestoreSitRepsResponse, errwrap.Error) {
+	ctx = middleware.AddTraceToCtx(ctx)
+	middleware.SpanStart(ctx, "RestoreSitReps")
+	defer middleware.SpanStop(ctx, "RestoreSitReps")
+
+	if err := ValidateRestoreSitRepsRequest(req); err != nil {
+		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
+	}
+
+	var resp *order.Order
+	err := s.Repository.WithTx(ctx, func(tx repository.RepositoryOrderAPI) errwrap.Error {
+		o, err := tx.ReadByID(ctx, req.GetOrderID())
+		if err != nil {
+			return err
+		}
+		versions, err := tx.ReadVersions(ctx, req.GetOrderID())
+		if err != nil {
+			return err
+		}
+
+		var restoredSitReps []*order.SitRep
+		for _, id := range req.GetSitRepIDs() {
+			if slices.ContainsFunc(o.GetSitReps(), func(a *order.SitRep) bool { return a.GetID() == id }) ||
+				slices.ContainsFunc(restoredSitReps, func(a *order.SitRep) bool { return a.GetID() == id }) {
+				continue
+			}
+			for i := len(versions) - 1; i >= 0; i-- { // NOTE: restore the sitrep as it was last seen
+				idx := slices.IndexFunc(versions[i].GetSitReps(), func(a *order.SitRep) bool { return a.GetID() == id })
+				if idx < 0 {
+					continue
+				}
+				restoredSitReps = append(restoredSitReps, versions[i].GetSitReps()[idx])
+				break
+			}
+		}
+		if len(restoredSitReps) == 0 { // NOTE: nothing to restore, return existing order
+			resp = o
+			return nil
+		}
+
+		m := o.GetMeta().Clone()
+		m.VersionIncr()
+		m.SetUpdated(time.Now().UTC())
+		m.SetUpdatedBy(middleware.GetUserFromCtx(ctx))
+
+		resp, err = tx.AppendSitReps(ctx, o.GetID(), restoredSitReps, m)
+		return err
+	})
+	if err != nil {
+		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
+	}
+	return &response.RestoreSitRepsResponse{
+		Order: resp,
+	}, nil
+}
+
+func (s *serviceMgmtOrder) PurgeOrders(ctx context.Context, req *request.PurgeOrdersRequest) (*response.PurgeOrdersResponse, errwrap.Error) {
+	ctx = middleware.AddTraceToCtx(ctx)
+	middleware.SpanStart(ctx, "PurgeOrders")
+	defer middleware.SpanStop(ctx, "PurgeOrders")
+
+	if err := ValidatePurgeOrdersRequest(req); err != nil {
+		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
+	}
+
+	orders, err := s.Repository.ReadDeleted(ctx)
+	if err != nil {
+		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
+	}
+
+	var ids []meta.ID
+	for _, o := range orders {
+		if !o.GetMeta().GetDeleted().Before(req.GetBefore()) {
+			continue
+		}
+
+		err := s.Repository.WithTx(ctx, func(tx repository.RepositoryOrderAPI) errwrap.Error { // NOTE: each order is purged as a whole or not at all
+			var sitrepIDs []meta.ID // NOTE: deleted sitreps are only referenced from the version history
+			versions, err := tx.ReadVersions(ctx, o.GetID())
+			if err != nil && err.GetStatusCode() != http.StatusNotFound {
+				return err
+			}
+			for _, version := range versions {
+				for _, sitrep := range version.GetSitReps() {
+					if !slices.Contains(sitrepIDs, sitrep.GetID()) {
+						sitrepIDs = append(sitrepIDs, sitrep.GetID())
+					}
+				}
+			}
+			if _, err := tx.DeleteSitReps(ctx, sitrepIDs); err != nil {
+				return err
+			}
+			return tx.DeleteOrder(ctx, o.GetID())
+		})
+		if err != nil {
+			return nil, middleware.AddTraceToErrFromCtx(err, ctx)
+		}
+		ids = append(ids, o.GetID())
+	}
+
+	return &response.PurgeOrdersResponse{
+		IDs: ids,
+	}, nil
+}
+
+// ChangeUserEmail points the tasks and sitreps of a user at their new email, orders in trash included.
+func (s *serviceMgmtOrder) ChangeUserEmail(ctx context.Context, req *request.ChangeUserEmailRequest) (*response.ChangeUserEmailResponse, errwrap.Error) {
+	ctx = middleware.AddTraceToCtx(ctx)
+	middleware.SpanStart(ctx, "ChangeUserEmail")
+	defer middleware.SpanStop(ctx, "ChangeUserEmail")
+
+	if err := ValidateChangeUserEmailRequest(req); err != nil {
+		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
+	}
+
+	var ids []meta.ID
+	err := s.Repository.WithTx(ctx, func(tx repository.RepositoryOrderAPI) errwrap.Error {
+		orders, _, err := tx.ReadBy(ctx, &request.GetOrdersRequest{})
+		if err != nil {
+			return err
+		}
+		deleted, err := tx.ReadDeleted(ctx)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		for _, o := range append(orders, deleted...) {
+			var task *order.Task
+			if o.GetTask().GetAccountable() == req.GetFrom() {
+				task = o.GetTask().Clone()
+				task.SetAccountable(req.GetTo())
+			}
+			var sitreps []*order.SitRep
+			for _, sitrep := range o.GetSitReps() {
+				if sitrep.GetBy() == req.GetFrom() {
+					sitrep = sitrep.Clone()
+					sitrep.SetBy(req.GetTo())
+					sitreps = append(sitreps, sitrep)
+				}
+			}
+			if task == nil && len(sitreps) == 0 {
+				continue
+			}
+
+			m := o.GetMeta().Clone()
+			m.VersionIncr()
+			m.SetUpdated(now)
+			m.SetUpdatedBy(middleware.GetUserFromCtx(ctx))
+			if task != nil { // NOTE: delegated tasks are orders on their own, so they're changed as well
+				if _, err := tx.UpdateTask(ctx, o.GetID(), task, m); err != nil {
+					return err
+				}
+			}
+			for _, sitrep := range sitreps {
+				if _, err := tx.UpdateSitRep(ctx, o.GetID(), sitrep, m); err != nil {
+					return err
+				}
+			}
+			ids = append(ids, o.GetID())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
+	}
+	return &response.ChangeUserEmailResponse{
+		OrderIDs: ids,
 	}, nil
 }
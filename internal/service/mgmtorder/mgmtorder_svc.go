@@ -23,6 +23,7 @@ type ServiceMgmtOrderAPI interface {
 	GetOrders(ctx context.Context, req *request.GetOrdersRequest) (*response.GetOrdersResponse, errwrap.Error)
 	PatchOrder(ctx context.Context, req *request.PatchOrderRequest) (*response.PatchOrderResponse, errwrap.Error)
 	DeleteOrder(ctx context.Context, req *request.DeleteOrderRequest) (*response.DeleteOrderResponse, errwrap.Error)
+	MoveOrder(ctx context.Context, req *request.MoveOrderRequest) (*response.MoveOrderResponse, errwrap.Error)
 	////
 	PutDelegatedTasks(ctx context.Context, req *request.PutDelegatedTasksRequest) (*response.PutDelegatedTasksResponse, errwrap.Error)
 	PatchDelegatedTasks(ctx context.Context, req *request.PatchDelegatedTasksRequest) (*response.PatchDelegatedTasksResponse, errwrap.Error)
@@ -31,10 +32,28 @@ type ServiceMgmtOrderAPI interface {
 	PutSitReps(ctx context.Context, req *request.PutSitRepsRequest) (*response.PutSitRepsResponse, errwrap.Error)
 	PatchSitReps(ctx context.Context, req *request.PatchSitRepsRequest) (*response.PatchSitRepsResponse, errwrap.Error)
 	DeleteSitReps(ctx context.Context, req *request.DeleteSitRepsRequest) (*response.DeleteSitRepsResponse, errwrap.Error)
+	////
+	GetOrderVersions(ctx context.Context, req *request.GetOrderVersionsRequest) (*response.GetOrderVersionsResponse, errwrap.Error)
+	GetOrderVersion(ctx context.Context, req *request.GetOrderVersionRequest) (*response.GetOrderVersionResponse, errwrap.Error)
+	GetOrderDiff(ctx context.Context, req *request.GetOrderDiffRequest) (*response.GetOrderDiffResponse, errwrap.Error)
+	GetOrderTree(ctx context.Context, req *request.GetOrderTreeRequest) (*response.GetOrderTreeResponse, errwrap.Error)
+	GetOrderDocument(ctx context.Context, req *request.GetOrderDocumentRequest) (*response.GetOrderDocumentResponse, errwrap.Error)
+	GetOverdueReport(ctx context.Context, req *request.GetOverdueReportRequest) (*response.GetOverdueReportResponse, errwrap.Error)
+	GetCalendar(ctx context.Context, req *request.GetCalendarRequest) (*response.GetCalendarResponse, errwrap.Error)
+	Search(ctx context.Context, req *request.SearchRequest) (*response.SearchResponse, errwrap.Error)
+	////
+	GetDeletedOrders(ctx context.Context, req *request.GetDeletedOrdersRequest) (*response.GetDeletedOrdersResponse, errwrap.Error)
+	RestoreOrder(ctx context.Context, req *request.RestoreOrderRequest) (*response.RestoreOrderResponse, errwrap.Error)
+	RestoreSitReps(ctx context.Context, req *request.RestoreSitRepsRequest) (*response.RestoreSitRepsResponse, errwrap.Error)
+	PurgeOrders(ctx context.Context, req *request.PurgeOrdersRequest) (*response.PurgeOrdersResponse, errwrap.Error)
+	////
+	ChangeUserEmail(ctx context.Context, req *request.ChangeUserEmailRequest) (*response.ChangeUserEmailResponse, errwrap.Error)
 }
 
 type serviceMgmtOrder struct {
 	Repository repository.RepositoryOrderAPI
+	Users      UserLookup
+	Config     Config
 	RootOrder  *order.Order
 }
 
@@ -43,6 +62,10 @@ var (
 	svc ServiceMgmtOrderAPI = nil
 )
 
+const (
+	rootEmail user.Email = "root@root.com"
+)
+
 func postRootOrder(ctx context.Context, repo repository.RepositoryOrderAPI) (*order.Order, errwrap.Error) {
 	now := time.Now().UTC()
 	id := meta.NewID()
@@ -50,7 +73,7 @@ func postRootOrder(ctx context.Context, repo repository.RepositoryOrderAPI) (*or
 		Task: &order.Task{
 			ID:          id,
 			State:       utils.Ptr(order.InProgress),
-			Accountable: user.Email("root@root.com"),
+			Accountable: rootEmail,
 			Objective:   "Root Order",
 			Deadline:    time.Now().UTC().Add(100 * 365 * 24 * time.Hour),
 		},
@@ -62,25 +85,56 @@ func postRootOrder(ctx context.Context, repo repository.RepositoryOrderAPI) (*or
 		},
 	}
 
-	o, err := repo.Write(ctx, order)
+	o, err := repo.CreateOrder(ctx, order)
 	if err != nil {
 		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
 	}
 	return o, nil
 }
 
+// getRootOrder returns the root order already stored in a persistent repository, nil if there is none.
+func getRootOrder(ctx context.Context, repo repository.RepositoryOrderAPI) (*order.Order, errwrap.Error) {
+	orders, _, err := repo.ReadBy(ctx, &request.GetOrdersRequest{
+		Accountable: rootEmail,
+	})
+	if err != nil {
+		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
+	}
+	for _, o := range orders {
+		if o.GetID() == o.GetParentOrderID() {
+			return o, nil
+		}
+	}
+	return nil, nil
+}
+
 func GetServiceMgmtOrder() ServiceMgmtOrderAPI {
 	return svc
 }
 
-func NewServiceMgmtOrder(repo repository.RepositoryOrderAPI) ServiceMgmtOrderAPI {
-	rootOrder, err := postRootOrder(context.Background(), repo)
+// NOTE: with nil cfg the DefaultConfig is used
+func NewServiceMgmtOrder(repo repository.RepositoryOrderAPI, users UserLookup, cfg *Config) ServiceMgmtOrderAPI {
+	if cfg == nil {
+		cfg = &DefaultConfig
+	}
+	if err := cfg.validate(); err != nil {
+		panic(err)
+	}
+	rootOrder, err := getRootOrder(context.Background(), repo)
 	if err != nil {
 		panic(err)
 	}
+	if rootOrder == nil {
+		rootOrder, err = postRootOrder(context.Background(), repo)
+		if err != nil {
+			panic(err)
+		}
+	}
 	svc = &serviceMgmtOrder{
 		RootOrder:  rootOrder,
 		Repository: repo,
+		Users:      users,
+		Config:     *cfg,
 	}
 	return svc
 }
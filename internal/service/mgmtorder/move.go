@@ -0,0 +1,133 @@
+package mgmtorder
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/moledoc/orderly/internal/domain/errwrap"
+	"github.com/moledoc/orderly/internal/domain/meta"
+	"github.com/moledoc/orderly/internal/domain/order"
+	"github.com/moledoc/orderly/internal/domain/request"
+	"github.com/moledoc/orderly/internal/domain/response"
+	"github.com/moledoc/orderly/internal/middleware"
+	"github.com/moledoc/orderly/internal/repository"
+	"github.com/moledoc/orderly/internal/service/common/validation"
+)
+
+// checkMoveTarget makes sure the order can be delegated below the parent: the parent exists and isn't the order itself or delegated below it.
+func checkMoveTarget(ctx context.Context, repo repository.RepositoryOrderAPI, o *order.Order, parentID meta.ID, field string) errwrap.Error {
+	invalid := func(format string, args ...any) errwrap.Error {
+		return errwrap.NewFieldError(http.StatusUnprocessableEntity, errwrap.FieldError{
+			Field:   field,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+	if o.GetID() == o.GetParentOrderID() {
+		return invalid("root order '%s' can't be moved", o.GetID())
+	}
+
+	// NOTE: walk up from the new parent, the move makes a cycle when the order is found on the way
+	seen := make(map[meta.ID]bool)
+	for id := parentID; !seen[id]; {
+		seen[id] = true
+		if id == o.GetID() {
+			return invalid("order '%s' is delegated below order '%s'", parentID, o.GetID())
+		}
+		ancestor, err := repo.ReadByID(ctx, id)
+		if err != nil && err.GetStatusCode() == http.StatusNotFound && id == parentID {
+			return invalid("order '%s' not found", parentID)
+		}
+		if err != nil && err.GetStatusCode() == http.StatusNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		id = ancestor.GetParentOrderID()
+	}
+	return nil
+}
+
+// moveOrder delegates the order to the new parent, the order keeps its own delegated orders.
+// NOTE: both parents get a new version too, since their delegated tasks change
+func moveOrder(ctx context.Context, repo repository.RepositoryOrderAPI, o *order.Order, parentID meta.ID, m *meta.Meta, now time.Time) (*order.Order, errwrap.Error) {
+	resp, err := repo.Reparent(ctx, o.GetID(), parentID, m)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range []meta.ID{o.GetParentOrderID(), parentID} {
+		parent, err := repo.ReadByID(ctx, id)
+		if err != nil && err.GetStatusCode() == http.StatusNotFound { // NOTE: old parent can be missing
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		pm := parent.GetMeta().Clone()
+		pm.VersionIncr()
+		pm.SetUpdated(now)
+		pm.SetUpdatedBy(middleware.GetUserFromCtx(ctx))
+		if _, err := repo.UpdateMeta(ctx, id, pm); err != nil {
+			return nil, err
+		}
+	}
+	// NOTE: both parents' delegated tasks changed, so do their rolled up states
+	if err := rollup(ctx, repo, now, o.GetParentOrderID(), parentID); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *serviceMgmtOrder) MoveOrder(ctx context.Context, req *request.MoveOrderRequest) (*response.MoveOrderResponse, errwrap.Error) {
+	ctx = middleware.AddTraceToCtx(ctx)
+	middleware.SpanStart(ctx, "MoveOrder")
+	defer middleware.SpanStop(ctx, "MoveOrder")
+
+	if err := ValidateMoveOrderRequest(req); err != nil {
+		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
+	}
+
+	var resp *order.Order
+	var conflicts []*order.DeadlineConflict
+	err := s.Repository.WithTx(ctx, func(tx repository.RepositoryOrderAPI) errwrap.Error {
+		o, err := tx.ReadByID(ctx, req.GetID())
+		if err != nil {
+			return err
+		}
+		if err := validation.ValidateVersion(req.GetExpectedVersion(), o.GetMeta().GetVersion()); err != nil {
+			return err
+		}
+		if o.GetParentOrderID() == req.GetParentOrderID() { // NOTE: no changes, return current
+			resp = o
+			return nil
+		}
+		if err := checkMoveTarget(ctx, tx, o, req.GetParentOrderID(), "parent_order_id"); err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		m := o.GetMeta().Clone()
+		m.VersionIncr()
+		m.SetUpdated(now)
+		m.SetUpdatedBy(middleware.GetUserFromCtx(ctx))
+
+		if resp, err = moveOrder(ctx, tx, o, req.GetParentOrderID(), m, now); err != nil {
+			return err
+		}
+		if conflicts, err = deadlineConflicts(ctx, tx, false, o.GetID()); err != nil {
+			return err
+		}
+		conflicts, err = s.checkDeadlines(conflicts, "parent_order_id")
+		return err
+	})
+	if err != nil {
+		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
+	}
+	return &response.MoveOrderResponse{
+		Order:             resp,
+		DeadlineConflicts: conflicts,
+	}, nil
+}
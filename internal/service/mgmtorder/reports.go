@@ -0,0 +1,153 @@
+package mgmtorder
+
+import (
+	"cmp"
+	"context"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/moledoc/orderly/internal/domain/errwrap"
+	"github.com/moledoc/orderly/internal/domain/order"
+	"github.com/moledoc/orderly/internal/domain/request"
+	"github.com/moledoc/orderly/internal/domain/response"
+	"github.com/moledoc/orderly/internal/domain/user"
+	"github.com/moledoc/orderly/internal/middleware"
+	"github.com/moledoc/orderly/internal/repository"
+)
+
+const (
+	defaultAtRiskDays = 7
+)
+
+// buildOverdueReport groups the overdue and at risk tasks by accountable user.
+// NOTE: groups are sorted by accountable, tasks by deadline
+func buildOverdueReport(tasks []*order.Task, now time.Time, atRiskWithin time.Duration) []*order.ReportGroup {
+	byAccountable := make(map[user.Email]*order.ReportGroup)
+	for _, task := range tasks {
+		overdue := task.IsOverdue(now)
+		atRisk := task.IsAtRisk(now, atRiskWithin)
+		if !overdue && !atRisk {
+			continue
+		}
+
+		group, ok := byAccountable[task.GetAccountable()]
+		if !ok {
+			group = &order.ReportGroup{Accountable: task.GetAccountable()}
+			byAccountable[task.GetAccountable()] = group
+		}
+		if overdue {
+			group.Overdue = append(group.Overdue, task)
+		} else {
+			group.AtRisk = append(group.AtRisk, task)
+		}
+	}
+
+	byDeadline := func(a, b *order.Task) int {
+		return cmp.Or(a.GetDeadline().Compare(b.GetDeadline()), cmp.Compare(a.GetID(), b.GetID()))
+	}
+	groups := make([]*order.ReportGroup, 0, len(byAccountable))
+	for _, group := range byAccountable {
+		slices.SortFunc(group.Overdue, byDeadline)
+		slices.SortFunc(group.AtRisk, byDeadline)
+		groups = append(groups, group)
+	}
+	slices.SortFunc(groups, func(a, b *order.ReportGroup) int {
+		return cmp.Compare(a.GetAccountable(), b.GetAccountable())
+	})
+	return groups
+}
+
+// subordinates returns the users in the supervisor's reporting tree, directly or through other subordinates.
+// NOTE: unknown supervisor has no subordinates
+func (s *serviceMgmtOrder) subordinates(ctx context.Context, supervisor user.Email) (map[user.Email]bool, errwrap.Error) {
+	if s.Users == nil {
+		return nil, errwrap.NewError(http.StatusNotImplemented, "supervisor filter needs the user service")
+	}
+	resp, err := s.Users.GetUsers(ctx, &request.GetUsersRequest{
+		Emails: []user.Email{supervisor},
+	})
+	if err != nil {
+		return nil, err
+	}
+	emails := make(map[user.Email]bool)
+	if len(resp.GetUsers()) == 0 {
+		return emails, nil
+	}
+
+	tree, err := s.Users.GetUserSubordinates(ctx, &request.GetUserSubordinatesRequest{
+		ID: resp.GetUsers()[0].GetID(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	var collect func(subordinates []*user.Subordinate)
+	collect = func(subordinates []*user.Subordinate) {
+		for _, sub := range subordinates {
+			emails[sub.GetUser().GetEmail()] = true
+			collect(sub.GetSubordinates())
+		}
+	}
+	collect(tree.GetSubordinates())
+	return emails, nil
+}
+
+func (s *serviceMgmtOrder) GetOverdueReport(ctx context.Context, req *request.GetOverdueReportRequest) (*response.GetOverdueReportResponse, errwrap.Error) {
+	ctx = middleware.AddTraceToCtx(ctx)
+	middleware.SpanStart(ctx, "GetOverdueReport")
+	defer middleware.SpanStop(ctx, "GetOverdueReport")
+
+	if err := ValidateGetOverdueReportRequest(req); err != nil {
+		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
+	}
+
+	var subordinates map[user.Email]bool
+	if len(req.GetSupervisor()) > 0 {
+		var err errwrap.Error
+		subordinates, err = s.subordinates(ctx, req.GetSupervisor())
+		if err != nil {
+			return nil, middleware.AddTraceToErrFromCtx(err, ctx)
+		}
+	}
+
+	var tasks []*order.Task
+	err := s.Repository.WithTx(ctx, func(tx repository.RepositoryOrderAPI) errwrap.Error {
+		var orders []*order.Order
+		var err errwrap.Error
+		if len(req.GetRootOrderID()) > 0 {
+			var root *order.Order
+			root, err = tx.ReadByID(ctx, req.GetRootOrderID())
+			if err != nil {
+				return err
+			}
+			orders, err = readSubtree(ctx, tx, root)
+		} else {
+			orders, _, err = tx.ReadBy(ctx, &request.GetOrdersRequest{})
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, o := range orders {
+			if o.GetID() == s.RootOrder.GetID() { // NOTE: root order stands for the whole organisation, it's never late
+				continue
+			}
+			if subordinates != nil && !subordinates[o.GetTask().GetAccountable()] {
+				continue
+			}
+			tasks = append(tasks, o.GetTask())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
+	}
+
+	days := req.GetDays()
+	if days == 0 {
+		days = defaultAtRiskDays
+	}
+	return &response.GetOverdueReportResponse{
+		Groups: buildOverdueReport(tasks, time.Now().UTC(), time.Duration(days)*24*time.Hour),
+	}, nil
+}
@@ -0,0 +1,132 @@
+package mgmtorder
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/moledoc/orderly/internal/domain/errwrap"
+	"github.com/moledoc/orderly/internal/domain/meta"
+	"github.com/moledoc/orderly/internal/domain/order"
+	"github.com/moledoc/orderly/internal/middleware"
+	"github.com/moledoc/orderly/internal/repository"
+)
+
+// rollupState returns the state the order is in under its rollup policy, given the states of its delegated tasks,
+// and the state to restore once the order is no longer 'Having Issues' because of a blocked delegated task.
+// NOTE: the state to restore is dropped when the order's state was changed since the rollup
+func rollupState(o *order.Order) (order.State, order.State) {
+	blocked, completed := false, true
+	for _, delegated := range o.GetDelegatedTasks() {
+		blocked = blocked || delegated.GetState() == order.Blocked
+		completed = completed && delegated.GetState() == order.Completed
+	}
+
+	state := o.GetTask().GetState()
+	preRollup := o.GetRollupPolicy().GetPreRollupState()
+	if !o.GetRollupPolicy().GetIssuesOnBlocked() || state != order.HavingIssues {
+		preRollup = 0
+	}
+	if o.GetRollupPolicy().GetCompleteDelegated() && state == order.Completed && !completed {
+		state = order.InProgress
+	}
+	if preRollup != 0 && !blocked {
+		state, preRollup = preRollup, 0
+	}
+	if o.GetRollupPolicy().GetIssuesOnBlocked() && blocked && (state == order.NotStarted || state == order.InProgress) {
+		state, preRollup = order.HavingIssues, state
+	}
+	return state, preRollup
+}
+
+// checkCompleted rejects completing the order while its rollup policy expects the delegated tasks to be completed first.
+// NOTE: o holds the order as it is after the change, so delegated tasks completed within the same change count
+func checkCompleted(o *order.Order, field string) errwrap.Error {
+	if !o.GetRollupPolicy().GetCompleteDelegated() || o.GetTask().GetState() != order.Completed {
+		return nil
+	}
+	for _, delegated := range o.GetDelegatedTasks() {
+		if delegated.GetState() != order.Completed {
+			return errwrap.NewFieldError(http.StatusUnprocessableEntity, errwrap.FieldError{
+				Field:   field,
+				Message: fmt.Sprintf("delegated task '%s' of order '%s' isn't completed", delegated.GetID(), o.GetID()),
+			})
+		}
+	}
+	return nil
+}
+
+// checkDelegatedCompleted runs checkCompleted on the orders behind the parent's delegated tasks that are being completed.
+// NOTE: tasks not delegated by the parent are left out, they aren't patched either
+func checkDelegatedCompleted(ctx context.Context, repo repository.RepositoryOrderAPI, parentID meta.ID, tasks []*order.Task, field string) errwrap.Error {
+	for i, task := range tasks {
+		if task.GetState() != order.Completed {
+			continue
+		}
+		delegatedOrder, err := repo.ReadByID(ctx, task.GetID())
+		if err != nil && err.GetStatusCode() == http.StatusNotFound {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if delegatedOrder.GetParentOrderID() != parentID || delegatedOrder.GetTask().GetState() == order.Completed { // NOTE: already completed, nothing changes
+			continue
+		}
+		delegatedOrder.GetTask().SetState(task.GetState())
+		if err := checkCompleted(delegatedOrder, fmt.Sprintf("%s[%v].state", field, i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rollup brings the orders' states in line with their rollup policies, orders are rolled up in the given order.
+// An order whose state changes is a changed delegated task for its parent, so the parent is rolled up after it.
+// NOTE: orders already changed at now keep their version, the rollup is part of the same change
+func rollup(ctx context.Context, repo repository.RepositoryOrderAPI, now time.Time, ids ...meta.ID) errwrap.Error {
+	for len(ids) > 0 {
+		id := ids[0]
+		ids = ids[1:]
+
+		o, err := repo.ReadByID(ctx, id)
+		if err != nil && err.GetStatusCode() == http.StatusNotFound {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		state, preRollup := rollupState(o)
+		restate := state != o.GetTask().GetState()
+		if !restate && preRollup == o.GetRollupPolicy().GetPreRollupState() {
+			continue
+		}
+
+		m := o.GetMeta().Clone()
+		if !m.GetUpdated().Equal(now) {
+			m.VersionIncr()
+		}
+		m.SetUpdated(now)
+		m.SetUpdatedBy(middleware.GetUserFromCtx(ctx))
+		if preRollup != o.GetRollupPolicy().GetPreRollupState() {
+			policy := o.GetRollupPolicy().Clone()
+			policy.SetPreRollupState(preRollup)
+			if _, err := repo.UpdateRollupPolicy(ctx, id, policy, m); err != nil {
+				return err
+			}
+		}
+		if !restate {
+			continue
+		}
+		task := o.GetTask().Clone()
+		task.SetState(state)
+		if _, err := repo.UpdateTask(ctx, id, task, m); err != nil {
+			return err
+		}
+		if o.GetParentOrderID() != id { // NOTE: root order is its own parent
+			ids = append(ids, o.GetParentOrderID())
+		}
+	}
+	return nil
+}
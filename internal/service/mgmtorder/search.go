@@ -0,0 +1,39 @@
+package mgmtorder
+
+import (
+	"context"
+
+	"github.com/moledoc/orderly/internal/domain/errwrap"
+	"github.com/moledoc/orderly/internal/domain/request"
+	"github.com/moledoc/orderly/internal/domain/response"
+	"github.com/moledoc/orderly/internal/middleware"
+)
+
+const (
+	defaultSearchLimit = 20
+)
+
+func (s *serviceMgmtOrder) Search(ctx context.Context, req *request.SearchRequest) (*response.SearchResponse, errwrap.Error) {
+	ctx = middleware.AddTraceToCtx(ctx)
+	middleware.SpanStart(ctx, "Search")
+	defer middleware.SpanStop(ctx, "Search")
+
+	if err := ValidateSearchRequest(req); err != nil {
+		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
+	}
+
+	limit := req.GetLimit()
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+	hits, err := s.Repository.Search(ctx, &request.SearchRequest{
+		Query: req.GetQuery(),
+		Limit: limit,
+	})
+	if err != nil {
+		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
+	}
+	return &response.SearchResponse{
+		Hits: hits,
+	}, nil
+}
@@ -0,0 +1,92 @@
+package mgmtorder
+
+import (
+	"context"
+	"time"
+
+	"github.com/moledoc/orderly/internal/domain/errwrap"
+	"github.com/moledoc/orderly/internal/domain/meta"
+	"github.com/moledoc/orderly/internal/domain/order"
+	"github.com/moledoc/orderly/internal/domain/request"
+	"github.com/moledoc/orderly/internal/domain/response"
+	"github.com/moledoc/orderly/internal/middleware"
+	"github.com/moledoc/orderly/internal/repository"
+)
+
+// buildOrderTree nests the orders delegated below o, depth levels deep, 0 meaning the whole tree.
+// NOTE: rollups count the whole subtree, also the levels cut off by depth.
+// The root order is its own parent, so from there the tree covers the whole organisation
+func buildOrderTree(o *order.Order, children map[meta.ID][]*order.Order, depth uint, now time.Time) *order.Tree {
+	seen := map[meta.ID]bool{o.GetID(): true}
+
+	var build func(o *order.Order, level uint) *order.Tree
+	build = func(o *order.Order, level uint) *order.Tree {
+		tree := &order.Tree{
+			Order:  o,
+			Rollup: &order.Rollup{},
+		}
+		for _, child := range children[o.GetID()] {
+			if seen[child.GetID()] {
+				continue
+			}
+			seen[child.GetID()] = true
+
+			suborder := build(child, level+1)
+			tree.GetRollup().Add(child, now)
+			tree.GetRollup().Merge(suborder.GetRollup())
+			if depth == 0 || level < depth {
+				tree.Suborders = append(tree.Suborders, suborder)
+			}
+		}
+		return tree
+	}
+	return build(o, 0)
+}
+
+// readOrderTree reads the subtree of o and nests it, see buildOrderTree.
+func readOrderTree(ctx context.Context, repo repository.RepositoryOrderAPI, o *order.Order, depth uint, now time.Time) (*order.Tree, errwrap.Error) {
+	subtree, err := readSubtree(ctx, repo, o)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[meta.ID]*order.Order, len(subtree))
+	for _, so := range subtree {
+		byID[so.GetID()] = so
+	}
+	children := make(map[meta.ID][]*order.Order)
+	for _, so := range subtree {
+		for _, delegated := range so.GetDelegatedTasks() {
+			if child, ok := byID[delegated.GetID()]; ok {
+				children[so.GetID()] = append(children[so.GetID()], child)
+			}
+		}
+	}
+	return buildOrderTree(o, children, depth, now), nil
+}
+
+func (s *serviceMgmtOrder) GetOrderTree(ctx context.Context, req *request.GetOrderTreeRequest) (*response.GetOrderTreeResponse, errwrap.Error) {
+	ctx = middleware.AddTraceToCtx(ctx)
+	middleware.SpanStart(ctx, "GetOrderTree")
+	defer middleware.SpanStop(ctx, "GetOrderTree")
+
+	if err := ValidateGetOrderTreeRequest(req); err != nil {
+		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
+	}
+
+	var resp *order.Tree
+	err := s.Repository.WithTx(ctx, func(tx repository.RepositoryOrderAPI) errwrap.Error {
+		o, err := tx.ReadByID(ctx, req.GetID())
+		if err != nil {
+			return err
+		}
+
+		resp, err = readOrderTree(ctx, tx, o, req.GetDepth(), time.Now().UTC())
+		return err
+	})
+	if err != nil {
+		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
+	}
+	return &response.GetOrderTreeResponse{
+		Tree: resp,
+	}, nil
+}
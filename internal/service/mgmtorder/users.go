@@ -0,0 +1,65 @@
+package mgmtorder
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/moledoc/orderly/internal/domain/errwrap"
+	"github.com/moledoc/orderly/internal/domain/order"
+	"github.com/moledoc/orderly/internal/domain/request"
+	"github.com/moledoc/orderly/internal/domain/response"
+	"github.com/moledoc/orderly/internal/domain/user"
+)
+
+// UserLookup finds the users orders refer to, the user service satisfies it.
+// NOTE: with nil lookup the users aren't checked, e.g when orders are served without the user service
+type UserLookup interface {
+	GetUsers(ctx context.Context, req *request.GetUsersRequest) (*response.GetUsersResponse, errwrap.Error)
+	GetUserSubordinates(ctx context.Context, req *request.GetUserSubordinatesRequest) (*response.GetUserSubordinatesResponse, errwrap.Error)
+}
+
+// checkUsers makes sure the referenced users exist, every unknown user is reported on its own field.
+// NOTE: empty references are left for the request validation
+func (s *serviceMgmtOrder) checkUsers(ctx context.Context, refs []*order.UserRef) errwrap.Error {
+	if s.Users == nil {
+		return nil
+	}
+
+	var emails []user.Email
+	for _, ref := range refs {
+		if len(ref.GetEmail()) > 0 && !slices.Contains(emails, ref.GetEmail()) {
+			emails = append(emails, ref.GetEmail())
+		}
+	}
+	if len(emails) == 0 {
+		return nil
+	}
+
+	resp, err := s.Users.GetUsers(ctx, &request.GetUsersRequest{
+		Emails: emails,
+	})
+	if err != nil {
+		return err
+	}
+	known := make(map[user.Email]bool, len(resp.GetUsers()))
+	for _, u := range resp.GetUsers() {
+		known[u.GetEmail()] = true
+	}
+
+	var fields []errwrap.FieldError
+	for _, ref := range refs {
+		if len(ref.GetEmail()) == 0 || known[ref.GetEmail()] {
+			continue
+		}
+		fields = append(fields, errwrap.FieldError{
+			Field:   ref.GetField(),
+			Message: fmt.Sprintf("user '%s' not found", ref.GetEmail()),
+		})
+	}
+	if len(fields) > 0 {
+		return errwrap.NewFieldError(http.StatusUnprocessableEntity, fields...)
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/moledoc/orderly/internal/domain/errwrap"
+	"github.com/moledoc/orderly/internal/domain/meta"
 	"github.com/moledoc/orderly/internal/domain/order"
 	"github.com/moledoc/orderly/internal/domain/request"
 	"github.com/moledoc/orderly/internal/service/common/validation"
@@ -165,7 +166,30 @@ func ValidateGetOrdersRequest(req *request.GetOrdersRequest) errwrap.Error {
 		}
 	}
 
-	return nil
+	for _, state := range req.GetStates() {
+		if state < order.NotStarted || order.Completed < state {
+			return errwrap.NewError(http.StatusBadRequest, "invalid states")
+		}
+	}
+
+	if err := validation.ValidateRange("deadline", req.GetDeadlineFrom(), req.GetDeadlineTo()); err != nil {
+		return err
+	}
+	if err := validation.ValidateRange("created", req.GetCreatedFrom(), req.GetCreatedTo()); err != nil {
+		return err
+	}
+	if err := validation.ValidateRange("updated", req.GetUpdatedFrom(), req.GetUpdatedTo()); err != nil {
+		return err
+	}
+
+	if len(req.GetSitRepBy()) > 0 {
+		err := validation.ValidateEmail(req.GetSitRepBy())
+		if err != nil {
+			return errwrap.NewError(http.StatusBadRequest, "invalid sitrep_by: %s", err.GetStatusMessage())
+		}
+	}
+
+	return validation.ValidatePage(req.GetSort(), req.GetCursor(), meta.SortCreated, meta.SortUpdated, meta.SortDeadline, meta.SortState)
 }
 
 func ValidatePatchOrderRequest(req *request.PatchOrderRequest) errwrap.Error {
@@ -190,6 +214,19 @@ func ValidateDeleteOrderRequest(req *request.DeleteOrderRequest) errwrap.Error {
 	return nil
 }
 
+func ValidateMoveOrderRequest(req *request.MoveOrderRequest) errwrap.Error {
+
+	err := validation.ValidateID(req.GetID())
+	if err != nil {
+		return errwrap.NewError(http.StatusBadRequest, "invalid order_id: %s", err.GetStatusMessage())
+	}
+	err = validation.ValidateID(req.GetParentOrderID())
+	if err != nil {
+		return errwrap.NewError(http.StatusBadRequest, "invalid parent_order_id: %s", err.GetStatusMessage())
+	}
+	return nil
+}
+
 ////////
 
 func ValidatePutDelegatedTaskRequest(req *request.PutDelegatedTasksRequest) errwrap.Error {
@@ -335,3 +372,156 @@ func ValidateDeleteSitRepRequest(req *request.DeleteSitRepsRequest) errwrap.Erro
 
 	return nil
 }
+
+////////
+
+func ValidateGetOrderVersionsRequest(req *request.GetOrderVersionsRequest) errwrap.Error {
+
+	err := validation.ValidateID(req.GetID())
+	if err != nil {
+		return errwrap.NewError(http.StatusBadRequest, "invalid order_id: %s", err.GetStatusMessage())
+	}
+	return nil
+}
+
+func ValidateGetOrderVersionRequest(req *request.GetOrderVersionRequest) errwrap.Error {
+
+	err := validation.ValidateID(req.GetID())
+	if err != nil {
+		return errwrap.NewError(http.StatusBadRequest, "invalid order_id: %s", err.GetStatusMessage())
+	}
+	if req.GetVersion() == 0 {
+		return errwrap.NewError(http.StatusBadRequest, "invalid version")
+	}
+	return nil
+}
+
+func ValidateGetOrderDiffRequest(req *request.GetOrderDiffRequest) errwrap.Error {
+
+	err := validation.ValidateID(req.GetID())
+	if err != nil {
+		return errwrap.NewError(http.StatusBadRequest, "invalid order_id: %s", err.GetStatusMessage())
+	}
+	if req.GetTo() > 0 && req.GetFrom() > req.GetTo() {
+		return errwrap.NewError(http.StatusBadRequest, "invalid version range: from %v is after to %v", req.GetFrom(), req.GetTo())
+	}
+	return nil
+}
+
+func ValidateGetOrderTreeRequest(req *request.GetOrderTreeRequest) errwrap.Error {
+
+	err := validation.ValidateID(req.GetID())
+	if err != nil {
+		return errwrap.NewError(http.StatusBadRequest, "invalid order_id: %s", err.GetStatusMessage())
+	}
+	return nil
+}
+
+func ValidateGetOrderDocumentRequest(req *request.GetOrderDocumentRequest) errwrap.Error {
+
+	err := validation.ValidateID(req.GetID())
+	if err != nil {
+		return errwrap.NewError(http.StatusBadRequest, "invalid order_id: %s", err.GetStatusMessage())
+	}
+	switch req.GetFormat() {
+	case "", order.DocumentMarkdown, order.DocumentHTML:
+	default:
+		return errwrap.NewError(http.StatusBadRequest, "invalid format: expected '%s' or '%s', got '%s'", order.DocumentMarkdown, order.DocumentHTML, req.GetFormat())
+	}
+	if req.GetSitReps() > maxDocumentSitReps {
+		return errwrap.NewError(http.StatusBadRequest, "invalid sitreps: at most %v", maxDocumentSitReps)
+	}
+	return nil
+}
+
+func ValidateGetOverdueReportRequest(req *request.GetOverdueReportRequest) errwrap.Error {
+
+	if len(req.GetRootOrderID()) > 0 {
+		err := validation.ValidateID(req.GetRootOrderID())
+		if err != nil {
+			return errwrap.NewError(http.StatusBadRequest, "invalid root_order_id: %s", err.GetStatusMessage())
+		}
+	}
+
+	if len(req.GetSupervisor()) > 0 {
+		err := validation.ValidateEmail(req.GetSupervisor())
+		if err != nil {
+			return errwrap.NewError(http.StatusBadRequest, "invalid supervisor: %s", err.GetStatusMessage())
+		}
+	}
+
+	return nil
+}
+
+func ValidateGetCalendarRequest(req *request.GetCalendarRequest) errwrap.Error {
+
+	err := validation.ValidateEmail(req.GetAccountable())
+	if err != nil {
+		return errwrap.NewError(http.StatusBadRequest, "invalid accountable: %s", err.GetStatusMessage())
+	}
+	if len(req.GetRootOrderID()) > 0 {
+		err := validation.ValidateID(req.GetRootOrderID())
+		if err != nil {
+			return errwrap.NewError(http.StatusBadRequest, "invalid root_order_id: %s", err.GetStatusMessage())
+		}
+	}
+	return nil
+}
+
+func ValidateSearchRequest(req *request.SearchRequest) errwrap.Error {
+	if len(order.Tokenize(req.GetQuery())) == 0 {
+		return errwrap.NewError(http.StatusBadRequest, "invalid q: no words to search for")
+	}
+	return nil
+}
+
+////////
+
+func ValidateRestoreOrderRequest(req *request.RestoreOrderRequest) errwrap.Error {
+
+	err := validation.ValidateID(req.GetID())
+	if err != nil {
+		return errwrap.NewError(http.StatusBadRequest, "invalid order_id: %s", err.GetStatusMessage())
+	}
+	return nil
+}
+
+func ValidateRestoreSitRepsRequest(req *request.RestoreSitRepsRequest) errwrap.Error {
+
+	err := validation.ValidateID(req.GetOrderID())
+	if err != nil {
+		return errwrap.NewError(http.StatusBadRequest, "invalid order_id: %s", err.GetStatusMessage())
+	}
+
+	if len(req.GetSitRepIDs()) == 0 {
+		return errwrap.NewError(http.StatusBadRequest, "empty sitrep_ids")
+	}
+
+	for i, sitrep := range req.GetSitRepIDs() {
+		err = validation.ValidateID(sitrep)
+		if err != nil {
+			return errwrap.NewError(http.StatusBadRequest, "invalid sitrep_ids.%v: %s", i, err.GetStatusMessage())
+		}
+	}
+
+	return nil
+}
+
+func ValidatePurgeOrdersRequest(req *request.PurgeOrdersRequest) errwrap.Error {
+	if req.GetBefore().IsZero() {
+		return errwrap.NewError(http.StatusBadRequest, "missing before")
+	}
+	return nil
+}
+
+////////
+
+func ValidateChangeUserEmailRequest(req *request.ChangeUserEmailRequest) errwrap.Error {
+	if err := validation.ValidateEmail(req.GetFrom()); err != nil {
+		return errwrap.NewError(http.StatusBadRequest, "invalid from: %s", err.GetStatusMessage())
+	}
+	if err := validation.ValidateEmail(req.GetTo()); err != nil {
+		return errwrap.NewError(http.StatusBadRequest, "invalid to: %s", err.GetStatusMessage())
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package mgmtuser
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/moledoc/orderly/internal/domain/errwrap"
+	"github.com/moledoc/orderly/internal/domain/request"
+	"github.com/moledoc/orderly/internal/domain/response"
+	"github.com/moledoc/orderly/internal/domain/user"
+	"github.com/moledoc/orderly/internal/middleware"
+	"github.com/moledoc/orderly/internal/repository"
+)
+
+// EmailPropagator carries user email changes over to the orders, the order service satisfies it.
+// NOTE: with nil propagator orders aren't changed, e.g when users are served without the order service
+type EmailPropagator interface {
+	ChangeUserEmail(ctx context.Context, req *request.ChangeUserEmailRequest) (*response.ChangeUserEmailResponse, errwrap.Error)
+}
+
+// EmailPropagatorFunc lets a function be used as EmailPropagator, e.g when the order service is created after the user service.
+type EmailPropagatorFunc func(ctx context.Context, req *request.ChangeUserEmailRequest) (*response.ChangeUserEmailResponse, errwrap.Error)
+
+func (f EmailPropagatorFunc) ChangeUserEmail(ctx context.Context, req *request.ChangeUserEmailRequest) (*response.ChangeUserEmailResponse, errwrap.Error) {
+	return f(ctx, req)
+}
+
+type userChange struct {
+	before *user.User
+	after  *user.User
+}
+
+// changeSupervisor points the subordinates of a user at the user's new email, users in trash included.
+func changeSupervisor(ctx context.Context, tx repository.RepositoryUserAPI, u *user.User, from user.Email, now time.Time) ([]userChange, errwrap.Error) {
+	subordinates, _, err := tx.ReadBy(ctx, &request.GetUsersRequest{
+		Supervisor: from,
+	})
+	if err != nil {
+		return nil, err
+	}
+	deleted, err := tx.ReadDeleted(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []userChange
+	for _, subordinate := range append(subordinates, deleted...) {
+		if subordinate.GetID() == u.GetID() || subordinate.GetSupervisor() != from {
+			continue
+		}
+		changed := subordinate.Clone()
+		changed.SetSupervisor(u.GetEmail())
+		changed.GetMeta().VersionIncr()
+		changed.GetMeta().SetUpdated(now)
+		changed.GetMeta().SetUpdatedBy(middleware.GetUserFromCtx(ctx))
+		if changed, err = tx.UpdateUser(ctx, changed); err != nil {
+			return nil, err
+		}
+		changes = append(changes, userChange{before: subordinate, after: changed})
+	}
+	return changes, nil
+}
+
+// undoUserChanges puts the users back as they were before the changes, as new versions.
+// NOTE: used when the orders couldn't follow an email change, since orders and users don't share a transaction unless they share the storage
+func (s *serviceMgmtUser) undoUserChanges(ctx context.Context, changes []userChange) errwrap.Error {
+	return s.Repository.WithTx(ctx, func(tx repository.RepositoryUserAPI) errwrap.Error {
+		now := time.Now().UTC()
+		for _, change := range changes {
+			reverted := change.before.Clone()
+			reverted.Meta = change.after.GetMeta().Clone()
+			reverted.GetMeta().VersionIncr()
+			reverted.GetMeta().SetUpdated(now)
+			reverted.GetMeta().SetUpdatedBy(middleware.GetUserFromCtx(ctx))
+			if _, err := tx.UpdateUser(ctx, reverted); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// propagateEmail carries the email change over to the orders, undoing the user changes if that fails.
+// NOTE: used when the users and orders don't share the storage, see PatchUser
+func (s *serviceMgmtUser) propagateEmail(ctx context.Context, from user.Email, to user.Email, changes []userChange) errwrap.Error {
+	if s.Orders == nil {
+		return nil
+	}
+	_, err := s.Orders.ChangeUserEmail(ctx, &request.ChangeUserEmailRequest{
+		From: from,
+		To:   to,
+	})
+	if err == nil {
+		return nil
+	}
+	if errUndo := s.undoUserChanges(ctx, changes); errUndo != nil {
+		var users []string
+		for _, change := range changes {
+			users = append(users, fmt.Sprintf("'%s' (%s)", change.after.GetEmail(), change.after.GetID()))
+		}
+		return errwrap.NewError(http.StatusInternalServerError, "changing email in orders failed: %s; undoing user changes failed: %s; users left changed: %s",
+			err.GetStatusMessage(), errUndo.GetStatusMessage(), strings.Join(users, ", "))
+	}
+	return err
+}
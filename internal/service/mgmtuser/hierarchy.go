@@ -0,0 +1,118 @@
+package mgmtuser
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/moledoc/orderly/internal/domain/errwrap"
+	"github.com/moledoc/orderly/internal/domain/meta"
+	"github.com/moledoc/orderly/internal/domain/request"
+	"github.com/moledoc/orderly/internal/domain/user"
+	"github.com/moledoc/orderly/internal/repository"
+)
+
+// readSupervisor returns the supervisor of the user, nil if the user supervises themselves or the supervisor doesn't exist.
+func readSupervisor(ctx context.Context, repo repository.RepositoryUserAPI, u *user.User) (*user.User, errwrap.Error) {
+	if u.GetSupervisor() == u.GetEmail() {
+		return nil, nil
+	}
+	users, _, err := repo.ReadBy(ctx, &request.GetUsersRequest{
+		Emails: []user.Email{u.GetSupervisor()},
+	})
+	if err != nil || len(users) == 0 {
+		return nil, err
+	}
+	return users[0], nil
+}
+
+// readChain returns the chain of command of the user, from the direct supervisor up to the user supervising themselves.
+// NOTE: chain stops early at a missing supervisor or a cycle, which can only come from data stored before the hierarchy was validated
+func readChain(ctx context.Context, repo repository.RepositoryUserAPI, u *user.User) ([]*user.User, errwrap.Error) {
+	var chain []*user.User
+	seen := map[meta.ID]bool{u.GetID(): true}
+	for {
+		supervisor, err := readSupervisor(ctx, repo, u)
+		if err != nil {
+			return nil, err
+		}
+		if supervisor == nil || seen[supervisor.GetID()] {
+			return chain, nil
+		}
+		seen[supervisor.GetID()] = true
+		chain = append(chain, supervisor)
+		u = supervisor
+	}
+}
+
+// checkSupervisor makes sure the supervisor of the user exists and doesn't report to the user.
+func checkSupervisor(ctx context.Context, repo repository.RepositoryUserAPI, u *user.User) errwrap.Error {
+	supervisor, err := readSupervisor(ctx, repo, u)
+	if err != nil {
+		return err
+	}
+	if supervisor == nil && u.GetSupervisor() != u.GetEmail() {
+		return errwrap.NewFieldError(http.StatusUnprocessableEntity, errwrap.FieldError{
+			Field:   "user.supervisor",
+			Message: fmt.Sprintf("user '%s' not found", u.GetSupervisor()),
+		})
+	}
+	if supervisor == nil || len(u.GetID()) == 0 {
+		return nil
+	}
+
+	chain, err := readChain(ctx, repo, supervisor)
+	if err != nil {
+		return err
+	}
+	for _, c := range append([]*user.User{supervisor}, chain...) {
+		if c.GetID() == u.GetID() {
+			return errwrap.NewFieldError(http.StatusUnprocessableEntity, errwrap.FieldError{
+				Field:   "user.supervisor",
+				Message: fmt.Sprintf("user '%s' reports to user '%s'", u.GetSupervisor(), u.GetEmail()),
+			})
+		}
+	}
+	return nil
+}
+
+// readSubordinates returns the reporting tree below the user, depth levels deep, 0 meaning the whole tree.
+func readSubordinates(ctx context.Context, repo repository.RepositoryUserAPI, u *user.User, depth uint) ([]*user.Subordinate, errwrap.Error) {
+	seen := map[meta.ID]bool{u.GetID(): true}
+
+	var read func(u *user.User, level uint) ([]*user.Subordinate, errwrap.Error)
+	read = func(u *user.User, level uint) ([]*user.Subordinate, errwrap.Error) {
+		if depth > 0 && level > depth {
+			return nil, nil
+		}
+		users, _, err := repo.ReadBy(ctx, &request.GetUsersRequest{
+			Supervisor: u.GetEmail(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		slices.SortFunc(users, func(a *user.User, b *user.User) int {
+			return strings.Compare(string(a.GetEmail()), string(b.GetEmail()))
+		})
+
+		var subordinates []*user.Subordinate
+		for _, s := range users {
+			if seen[s.GetID()] {
+				continue
+			}
+			seen[s.GetID()] = true
+			below, err := read(s, level+1)
+			if err != nil {
+				return nil, err
+			}
+			subordinates = append(subordinates, &user.Subordinate{
+				User:         s,
+				Subordinates: below,
+			})
+		}
+		return subordinates, nil
+	}
+	return read(u, 1)
+}
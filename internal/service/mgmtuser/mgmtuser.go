@@ -3,6 +3,7 @@ package mgmtuser
 import (
 	"context"
 	"net/http"
+	"slices"
 	"time"
 
 	"github.com/moledoc/orderly/internal/domain/errwrap"
@@ -11,6 +12,8 @@ import (
 	"github.com/moledoc/orderly/internal/domain/response"
 	"github.com/moledoc/orderly/internal/domain/user"
 	"github.com/moledoc/orderly/internal/middleware"
+	"github.com/moledoc/orderly/internal/repository"
+	"github.com/moledoc/orderly/internal/service/common/validation"
 	"github.com/moledoc/orderly/pkg/utils"
 )
 
@@ -28,30 +31,39 @@ func (s *serviceMgmtUser) PostUser(ctx context.Context, req *request.PostUserReq
 		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
 	}
 
-	respGetUsers, _ := s.Repository.ReadBy(ctx, &request.GetUsersRequest{
-		Emails: []user.Email{req.GetUser().GetEmail()},
-	})
-	if len(respGetUsers) > 0 {
-		return nil, errwrap.NewError(http.StatusConflict, "user with email '%s' already exists", req.GetUser().GetEmail())
-	}
+	var resp *user.User
+	err := s.Repository.WithTx(ctx, func(tx repository.RepositoryUserAPI) errwrap.Error {
+		respGetUsers, _, _ := tx.ReadBy(ctx, &request.GetUsersRequest{
+			Emails: []user.Email{req.GetUser().GetEmail()},
+		})
+		if len(respGetUsers) > 0 {
+			return errwrap.NewError(http.StatusConflict, "user with email '%s' already exists", req.GetUser().GetEmail())
+		}
+		if err := checkSupervisor(ctx, tx, req.GetUser()); err != nil {
+			return err
+		}
 
-	u := req.GetUser().Clone()
-	u.ID = meta.ID(utils.RandAlphanum())
+		u := req.GetUser().Clone()
+		u.ID = meta.ID(utils.RandAlphanum())
 
-	now := time.Now().UTC()
-	u.Meta = &meta.Meta{
-		Version: 1,
-		Created: now,
-		Updated: now,
-	}
+		now := time.Now().UTC()
+		u.Meta = &meta.Meta{
+			Version:   1,
+			Created:   now,
+			Updated:   now,
+			UpdatedBy: middleware.GetUserFromCtx(ctx),
+		}
 
-	user, err := s.Repository.Write(ctx, u)
+		var err errwrap.Error
+		resp, err = tx.CreateUser(ctx, u)
+		return err
+	})
 	if err != nil {
 		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
 	}
 
 	return &response.PostUserResponse{
-		User: user,
+		User: resp,
 	}, nil
 }
 
@@ -82,12 +94,13 @@ func (s *serviceMgmtUser) GetUsers(ctx context.Context, req *request.GetUsersReq
 		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
 	}
 
-	resp, err := s.Repository.ReadBy(ctx, req)
+	resp, page, err := s.Repository.ReadBy(ctx, req)
 	if err != nil {
 		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
 	}
 	return &response.GetUsersResponse{
 		Users: resp,
+		Page:  page,
 	}, nil
 }
 
@@ -100,55 +113,319 @@ func (s *serviceMgmtUser) PatchUser(ctx context.Context, req *request.PatchUserR
 		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
 	}
 
-	user, err := s.Repository.ReadByID(ctx, req.GetUser().GetID())
+	var resp *user.User
+	var changes []userChange
+	var from, to user.Email // NOTE: set when the email changes
+	patch := func(ctx context.Context) errwrap.Error {
+		return s.Repository.WithTx(ctx, func(tx repository.RepositoryUserAPI) errwrap.Error {
+			u, err := tx.ReadByID(ctx, req.GetUser().GetID())
+			if err != nil {
+				return err
+			}
+			if err := validation.ValidateVersion(req.GetExpectedVersion(), u.GetMeta().GetVersion()); err != nil {
+				return err
+			}
+
+			patchedUser := u.Clone()
+			reqUser := req.GetUser()
+			hasChanges := false
+
+			if !utils.IsZeroValue(reqUser.GetName()) && reqUser.GetName() != patchedUser.GetName() {
+				patchedUser.SetName(reqUser.GetName())
+				hasChanges = true
+			}
+			if !utils.IsZeroValue(reqUser.GetEmail()) && reqUser.GetEmail() != patchedUser.GetEmail() {
+				respGetUsers, _, _ := tx.ReadBy(ctx, &request.GetUsersRequest{
+					Emails: []user.Email{reqUser.GetEmail()},
+				})
+				if len(respGetUsers) > 0 {
+					return errwrap.NewError(http.StatusConflict, "user with email '%s' already exists", reqUser.GetEmail())
+				}
+				if patchedUser.GetSupervisor() == patchedUser.GetEmail() { // NOTE: user supervising themselves, e.g root
+					patchedUser.SetSupervisor(reqUser.GetEmail())
+				}
+				from, to = patchedUser.GetEmail(), reqUser.GetEmail()
+				patchedUser.SetEmail(reqUser.GetEmail())
+				hasChanges = true
+			}
+			if !utils.IsZeroValue(reqUser.GetSupervisor()) && reqUser.GetSupervisor() != patchedUser.GetSupervisor() {
+				patchedUser.SetSupervisor(reqUser.GetSupervisor())
+				if err := checkSupervisor(ctx, tx, patchedUser); err != nil {
+					return err
+				}
+				hasChanges = true
+			}
+
+			if !hasChanges { // no changes, return current user
+				resp = u
+				return nil
+			}
+
+			now := time.Now().UTC()
+			patchedUser.GetMeta().VersionIncr()
+			patchedUser.GetMeta().SetUpdated(now)
+			patchedUser.GetMeta().SetUpdatedBy(middleware.GetUserFromCtx(ctx))
+
+			resp, err = tx.UpdateUser(ctx, patchedUser)
+			if err != nil || len(from) == 0 {
+				return err
+			}
+			changes = append(changes, userChange{before: u, after: resp})
+
+			subordinateChanges, err := changeSupervisor(ctx, tx, resp, from, now)
+			changes = append(changes, subordinateChanges...)
+			return err
+		})
+	}
+
+	var err errwrap.Error
+	if shared, ok := s.Repository.(repository.RepositoryTxContext); ok && s.Orders != nil { // NOTE: orders on the same storage join the transaction, users and orders change together
+		err = shared.WithTxContext(ctx, func(ctx context.Context) errwrap.Error {
+			if err := patch(ctx); err != nil || len(from) == 0 {
+				return err
+			}
+			_, err := s.Orders.ChangeUserEmail(ctx, &request.ChangeUserEmailRequest{
+				From: from,
+				To:   to,
+			})
+			return err
+		})
+	} else {
+		err = patch(ctx)
+		if err == nil && len(from) > 0 {
+			err = s.propagateEmail(ctx, from, to, changes)
+		}
+	}
 	if err != nil {
 		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
 	}
+	return &response.PatchUserResponse{
+		User: resp,
+	}, nil
+}
 
-	patchedUser := user.Clone()
-	reqUser := req.GetUser()
-	hasChanges := false
+func (s *serviceMgmtUser) DeleteUser(ctx context.Context, req *request.DeleteUserRequest) (*response.DeleteUserResponse, errwrap.Error) {
+	ctx = middleware.AddTraceToCtx(ctx)
+	middleware.SpanStart(ctx, "DeleteUser")
+	defer middleware.SpanStop(ctx, "DeleteUser")
 
-	if !utils.IsZeroValue(reqUser.GetName()) && reqUser.GetName() != patchedUser.GetName() {
-		patchedUser.SetName(reqUser.GetName())
-		hasChanges = true
+	if err := ValidateDeleteUserRequest(req); err != nil {
+		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
 	}
-	if !utils.IsZeroValue(reqUser.GetEmail()) && reqUser.GetEmail() != patchedUser.GetEmail() {
-		patchedUser.SetEmail(reqUser.GetEmail())
-		hasChanges = true
+
+	err := s.Repository.WithTx(ctx, func(tx repository.RepositoryUserAPI) errwrap.Error {
+		u, err := tx.ReadByID(ctx, req.GetID())
+		if err != nil && err.GetStatusCode() != http.StatusNotFound {
+			return err
+		}
+		if err != nil && err.GetStatusCode() == http.StatusNotFound {
+			return nil
+		}
+
+		subordinates, _, err := tx.ReadBy(ctx, &request.GetUsersRequest{
+			Supervisor: u.GetEmail(),
+		})
+		if err != nil {
+			return err
+		}
+		for _, subordinate := range subordinates {
+			if subordinate.GetID() != u.GetID() { // NOTE: deleting would leave the subordinates without a supervisor
+				return errwrap.NewError(http.StatusConflict, "user '%s' has subordinates, assign them a new supervisor first", u.GetEmail())
+			}
+		}
+
+		// NOTE: user is moved to trash, it's purged for good once the retention period has passed
+		now := time.Now().UTC()
+		deletedUser := u.Clone()
+		deletedUser.GetMeta().VersionIncr()
+		deletedUser.GetMeta().SetUpdated(now)
+		deletedUser.GetMeta().SetUpdatedBy(middleware.GetUserFromCtx(ctx))
+		deletedUser.GetMeta().SetDeleted(now)
+
+		_, err = tx.UpdateUser(ctx, deletedUser)
+		return err
+	})
+	if err != nil {
+		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
 	}
-	if !utils.IsZeroValue(reqUser.GetSupervisor()) && reqUser.GetSupervisor() != patchedUser.GetSupervisor() {
-		patchedUser.SetSupervisor(reqUser.GetSupervisor())
-		hasChanges = true
+	return &response.DeleteUserResponse{}, nil
+}
+
+func (s *serviceMgmtUser) GetUserVersions(ctx context.Context, req *request.GetUserVersionsRequest) (*response.GetUserVersionsResponse, errwrap.Error) {
+	ctx = middleware.AddTraceToCtx(ctx)
+	middleware.SpanStart(ctx, "GetUserVersions")
+	defer middleware.SpanStop(ctx, "GetUserVersions")
+
+	if err := ValidateGetUserVersionsRequest(req); err != nil {
+		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
 	}
 
-	if !hasChanges { // no changes, return current user
-		return &response.PatchUserResponse{
-			User: user,
-		}, nil
+	resp, err := s.Repository.ReadVersions(ctx, req.GetID())
+	if err != nil {
+		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
 	}
+	return &response.GetUserVersionsResponse{
+		Versions: resp,
+	}, nil
+}
 
-	now := time.Now().UTC()
-	patchedUser.GetMeta().VersionIncr()
-	patchedUser.GetMeta().SetUpdated(now)
+func (s *serviceMgmtUser) GetUserVersion(ctx context.Context, req *request.GetUserVersionRequest) (*response.GetUserVersionResponse, errwrap.Error) {
+	ctx = middleware.AddTraceToCtx(ctx)
+	middleware.SpanStart(ctx, "GetUserVersion")
+	defer middleware.SpanStop(ctx, "GetUserVersion")
 
-	resp, err := s.Repository.Write(ctx, patchedUser)
+	if err := ValidateGetUserVersionRequest(req); err != nil {
+		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
+	}
+
+	resp, err := s.Repository.ReadVersion(ctx, req.GetID(), req.GetVersion())
 	if err != nil {
 		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
 	}
-	return &response.PatchUserResponse{
+	return &response.GetUserVersionResponse{
 		User: resp,
 	}, nil
 }
 
-func (s *serviceMgmtUser) DeleteUser(ctx context.Context, req *request.DeleteUserRequest) (*response.DeleteUserResponse, errwrap.Error) {
+func (s *serviceMgmtUser) GetUserChain(ctx context.Context, req *request.GetUserChainRequest) (*response.GetUserChainResponse, errwrap.Error) {
 	ctx = middleware.AddTraceToCtx(ctx)
-	middleware.SpanStart(ctx, "DeleteUser")
-	defer middleware.SpanStop(ctx, "DeleteUser")
+	middleware.SpanStart(ctx, "GetUserChain")
+	defer middleware.SpanStop(ctx, "GetUserChain")
 
-	if err := ValidateDeleteUserRequest(req); err != nil {
+	if err := ValidateGetUserChainRequest(req); err != nil {
 		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
 	}
 
-	return &response.DeleteUserResponse{}, s.Repository.Delete(ctx, req.GetID())
+	var resp []*user.User
+	err := s.Repository.WithTx(ctx, func(tx repository.RepositoryUserAPI) errwrap.Error {
+		u, err := tx.ReadByID(ctx, req.GetID())
+		if err != nil {
+			return err
+		}
+		resp, err = readChain(ctx, tx, u)
+		return err
+	})
+	if err != nil {
+		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
+	}
+	return &response.GetUserChainResponse{
+		Users: resp,
+	}, nil
+}
+
+func (s *serviceMgmtUser) GetUserSubordinates(ctx context.Context, req *request.GetUserSubordinatesRequest) (*response.GetUserSubordinatesResponse, errwrap.Error) {
+	ctx = middleware.AddTraceToCtx(ctx)
+	middleware.SpanStart(ctx, "GetUserSubordinates")
+	defer middleware.SpanStop(ctx, "GetUserSubordinates")
+
+	if err := ValidateGetUserSubordinatesRequest(req); err != nil {
+		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
+	}
+
+	var resp []*user.Subordinate
+	err := s.Repository.WithTx(ctx, func(tx repository.RepositoryUserAPI) errwrap.Error {
+		u, err := tx.ReadByID(ctx, req.GetID())
+		if err != nil {
+			return err
+		}
+		resp, err = readSubordinates(ctx, tx, u, req.GetDepth())
+		return err
+	})
+	if err != nil {
+		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
+	}
+	return &response.GetUserSubordinatesResponse{
+		Subordinates: resp,
+	}, nil
+}
+
+func (s *serviceMgmtUser) GetDeletedUsers(ctx context.Context, req *request.GetDeletedUsersRequest) (*response.GetDeletedUsersResponse, errwrap.Error) {
+	ctx = middleware.AddTraceToCtx(ctx)
+	middleware.SpanStart(ctx, "GetDeletedUsers")
+	defer middleware.SpanStop(ctx, "GetDeletedUsers")
+
+	users, err := s.Repository.ReadDeleted(ctx)
+	if err != nil {
+		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
+	}
+	slices.SortFunc(users, func(a *user.User, b *user.User) int { // NOTE: most recently deleted first
+		return b.GetMeta().GetDeleted().Compare(a.GetMeta().GetDeleted())
+	})
+
+	return &response.GetDeletedUsersResponse{
+		Users: users,
+	}, nil
+}
+
+func (s *serviceMgmtUser) RestoreUser(ctx context.Context, req *request.RestoreUserRequest) (*response.RestoreUserResponse, errwrap.Error) {
+	ctx = middleware.AddTraceToCtx(ctx)
+	middleware.SpanStart(ctx, "RestoreUser")
+	defer middleware.SpanStop(ctx, "RestoreUser")
+
+	if err := ValidateRestoreUserRequest(req); err != nil {
+		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
+	}
+
+	var resp *user.User
+	err := s.Repository.WithTx(ctx, func(tx repository.RepositoryUserAPI) errwrap.Error {
+		u, err := tx.ReadDeletedByID(ctx, req.GetID())
+		if err != nil {
+			return err
+		}
+
+		respGetUsers, _, _ := tx.ReadBy(ctx, &request.GetUsersRequest{
+			Emails: []user.Email{u.GetEmail()},
+		})
+		if len(respGetUsers) > 0 { // NOTE: email was taken while the user was in trash
+			return errwrap.NewError(http.StatusConflict, "user with email '%s' already exists", u.GetEmail())
+		}
+		if err := checkSupervisor(ctx, tx, u); err != nil { // NOTE: supervisor could have been deleted while the user was in trash
+			return err
+		}
+
+		restoredUser := u.Clone()
+		restoredUser.GetMeta().VersionIncr()
+		restoredUser.GetMeta().SetUpdated(time.Now().UTC())
+		restoredUser.GetMeta().SetUpdatedBy(middleware.GetUserFromCtx(ctx))
+		restoredUser.GetMeta().SetDeleted(time.Time{})
+
+		resp, err = tx.UpdateUser(ctx, restoredUser)
+		return err
+	})
+	if err != nil {
+		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
+	}
+	return &response.RestoreUserResponse{
+		User: resp,
+	}, nil
+}
+
+func (s *serviceMgmtUser) PurgeUsers(ctx context.Context, req *request.PurgeUsersRequest) (*response.PurgeUsersResponse, errwrap.Error) {
+	ctx = middleware.AddTraceToCtx(ctx)
+	middleware.SpanStart(ctx, "PurgeUsers")
+	defer middleware.SpanStop(ctx, "PurgeUsers")
+
+	if err := ValidatePurgeUsersRequest(req); err != nil {
+		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
+	}
+
+	users, err := s.Repository.ReadDeleted(ctx)
+	if err != nil {
+		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
+	}
+
+	var ids []meta.ID
+	for _, u := range users {
+		if !u.GetMeta().GetDeleted().Before(req.GetBefore()) {
+			continue
+		}
+		if err := s.Repository.Delete(ctx, u.GetID()); err != nil {
+			return nil, middleware.AddTraceToErrFromCtx(err, ctx)
+		}
+		ids = append(ids, u.GetID())
+	}
+
+	return &response.PurgeUsersResponse{
+		IDs: ids,
+	}, nil
 }
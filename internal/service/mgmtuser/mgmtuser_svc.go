@@ -21,11 +21,22 @@ type ServiceMgmtUserAPI interface {
 	GetUsers(ctx context.Context, req *request.GetUsersRequest) (*response.GetUsersResponse, errwrap.Error)
 	PatchUser(ctx context.Context, req *request.PatchUserRequest) (*response.PatchUserResponse, errwrap.Error)
 	DeleteUser(ctx context.Context, req *request.DeleteUserRequest) (*response.DeleteUserResponse, errwrap.Error)
+	////
+	GetUserVersions(ctx context.Context, req *request.GetUserVersionsRequest) (*response.GetUserVersionsResponse, errwrap.Error)
+	GetUserVersion(ctx context.Context, req *request.GetUserVersionRequest) (*response.GetUserVersionResponse, errwrap.Error)
+	////
+	GetUserChain(ctx context.Context, req *request.GetUserChainRequest) (*response.GetUserChainResponse, errwrap.Error)
+	GetUserSubordinates(ctx context.Context, req *request.GetUserSubordinatesRequest) (*response.GetUserSubordinatesResponse, errwrap.Error)
+	////
+	GetDeletedUsers(ctx context.Context, req *request.GetDeletedUsersRequest) (*response.GetDeletedUsersResponse, errwrap.Error)
+	RestoreUser(ctx context.Context, req *request.RestoreUserRequest) (*response.RestoreUserResponse, errwrap.Error)
+	PurgeUsers(ctx context.Context, req *request.PurgeUsersRequest) (*response.PurgeUsersResponse, errwrap.Error)
 }
 
 type serviceMgmtUser struct {
 	RootUser   *user.User
 	Repository repository.RepositoryUserAPI
+	Orders     EmailPropagator
 }
 
 var (
@@ -33,13 +44,17 @@ var (
 	svc ServiceMgmtUserAPI = nil
 )
 
+const (
+	RootEmail user.Email = "root@root.com"
+)
+
 func postRootUser(ctx context.Context, repo repository.RepositoryUserAPI) (*user.User, errwrap.Error) {
 	now := time.Now().UTC()
 	u := &user.User{
 		ID:         meta.NewID(),
 		Name:       "Root",
-		Email:      "root@root.com",
-		Supervisor: "root@root.com",
+		Email:      RootEmail,
+		Supervisor: RootEmail,
 		Meta: &meta.Meta{
 			Version: 1,
 			Created: now,
@@ -47,25 +62,46 @@ func postRootUser(ctx context.Context, repo repository.RepositoryUserAPI) (*user
 		},
 	}
 
-	u, err := repo.Write(ctx, u)
+	u, err := repo.CreateUser(ctx, u)
 	if err != nil {
 		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
 	}
 	return u, nil
 }
 
+// getRootUser returns the root user already stored in a persistent repository, nil if there is none.
+func getRootUser(ctx context.Context, repo repository.RepositoryUserAPI) (*user.User, errwrap.Error) {
+	users, _, err := repo.ReadBy(ctx, &request.GetUsersRequest{
+		Emails: []user.Email{RootEmail},
+	})
+	if err != nil {
+		return nil, middleware.AddTraceToErrFromCtx(err, ctx)
+	}
+	if len(users) == 0 {
+		return nil, nil
+	}
+	return users[0], nil
+}
+
 func GetServiceMgmtUser() ServiceMgmtUserAPI {
 	return svc
 }
 
-func NewServiceMgmtUser(repo repository.RepositoryUserAPI) ServiceMgmtUserAPI {
-	u, err := postRootUser(context.Background(), repo)
+func NewServiceMgmtUser(repo repository.RepositoryUserAPI, orders EmailPropagator) ServiceMgmtUserAPI {
+	u, err := getRootUser(context.Background(), repo)
 	if err != nil {
 		panic(err)
 	}
+	if u == nil {
+		u, err = postRootUser(context.Background(), repo)
+		if err != nil {
+			panic(err)
+		}
+	}
 	svc = &serviceMgmtUser{
 		RootUser:   u,
 		Repository: repo,
+		Orders:     orders,
 	}
 	return svc
 }
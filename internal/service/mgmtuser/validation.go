@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/moledoc/orderly/internal/domain/errwrap"
+	"github.com/moledoc/orderly/internal/domain/meta"
 	"github.com/moledoc/orderly/internal/domain/request"
 	"github.com/moledoc/orderly/internal/domain/user"
 	"github.com/moledoc/orderly/internal/service/common/validation"
@@ -77,7 +78,7 @@ func ValidateGetUsersRequest(req *request.GetUsersRequest) errwrap.Error {
 			return errwrap.NewError(http.StatusBadRequest, "%s", err.GetStatusMessage())
 		}
 	}
-	return nil
+	return validation.ValidatePage(req.GetSort(), req.GetCursor(), meta.SortCreated, meta.SortUpdated)
 }
 
 func ValidatePatchUserRequest(req *request.PatchUserRequest) errwrap.Error {
@@ -105,3 +106,79 @@ func ValidateDeleteUserRequest(req *request.DeleteUserRequest) errwrap.Error {
 
 	return nil
 }
+
+func ValidateGetUserVersionsRequest(req *request.GetUserVersionsRequest) errwrap.Error {
+	if req == nil || len(req.GetID()) == 0 {
+		return errwrap.NewError(http.StatusBadRequest, "empty request")
+	}
+
+	err := validation.ValidateID(req.GetID())
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func ValidateGetUserVersionRequest(req *request.GetUserVersionRequest) errwrap.Error {
+	if req == nil || len(req.GetID()) == 0 {
+		return errwrap.NewError(http.StatusBadRequest, "empty request")
+	}
+
+	err := validation.ValidateID(req.GetID())
+	if err != nil {
+		return err
+	}
+
+	if req.GetVersion() == 0 {
+		return errwrap.NewError(http.StatusBadRequest, "invalid version")
+	}
+
+	return nil
+}
+
+func ValidateGetUserChainRequest(req *request.GetUserChainRequest) errwrap.Error {
+	if req == nil || len(req.GetID()) == 0 {
+		return errwrap.NewError(http.StatusBadRequest, "empty request")
+	}
+
+	err := validation.ValidateID(req.GetID())
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func ValidateGetUserSubordinatesRequest(req *request.GetUserSubordinatesRequest) errwrap.Error {
+	if req == nil || len(req.GetID()) == 0 {
+		return errwrap.NewError(http.StatusBadRequest, "empty request")
+	}
+
+	err := validation.ValidateID(req.GetID())
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func ValidateRestoreUserRequest(req *request.RestoreUserRequest) errwrap.Error {
+	if req == nil || len(req.GetID()) == 0 {
+		return errwrap.NewError(http.StatusBadRequest, "empty request")
+	}
+
+	err := validation.ValidateID(req.GetID())
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func ValidatePurgeUsersRequest(req *request.PurgeUsersRequest) errwrap.Error {
+	if req.GetBefore().IsZero() {
+		return errwrap.NewError(http.StatusBadRequest, "missing before")
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package trash
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/moledoc/orderly/internal/domain/request"
+	"github.com/moledoc/orderly/internal/service/mgmtorder"
+	"github.com/moledoc/orderly/internal/service/mgmtuser"
+)
+
+// Config sets how long deleted orders and users are kept in trash before they're purged for good.
+type Config struct {
+	Retention time.Duration
+	Interval  time.Duration
+}
+
+func RegisterFlags(fs *flag.FlagSet) *Config {
+	cfg := &Config{}
+	fs.DurationVar(&cfg.Retention, "trash-retention", 30*24*time.Hour, "how long deleted orders and users are kept in trash, 0 disables purging")
+	fs.DurationVar(&cfg.Interval, "trash-purge-interval", time.Hour, "how often trash is checked for objects past retention")
+	return cfg
+}
+
+// Purge removes the orders and users that have been in trash longer than the retention period.
+// NOTE: nil services are skipped
+func (cfg *Config) Purge(ctx context.Context, orders mgmtorder.ServiceMgmtOrderAPI, users mgmtuser.ServiceMgmtUserAPI) {
+	before := time.Now().UTC().Add(-cfg.Retention)
+	if orders != nil {
+		resp, err := orders.PurgeOrders(ctx, &request.PurgeOrdersRequest{Before: before})
+		if err != nil {
+			fmt.Printf("[WARNING]: purging orders failed: %s\n", err)
+		} else if len(resp.GetIDs()) > 0 {
+			fmt.Printf("[INFO]: purged %v orders from trash\n", len(resp.GetIDs()))
+		}
+	}
+	if users != nil {
+		resp, err := users.PurgeUsers(ctx, &request.PurgeUsersRequest{Before: before})
+		if err != nil {
+			fmt.Printf("[WARNING]: purging users failed: %s\n", err)
+		} else if len(resp.GetIDs()) > 0 {
+			fmt.Printf("[INFO]: purged %v users from trash\n", len(resp.GetIDs()))
+		}
+	}
+}
+
+// Run purges trash every interval until ctx is done.
+func (cfg *Config) Run(ctx context.Context, orders mgmtorder.ServiceMgmtOrderAPI, users mgmtuser.ServiceMgmtUserAPI) {
+	if cfg.Retention <= 0 || cfg.Interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+
+	cfg.Purge(ctx, orders, users)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			cfg.Purge(ctx, orders, users)
+		}
+	}
+}
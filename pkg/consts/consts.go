@@ -17,9 +17,11 @@ const (
 )
 
 const (
-	TraceID string = "Trace-Id"
+	TraceID   string = "Trace-Id"
+	UserEmail string = "User-Email"
 )
 
 var (
 	CtxKeyTrace = span.CtxKey{Key: TraceID}
+	CtxKeyUser  = span.CtxKey{Key: UserEmail}
 )
@@ -0,0 +1,436 @@
+package tests
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/moledoc/orderly/internal/domain/dump"
+	"github.com/moledoc/orderly/internal/domain/errwrap"
+	"github.com/moledoc/orderly/internal/domain/meta"
+	"github.com/moledoc/orderly/internal/domain/order"
+	"github.com/moledoc/orderly/internal/domain/request"
+	"github.com/moledoc/orderly/internal/domain/response"
+	"github.com/moledoc/orderly/internal/domain/user"
+	"github.com/moledoc/orderly/internal/repository"
+	"github.com/moledoc/orderly/internal/repository/local"
+	"github.com/moledoc/orderly/internal/repository/sqldb"
+	"github.com/moledoc/orderly/internal/router"
+	"github.com/moledoc/orderly/internal/service/admin"
+	"github.com/moledoc/orderly/internal/service/mgmtorder"
+	"github.com/moledoc/orderly/internal/service/mgmtuser"
+	"github.com/moledoc/orderly/tests/setup"
+	"github.com/stretchr/testify/require"
+)
+
+// env is a running organisation, services share the repositories
+type env struct {
+	Orders    mgmtorder.ServiceMgmtOrderAPI
+	Users     mgmtuser.ServiceMgmtUserAPI
+	Admin     admin.ServiceAdminAPI
+	OrderRepo repository.RepositoryOrderAPI
+	UserRepo  repository.RepositoryUserAPI
+}
+
+func newEnv(t *testing.T, kind string) *env {
+	var orderRepo repository.RepositoryOrderAPI = local.NewLocalRepositoryOrder()
+	var userRepo repository.RepositoryUserAPI = local.NewLocalRepositoryUser()
+	if kind == "sql" {
+		db := setup.SQLiteDB(t)
+		orderRepo = sqldb.NewSQLRepositoryOrder(db)
+		userRepo = sqldb.NewSQLRepositoryUser(db)
+	}
+	users := mgmtuser.NewServiceMgmtUser(userRepo, nil)
+	orders := mgmtorder.NewServiceMgmtOrder(orderRepo, users, nil)
+	return &env{
+		Orders:    orders,
+		Users:     users,
+		Admin:     admin.NewServiceAdmin(orderRepo, userRepo, orders.GetRootOrder(context.Background()), users.GetRootUser(context.Background())),
+		OrderRepo: orderRepo,
+		UserRepo:  userRepo,
+	}
+}
+
+// populate fills the organisation with users and a tree of orders, some of both in trash
+func populate(t *testing.T, e *env, extra string) {
+	ctx := context.Background()
+
+	lead, err := e.Users.PostUser(ctx, &request.PostUserRequest{User: setup.UserObj(extra)})
+	require.NoError(t, err)
+	for _, name := range []string{"member", "leaver"} {
+		u := setup.UserObj(extra, name)
+		u.SetSupervisor(lead.GetUser().GetEmail())
+		_, err := e.Users.PostUser(ctx, &request.PostUserRequest{User: u})
+		require.NoError(t, err)
+	}
+
+	obj := setup.OrderObj()
+	obj.SetParentOrderID(e.Orders.GetRootOrder(ctx).GetID())
+	setOrderUsers(obj, lead.GetUser().GetEmail())
+	parent, err := e.Orders.PostOrder(ctx, &request.PostOrderRequest{Order: obj})
+	require.NoError(t, err)
+
+	child := setup.OrderObj()
+	child.SetParentOrderID(parent.GetOrder().GetDelegatedTasks()[0].GetID())
+	child.SetDelegatedTasks(nil)
+	setOrderUsers(child, lead.GetUser().GetEmail())
+	_, err = e.Orders.PostOrder(ctx, &request.PostOrderRequest{Order: child})
+	require.NoError(t, err)
+
+	_, err = e.Orders.DeleteOrder(ctx, &request.DeleteOrderRequest{ID: parent.GetOrder().GetDelegatedTasks()[1].GetID()})
+	require.NoError(t, err)
+	leaver, err := e.Users.GetUsers(ctx, &request.GetUsersRequest{Emails: []user.Email{setup.UserObj(extra, "leaver").GetEmail()}})
+	require.NoError(t, err)
+	_, err = e.Users.DeleteUser(ctx, &request.DeleteUserRequest{ID: leaver.GetUsers()[0].GetID()})
+	require.NoError(t, err)
+}
+
+func setOrderUsers(o *order.Order, email user.Email) {
+	o.GetTask().SetAccountable(email)
+	for _, task := range o.GetDelegatedTasks() {
+		task.SetAccountable(email)
+	}
+	for _, sitrep := range o.GetSitReps() {
+		sitrep.SetBy(email)
+	}
+}
+
+func mustExport(t *testing.T, e *env) *dump.Document {
+	resp, err := e.Admin.Export(context.Background(), &request.ExportRequest{})
+	require.NoError(t, err)
+	return resp.GetDocument()
+}
+
+// organisation lists the objects of the document as json, roots left out, so that organisations can be compared
+func organisation(t *testing.T, doc *dump.Document) string {
+	var users []*user.User
+	for _, u := range doc.GetUsers() {
+		if u.GetEmail() != mgmtuser.RootEmail {
+			users = append(users, u)
+		}
+	}
+	roots := make(map[meta.ID]bool)
+	var orders []*order.Order
+	for _, o := range doc.GetOrders() {
+		if o.GetID() == o.GetParentOrderID() {
+			roots[o.GetID()] = true
+			continue
+		}
+		orders = append(orders, o.Clone())
+	}
+	for _, o := range orders {
+		if roots[o.GetParentOrderID()] {
+			o.SetParentOrderID("root")
+		}
+	}
+	slices.SortFunc(users, func(a, b *user.User) int { return cmp.Compare(a.GetID(), b.GetID()) })
+	slices.SortFunc(orders, func(a, b *order.Order) int { return cmp.Compare(a.GetID(), b.GetID()) })
+
+	bs, err := json.Marshal(map[string]any{"users": users, "orders": orders})
+	require.NoError(t, err)
+	return string(bs)
+}
+
+// userVersions lists the version history of every user of the organisation as json, so that histories can be compared
+func userVersions(t *testing.T, e *env) string {
+	versions := make(map[meta.ID][]*user.User)
+	for _, u := range mustExport(t, e).GetUsers() {
+		resp, err := e.Users.GetUserVersions(context.Background(), &request.GetUserVersionsRequest{ID: u.GetID()})
+		require.NoError(t, err)
+		versions[u.GetID()] = resp.GetVersions()
+	}
+	bs, err := json.Marshal(versions)
+	require.NoError(t, err)
+	return string(bs)
+}
+
+func TestExportImport(t *testing.T) {
+	ctx := context.Background()
+
+	for _, from := range []string{"local", "sql"} {
+		for _, to := range []string{"local", "sql"} {
+			t.Run(from+".to."+to, func(t *testing.T) {
+				src := newEnv(t, from)
+				populate(t, src, "src")
+				doc := mustExport(t, src)
+				require.Equal(t, dump.Version, doc.GetVersion())
+				require.Len(t, doc.GetUsers(), 4)
+				require.Len(t, doc.GetOrders(), 6)
+
+				bs, errj := json.Marshal(doc)
+				require.NoError(t, errj)
+				var decoded dump.Document
+				require.NoError(t, json.Unmarshal(bs, &decoded))
+
+				dst := newEnv(t, to)
+				resp, err := dst.Admin.Import(ctx, &request.ImportRequest{Mode: dump.ModeReplace, Document: &decoded})
+				require.NoError(t, err)
+				require.Equal(t, dump.ModeReplace, resp.GetMode())
+				require.Len(t, resp.GetImportedUsers(), 3)
+				require.Len(t, resp.GetImportedOrders(), 5)
+				require.Len(t, resp.GetSkippedUsers(), 1)
+				require.Len(t, resp.GetSkippedOrders(), 1)
+				require.Equal(t, organisation(t, doc), organisation(t, mustExport(t, dst)))
+
+				for _, id := range resp.GetImportedOrders() {
+					stored, err := dst.Orders.GetOrderByID(ctx, &request.GetOrderByIDRequest{ID: id})
+					if err != nil { // NOTE: order in trash
+						continue
+					}
+					version, err := dst.Orders.GetOrderVersion(ctx, &request.GetOrderVersionRequest{ID: id, Version: stored.GetOrder().GetMeta().GetVersion()})
+					require.NoError(t, err)
+					require.Equal(t, organisation(t, &dump.Document{Orders: []*order.Order{stored.GetOrder()}}), organisation(t, &dump.Document{Orders: []*order.Order{version.GetOrder()}}))
+				}
+
+				hits, err := dst.Orders.Search(ctx, &request.SearchRequest{Query: "objective"})
+				require.NoError(t, err)
+				require.NotEmpty(t, hits.GetHits())
+			})
+		}
+	}
+}
+
+func TestImport_Merge(t *testing.T) {
+	ctx := context.Background()
+
+	for _, kind := range []string{"local", "sql"} {
+		t.Run(kind, func(t *testing.T) {
+			src := newEnv(t, kind)
+			populate(t, src, "src")
+			doc := mustExport(t, src)
+
+			resp, err := src.Admin.Import(ctx, &request.ImportRequest{Document: doc})
+			require.NoError(t, err)
+			require.Equal(t, dump.ModeMerge, resp.GetMode())
+			require.Empty(t, resp.GetImportedUsers())
+			require.Empty(t, resp.GetImportedOrders())
+			require.Equal(t, organisation(t, doc), organisation(t, mustExport(t, src)))
+
+			dst := newEnv(t, kind)
+			populate(t, dst, "dst")
+			before := mustExport(t, dst)
+			resp, err = dst.Admin.Import(ctx, &request.ImportRequest{Mode: dump.ModeMerge, Document: doc})
+			require.NoError(t, err)
+			require.Len(t, resp.GetImportedUsers(), 3)
+			require.Len(t, resp.GetImportedOrders(), 5)
+			require.Empty(t, resp.GetRemovedUsers())
+			require.Empty(t, resp.GetRemovedOrders())
+
+			after := mustExport(t, dst)
+			require.Len(t, after.GetUsers(), 7)
+			require.Len(t, after.GetOrders(), 11)
+			merged := &dump.Document{
+				Users:  append(slices.Clone(before.GetUsers()), doc.GetUsers()...),
+				Orders: append(slices.Clone(before.GetOrders()), doc.GetOrders()...),
+			}
+			require.Equal(t, organisation(t, merged), organisation(t, after))
+		})
+	}
+}
+
+func TestImport_Replace(t *testing.T) {
+	ctx := context.Background()
+
+	for _, kind := range []string{"local", "sql"} {
+		t.Run(kind, func(t *testing.T) {
+			src := newEnv(t, kind)
+			populate(t, src, "src")
+			doc := mustExport(t, src)
+
+			dst := newEnv(t, kind)
+			populate(t, dst, "dst")
+			resp, err := dst.Admin.Import(ctx, &request.ImportRequest{Mode: dump.ModeReplace, Document: doc})
+			require.NoError(t, err)
+			require.Len(t, resp.GetRemovedUsers(), 3)
+			require.Len(t, resp.GetRemovedOrders(), 5)
+			require.Equal(t, organisation(t, doc), organisation(t, mustExport(t, dst)))
+
+			root, err := dst.Orders.GetOrderByID(ctx, &request.GetOrderByIDRequest{ID: dst.Orders.GetRootOrder(ctx).GetID()})
+			require.NoError(t, err)
+			require.Len(t, root.GetOrder().GetDelegatedTasks(), 1)
+		})
+	}
+}
+
+func TestImport_Failed(t *testing.T) {
+	ctx := context.Background()
+
+	src := newEnv(t, "local")
+	populate(t, src, "src")
+
+	tt := []struct {
+		name   string
+		mode   dump.Mode
+		change func(doc *dump.Document)
+		code   int
+	}{
+		{name: "invalid.mode", mode: "overwrite", change: func(doc *dump.Document) {}, code: http.StatusBadRequest},
+		{name: "invalid.version", change: func(doc *dump.Document) { doc.Version = 0 }, code: http.StatusBadRequest},
+		{name: "duplicate.user", change: func(doc *dump.Document) { doc.Users = append(doc.Users, doc.Users[0]) }, code: http.StatusBadRequest},
+		{name: "missing.meta", change: func(doc *dump.Document) { doc.Orders[0].SetMeta(nil) }, code: http.StatusBadRequest},
+		{
+			name: "unknown.parent",
+			change: func(doc *dump.Document) {
+				for _, o := range doc.Orders {
+					if len(o.GetDelegatedTasks()) == 0 {
+						o.SetParentOrderID(meta.NewID())
+						return
+					}
+				}
+			},
+			code: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "unknown.supervisor",
+			change: func(doc *dump.Document) {
+				for _, u := range doc.Users {
+					if u.GetEmail() != mgmtuser.RootEmail {
+						u.SetSupervisor("nobody@example.com")
+						return
+					}
+				}
+			},
+			code: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "unknown.accountable",
+			change: func(doc *dump.Document) {
+				for _, o := range doc.Orders {
+					if o.GetID() != o.GetParentOrderID() {
+						o.GetTask().SetAccountable("nobody@example.com")
+						return
+					}
+				}
+			},
+			code: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "replace.unknown.accountable", // NOTE: users are replaced before the orders are checked
+			mode: dump.ModeReplace,
+			change: func(doc *dump.Document) {
+				for _, o := range doc.Orders {
+					if o.GetID() != o.GetParentOrderID() {
+						o.GetTask().SetAccountable("nobody@example.com")
+						return
+					}
+				}
+			},
+			code: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "taken.email",
+			mode: dump.ModeMerge,
+			change: func(doc *dump.Document) {
+				for _, u := range doc.Users {
+					if u.GetEmail() == setup.UserObj("src").GetEmail() {
+						u.SetEmail(setup.UserObj("dst").GetEmail())
+					}
+				}
+			},
+			code: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, kind := range []string{"local", "sql"} {
+		for _, tc := range tt {
+			t.Run(kind+"."+tc.name, func(t *testing.T) {
+				dst := newEnv(t, kind)
+				populate(t, dst, "dst")
+				before := mustExport(t, dst)
+				versions := userVersions(t, dst)
+
+				doc := mustExport(t, src)
+				tc.change(doc)
+				_, err := dst.Admin.Import(ctx, &request.ImportRequest{Mode: tc.mode, Document: doc})
+				require.Error(t, err)
+				require.Equal(t, tc.code, err.GetStatusCode(), err)
+				require.Equal(t, organisation(t, before), organisation(t, mustExport(t, dst)))
+				require.Equal(t, versions, userVersions(t, dst))
+			})
+		}
+	}
+}
+
+// failingOrders fails creating the given order, the transactions it's used in included
+type failingOrders struct {
+	repository.RepositoryOrderAPI
+	id meta.ID
+}
+
+func (r *failingOrders) WithTx(ctx context.Context, fn func(tx repository.RepositoryOrderAPI) errwrap.Error) errwrap.Error {
+	return r.RepositoryOrderAPI.WithTx(ctx, func(tx repository.RepositoryOrderAPI) errwrap.Error {
+		return fn(&failingOrders{RepositoryOrderAPI: tx, id: r.id})
+	})
+}
+
+func (r *failingOrders) CreateOrder(ctx context.Context, o *order.Order) (*order.Order, errwrap.Error) {
+	if o.GetID() == r.id {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "creating order '%s' failed", o.GetID())
+	}
+	return r.RepositoryOrderAPI.CreateOrder(ctx, o)
+}
+
+func TestImport_Failed_Orders(t *testing.T) {
+	ctx := context.Background()
+
+	src := newEnv(t, "local")
+	populate(t, src, "src")
+	doc := mustExport(t, src)
+	var failing meta.ID
+	for _, o := range doc.GetOrders() {
+		if o.GetID() != o.GetParentOrderID() && len(o.GetDelegatedTasks()) == 0 {
+			failing = o.GetID()
+		}
+	}
+
+	for _, kind := range []string{"local", "sql"} {
+		for _, mode := range []dump.Mode{dump.ModeMerge, dump.ModeReplace} {
+			t.Run(kind+"."+string(mode), func(t *testing.T) {
+				dst := newEnv(t, kind)
+				populate(t, dst, "dst")
+				before := mustExport(t, dst)
+				versions := userVersions(t, dst)
+
+				svc := admin.NewServiceAdmin(&failingOrders{RepositoryOrderAPI: dst.OrderRepo, id: failing}, dst.UserRepo, dst.Orders.GetRootOrder(ctx), dst.Users.GetRootUser(ctx))
+				_, err := svc.Import(ctx, &request.ImportRequest{Mode: mode, Document: doc})
+				require.Error(t, err)
+				require.Equal(t, http.StatusInternalServerError, err.GetStatusCode(), err)
+				require.Equal(t, organisation(t, before), organisation(t, mustExport(t, dst)))
+				require.Equal(t, versions, userVersions(t, dst))
+			})
+		}
+	}
+}
+
+func TestExportImport_HTTP(t *testing.T) {
+	src := newEnv(t, "local")
+	populate(t, src, "src")
+	mux := router.RouteAdmin(src.Admin)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/admin/export", nil))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	exported := rec.Body.Bytes()
+
+	dst := newEnv(t, "local")
+	router.RouteAdmin(dst.Admin)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/admin/import?mode=replace", bytes.NewReader(exported)))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var resp response.ImportResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.Len(t, resp.GetImportedOrders(), 5)
+
+	var doc dump.Document
+	require.NoError(t, json.Unmarshal(exported, &doc))
+	require.Equal(t, organisation(t, &doc), organisation(t, mustExport(t, dst)))
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/admin/import?mode=overwrite", bytes.NewReader(exported)))
+	require.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+}
@@ -15,6 +15,7 @@ type Order interface {
 	GetOrders(t *testing.T, ctx context.Context, req *request.GetOrdersRequest) (*response.GetOrdersResponse, errwrap.Error)
 	PatchOrder(t *testing.T, ctx context.Context, req *request.PatchOrderRequest) (*response.PatchOrderResponse, errwrap.Error)
 	DeleteOrder(t *testing.T, ctx context.Context, req *request.DeleteOrderRequest) (*response.DeleteOrderResponse, errwrap.Error)
+	MoveOrder(t *testing.T, ctx context.Context, req *request.MoveOrderRequest) (*response.MoveOrderResponse, errwrap.Error)
 	////
 	PutDelegatedTasks(t *testing.T, ctx context.Context, req *request.PutDelegatedTasksRequest) (*response.PutDelegatedTasksResponse, errwrap.Error)
 	PatchDelegatedTasks(t *testing.T, ctx context.Context, req *request.PatchDelegatedTasksRequest) (*response.PatchDelegatedTasksResponse, errwrap.Error)
@@ -23,4 +24,17 @@ type Order interface {
 	PutSitReps(t *testing.T, ctx context.Context, req *request.PutSitRepsRequest) (*response.PutSitRepsResponse, errwrap.Error)
 	PatchSitReps(t *testing.T, ctx context.Context, req *request.PatchSitRepsRequest) (*response.PatchSitRepsResponse, errwrap.Error)
 	DeleteSitReps(t *testing.T, ctx context.Context, req *request.DeleteSitRepsRequest) (*response.DeleteSitRepsResponse, errwrap.Error)
+	////
+	GetOrderVersions(t *testing.T, ctx context.Context, req *request.GetOrderVersionsRequest) (*response.GetOrderVersionsResponse, errwrap.Error)
+	GetOrderVersion(t *testing.T, ctx context.Context, req *request.GetOrderVersionRequest) (*response.GetOrderVersionResponse, errwrap.Error)
+	GetOrderDiff(t *testing.T, ctx context.Context, req *request.GetOrderDiffRequest) (*response.GetOrderDiffResponse, errwrap.Error)
+	GetOrderTree(t *testing.T, ctx context.Context, req *request.GetOrderTreeRequest) (*response.GetOrderTreeResponse, errwrap.Error)
+	GetOrderDocument(t *testing.T, ctx context.Context, req *request.GetOrderDocumentRequest) (*response.GetOrderDocumentResponse, errwrap.Error)
+	GetOverdueReport(t *testing.T, ctx context.Context, req *request.GetOverdueReportRequest) (*response.GetOverdueReportResponse, errwrap.Error)
+	Search(t *testing.T, ctx context.Context, req *request.SearchRequest) (*response.SearchResponse, errwrap.Error)
+	////
+	GetDeletedOrders(t *testing.T, ctx context.Context, req *request.GetDeletedOrdersRequest) (*response.GetDeletedOrdersResponse, errwrap.Error)
+	RestoreOrder(t *testing.T, ctx context.Context, req *request.RestoreOrderRequest) (*response.RestoreOrderResponse, errwrap.Error)
+	RestoreSitReps(t *testing.T, ctx context.Context, req *request.RestoreSitRepsRequest) (*response.RestoreSitRepsResponse, errwrap.Error)
+	PurgeOrders(t *testing.T, ctx context.Context, req *request.PurgeOrdersRequest) (*response.PurgeOrdersResponse, errwrap.Error)
 }
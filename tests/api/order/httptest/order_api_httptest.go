@@ -5,12 +5,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/http/httputil"
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/moledoc/orderly/internal/domain/errwrap"
 	"github.com/moledoc/orderly/internal/domain/request"
@@ -102,6 +104,39 @@ func (api *OrderAPIHTTPTest) GetOrders(t *testing.T, ctx context.Context, req *r
 	if len(req.GetAccountable()) > 0 {
 		params.Add("accountable", string(req.GetAccountable()))
 	}
+	for _, state := range req.GetStates() {
+		params.Add("state", state.String())
+	}
+	for name, value := range map[string]time.Time{
+		"deadline_from": req.GetDeadlineFrom(),
+		"deadline_to":   req.GetDeadlineTo(),
+		"created_from":  req.GetCreatedFrom(),
+		"created_to":    req.GetCreatedTo(),
+		"updated_from":  req.GetUpdatedFrom(),
+		"updated_to":    req.GetUpdatedTo(),
+	} {
+		if !value.IsZero() {
+			params.Add(name, value.Format(time.RFC3339Nano))
+		}
+	}
+	if len(req.GetObjective()) > 0 {
+		params.Add("objective", req.GetObjective())
+	}
+	if len(req.GetSitRepBy()) > 0 {
+		params.Add("sitrep_by", string(req.GetSitRepBy()))
+	}
+	if has := req.GetHasDelegatedTasks(); has != nil {
+		params.Add("has_delegated_tasks", fmt.Sprint(*has))
+	}
+	if req.GetLimit() > 0 {
+		params.Add("limit", fmt.Sprint(req.GetLimit()))
+	}
+	if len(req.GetCursor()) > 0 {
+		params.Add("cursor", req.GetCursor())
+	}
+	if len(req.GetSort()) > 0 {
+		params.Add("sort", string(req.GetSort()))
+	}
 	baseURL.RawQuery = params.Encode()
 
 	reqHttp := httptest.NewRequest(http.MethodGet, baseURL.String(), nil)
@@ -136,6 +171,9 @@ func (api *OrderAPIHTTPTest) PatchOrder(t *testing.T, ctx context.Context, req *
 
 	reqHttp := httptest.NewRequest(http.MethodPatch, "/v1/mgmt/order", bytes.NewBuffer(reqBytes))
 	reqHttp.Header.Set("Content-Type", "application/json")
+	if req.GetExpectedVersion() > 0 {
+		reqHttp.Header.Set("If-Match", fmt.Sprintf("\"%v\"", req.GetExpectedVersion()))
+	}
 
 	rr := httptest.NewRecorder()
 	api.Mux.ServeHTTP(rr, reqHttp)
@@ -161,7 +199,17 @@ func (api *OrderAPIHTTPTest) PatchOrder(t *testing.T, ctx context.Context, req *
 func (api *OrderAPIHTTPTest) DeleteOrder(t *testing.T, ctx context.Context, req *request.DeleteOrderRequest) (*response.DeleteOrderResponse, errwrap.Error) {
 	t.Helper()
 
-	reqHttp := httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/mgmt/order/%v", req.GetID()), nil)
+	baseURL, _ := url.Parse(fmt.Sprintf("/v1/mgmt/order/%v", req.GetID()))
+	params := url.Values{}
+	if req.GetCascade() {
+		params.Add("cascade", "true")
+	}
+	if req.GetDryRun() {
+		params.Add("dry_run", "true")
+	}
+	baseURL.RawQuery = params.Encode()
+
+	reqHttp := httptest.NewRequest(http.MethodDelete, baseURL.String(), nil)
 
 	rr := httptest.NewRecorder()
 	api.Mux.ServeHTTP(rr, reqHttp)
@@ -171,6 +219,44 @@ func (api *OrderAPIHTTPTest) DeleteOrder(t *testing.T, ctx context.Context, req
 	if respHttp.StatusCode == http.StatusNoContent {
 		return &response.DeleteOrderResponse{}, nil
 	}
+	if respHttp.StatusCode == http.StatusOK {
+		var resp response.DeleteOrderResponse
+		if err := json.NewDecoder(respHttp.Body).Decode(&resp); err != nil {
+			return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s", err)
+		}
+		return &resp, nil
+	}
+	var errw errwrap.Err
+	if err := json.NewDecoder(respHttp.Body).Decode(&errw); err != nil {
+		rawResponse, _ := httputil.DumpResponse(respHttp, false)
+		return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s\nRaw response: %v", err, string(rawResponse))
+	}
+
+	return nil, &errw
+}
+
+func (api *OrderAPIHTTPTest) MoveOrder(t *testing.T, ctx context.Context, req *request.MoveOrderRequest) (*response.MoveOrderResponse, errwrap.Error) {
+	t.Helper()
+
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, errwrap.NewError(http.StatusBadRequest, "marshaling request failed: %s", err)
+	}
+
+	reqHttp := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/v1/mgmt/order/%v/move", req.GetID()), bytes.NewBuffer(reqBytes))
+
+	rr := httptest.NewRecorder()
+	api.Mux.ServeHTTP(rr, reqHttp)
+	respHttp := rr.Result()
+	defer respHttp.Body.Close()
+
+	if respHttp.StatusCode == http.StatusOK {
+		var resp response.MoveOrderResponse
+		if err := json.NewDecoder(respHttp.Body).Decode(&resp); err != nil {
+			return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s", err)
+		}
+		return &resp, nil
+	}
 	var errw errwrap.Err
 	if err := json.NewDecoder(respHttp.Body).Decode(&errw); err != nil {
 		rawResponse, _ := httputil.DumpResponse(respHttp, false)
@@ -228,6 +314,9 @@ func (api *OrderAPIHTTPTest) PatchDelegatedTasks(t *testing.T, ctx context.Conte
 	path = strings.ReplaceAll(path, "//", "/")
 	reqHttp := httptest.NewRequest(http.MethodPatch, path, bytes.NewBuffer(reqBytes))
 	reqHttp.Header.Set("Content-Type", "application/json")
+	if req.GetExpectedVersion() > 0 {
+		reqHttp.Header.Set("If-Match", fmt.Sprintf("\"%v\"", req.GetExpectedVersion()))
+	}
 
 	rr := httptest.NewRecorder()
 	api.Mux.ServeHTTP(rr, reqHttp)
@@ -330,6 +419,9 @@ func (api *OrderAPIHTTPTest) PatchSitReps(t *testing.T, ctx context.Context, req
 	path = strings.ReplaceAll(path, "//", "/")
 	reqHttp := httptest.NewRequest(http.MethodPatch, path, bytes.NewBuffer(reqBytes))
 	reqHttp.Header.Set("Content-Type", "application/json")
+	if req.GetExpectedVersion() > 0 {
+		reqHttp.Header.Set("If-Match", fmt.Sprintf("\"%v\"", req.GetExpectedVersion()))
+	}
 
 	rr := httptest.NewRecorder()
 	api.Mux.ServeHTTP(rr, reqHttp)
@@ -384,3 +476,356 @@ func (api *OrderAPIHTTPTest) DeleteSitReps(t *testing.T, ctx context.Context, re
 
 	return nil, &errw
 }
+
+////
+
+func (api *OrderAPIHTTPTest) GetOrderVersions(t *testing.T, ctx context.Context, req *request.GetOrderVersionsRequest) (*response.GetOrderVersionsResponse, errwrap.Error) {
+	t.Helper()
+
+	reqHttp := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/v1/mgmt/order/%v/versions", req.GetID()), nil)
+
+	rr := httptest.NewRecorder()
+	api.Mux.ServeHTTP(rr, reqHttp)
+	respHttp := rr.Result()
+	defer respHttp.Body.Close()
+
+	if respHttp.StatusCode == http.StatusOK {
+		var resp response.GetOrderVersionsResponse
+		if err := json.NewDecoder(respHttp.Body).Decode(&resp); err != nil {
+			return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s", err)
+		}
+		return &resp, nil
+	}
+	var errw errwrap.Err
+	if err := json.NewDecoder(respHttp.Body).Decode(&errw); err != nil {
+		rawResponse, _ := httputil.DumpResponse(respHttp, false)
+		return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s\nRaw response: %v", err, string(rawResponse))
+	}
+
+	return nil, &errw
+}
+
+func (api *OrderAPIHTTPTest) GetOrderVersion(t *testing.T, ctx context.Context, req *request.GetOrderVersionRequest) (*response.GetOrderVersionResponse, errwrap.Error) {
+	t.Helper()
+
+	reqHttp := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/v1/mgmt/order/%v/version/%v", req.GetID(), req.GetVersion()), nil)
+
+	rr := httptest.NewRecorder()
+	api.Mux.ServeHTTP(rr, reqHttp)
+	respHttp := rr.Result()
+	defer respHttp.Body.Close()
+
+	if respHttp.StatusCode == http.StatusOK {
+		var resp response.GetOrderVersionResponse
+		if err := json.NewDecoder(respHttp.Body).Decode(&resp); err != nil {
+			return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s", err)
+		}
+		return &resp, nil
+	}
+	var errw errwrap.Err
+	if err := json.NewDecoder(respHttp.Body).Decode(&errw); err != nil {
+		rawResponse, _ := httputil.DumpResponse(respHttp, false)
+		return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s\nRaw response: %v", err, string(rawResponse))
+	}
+
+	return nil, &errw
+}
+
+func (api *OrderAPIHTTPTest) GetOrderDiff(t *testing.T, ctx context.Context, req *request.GetOrderDiffRequest) (*response.GetOrderDiffResponse, errwrap.Error) {
+	t.Helper()
+
+	baseURL, _ := url.Parse(fmt.Sprintf("/v1/mgmt/order/%v/diff", req.GetID()))
+	params := url.Values{}
+	if req.GetFrom() > 0 {
+		params.Add("from", fmt.Sprint(req.GetFrom()))
+	}
+	if req.GetTo() > 0 {
+		params.Add("to", fmt.Sprint(req.GetTo()))
+	}
+	baseURL.RawQuery = params.Encode()
+
+	reqHttp := httptest.NewRequest(http.MethodGet, baseURL.String(), nil)
+
+	rr := httptest.NewRecorder()
+	api.Mux.ServeHTTP(rr, reqHttp)
+	respHttp := rr.Result()
+	defer respHttp.Body.Close()
+
+	if respHttp.StatusCode == http.StatusOK {
+		var resp response.GetOrderDiffResponse
+		if err := json.NewDecoder(respHttp.Body).Decode(&resp); err != nil {
+			return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s", err)
+		}
+		return &resp, nil
+	}
+	var errw errwrap.Err
+	if err := json.NewDecoder(respHttp.Body).Decode(&errw); err != nil {
+		rawResponse, _ := httputil.DumpResponse(respHttp, false)
+		return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s\nRaw response: %v", err, string(rawResponse))
+	}
+
+	return nil, &errw
+}
+
+func (api *OrderAPIHTTPTest) GetOrderTree(t *testing.T, ctx context.Context, req *request.GetOrderTreeRequest) (*response.GetOrderTreeResponse, errwrap.Error) {
+	t.Helper()
+
+	baseURL, _ := url.Parse(fmt.Sprintf("/v1/mgmt/order/%v/tree", req.GetID()))
+	params := url.Values{}
+	if req.GetDepth() > 0 {
+		params.Add("depth", fmt.Sprint(req.GetDepth()))
+	}
+	baseURL.RawQuery = params.Encode()
+
+	reqHttp := httptest.NewRequest(http.MethodGet, baseURL.String(), nil)
+
+	rr := httptest.NewRecorder()
+	api.Mux.ServeHTTP(rr, reqHttp)
+	respHttp := rr.Result()
+	defer respHttp.Body.Close()
+
+	if respHttp.StatusCode == http.StatusOK {
+		var resp response.GetOrderTreeResponse
+		if err := json.NewDecoder(respHttp.Body).Decode(&resp); err != nil {
+			return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s", err)
+		}
+		return &resp, nil
+	}
+	var errw errwrap.Err
+	if err := json.NewDecoder(respHttp.Body).Decode(&errw); err != nil {
+		rawResponse, _ := httputil.DumpResponse(respHttp, false)
+		return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s\nRaw response: %v", err, string(rawResponse))
+	}
+
+	return nil, &errw
+}
+
+func (api *OrderAPIHTTPTest) GetOrderDocument(t *testing.T, ctx context.Context, req *request.GetOrderDocumentRequest) (*response.GetOrderDocumentResponse, errwrap.Error) {
+	t.Helper()
+
+	baseURL, _ := url.Parse(fmt.Sprintf("/v1/mgmt/order/%v/document", req.GetID()))
+	params := url.Values{}
+	if len(req.GetFormat()) > 0 {
+		params.Add("format", string(req.GetFormat()))
+	}
+	if req.GetSitReps() > 0 {
+		params.Add("sitreps", fmt.Sprint(req.GetSitReps()))
+	}
+	baseURL.RawQuery = params.Encode()
+
+	reqHttp := httptest.NewRequest(http.MethodGet, baseURL.String(), nil)
+
+	rr := httptest.NewRecorder()
+	api.Mux.ServeHTTP(rr, reqHttp)
+	respHttp := rr.Result()
+	defer respHttp.Body.Close()
+
+	if respHttp.StatusCode == http.StatusOK {
+		bs, err := io.ReadAll(respHttp.Body)
+		if err != nil {
+			return nil, errwrap.NewError(http.StatusInternalServerError, "reading response failed: %s", err)
+		}
+		return &response.GetOrderDocumentResponse{
+			ContentType: respHttp.Header.Get("Content-Type"),
+			Document:    string(bs),
+		}, nil
+	}
+	var errw errwrap.Err
+	if err := json.NewDecoder(respHttp.Body).Decode(&errw); err != nil {
+		rawResponse, _ := httputil.DumpResponse(respHttp, false)
+		return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s\nRaw response: %v", err, string(rawResponse))
+	}
+
+	return nil, &errw
+}
+
+func (api *OrderAPIHTTPTest) GetOverdueReport(t *testing.T, ctx context.Context, req *request.GetOverdueReportRequest) (*response.GetOverdueReportResponse, errwrap.Error) {
+	t.Helper()
+
+	baseURL, _ := url.Parse("/v1/mgmt/reports/overdue")
+	params := url.Values{}
+	if len(req.GetRootOrderID()) > 0 {
+		params.Add("root_order_id", string(req.GetRootOrderID()))
+	}
+	if len(req.GetSupervisor()) > 0 {
+		params.Add("supervisor", string(req.GetSupervisor()))
+	}
+	if req.GetDays() > 0 {
+		params.Add("days", fmt.Sprint(req.GetDays()))
+	}
+	baseURL.RawQuery = params.Encode()
+
+	reqHttp := httptest.NewRequest(http.MethodGet, baseURL.String(), nil)
+
+	rr := httptest.NewRecorder()
+	api.Mux.ServeHTTP(rr, reqHttp)
+	respHttp := rr.Result()
+	defer respHttp.Body.Close()
+
+	if respHttp.StatusCode == http.StatusOK {
+		var resp response.GetOverdueReportResponse
+		if err := json.NewDecoder(respHttp.Body).Decode(&resp); err != nil {
+			return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s", err)
+		}
+		return &resp, nil
+	}
+	var errw errwrap.Err
+	if err := json.NewDecoder(respHttp.Body).Decode(&errw); err != nil {
+		rawResponse, _ := httputil.DumpResponse(respHttp, false)
+		return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s\nRaw response: %v", err, string(rawResponse))
+	}
+
+	return nil, &errw
+}
+
+func (api *OrderAPIHTTPTest) Search(t *testing.T, ctx context.Context, req *request.SearchRequest) (*response.SearchResponse, errwrap.Error) {
+	t.Helper()
+
+	baseURL, _ := url.Parse("/v1/mgmt/search")
+	params := url.Values{}
+	params.Add("q", req.GetQuery())
+	if req.GetLimit() > 0 {
+		params.Add("limit", fmt.Sprint(req.GetLimit()))
+	}
+	baseURL.RawQuery = params.Encode()
+
+	reqHttp := httptest.NewRequest(http.MethodGet, baseURL.String(), nil)
+
+	rr := httptest.NewRecorder()
+	api.Mux.ServeHTTP(rr, reqHttp)
+	respHttp := rr.Result()
+	defer respHttp.Body.Close()
+
+	if respHttp.StatusCode == http.StatusOK {
+		var resp response.SearchResponse
+		if err := json.NewDecoder(respHttp.Body).Decode(&resp); err != nil {
+			return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s", err)
+		}
+		return &resp, nil
+	}
+	var errw errwrap.Err
+	if err := json.NewDecoder(respHttp.Body).Decode(&errw); err != nil {
+		rawResponse, _ := httputil.DumpResponse(respHttp, false)
+		return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s\nRaw response: %v", err, string(rawResponse))
+	}
+
+	return nil, &errw
+}
+
+////
+
+func (api *OrderAPIHTTPTest) GetDeletedOrders(t *testing.T, ctx context.Context, req *request.GetDeletedOrdersRequest) (*response.GetDeletedOrdersResponse, errwrap.Error) {
+	t.Helper()
+
+	reqHttp := httptest.NewRequest(http.MethodGet, "/v1/mgmt/trash/orders", nil)
+
+	rr := httptest.NewRecorder()
+	api.Mux.ServeHTTP(rr, reqHttp)
+	respHttp := rr.Result()
+	defer respHttp.Body.Close()
+
+	if respHttp.StatusCode == http.StatusOK {
+		var resp response.GetDeletedOrdersResponse
+		if err := json.NewDecoder(respHttp.Body).Decode(&resp); err != nil {
+			return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s", err)
+		}
+		return &resp, nil
+	}
+	var errw errwrap.Err
+	if err := json.NewDecoder(respHttp.Body).Decode(&errw); err != nil {
+		rawResponse, _ := httputil.DumpResponse(respHttp, false)
+		return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s\nRaw response: %v", err, string(rawResponse))
+	}
+
+	return nil, &errw
+}
+
+func (api *OrderAPIHTTPTest) RestoreOrder(t *testing.T, ctx context.Context, req *request.RestoreOrderRequest) (*response.RestoreOrderResponse, errwrap.Error) {
+	t.Helper()
+
+	reqHttp := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/v1/mgmt/order/%v/restore", req.GetID()), nil)
+
+	rr := httptest.NewRecorder()
+	api.Mux.ServeHTTP(rr, reqHttp)
+	respHttp := rr.Result()
+	defer respHttp.Body.Close()
+
+	if respHttp.StatusCode == http.StatusOK {
+		var resp response.RestoreOrderResponse
+		if err := json.NewDecoder(respHttp.Body).Decode(&resp); err != nil {
+			return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s", err)
+		}
+		return &resp, nil
+	}
+	var errw errwrap.Err
+	if err := json.NewDecoder(respHttp.Body).Decode(&errw); err != nil {
+		rawResponse, _ := httputil.DumpResponse(respHttp, false)
+		return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s\nRaw response: %v", err, string(rawResponse))
+	}
+
+	return nil, &errw
+}
+
+func (api *OrderAPIHTTPTest) RestoreSitReps(t *testing.T, ctx context.Context, req *request.RestoreSitRepsRequest) (*response.RestoreSitRepsResponse, errwrap.Error) {
+	t.Helper()
+
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, errwrap.NewError(http.StatusBadRequest, "marshaling request failed: %s", err)
+	}
+
+	reqHttp := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/v1/mgmt/order/%v/sitrep/restore", req.GetOrderID()), bytes.NewBuffer(reqBytes))
+
+	rr := httptest.NewRecorder()
+	api.Mux.ServeHTTP(rr, reqHttp)
+	respHttp := rr.Result()
+	defer respHttp.Body.Close()
+
+	if respHttp.StatusCode == http.StatusOK {
+		var resp response.RestoreSitRepsResponse
+		if err := json.NewDecoder(respHttp.Body).Decode(&resp); err != nil {
+			return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s", err)
+		}
+		return &resp, nil
+	}
+	var errw errwrap.Err
+	if err := json.NewDecoder(respHttp.Body).Decode(&errw); err != nil {
+		rawResponse, _ := httputil.DumpResponse(respHttp, false)
+		return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s\nRaw response: %v", err, string(rawResponse))
+	}
+
+	return nil, &errw
+}
+
+func (api *OrderAPIHTTPTest) PurgeOrders(t *testing.T, ctx context.Context, req *request.PurgeOrdersRequest) (*response.PurgeOrdersResponse, errwrap.Error) {
+	t.Helper()
+
+	baseURL, _ := url.Parse("/v1/mgmt/trash/orders")
+	params := url.Values{}
+	if !req.GetBefore().IsZero() {
+		params.Add("before", req.GetBefore().Format(time.RFC3339Nano))
+	}
+	baseURL.RawQuery = params.Encode()
+
+	reqHttp := httptest.NewRequest(http.MethodDelete, baseURL.String(), nil)
+
+	rr := httptest.NewRecorder()
+	api.Mux.ServeHTTP(rr, reqHttp)
+	respHttp := rr.Result()
+	defer respHttp.Body.Close()
+
+	if respHttp.StatusCode == http.StatusOK {
+		var resp response.PurgeOrdersResponse
+		if err := json.NewDecoder(respHttp.Body).Decode(&resp); err != nil {
+			return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s", err)
+		}
+		return &resp, nil
+	}
+	var errw errwrap.Err
+	if err := json.NewDecoder(respHttp.Body).Decode(&errw); err != nil {
+		rawResponse, _ := httputil.DumpResponse(respHttp, false)
+		return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s\nRaw response: %v", err, string(rawResponse))
+	}
+
+	return nil, &errw
+}
@@ -5,10 +5,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/moledoc/orderly/internal/domain/errwrap"
 	"github.com/moledoc/orderly/internal/domain/request"
@@ -98,6 +100,39 @@ func (api *OrderAPIReq) GetOrders(t *testing.T, ctx context.Context, req *reques
 	if len(req.GetAccountable()) > 0 {
 		params.Add("accountable", string(req.GetAccountable()))
 	}
+	for _, state := range req.GetStates() {
+		params.Add("state", state.String())
+	}
+	for name, value := range map[string]time.Time{
+		"deadline_from": req.GetDeadlineFrom(),
+		"deadline_to":   req.GetDeadlineTo(),
+		"created_from":  req.GetCreatedFrom(),
+		"created_to":    req.GetCreatedTo(),
+		"updated_from":  req.GetUpdatedFrom(),
+		"updated_to":    req.GetUpdatedTo(),
+	} {
+		if !value.IsZero() {
+			params.Add(name, value.Format(time.RFC3339Nano))
+		}
+	}
+	if len(req.GetObjective()) > 0 {
+		params.Add("objective", req.GetObjective())
+	}
+	if len(req.GetSitRepBy()) > 0 {
+		params.Add("sitrep_by", string(req.GetSitRepBy()))
+	}
+	if has := req.GetHasDelegatedTasks(); has != nil {
+		params.Add("has_delegated_tasks", fmt.Sprint(*has))
+	}
+	if req.GetLimit() > 0 {
+		params.Add("limit", fmt.Sprint(req.GetLimit()))
+	}
+	if len(req.GetCursor()) > 0 {
+		params.Add("cursor", req.GetCursor())
+	}
+	if len(req.GetSort()) > 0 {
+		params.Add("sort", string(req.GetSort()))
+	}
 	baseURL.RawQuery = params.Encode()
 
 	respHttp, err := api.HttpClient.Get(baseURL.String())
@@ -135,6 +170,9 @@ func (api *OrderAPIReq) PatchOrder(t *testing.T, ctx context.Context, req *reque
 	}
 
 	reqHttp.Header.Set("Content-Type", "application/json")
+	if req.GetExpectedVersion() > 0 {
+		reqHttp.Header.Set("If-Match", fmt.Sprintf("\"%v\"", req.GetExpectedVersion()))
+	}
 	respHttp, err := api.HttpClient.Do(reqHttp)
 	if err != nil {
 		return nil, errwrap.NewError(http.StatusInternalServerError, "sending request failed: %s", err)
@@ -160,7 +198,17 @@ func (api *OrderAPIReq) PatchOrder(t *testing.T, ctx context.Context, req *reque
 func (api *OrderAPIReq) DeleteOrder(t *testing.T, ctx context.Context, req *request.DeleteOrderRequest) (*response.DeleteOrderResponse, errwrap.Error) {
 	t.Helper()
 
-	reqHttp, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/v1/mgmt/order/%v", api.BaseURL, req.GetID()), nil)
+	baseURL, _ := url.Parse(fmt.Sprintf("%s/v1/mgmt/order/%v", api.BaseURL, req.GetID()))
+	params := url.Values{}
+	if req.GetCascade() {
+		params.Add("cascade", "true")
+	}
+	if req.GetDryRun() {
+		params.Add("dry_run", "true")
+	}
+	baseURL.RawQuery = params.Encode()
+
+	reqHttp, err := http.NewRequest(http.MethodDelete, baseURL.String(), nil)
 	if err != nil {
 		return nil, errwrap.NewError(http.StatusInternalServerError, "new request failed: %s", err)
 	}
@@ -174,6 +222,48 @@ func (api *OrderAPIReq) DeleteOrder(t *testing.T, ctx context.Context, req *requ
 	if respHttp.StatusCode == http.StatusNoContent {
 		return &response.DeleteOrderResponse{}, nil
 	}
+	if respHttp.StatusCode == http.StatusOK {
+		var resp response.DeleteOrderResponse
+		if err := json.NewDecoder(respHttp.Body).Decode(&resp); err != nil {
+			return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s", err)
+		}
+		return &resp, nil
+	}
+	var errw errwrap.Err
+	if err := json.NewDecoder(respHttp.Body).Decode(&errw); err != nil {
+		rawResponse, _ := httputil.DumpResponse(respHttp, false)
+		return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s\nRaw response: %v", err, string(rawResponse))
+	}
+
+	return nil, &errw
+}
+
+func (api *OrderAPIReq) MoveOrder(t *testing.T, ctx context.Context, req *request.MoveOrderRequest) (*response.MoveOrderResponse, errwrap.Error) {
+	t.Helper()
+
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, errwrap.NewError(http.StatusBadRequest, "marshaling request failed: %s", err)
+	}
+
+	reqHttp, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/v1/mgmt/order/%v/move", api.BaseURL, req.GetID()), bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "new request failed: %s", err)
+	}
+
+	respHttp, err := api.HttpClient.Do(reqHttp)
+	if err != nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "sending request failed: %s", err)
+	}
+	defer respHttp.Body.Close()
+
+	if respHttp.StatusCode == http.StatusOK {
+		var resp response.MoveOrderResponse
+		if err := json.NewDecoder(respHttp.Body).Decode(&resp); err != nil {
+			return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s", err)
+		}
+		return &resp, nil
+	}
 	var errw errwrap.Err
 	if err := json.NewDecoder(respHttp.Body).Decode(&errw); err != nil {
 		rawResponse, _ := httputil.DumpResponse(respHttp, false)
@@ -235,6 +325,9 @@ func (api *OrderAPIReq) PatchDelegatedTasks(t *testing.T, ctx context.Context, r
 	}
 
 	reqHttp.Header.Set("Content-Type", "application/json")
+	if req.GetExpectedVersion() > 0 {
+		reqHttp.Header.Set("If-Match", fmt.Sprintf("\"%v\"", req.GetExpectedVersion()))
+	}
 	respHttp, err := api.HttpClient.Do(reqHttp)
 	if err != nil {
 		return nil, errwrap.NewError(http.StatusInternalServerError, "sending request failed: %s", err)
@@ -344,6 +437,9 @@ func (api *OrderAPIReq) PatchSitReps(t *testing.T, ctx context.Context, req *req
 	}
 
 	reqHttp.Header.Set("Content-Type", "application/json")
+	if req.GetExpectedVersion() > 0 {
+		reqHttp.Header.Set("If-Match", fmt.Sprintf("\"%v\"", req.GetExpectedVersion()))
+	}
 	respHttp, err := api.HttpClient.Do(reqHttp)
 	if err != nil {
 		return nil, errwrap.NewError(http.StatusInternalServerError, "sending request failed: %s", err)
@@ -400,3 +496,360 @@ func (api *OrderAPIReq) DeleteSitReps(t *testing.T, ctx context.Context, req *re
 
 	return nil, &errw
 }
+
+////
+
+func (api *OrderAPIReq) GetOrderVersions(t *testing.T, ctx context.Context, req *request.GetOrderVersionsRequest) (*response.GetOrderVersionsResponse, errwrap.Error) {
+	t.Helper()
+
+	respHttp, err := api.HttpClient.Get(fmt.Sprintf("%s/v1/mgmt/order/%v/versions", api.BaseURL, req.GetID()))
+	if err != nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "sending request failed: %s", err)
+	}
+	defer respHttp.Body.Close()
+
+	if respHttp.StatusCode == http.StatusOK {
+		var resp response.GetOrderVersionsResponse
+		if err := json.NewDecoder(respHttp.Body).Decode(&resp); err != nil {
+			return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s", err)
+		}
+		return &resp, nil
+	}
+	var errw errwrap.Err
+	if err := json.NewDecoder(respHttp.Body).Decode(&errw); err != nil {
+		rawResponse, _ := httputil.DumpResponse(respHttp, false)
+		return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s\nRaw response: %v", err, string(rawResponse))
+	}
+
+	return nil, &errw
+}
+
+func (api *OrderAPIReq) GetOrderVersion(t *testing.T, ctx context.Context, req *request.GetOrderVersionRequest) (*response.GetOrderVersionResponse, errwrap.Error) {
+	t.Helper()
+
+	respHttp, err := api.HttpClient.Get(fmt.Sprintf("%s/v1/mgmt/order/%v/version/%v", api.BaseURL, req.GetID(), req.GetVersion()))
+	if err != nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "sending request failed: %s", err)
+	}
+	defer respHttp.Body.Close()
+
+	if respHttp.StatusCode == http.StatusOK {
+		var resp response.GetOrderVersionResponse
+		if err := json.NewDecoder(respHttp.Body).Decode(&resp); err != nil {
+			return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s", err)
+		}
+		return &resp, nil
+	}
+	var errw errwrap.Err
+	if err := json.NewDecoder(respHttp.Body).Decode(&errw); err != nil {
+		rawResponse, _ := httputil.DumpResponse(respHttp, false)
+		return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s\nRaw response: %v", err, string(rawResponse))
+	}
+
+	return nil, &errw
+}
+
+func (api *OrderAPIReq) GetOrderDiff(t *testing.T, ctx context.Context, req *request.GetOrderDiffRequest) (*response.GetOrderDiffResponse, errwrap.Error) {
+	t.Helper()
+
+	baseURL, _ := url.Parse(fmt.Sprintf("%s/v1/mgmt/order/%v/diff", api.BaseURL, req.GetID()))
+	params := url.Values{}
+	if req.GetFrom() > 0 {
+		params.Add("from", fmt.Sprint(req.GetFrom()))
+	}
+	if req.GetTo() > 0 {
+		params.Add("to", fmt.Sprint(req.GetTo()))
+	}
+	baseURL.RawQuery = params.Encode()
+
+	respHttp, err := api.HttpClient.Get(baseURL.String())
+	if err != nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "sending request failed: %s", err)
+	}
+	defer respHttp.Body.Close()
+
+	if respHttp.StatusCode == http.StatusOK {
+		var resp response.GetOrderDiffResponse
+		if err := json.NewDecoder(respHttp.Body).Decode(&resp); err != nil {
+			return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s", err)
+		}
+		return &resp, nil
+	}
+	var errw errwrap.Err
+	if err := json.NewDecoder(respHttp.Body).Decode(&errw); err != nil {
+		rawResponse, _ := httputil.DumpResponse(respHttp, false)
+		return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s\nRaw response: %v", err, string(rawResponse))
+	}
+
+	return nil, &errw
+}
+
+func (api *OrderAPIReq) GetOrderTree(t *testing.T, ctx context.Context, req *request.GetOrderTreeRequest) (*response.GetOrderTreeResponse, errwrap.Error) {
+	t.Helper()
+
+	baseURL, _ := url.Parse(fmt.Sprintf("%s/v1/mgmt/order/%v/tree", api.BaseURL, req.GetID()))
+	params := url.Values{}
+	if req.GetDepth() > 0 {
+		params.Add("depth", fmt.Sprint(req.GetDepth()))
+	}
+	baseURL.RawQuery = params.Encode()
+
+	respHttp, err := api.HttpClient.Get(baseURL.String())
+	if err != nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "sending request failed: %s", err)
+	}
+	defer respHttp.Body.Close()
+
+	if respHttp.StatusCode == http.StatusOK {
+		var resp response.GetOrderTreeResponse
+		if err := json.NewDecoder(respHttp.Body).Decode(&resp); err != nil {
+			return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s", err)
+		}
+		return &resp, nil
+	}
+	var errw errwrap.Err
+	if err := json.NewDecoder(respHttp.Body).Decode(&errw); err != nil {
+		rawResponse, _ := httputil.DumpResponse(respHttp, false)
+		return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s\nRaw response: %v", err, string(rawResponse))
+	}
+
+	return nil, &errw
+}
+
+func (api *OrderAPIReq) GetOrderDocument(t *testing.T, ctx context.Context, req *request.GetOrderDocumentRequest) (*response.GetOrderDocumentResponse, errwrap.Error) {
+	t.Helper()
+
+	baseURL, _ := url.Parse(fmt.Sprintf("%s/v1/mgmt/order/%v/document", api.BaseURL, req.GetID()))
+	params := url.Values{}
+	if len(req.GetFormat()) > 0 {
+		params.Add("format", string(req.GetFormat()))
+	}
+	if req.GetSitReps() > 0 {
+		params.Add("sitreps", fmt.Sprint(req.GetSitReps()))
+	}
+	baseURL.RawQuery = params.Encode()
+
+	respHttp, err := api.HttpClient.Get(baseURL.String())
+	if err != nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "sending request failed: %s", err)
+	}
+	defer respHttp.Body.Close()
+
+	if respHttp.StatusCode == http.StatusOK {
+		bs, err := io.ReadAll(respHttp.Body)
+		if err != nil {
+			return nil, errwrap.NewError(http.StatusInternalServerError, "reading response failed: %s", err)
+		}
+		return &response.GetOrderDocumentResponse{
+			ContentType: respHttp.Header.Get("Content-Type"),
+			Document:    string(bs),
+		}, nil
+	}
+	var errw errwrap.Err
+	if err := json.NewDecoder(respHttp.Body).Decode(&errw); err != nil {
+		rawResponse, _ := httputil.DumpResponse(respHttp, false)
+		return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s\nRaw response: %v", err, string(rawResponse))
+	}
+
+	return nil, &errw
+}
+
+func (api *OrderAPIReq) GetOverdueReport(t *testing.T, ctx context.Context, req *request.GetOverdueReportRequest) (*response.GetOverdueReportResponse, errwrap.Error) {
+	t.Helper()
+
+	baseURL, _ := url.Parse(fmt.Sprintf("%s/v1/mgmt/reports/overdue", api.BaseURL))
+	params := url.Values{}
+	if len(req.GetRootOrderID()) > 0 {
+		params.Add("root_order_id", string(req.GetRootOrderID()))
+	}
+	if len(req.GetSupervisor()) > 0 {
+		params.Add("supervisor", string(req.GetSupervisor()))
+	}
+	if req.GetDays() > 0 {
+		params.Add("days", fmt.Sprint(req.GetDays()))
+	}
+	baseURL.RawQuery = params.Encode()
+
+	respHttp, err := api.HttpClient.Get(baseURL.String())
+	if err != nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "sending request failed: %s", err)
+	}
+	defer respHttp.Body.Close()
+
+	if respHttp.StatusCode == http.StatusOK {
+		var resp response.GetOverdueReportResponse
+		if err := json.NewDecoder(respHttp.Body).Decode(&resp); err != nil {
+			return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s", err)
+		}
+		return &resp, nil
+	}
+	var errw errwrap.Err
+	if err := json.NewDecoder(respHttp.Body).Decode(&errw); err != nil {
+		rawResponse, _ := httputil.DumpResponse(respHttp, false)
+		return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s\nRaw response: %v", err, string(rawResponse))
+	}
+
+	return nil, &errw
+}
+
+func (api *OrderAPIReq) Search(t *testing.T, ctx context.Context, req *request.SearchRequest) (*response.SearchResponse, errwrap.Error) {
+	t.Helper()
+
+	baseURL, _ := url.Parse(fmt.Sprintf("%s/v1/mgmt/search", api.BaseURL))
+	params := url.Values{}
+	params.Add("q", req.GetQuery())
+	if req.GetLimit() > 0 {
+		params.Add("limit", fmt.Sprint(req.GetLimit()))
+	}
+	baseURL.RawQuery = params.Encode()
+
+	respHttp, err := api.HttpClient.Get(baseURL.String())
+	if err != nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "sending request failed: %s", err)
+	}
+	defer respHttp.Body.Close()
+
+	if respHttp.StatusCode == http.StatusOK {
+		var resp response.SearchResponse
+		if err := json.NewDecoder(respHttp.Body).Decode(&resp); err != nil {
+			return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s", err)
+		}
+		return &resp, nil
+	}
+	var errw errwrap.Err
+	if err := json.NewDecoder(respHttp.Body).Decode(&errw); err != nil {
+		rawResponse, _ := httputil.DumpResponse(respHttp, false)
+		return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s\nRaw response: %v", err, string(rawResponse))
+	}
+
+	return nil, &errw
+}
+
+////
+
+func (api *OrderAPIReq) GetDeletedOrders(t *testing.T, ctx context.Context, req *request.GetDeletedOrdersRequest) (*response.GetDeletedOrdersResponse, errwrap.Error) {
+	t.Helper()
+
+	respHttp, err := api.HttpClient.Get(fmt.Sprintf("%s/v1/mgmt/trash/orders", api.BaseURL))
+	if err != nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "sending request failed: %s", err)
+	}
+	defer respHttp.Body.Close()
+
+	if respHttp.StatusCode == http.StatusOK {
+		var resp response.GetDeletedOrdersResponse
+		if err := json.NewDecoder(respHttp.Body).Decode(&resp); err != nil {
+			return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s", err)
+		}
+		return &resp, nil
+	}
+	var errw errwrap.Err
+	if err := json.NewDecoder(respHttp.Body).Decode(&errw); err != nil {
+		rawResponse, _ := httputil.DumpResponse(respHttp, false)
+		return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s\nRaw response: %v", err, string(rawResponse))
+	}
+
+	return nil, &errw
+}
+
+func (api *OrderAPIReq) RestoreOrder(t *testing.T, ctx context.Context, req *request.RestoreOrderRequest) (*response.RestoreOrderResponse, errwrap.Error) {
+	t.Helper()
+
+	reqHttp, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/v1/mgmt/order/%v/restore", api.BaseURL, req.GetID()), nil)
+	if err != nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "new request failed: %s", err)
+	}
+
+	respHttp, err := api.HttpClient.Do(reqHttp)
+	if err != nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "sending request failed: %s", err)
+	}
+	defer respHttp.Body.Close()
+
+	if respHttp.StatusCode == http.StatusOK {
+		var resp response.RestoreOrderResponse
+		if err := json.NewDecoder(respHttp.Body).Decode(&resp); err != nil {
+			return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s", err)
+		}
+		return &resp, nil
+	}
+	var errw errwrap.Err
+	if err := json.NewDecoder(respHttp.Body).Decode(&errw); err != nil {
+		rawResponse, _ := httputil.DumpResponse(respHttp, false)
+		return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s\nRaw response: %v", err, string(rawResponse))
+	}
+
+	return nil, &errw
+}
+
+func (api *OrderAPIReq) RestoreSitReps(t *testing.T, ctx context.Context, req *request.RestoreSitRepsRequest) (*response.RestoreSitRepsResponse, errwrap.Error) {
+	t.Helper()
+
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, errwrap.NewError(http.StatusBadRequest, "marshaling request failed: %s", err)
+	}
+
+	reqHttp, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/v1/mgmt/order/%v/sitrep/restore", api.BaseURL, req.GetOrderID()), bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "new request failed: %s", err)
+	}
+
+	respHttp, err := api.HttpClient.Do(reqHttp)
+	if err != nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "sending request failed: %s", err)
+	}
+	defer respHttp.Body.Close()
+
+	if respHttp.StatusCode == http.StatusOK {
+		var resp response.RestoreSitRepsResponse
+		if err := json.NewDecoder(respHttp.Body).Decode(&resp); err != nil {
+			return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s", err)
+		}
+		return &resp, nil
+	}
+	var errw errwrap.Err
+	if err := json.NewDecoder(respHttp.Body).Decode(&errw); err != nil {
+		rawResponse, _ := httputil.DumpResponse(respHttp, false)
+		return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s\nRaw response: %v", err, string(rawResponse))
+	}
+
+	return nil, &errw
+}
+
+func (api *OrderAPIReq) PurgeOrders(t *testing.T, ctx context.Context, req *request.PurgeOrdersRequest) (*response.PurgeOrdersResponse, errwrap.Error) {
+	t.Helper()
+
+	baseURL, _ := url.Parse(fmt.Sprintf("%s/v1/mgmt/trash/orders", api.BaseURL))
+	params := url.Values{}
+	if !req.GetBefore().IsZero() {
+		params.Add("before", req.GetBefore().Format(time.RFC3339Nano))
+	}
+	baseURL.RawQuery = params.Encode()
+
+	reqHttp, err := http.NewRequest(http.MethodDelete, baseURL.String(), nil)
+	if err != nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "new request failed: %s", err)
+	}
+
+	respHttp, err := api.HttpClient.Do(reqHttp)
+	if err != nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "sending request failed: %s", err)
+	}
+	defer respHttp.Body.Close()
+
+	if respHttp.StatusCode == http.StatusOK {
+		var resp response.PurgeOrdersResponse
+		if err := json.NewDecoder(respHttp.Body).Decode(&resp); err != nil {
+			return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s", err)
+		}
+		return &resp, nil
+	}
+	var errw errwrap.Err
+	if err := json.NewDecoder(respHttp.Body).Decode(&errw); err != nil {
+		rawResponse, _ := httputil.DumpResponse(respHttp, false)
+		return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s\nRaw response: %v", err, string(rawResponse))
+	}
+
+	return nil, &errw
+}
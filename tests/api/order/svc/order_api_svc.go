@@ -7,6 +7,7 @@ import (
 	"github.com/moledoc/orderly/internal/domain/errwrap"
 	"github.com/moledoc/orderly/internal/domain/request"
 	"github.com/moledoc/orderly/internal/domain/response"
+	"github.com/moledoc/orderly/internal/repository"
 	"github.com/moledoc/orderly/internal/repository/local"
 	"github.com/moledoc/orderly/internal/service/mgmtorder"
 	"github.com/moledoc/orderly/tests/api"
@@ -17,9 +18,18 @@ type OrderAPISvc struct { // NOTE: tests service layer methods
 }
 
 func NewOrderAPISvc() *OrderAPISvc {
-	// TODO: local vs db
+	return NewOrderAPISvcWithRepository(local.NewLocalRepositoryOrder())
+}
+
+func NewOrderAPISvcWithRepository(repo repository.RepositoryOrderAPI) *OrderAPISvc {
+	return &OrderAPISvc{
+		Svc: mgmtorder.NewServiceMgmtOrder(repo, nil, nil),
+	}
+}
+
+func NewOrderAPISvcWithUserLookup(users mgmtorder.UserLookup) *OrderAPISvc {
 	return &OrderAPISvc{
-		Svc: mgmtorder.NewServiceMgmtOrder(local.NewLocalRepositoryOrder()),
+		Svc: mgmtorder.NewServiceMgmtOrder(local.NewLocalRepositoryOrder(), users, nil),
 	}
 }
 
@@ -52,6 +62,11 @@ func (api *OrderAPISvc) DeleteOrder(t *testing.T, ctx context.Context, req *requ
 	return api.Svc.DeleteOrder(ctx, req)
 }
 
+func (api *OrderAPISvc) MoveOrder(t *testing.T, ctx context.Context, req *request.MoveOrderRequest) (*response.MoveOrderResponse, errwrap.Error) {
+	t.Helper()
+	return api.Svc.MoveOrder(ctx, req)
+}
+
 ////
 
 func (api *OrderAPISvc) PutDelegatedTasks(t *testing.T, ctx context.Context, req *request.PutDelegatedTasksRequest) (*response.PutDelegatedTasksResponse, errwrap.Error) {
@@ -85,3 +100,62 @@ func (api *OrderAPISvc) DeleteSitReps(t *testing.T, ctx context.Context, req *re
 	t.Helper()
 	return api.Svc.DeleteSitReps(ctx, req)
 }
+
+////
+
+func (api *OrderAPISvc) GetOrderVersions(t *testing.T, ctx context.Context, req *request.GetOrderVersionsRequest) (*response.GetOrderVersionsResponse, errwrap.Error) {
+	t.Helper()
+	return api.Svc.GetOrderVersions(ctx, req)
+}
+
+func (api *OrderAPISvc) GetOrderVersion(t *testing.T, ctx context.Context, req *request.GetOrderVersionRequest) (*response.GetOrderVersionResponse, errwrap.Error) {
+	t.Helper()
+	return api.Svc.GetOrderVersion(ctx, req)
+}
+
+func (api *OrderAPISvc) GetOrderDiff(t *testing.T, ctx context.Context, req *request.GetOrderDiffRequest) (*response.GetOrderDiffResponse, errwrap.Error) {
+	t.Helper()
+	return api.Svc.GetOrderDiff(ctx, req)
+}
+
+func (api *OrderAPISvc) GetOrderTree(t *testing.T, ctx context.Context, req *request.GetOrderTreeRequest) (*response.GetOrderTreeResponse, errwrap.Error) {
+	t.Helper()
+	return api.Svc.GetOrderTree(ctx, req)
+}
+
+func (api *OrderAPISvc) GetOrderDocument(t *testing.T, ctx context.Context, req *request.GetOrderDocumentRequest) (*response.GetOrderDocumentResponse, errwrap.Error) {
+	t.Helper()
+	return api.Svc.GetOrderDocument(ctx, req)
+}
+
+func (api *OrderAPISvc) GetOverdueReport(t *testing.T, ctx context.Context, req *request.GetOverdueReportRequest) (*response.GetOverdueReportResponse, errwrap.Error) {
+	t.Helper()
+	return api.Svc.GetOverdueReport(ctx, req)
+}
+
+func (api *OrderAPISvc) Search(t *testing.T, ctx context.Context, req *request.SearchRequest) (*response.SearchResponse, errwrap.Error) {
+	t.Helper()
+	return api.Svc.Search(ctx, req)
+}
+
+////
+
+func (api *OrderAPISvc) GetDeletedOrders(t *testing.T, ctx context.Context, req *request.GetDeletedOrdersRequest) (*response.GetDeletedOrdersResponse, errwrap.Error) {
+	t.Helper()
+	return api.Svc.GetDeletedOrders(ctx, req)
+}
+
+func (api *OrderAPISvc) RestoreOrder(t *testing.T, ctx context.Context, req *request.RestoreOrderRequest) (*response.RestoreOrderResponse, errwrap.Error) {
+	t.Helper()
+	return api.Svc.RestoreOrder(ctx, req)
+}
+
+func (api *OrderAPISvc) RestoreSitReps(t *testing.T, ctx context.Context, req *request.RestoreSitRepsRequest) (*response.RestoreSitRepsResponse, errwrap.Error) {
+	t.Helper()
+	return api.Svc.RestoreSitReps(ctx, req)
+}
+
+func (api *OrderAPISvc) PurgeOrders(t *testing.T, ctx context.Context, req *request.PurgeOrdersRequest) (*response.PurgeOrdersResponse, errwrap.Error) {
+	t.Helper()
+	return api.Svc.PurgeOrders(ctx, req)
+}
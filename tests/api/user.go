@@ -15,4 +15,14 @@ type User interface {
 	GetUsers(t *testing.T, ctx context.Context, req *request.GetUsersRequest) (*response.GetUsersResponse, errwrap.Error)
 	PatchUser(t *testing.T, ctx context.Context, req *request.PatchUserRequest) (*response.PatchUserResponse, errwrap.Error)
 	DeleteUser(t *testing.T, ctx context.Context, req *request.DeleteUserRequest) (*response.DeleteUserResponse, errwrap.Error)
+	////
+	GetUserVersions(t *testing.T, ctx context.Context, req *request.GetUserVersionsRequest) (*response.GetUserVersionsResponse, errwrap.Error)
+	GetUserVersion(t *testing.T, ctx context.Context, req *request.GetUserVersionRequest) (*response.GetUserVersionResponse, errwrap.Error)
+	////
+	GetUserChain(t *testing.T, ctx context.Context, req *request.GetUserChainRequest) (*response.GetUserChainResponse, errwrap.Error)
+	GetUserSubordinates(t *testing.T, ctx context.Context, req *request.GetUserSubordinatesRequest) (*response.GetUserSubordinatesResponse, errwrap.Error)
+	////
+	GetDeletedUsers(t *testing.T, ctx context.Context, req *request.GetDeletedUsersRequest) (*response.GetDeletedUsersResponse, errwrap.Error)
+	RestoreUser(t *testing.T, ctx context.Context, req *request.RestoreUserRequest) (*response.RestoreUserResponse, errwrap.Error)
+	PurgeUsers(t *testing.T, ctx context.Context, req *request.PurgeUsersRequest) (*response.PurgeUsersResponse, errwrap.Error)
 }
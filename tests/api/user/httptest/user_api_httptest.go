@@ -10,6 +10,7 @@ import (
 	"net/http/httputil"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/moledoc/orderly/internal/domain/errwrap"
 	"github.com/moledoc/orderly/internal/domain/request"
@@ -103,6 +104,15 @@ func (api *UserAPIHTTPTest) GetUsers(t *testing.T, ctx context.Context, req *req
 	if len(req.GetSupervisor()) > 0 {
 		params.Add("supervisor", string(req.GetSupervisor()))
 	}
+	if req.GetLimit() > 0 {
+		params.Add("limit", fmt.Sprint(req.GetLimit()))
+	}
+	if len(req.GetCursor()) > 0 {
+		params.Add("cursor", req.GetCursor())
+	}
+	if len(req.GetSort()) > 0 {
+		params.Add("sort", string(req.GetSort()))
+	}
 	baseURL.RawQuery = params.Encode()
 	reqHttp := httptest.NewRequest(http.MethodGet, baseURL.String(), nil)
 
@@ -137,6 +147,9 @@ func (api *UserAPIHTTPTest) PatchUser(t *testing.T, ctx context.Context, req *re
 
 	reqHttp := httptest.NewRequest(http.MethodPatch, "/v1/mgmt/user", bytes.NewBuffer(reqBytes))
 	reqHttp.Header.Set("Content-Type", "application/json")
+	if req.GetExpectedVersion() > 0 {
+		reqHttp.Header.Set("If-Match", fmt.Sprintf("\"%v\"", req.GetExpectedVersion()))
+	}
 
 	rr := httptest.NewRecorder()
 	api.Mux.ServeHTTP(rr, reqHttp)
@@ -180,3 +193,203 @@ func (api *UserAPIHTTPTest) DeleteUser(t *testing.T, ctx context.Context, req *r
 
 	return nil, &errw
 }
+
+////
+
+func (api *UserAPIHTTPTest) GetUserVersions(t *testing.T, ctx context.Context, req *request.GetUserVersionsRequest) (*response.GetUserVersionsResponse, errwrap.Error) {
+	t.Helper()
+
+	reqHttp := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/v1/mgmt/user/%v/versions", req.GetID()), nil)
+
+	rr := httptest.NewRecorder()
+	api.Mux.ServeHTTP(rr, reqHttp)
+	respHttp := rr.Result()
+	defer respHttp.Body.Close()
+
+	if respHttp.StatusCode == http.StatusOK {
+		var resp response.GetUserVersionsResponse
+		if err := json.NewDecoder(respHttp.Body).Decode(&resp); err != nil {
+			return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s", err)
+		}
+		return &resp, nil
+	}
+	var errw errwrap.Err
+	if err := json.NewDecoder(respHttp.Body).Decode(&errw); err != nil {
+		rawResponse, _ := httputil.DumpResponse(respHttp, false)
+		return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s\nRaw response: %v", err, string(rawResponse))
+	}
+
+	return nil, &errw
+}
+
+func (api *UserAPIHTTPTest) GetUserVersion(t *testing.T, ctx context.Context, req *request.GetUserVersionRequest) (*response.GetUserVersionResponse, errwrap.Error) {
+	t.Helper()
+
+	reqHttp := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/v1/mgmt/user/%v/version/%v", req.GetID(), req.GetVersion()), nil)
+
+	rr := httptest.NewRecorder()
+	api.Mux.ServeHTTP(rr, reqHttp)
+	respHttp := rr.Result()
+	defer respHttp.Body.Close()
+
+	if respHttp.StatusCode == http.StatusOK {
+		var resp response.GetUserVersionResponse
+		if err := json.NewDecoder(respHttp.Body).Decode(&resp); err != nil {
+			return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s", err)
+		}
+		return &resp, nil
+	}
+	var errw errwrap.Err
+	if err := json.NewDecoder(respHttp.Body).Decode(&errw); err != nil {
+		rawResponse, _ := httputil.DumpResponse(respHttp, false)
+		return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s\nRaw response: %v", err, string(rawResponse))
+	}
+
+	return nil, &errw
+}
+
+////
+
+func (api *UserAPIHTTPTest) GetDeletedUsers(t *testing.T, ctx context.Context, req *request.GetDeletedUsersRequest) (*response.GetDeletedUsersResponse, errwrap.Error) {
+	t.Helper()
+
+	reqHttp := httptest.NewRequest(http.MethodGet, "/v1/mgmt/trash/users", nil)
+
+	rr := httptest.NewRecorder()
+	api.Mux.ServeHTTP(rr, reqHttp)
+	respHttp := rr.Result()
+	defer respHttp.Body.Close()
+
+	if respHttp.StatusCode == http.StatusOK {
+		var resp response.GetDeletedUsersResponse
+		if err := json.NewDecoder(respHttp.Body).Decode(&resp); err != nil {
+			return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s", err)
+		}
+		return &resp, nil
+	}
+	var errw errwrap.Err
+	if err := json.NewDecoder(respHttp.Body).Decode(&errw); err != nil {
+		rawResponse, _ := httputil.DumpResponse(respHttp, false)
+		return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s\nRaw response: %v", err, string(rawResponse))
+	}
+
+	return nil, &errw
+}
+
+func (api *UserAPIHTTPTest) GetUserChain(t *testing.T, ctx context.Context, req *request.GetUserChainRequest) (*response.GetUserChainResponse, errwrap.Error) {
+	t.Helper()
+
+	reqHttp := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/v1/mgmt/user/%v/chain", req.GetID()), nil)
+
+	rr := httptest.NewRecorder()
+	api.Mux.ServeHTTP(rr, reqHttp)
+	respHttp := rr.Result()
+	defer respHttp.Body.Close()
+
+	if respHttp.StatusCode == http.StatusOK {
+		var resp response.GetUserChainResponse
+		if err := json.NewDecoder(respHttp.Body).Decode(&resp); err != nil {
+			return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s", err)
+		}
+		return &resp, nil
+	}
+	var errw errwrap.Err
+	if err := json.NewDecoder(respHttp.Body).Decode(&errw); err != nil {
+		rawResponse, _ := httputil.DumpResponse(respHttp, false)
+		return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s\nRaw response: %v", err, string(rawResponse))
+	}
+
+	return nil, &errw
+}
+
+func (api *UserAPIHTTPTest) GetUserSubordinates(t *testing.T, ctx context.Context, req *request.GetUserSubordinatesRequest) (*response.GetUserSubordinatesResponse, errwrap.Error) {
+	t.Helper()
+
+	baseURL, _ := url.Parse(fmt.Sprintf("/v1/mgmt/user/%v/subordinates", req.GetID()))
+	params := url.Values{}
+	if req.GetDepth() > 0 {
+		params.Add("depth", fmt.Sprint(req.GetDepth()))
+	}
+	baseURL.RawQuery = params.Encode()
+
+	reqHttp := httptest.NewRequest(http.MethodGet, baseURL.String(), nil)
+
+	rr := httptest.NewRecorder()
+	api.Mux.ServeHTTP(rr, reqHttp)
+	respHttp := rr.Result()
+	defer respHttp.Body.Close()
+
+	if respHttp.StatusCode == http.StatusOK {
+		var resp response.GetUserSubordinatesResponse
+		if err := json.NewDecoder(respHttp.Body).Decode(&resp); err != nil {
+			return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s", err)
+		}
+		return &resp, nil
+	}
+	var errw errwrap.Err
+	if err := json.NewDecoder(respHttp.Body).Decode(&errw); err != nil {
+		rawResponse, _ := httputil.DumpResponse(respHttp, false)
+		return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s\nRaw response: %v", err, string(rawResponse))
+	}
+
+	return nil, &errw
+}
+
+func (api *UserAPIHTTPTest) RestoreUser(t *testing.T, ctx context.Context, req *request.RestoreUserRequest) (*response.RestoreUserResponse, errwrap.Error) {
+	t.Helper()
+
+	reqHttp := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/v1/mgmt/user/%v/restore", req.GetID()), nil)
+
+	rr := httptest.NewRecorder()
+	api.Mux.ServeHTTP(rr, reqHttp)
+	respHttp := rr.Result()
+	defer respHttp.Body.Close()
+
+	if respHttp.StatusCode == http.StatusOK {
+		var resp response.RestoreUserResponse
+		if err := json.NewDecoder(respHttp.Body).Decode(&resp); err != nil {
+			return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s", err)
+		}
+		return &resp, nil
+	}
+	var errw errwrap.Err
+	if err := json.NewDecoder(respHttp.Body).Decode(&errw); err != nil {
+		rawResponse, _ := httputil.DumpResponse(respHttp, false)
+		return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s\nRaw response: %v", err, string(rawResponse))
+	}
+
+	return nil, &errw
+}
+
+func (api *UserAPIHTTPTest) PurgeUsers(t *testing.T, ctx context.Context, req *request.PurgeUsersRequest) (*response.PurgeUsersResponse, errwrap.Error) {
+	t.Helper()
+
+	baseURL, _ := url.Parse("/v1/mgmt/trash/users")
+	params := url.Values{}
+	if !req.GetBefore().IsZero() {
+		params.Add("before", req.GetBefore().Format(time.RFC3339Nano))
+	}
+	baseURL.RawQuery = params.Encode()
+
+	reqHttp := httptest.NewRequest(http.MethodDelete, baseURL.String(), nil)
+
+	rr := httptest.NewRecorder()
+	api.Mux.ServeHTTP(rr, reqHttp)
+	respHttp := rr.Result()
+	defer respHttp.Body.Close()
+
+	if respHttp.StatusCode == http.StatusOK {
+		var resp response.PurgeUsersResponse
+		if err := json.NewDecoder(respHttp.Body).Decode(&resp); err != nil {
+			return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s", err)
+		}
+		return &resp, nil
+	}
+	var errw errwrap.Err
+	if err := json.NewDecoder(respHttp.Body).Decode(&errw); err != nil {
+		rawResponse, _ := httputil.DumpResponse(respHttp, false)
+		return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s\nRaw response: %v", err, string(rawResponse))
+	}
+
+	return nil, &errw
+}
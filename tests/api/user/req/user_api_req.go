@@ -9,6 +9,7 @@ import (
 	"net/http/httputil"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/moledoc/orderly/internal/domain/errwrap"
 	"github.com/moledoc/orderly/internal/domain/request"
@@ -102,6 +103,15 @@ func (api *UserAPIReq) GetUsers(t *testing.T, ctx context.Context, req *request.
 	if len(req.GetSupervisor()) > 0 {
 		params.Add("supervisor", string(req.GetSupervisor()))
 	}
+	if req.GetLimit() > 0 {
+		params.Add("limit", fmt.Sprint(req.GetLimit()))
+	}
+	if len(req.GetCursor()) > 0 {
+		params.Add("cursor", req.GetCursor())
+	}
+	if len(req.GetSort()) > 0 {
+		params.Add("sort", string(req.GetSort()))
+	}
 	baseURL.RawQuery = params.Encode()
 
 	respHttp, err := api.HttpClient.Get(baseURL.String())
@@ -138,6 +148,9 @@ func (api *UserAPIReq) PatchUser(t *testing.T, ctx context.Context, req *request
 		return nil, errwrap.NewError(http.StatusInternalServerError, "new request failed: %s", err)
 	}
 	reqHttp.Header.Set("Content-Type", "application/json")
+	if req.GetExpectedVersion() > 0 {
+		reqHttp.Header.Set("If-Match", fmt.Sprintf("\"%v\"", req.GetExpectedVersion()))
+	}
 	respHttp, err := api.HttpClient.Do(reqHttp)
 	if err != nil {
 		return nil, errwrap.NewError(http.StatusInternalServerError, "sending request failed: %s", err)
@@ -185,3 +198,206 @@ func (api *UserAPIReq) DeleteUser(t *testing.T, ctx context.Context, req *reques
 
 	return nil, &errw
 }
+
+////
+
+func (api *UserAPIReq) GetUserVersions(t *testing.T, ctx context.Context, req *request.GetUserVersionsRequest) (*response.GetUserVersionsResponse, errwrap.Error) {
+	t.Helper()
+
+	respHttp, err := api.HttpClient.Get(fmt.Sprintf("%s/v1/mgmt/user/%v/versions", api.BaseURL, req.GetID()))
+	if err != nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "sending request failed: %s", err)
+	}
+	defer respHttp.Body.Close()
+
+	if respHttp.StatusCode == http.StatusOK {
+		var resp response.GetUserVersionsResponse
+		if err := json.NewDecoder(respHttp.Body).Decode(&resp); err != nil {
+			return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s", err)
+		}
+		return &resp, nil
+	}
+	var errw errwrap.Err
+	if err := json.NewDecoder(respHttp.Body).Decode(&errw); err != nil {
+		rawResponse, _ := httputil.DumpResponse(respHttp, false)
+		return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s\nRaw response: %v", err, string(rawResponse))
+	}
+
+	return nil, &errw
+}
+
+func (api *UserAPIReq) GetUserVersion(t *testing.T, ctx context.Context, req *request.GetUserVersionRequest) (*response.GetUserVersionResponse, errwrap.Error) {
+	t.Helper()
+
+	respHttp, err := api.HttpClient.Get(fmt.Sprintf("%s/v1/mgmt/user/%v/version/%v", api.BaseURL, req.GetID(), req.GetVersion()))
+	if err != nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "sending request failed: %s", err)
+	}
+	defer respHttp.Body.Close()
+
+	if respHttp.StatusCode == http.StatusOK {
+		var resp response.GetUserVersionResponse
+		if err := json.NewDecoder(respHttp.Body).Decode(&resp); err != nil {
+			return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s", err)
+		}
+		return &resp, nil
+	}
+	var errw errwrap.Err
+	if err := json.NewDecoder(respHttp.Body).Decode(&errw); err != nil {
+		rawResponse, _ := httputil.DumpResponse(respHttp, false)
+		return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s\nRaw response: %v", err, string(rawResponse))
+	}
+
+	return nil, &errw
+}
+
+////
+
+func (api *UserAPIReq) GetDeletedUsers(t *testing.T, ctx context.Context, req *request.GetDeletedUsersRequest) (*response.GetDeletedUsersResponse, errwrap.Error) {
+	t.Helper()
+
+	respHttp, err := api.HttpClient.Get(fmt.Sprintf("%s/v1/mgmt/trash/users", api.BaseURL))
+	if err != nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "sending request failed: %s", err)
+	}
+	defer respHttp.Body.Close()
+
+	if respHttp.StatusCode == http.StatusOK {
+		var resp response.GetDeletedUsersResponse
+		if err := json.NewDecoder(respHttp.Body).Decode(&resp); err != nil {
+			return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s", err)
+		}
+		return &resp, nil
+	}
+	var errw errwrap.Err
+	if err := json.NewDecoder(respHttp.Body).Decode(&errw); err != nil {
+		rawResponse, _ := httputil.DumpResponse(respHttp, false)
+		return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s\nRaw response: %v", err, string(rawResponse))
+	}
+
+	return nil, &errw
+}
+
+func (api *UserAPIReq) GetUserChain(t *testing.T, ctx context.Context, req *request.GetUserChainRequest) (*response.GetUserChainResponse, errwrap.Error) {
+	t.Helper()
+
+	respHttp, err := api.HttpClient.Get(fmt.Sprintf("%s/v1/mgmt/user/%v/chain", api.BaseURL, req.GetID()))
+	if err != nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "sending request failed: %s", err)
+	}
+	defer respHttp.Body.Close()
+
+	if respHttp.StatusCode == http.StatusOK {
+		var resp response.GetUserChainResponse
+		if err := json.NewDecoder(respHttp.Body).Decode(&resp); err != nil {
+			return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s", err)
+		}
+		return &resp, nil
+	}
+	var errw errwrap.Err
+	if err := json.NewDecoder(respHttp.Body).Decode(&errw); err != nil {
+		rawResponse, _ := httputil.DumpResponse(respHttp, false)
+		return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s\nRaw response: %v", err, string(rawResponse))
+	}
+
+	return nil, &errw
+}
+
+func (api *UserAPIReq) GetUserSubordinates(t *testing.T, ctx context.Context, req *request.GetUserSubordinatesRequest) (*response.GetUserSubordinatesResponse, errwrap.Error) {
+	t.Helper()
+
+	baseURL, _ := url.Parse(fmt.Sprintf("%s/v1/mgmt/user/%v/subordinates", api.BaseURL, req.GetID()))
+	params := url.Values{}
+	if req.GetDepth() > 0 {
+		params.Add("depth", fmt.Sprint(req.GetDepth()))
+	}
+	baseURL.RawQuery = params.Encode()
+
+	respHttp, err := api.HttpClient.Get(baseURL.String())
+	if err != nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "sending request failed: %s", err)
+	}
+	defer respHttp.Body.Close()
+
+	if respHttp.StatusCode == http.StatusOK {
+		var resp response.GetUserSubordinatesResponse
+		if err := json.NewDecoder(respHttp.Body).Decode(&resp); err != nil {
+			return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s", err)
+		}
+		return &resp, nil
+	}
+	var errw errwrap.Err
+	if err := json.NewDecoder(respHttp.Body).Decode(&errw); err != nil {
+		rawResponse, _ := httputil.DumpResponse(respHttp, false)
+		return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s\nRaw response: %v", err, string(rawResponse))
+	}
+
+	return nil, &errw
+}
+
+func (api *UserAPIReq) RestoreUser(t *testing.T, ctx context.Context, req *request.RestoreUserRequest) (*response.RestoreUserResponse, errwrap.Error) {
+	t.Helper()
+
+	reqHttp, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/v1/mgmt/user/%v/restore", api.BaseURL, req.GetID()), nil)
+	if err != nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "new request failed: %s", err)
+	}
+
+	respHttp, err := api.HttpClient.Do(reqHttp)
+	if err != nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "sending request failed: %s", err)
+	}
+	defer respHttp.Body.Close()
+
+	if respHttp.StatusCode == http.StatusOK {
+		var resp response.RestoreUserResponse
+		if err := json.NewDecoder(respHttp.Body).Decode(&resp); err != nil {
+			return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s", err)
+		}
+		return &resp, nil
+	}
+	var errw errwrap.Err
+	if err := json.NewDecoder(respHttp.Body).Decode(&errw); err != nil {
+		rawResponse, _ := httputil.DumpResponse(respHttp, false)
+		return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s\nRaw response: %v", err, string(rawResponse))
+	}
+
+	return nil, &errw
+}
+
+func (api *UserAPIReq) PurgeUsers(t *testing.T, ctx context.Context, req *request.PurgeUsersRequest) (*response.PurgeUsersResponse, errwrap.Error) {
+	t.Helper()
+
+	baseURL, _ := url.Parse(fmt.Sprintf("%s/v1/mgmt/trash/users", api.BaseURL))
+	params := url.Values{}
+	if !req.GetBefore().IsZero() {
+		params.Add("before", req.GetBefore().Format(time.RFC3339Nano))
+	}
+	baseURL.RawQuery = params.Encode()
+
+	reqHttp, err := http.NewRequest(http.MethodDelete, baseURL.String(), nil)
+	if err != nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "new request failed: %s", err)
+	}
+
+	respHttp, err := api.HttpClient.Do(reqHttp)
+	if err != nil {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "sending request failed: %s", err)
+	}
+	defer respHttp.Body.Close()
+
+	if respHttp.StatusCode == http.StatusOK {
+		var resp response.PurgeUsersResponse
+		if err := json.NewDecoder(respHttp.Body).Decode(&resp); err != nil {
+			return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s", err)
+		}
+		return &resp, nil
+	}
+	var errw errwrap.Err
+	if err := json.NewDecoder(respHttp.Body).Decode(&errw); err != nil {
+		rawResponse, _ := httputil.DumpResponse(respHttp, false)
+		return nil, errwrap.NewError(http.StatusInternalServerError, "unmarshaling response failed: %s\nRaw response: %v", err, string(rawResponse))
+	}
+
+	return nil, &errw
+}
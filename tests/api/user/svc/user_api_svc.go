@@ -7,6 +7,7 @@ import (
 	"github.com/moledoc/orderly/internal/domain/errwrap"
 	"github.com/moledoc/orderly/internal/domain/request"
 	"github.com/moledoc/orderly/internal/domain/response"
+	"github.com/moledoc/orderly/internal/repository"
 	"github.com/moledoc/orderly/internal/repository/local"
 	"github.com/moledoc/orderly/internal/service/mgmtuser"
 	"github.com/moledoc/orderly/tests/api"
@@ -17,9 +18,12 @@ type UserAPISvc struct { // NOTE: tests service layer methods
 }
 
 func NewUserAPISvc() *UserAPISvc {
-	// TODO: local vs db
+	return NewUserAPISvcWithRepository(local.NewLocalRepositoryUser())
+}
+
+func NewUserAPISvcWithRepository(repo repository.RepositoryUserAPI) *UserAPISvc {
 	return &UserAPISvc{
-		Svc: mgmtuser.NewServiceMgmtUser(local.NewLocalRepositoryUser()),
+		Svc: mgmtuser.NewServiceMgmtUser(repo, nil),
 	}
 }
 
@@ -50,3 +54,42 @@ func (api *UserAPISvc) DeleteUser(t *testing.T, ctx context.Context, req *reques
 	t.Helper()
 	return api.Svc.DeleteUser(ctx, req)
 }
+
+////
+
+func (api *UserAPISvc) GetUserVersions(t *testing.T, ctx context.Context, req *request.GetUserVersionsRequest) (*response.GetUserVersionsResponse, errwrap.Error) {
+	t.Helper()
+	return api.Svc.GetUserVersions(ctx, req)
+}
+
+func (api *UserAPISvc) GetUserVersion(t *testing.T, ctx context.Context, req *request.GetUserVersionRequest) (*response.GetUserVersionResponse, errwrap.Error) {
+	t.Helper()
+	return api.Svc.GetUserVersion(ctx, req)
+}
+
+////
+
+func (api *UserAPISvc) GetDeletedUsers(t *testing.T, ctx context.Context, req *request.GetDeletedUsersRequest) (*response.GetDeletedUsersResponse, errwrap.Error) {
+	t.Helper()
+	return api.Svc.GetDeletedUsers(ctx, req)
+}
+
+func (api *UserAPISvc) GetUserChain(t *testing.T, ctx context.Context, req *request.GetUserChainRequest) (*response.GetUserChainResponse, errwrap.Error) {
+	t.Helper()
+	return api.Svc.GetUserChain(ctx, req)
+}
+
+func (api *UserAPISvc) GetUserSubordinates(t *testing.T, ctx context.Context, req *request.GetUserSubordinatesRequest) (*response.GetUserSubordinatesResponse, errwrap.Error) {
+	t.Helper()
+	return api.Svc.GetUserSubordinates(ctx, req)
+}
+
+func (api *UserAPISvc) RestoreUser(t *testing.T, ctx context.Context, req *request.RestoreUserRequest) (*response.RestoreUserResponse, errwrap.Error) {
+	t.Helper()
+	return api.Svc.RestoreUser(ctx, req)
+}
+
+func (api *UserAPISvc) PurgeUsers(t *testing.T, ctx context.Context, req *request.PurgeUsersRequest) (*response.PurgeUsersResponse, errwrap.Error) {
+	t.Helper()
+	return api.Svc.PurgeUsers(ctx, req)
+}
@@ -18,7 +18,8 @@ func Order(t *testing.T, api api.Order, o *order.Order) {
 			return
 		}
 		_, err := api.DeleteOrder(t, context.Background(), &request.DeleteOrderRequest{
-			ID: id,
+			ID:      id,
+			Cascade: true,
 		})
 		if err != nil {
 			t.Logf("[WARNING]: order '%v' wasn't cleaned up: %s\n", id, err)
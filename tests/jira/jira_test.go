@@ -0,0 +1,324 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moledoc/orderly/internal/domain/order"
+	"github.com/moledoc/orderly/internal/domain/request"
+	"github.com/moledoc/orderly/internal/domain/user"
+	"github.com/moledoc/orderly/internal/repository/local"
+	"github.com/moledoc/orderly/internal/service/jira"
+	"github.com/moledoc/orderly/internal/service/mgmtorder"
+	"github.com/moledoc/orderly/internal/service/mgmtuser"
+	"github.com/moledoc/orderly/tests/setup"
+	"github.com/stretchr/testify/require"
+)
+
+const exportJSON = `{
+  "issues": [
+    {
+      "id": "10001",
+      "key": "OPS-1",
+      "fields": {
+        "summary": "Migrate the datacenter",
+        "issuetype": {"name": "Epic"},
+        "status": {"name": "In Progress", "statusCategory": {"key": "indeterminate"}},
+        "assignee": {"accountId": "a-1", "displayName": "Jane Doe", "emailAddress": "jane@example.com"},
+        "created": "2026-01-02T10:00:00.000+0000",
+        "duedate": "2026-12-31"
+      }
+    },
+    {
+      "id": "10002",
+      "key": "OPS-2",
+      "fields": {
+        "summary": "Move the databases",
+        "description": {"type": "doc", "content": [
+          {"type": "paragraph", "content": [{"type": "text", "text": "Postgres "}, {"type": "text", "text": "first."}]},
+          {"type": "paragraph", "content": [{"type": "text", "text": "Then the rest."}]}
+        ]},
+        "issuetype": {"name": "Story"},
+        "status": {"name": "Waiting for vendor", "statusCategory": {"key": "done"}},
+        "assignee": {"accountId": "a-2", "displayName": "John Smith"},
+        "created": "2026-01-03T10:00:00.000+0000",
+        "parent": {"id": "10001", "key": "OPS-1"},
+        "comment": {"comments": [
+          {"author": {"accountId": "a-1", "displayName": "Jane Doe", "emailAddress": "jane@example.com"}, "body": "Vendor is late.", "created": "2026-01-04T10:00:00.000+0000"},
+          {"author": {"accountId": "a-3", "displayName": "Ann Other"}, "body": "", "created": "2026-01-05T10:00:00.000+0000"}
+        ]}
+      }
+    },
+    {
+      "id": "10003",
+      "key": "OPS-3",
+      "fields": {
+        "summary": "Backup the databases",
+        "issuetype": {"name": "Sub-task"},
+        "status": {"name": "Blocked"},
+        "created": "2026-01-04T10:00:00.000+0000",
+        "parent": {"key": "OPS-2"}
+      }
+    },
+    {
+      "id": "10004",
+      "key": "OPS-4",
+      "fields": {
+        "summary": "Orphan",
+        "issuetype": {"name": "Task"},
+        "status": {"name": "To Do"},
+        "parent": {"key": "OTHER-9"}
+      }
+    }
+  ]
+}`
+
+const exportCSV = `Summary,Issue key,Issue id,Issue Type,Status,Assignee,Assignee Id,Created,Due date,Parent,Comment,Comment
+Migrate the datacenter,OPS-1,10001,Epic,Done,Jane Doe,a-1,02/Jan/26 10:00 AM,31/Dec/26,,,
+Move the databases,OPS-2,10002,Story,In Review,John Smith,a-2,03/Jan/26 10:00 AM,,10001,"04/Jan/26 10:00 AM;a-1;Vendor is late.","05/Jan/26 11:30 AM;a-9;Unknown author."
+`
+
+func newServices() (mgmtorder.ServiceMgmtOrderAPI, mgmtuser.ServiceMgmtUserAPI) {
+	users := mgmtuser.NewServiceMgmtUser(local.NewLocalRepositoryUser(), nil)
+	orders := mgmtorder.NewServiceMgmtOrder(local.NewLocalRepositoryOrder(), users, nil)
+	return orders, users
+}
+
+func TestState(t *testing.T) {
+	tt := []struct {
+		Status   string
+		Category string
+		Expected order.State
+		OK       bool
+	}{
+		{Status: "To Do", Expected: order.NotStarted, OK: true},
+		{Status: " in progress ", Expected: order.InProgress, OK: true},
+		{Status: "On Hold", Expected: order.Blocked, OK: true},
+		{Status: "Resolved", Expected: order.Completed, OK: true},
+		{Status: "Waiting", Category: "indeterminate", Expected: order.InProgress, OK: true},
+		{Status: "Shipped", Category: "done", Expected: order.Completed, OK: true},
+		{Status: "Done", Category: "new", Expected: order.Completed, OK: true},
+		{Status: "Whatever", Expected: order.NotStarted, OK: false},
+	}
+	for _, tc := range tt {
+		t.Run(tc.Status, func(t *testing.T) {
+			state, ok := jira.State(tc.Status, tc.Category)
+			require.Equal(t, tc.Expected, state)
+			require.Equal(t, tc.OK, ok)
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	t.Run("JSON", func(t *testing.T) {
+		issues, err := jira.Parse(strings.NewReader("\n  " + exportJSON))
+		require.NoError(t, err)
+		require.Len(t, issues, 4)
+
+		epic := issues[0]
+		require.Equal(t, "OPS-1", epic.Key)
+		require.Equal(t, "Epic", epic.Type)
+		require.Equal(t, "In Progress", epic.Status)
+		require.Equal(t, &jira.Person{ID: "a-1", Name: "Jane Doe", Email: "jane@example.com"}, epic.Assignee)
+		require.Equal(t, time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC), epic.DueDate)
+		require.Empty(t, epic.Parent)
+
+		story := issues[1]
+		require.Equal(t, "OPS-1", story.Parent)
+		require.Equal(t, "Postgres first.\nThen the rest.", story.Description)
+		require.Len(t, story.Comments, 2)
+		require.Equal(t, "Vendor is late.", story.Comments[0].Body)
+		require.Equal(t, time.Date(2026, 1, 4, 10, 0, 0, 0, time.UTC), story.Comments[0].Created)
+
+		require.Nil(t, issues[2].Assignee)
+		require.Equal(t, "OPS-2", issues[2].Parent)
+	})
+	t.Run("JSON.Array", func(t *testing.T) {
+		issues, err := jira.Parse(strings.NewReader(`[{"key": "OPS-1", "fields": {"summary": "One"}}]`))
+		require.NoError(t, err)
+		require.Len(t, issues, 1)
+		require.Equal(t, "One", issues[0].Summary)
+	})
+	t.Run("CSV", func(t *testing.T) {
+		issues, err := jira.Parse(strings.NewReader(exportCSV))
+		require.NoError(t, err)
+		require.Len(t, issues, 2)
+
+		require.Equal(t, "OPS-1", issues[0].Key)
+		require.Equal(t, &jira.Person{ID: "a-1", Name: "Jane Doe"}, issues[0].Assignee)
+		require.Equal(t, time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC), issues[0].Created)
+		require.Equal(t, time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC), issues[0].DueDate)
+
+		story := issues[1]
+		require.Equal(t, "10001", story.Parent)
+		require.Len(t, story.Comments, 2)
+		require.Equal(t, "Jane Doe", story.Comments[0].Author.Name)
+		require.Equal(t, "a-9", story.Comments[1].Author.ID)
+		require.Equal(t, "Unknown author.", story.Comments[1].Body)
+	})
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := jira.Parse(strings.NewReader(`{"issues": [{"key": "OPS-1", "fields": {"created": "yesterday"}}]}`))
+		require.Error(t, err)
+		_, err = jira.Parse(strings.NewReader(""))
+		require.Error(t, err)
+	})
+}
+
+func TestImport(t *testing.T) {
+	ctx := context.Background()
+	orders, users := newServices()
+
+	existing := setup.UserObj("jira")
+	existing.SetEmail("jane@example.com")
+	respJane, err := users.PostUser(ctx, &request.PostUserRequest{User: existing})
+	require.NoError(t, err)
+
+	issues, errp := jira.Parse(strings.NewReader(exportJSON))
+	require.NoError(t, errp)
+	cfg := &jira.Config{EmailDomain: "jira.example.com", Deadline: 24 * time.Hour}
+	report, err := cfg.Import(ctx, issues, orders, users)
+	require.NoError(t, err)
+
+	t.Run("Users", func(t *testing.T) {
+		require.Len(t, report.Users, 3)
+		byEmail := make(map[user.Email]*jira.UserMapping)
+		for _, u := range report.Users {
+			byEmail[u.Email] = u
+		}
+		require.False(t, byEmail["jane@example.com"].Created)
+		require.Equal(t, respJane.GetUser().GetID(), byEmail["jane@example.com"].UserID)
+		require.True(t, byEmail["john.smith@jira.example.com"].Created)
+		require.True(t, byEmail["ann.other@jira.example.com"].Created)
+
+		resp, err := users.GetUsers(ctx, &request.GetUsersRequest{Emails: []user.Email{"john.smith@jira.example.com"}})
+		require.NoError(t, err)
+		require.Len(t, resp.GetUsers(), 1)
+		require.Equal(t, "John Smith", resp.GetUsers()[0].GetName())
+		require.Equal(t, users.GetRootUser(ctx).GetEmail(), resp.GetUsers()[0].GetSupervisor())
+	})
+
+	require.Len(t, report.Issues, 4)
+	byKey := make(map[string]*jira.IssueMapping)
+	for _, i := range report.Issues {
+		byKey[i.Key] = i
+	}
+	get := func(t *testing.T, key string) *order.Order {
+		resp, err := orders.GetOrderByID(ctx, &request.GetOrderByIDRequest{ID: byKey[key].OrderID})
+		require.NoError(t, err)
+		return resp.GetOrder()
+	}
+
+	t.Run("Hierarchy", func(t *testing.T) {
+		root := orders.GetRootOrder(ctx).GetID()
+		require.Equal(t, root, get(t, "OPS-1").GetParentOrderID())
+		require.Equal(t, root, get(t, "OPS-4").GetParentOrderID())
+		require.Equal(t, byKey["OPS-1"].OrderID, get(t, "OPS-2").GetParentOrderID())
+		require.Equal(t, byKey["OPS-2"].OrderID, get(t, "OPS-3").GetParentOrderID())
+
+		epic := get(t, "OPS-1")
+		require.Len(t, epic.GetDelegatedTasks(), 1)
+		require.Equal(t, byKey["OPS-2"].OrderID, epic.GetDelegatedTasks()[0].GetID())
+	})
+	t.Run("Orders", func(t *testing.T) {
+		epic := get(t, "OPS-1")
+		require.Equal(t, "[OPS-1] Migrate the datacenter", epic.GetTask().GetObjective())
+		require.Equal(t, order.InProgress, epic.GetTask().GetState())
+		require.Equal(t, user.Email("jane@example.com"), epic.GetTask().GetAccountable())
+		require.Equal(t, time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC), epic.GetTask().GetDeadline().UTC())
+
+		story := get(t, "OPS-2")
+		require.Equal(t, "[OPS-2] Move the databases\n\nPostgres first.\nThen the rest.", story.GetTask().GetObjective())
+		require.Equal(t, order.Completed, story.GetTask().GetState())
+		require.Equal(t, user.Email("john.smith@jira.example.com"), story.GetTask().GetAccountable())
+		require.Equal(t, epic.GetTask().GetDeadline(), story.GetTask().GetDeadline())
+		require.Len(t, story.GetSitReps(), 1) // NOTE: empty comments are dropped
+		require.Equal(t, "Vendor is late.", story.GetSitReps()[0].GetSituation())
+		require.Equal(t, user.Email("jane@example.com"), story.GetSitReps()[0].GetBy())
+		require.Equal(t, 1, byKey["OPS-2"].SitReps)
+
+		subtask := get(t, "OPS-3")
+		require.Equal(t, order.Blocked, subtask.GetTask().GetState())
+		require.Equal(t, users.GetRootUser(ctx).GetEmail(), subtask.GetTask().GetAccountable())
+
+		orphan := get(t, "OPS-4")
+		require.WithinDuration(t, time.Now().Add(24*time.Hour), orphan.GetTask().GetDeadline(), time.Minute)
+	})
+	t.Run("Warnings", func(t *testing.T) {
+		require.Len(t, report.Warnings, 1) // NOTE: 'Waiting for vendor' is known by its category
+		require.Contains(t, report.Warnings[0], "OTHER-9")
+	})
+	t.Run("Report", func(t *testing.T) {
+		var text bytes.Buffer
+		require.NoError(t, report.WriteText(&text))
+		require.Contains(t, text.String(), "OPS-3")
+		require.Contains(t, text.String(), string(byKey["OPS-3"].OrderID))
+
+		var decoded jira.Report
+		var js bytes.Buffer
+		require.NoError(t, report.WriteJSON(&js))
+		require.NoError(t, json.Unmarshal(js.Bytes(), &decoded))
+		require.Equal(t, report, &decoded)
+	})
+	t.Run("Reimport", func(t *testing.T) {
+		again, err := cfg.Import(ctx, issues, orders, users)
+		require.NoError(t, err)
+		for _, u := range again.Users {
+			require.False(t, u.Created)
+		}
+	})
+}
+
+func TestImport_Failed(t *testing.T) {
+	ctx := context.Background()
+	orders, users := newServices()
+
+	t.Run("DuplicateKey", func(t *testing.T) {
+		_, err := (&jira.Config{}).Import(ctx, []*jira.Issue{{Key: "OPS-1"}, {Key: "OPS-1"}}, orders, users)
+		require.Error(t, err)
+	})
+	t.Run("UnknownParentOrder", func(t *testing.T) {
+		cfg := &jira.Config{EmailDomain: "jira.example.com", ParentOrderID: "unknown"}
+		report, err := cfg.Import(ctx, []*jira.Issue{{Key: "OPS-1", Summary: "One"}}, orders, users)
+		require.Error(t, err)
+		require.Empty(t, report.Issues)
+	})
+	t.Run("Cycle", func(t *testing.T) {
+		cfg := &jira.Config{EmailDomain: "jira.example.com", Deadline: time.Hour}
+		report, err := cfg.Import(ctx, []*jira.Issue{
+			{Key: "OPS-1", Summary: "One", Parent: "OPS-2"},
+			{Key: "OPS-2", Summary: "Two", Parent: "OPS-1"},
+			{Key: "OPS-3", Summary: "Three", Status: "To Do"},
+		}, orders, users)
+		require.NoError(t, err)
+		require.Len(t, report.Issues, 1)
+		require.Equal(t, []string{"2 issues not imported, their parents form a cycle"}, report.Warnings)
+	})
+	t.Run("DeadlineConflict", func(t *testing.T) {
+		parentDue := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+		childDue := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+		cfg := &jira.Config{EmailDomain: "jira.example.com", Deadline: time.Hour}
+		report, err := cfg.Import(ctx, []*jira.Issue{
+			{Key: "OPS-1", Summary: "One", Status: "To Do", DueDate: parentDue},
+			{Key: "OPS-2", Summary: "Two", Status: "To Do", DueDate: childDue, Parent: "OPS-1"},
+		}, orders, users)
+		require.NoError(t, err)
+		require.Len(t, report.Issues, 2)
+		require.Equal(t, []string{
+			fmt.Sprintf("issue 'OPS-2': order '%s' is due 2026-02-01T00:00:00Z, after order '%s' it's delegated from, due 2026-01-10T00:00:00Z",
+				report.Issues[1].OrderID, report.Issues[0].OrderID),
+		}, report.Warnings)
+	})
+	t.Run("UnknownStatus", func(t *testing.T) {
+		cfg := &jira.Config{EmailDomain: "jira.example.com", Deadline: time.Hour}
+		report, err := cfg.Import(ctx, []*jira.Issue{{Key: "OPS-1", Summary: "One", Status: "Shipped"}}, orders, users)
+		require.NoError(t, err)
+		require.Equal(t, order.NotStarted.String(), report.Issues[0].State)
+		require.Len(t, report.Warnings, 1)
+		require.Contains(t, report.Warnings[0], "Shipped")
+	})
+}
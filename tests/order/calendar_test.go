@@ -0,0 +1,172 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moledoc/orderly/internal/domain/meta"
+	"github.com/moledoc/orderly/internal/domain/order"
+	"github.com/moledoc/orderly/internal/domain/request"
+	"github.com/moledoc/orderly/internal/router"
+	"github.com/moledoc/orderly/pkg/utils"
+	apiordersvc "github.com/moledoc/orderly/tests/api/order/svc"
+	apiusersvc "github.com/moledoc/orderly/tests/api/user/svc"
+	"github.com/moledoc/orderly/tests/setup"
+	"github.com/stretchr/testify/require"
+)
+
+var calendarUID = regexp.MustCompile(`(?m)^UID:(.*)@orderly\r$`)
+
+// calendarIDs lists the task ids of the calendar's events, in the order of the feed.
+func calendarIDs(calendar string) []meta.ID {
+	var ids []meta.ID
+	for _, match := range calendarUID.FindAllStringSubmatch(calendar, -1) {
+		ids = append(ids, meta.ID(match[1]))
+	}
+	return ids
+}
+
+func TestCalendar(t *testing.T) {
+	deadline := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tasks := []*order.Task{
+		{
+			ID:        "task-1",
+			State:     utils.Ptr(order.InProgress),
+			Objective: "Plan; build, ship\\done\n\n" + strings.Repeat("long objective ", 10) + "ä",
+			Deadline:  deadline,
+		},
+		{
+			ID:        "task-2",
+			Objective: "no deadline",
+		},
+	}
+
+	calendar := order.Calendar("Deadlines", tasks, now)
+	require.True(t, strings.HasPrefix(calendar, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	require.True(t, strings.HasSuffix(calendar, "END:VEVENT\r\nEND:VCALENDAR\r\n"))
+	require.Equal(t, []meta.ID{"task-1"}, calendarIDs(calendar))
+
+	for _, line := range strings.Split(strings.TrimSuffix(calendar, "\r\n"), "\r\n") {
+		require.LessOrEqual(t, len(line), 75, line)
+	}
+	unfolded := strings.ReplaceAll(calendar, "\r\n ", "")
+	require.Contains(t, unfolded, "DTSTART:20260304T050607Z\r\n")
+	require.Contains(t, unfolded, "DTSTAMP:20260102T030405Z\r\n")
+	require.Contains(t, unfolded, `SUMMARY:Plan\; build\, ship\\done [In Progress]`+"\r\n")
+	require.Contains(t, unfolded, `DESCRIPTION:Plan\; build\, ship\\done\n\n`+strings.Repeat("long objective ", 10)+`ä\n\nState: In Progress`+"\r\n")
+}
+
+func TestGetCalendar(t *testing.T) {
+	userAPI := apiusersvc.NewUserAPISvc()
+	orderAPI := apiordersvc.NewOrderAPISvcWithUserLookup(userAPI.Svc)
+
+	accountable := setup.MustCreateUserWithCleanup(t, context.Background(), userAPI, setup.UserObj(utils.RandAlphanum()))
+	other := setup.MustCreateUserWithCleanup(t, context.Background(), userAPI, setup.UserObj(utils.RandAlphanum()))
+
+	obj := setup.OrderObj()
+	obj.SetParentOrderID(orderAPI.Svc.GetRootOrder(context.Background()).GetID())
+	obj.GetTask().SetAccountable(accountable.GetEmail())
+	obj.GetDelegatedTasks()[0].SetAccountable(accountable.GetEmail())
+	obj.GetDelegatedTasks()[0].SetDeadline(obj.GetTask().GetDeadline().Add(-time.Hour))
+	obj.GetDelegatedTasks()[1].SetAccountable(other.GetEmail())
+	obj.GetDelegatedTasks()[2].SetAccountable(accountable.GetEmail())
+	obj.GetDelegatedTasks()[2].SetDeadline(obj.GetTask().GetDeadline().Add(-2 * time.Hour))
+	obj.SetSitReps(nil)
+	o := setup.MustCreateOrderWithCleanup(t, context.Background(), orderAPI, obj)
+	delegated := o.GetDelegatedTasks()
+
+	t.Run("accountable", func(t *testing.T) {
+		resp, err := orderAPI.Svc.GetCalendar(context.Background(), &request.GetCalendarRequest{
+			Accountable: accountable.GetEmail(),
+		})
+		require.NoError(t, err)
+		require.Equal(t, []meta.ID{delegated[2].GetID(), delegated[0].GetID(), o.GetID()}, calendarIDs(resp.GetCalendar())) // NOTE: sorted by deadline
+	})
+
+	t.Run("stable", func(t *testing.T) {
+		_, err := orderAPI.Svc.PatchOrder(context.Background(), &request.PatchOrderRequest{
+			Order: &order.Order{Task: &order.Task{ID: o.GetID(), State: utils.Ptr(order.Completed)}},
+		})
+		require.NoError(t, err)
+
+		resp, err := orderAPI.Svc.GetCalendar(context.Background(), &request.GetCalendarRequest{
+			Accountable: accountable.GetEmail(),
+		})
+		require.NoError(t, err)
+		require.Equal(t, []meta.ID{delegated[2].GetID(), delegated[0].GetID(), o.GetID()}, calendarIDs(resp.GetCalendar()))
+		require.Contains(t, resp.GetCalendar(), "CATEGORIES:Completed\r\n")
+	})
+
+	t.Run("subtree", func(t *testing.T) {
+		resp, err := orderAPI.Svc.GetCalendar(context.Background(), &request.GetCalendarRequest{
+			Accountable: accountable.GetEmail(),
+			RootOrderID: delegated[0].GetID(),
+		})
+		require.NoError(t, err)
+		require.Equal(t, []meta.ID{delegated[0].GetID()}, calendarIDs(resp.GetCalendar()))
+	})
+
+	t.Run("deleted", func(t *testing.T) {
+		_, err := orderAPI.Svc.DeleteOrder(context.Background(), &request.DeleteOrderRequest{ID: delegated[2].GetID()})
+		require.NoError(t, err)
+
+		resp, err := orderAPI.Svc.GetCalendar(context.Background(), &request.GetCalendarRequest{
+			Accountable: accountable.GetEmail(),
+		})
+		require.NoError(t, err)
+		require.Equal(t, []meta.ID{delegated[0].GetID(), o.GetID()}, calendarIDs(resp.GetCalendar()))
+	})
+
+	t.Run("other", func(t *testing.T) {
+		resp, err := orderAPI.Svc.GetCalendar(context.Background(), &request.GetCalendarRequest{
+			Accountable: other.GetEmail(),
+		})
+		require.NoError(t, err)
+		require.Equal(t, []meta.ID{delegated[1].GetID()}, calendarIDs(resp.GetCalendar()))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for name, req := range map[string]*request.GetCalendarRequest{
+			"accountable":   {Accountable: "invalid"},
+			"root_order_id": {Accountable: accountable.GetEmail(), RootOrderID: "invalid"},
+		} {
+			resp, err := orderAPI.Svc.GetCalendar(context.Background(), req)
+			require.Error(t, err, name)
+			require.Empty(t, resp, name)
+			require.Equal(t, http.StatusBadRequest, err.GetStatusCode(), err)
+		}
+	})
+
+	t.Run("http", func(t *testing.T) {
+		mux := router.RouteCalendar(orderAPI.Svc, userAPI.Svc)
+		get := func(path string) (*http.Response, string) {
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+			bs, err := io.ReadAll(rr.Result().Body)
+			require.NoError(t, err)
+			return rr.Result(), string(bs)
+		}
+
+		resp, body := get(fmt.Sprintf("/calendar/%s.ics", accountable.GetID()))
+		require.Equal(t, http.StatusOK, resp.StatusCode, body)
+		require.Equal(t, order.CalendarContentType, resp.Header.Get("Content-Type"))
+		require.Equal(t, []meta.ID{delegated[0].GetID(), o.GetID()}, calendarIDs(body))
+
+		resp, body = get(fmt.Sprintf("/calendar/%s.ics?root_order_id=%s", accountable.GetID(), delegated[0].GetID()))
+		require.Equal(t, http.StatusOK, resp.StatusCode, body)
+		require.Equal(t, []meta.ID{delegated[0].GetID()}, calendarIDs(body))
+
+		resp, _ = get(fmt.Sprintf("/calendar/%s", accountable.GetID()))
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+		resp, _ = get(fmt.Sprintf("/calendar/%s.ics", meta.NewID()))
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/moledoc/orderly/internal/domain/errwrap"
+	"github.com/moledoc/orderly/internal/domain/meta"
+	"github.com/moledoc/orderly/internal/domain/order"
+	"github.com/moledoc/orderly/internal/domain/request"
+	"github.com/moledoc/orderly/internal/repository/local"
+	"github.com/moledoc/orderly/internal/service/mgmtorder"
+	apiordersvc "github.com/moledoc/orderly/tests/api/order/svc"
+	"github.com/moledoc/orderly/tests/setup"
+	"github.com/stretchr/testify/require"
+)
+
+func conflictIDs(conflicts []*order.DeadlineConflict) [][2]meta.ID {
+	var ids [][2]meta.ID
+	for _, conflict := range conflicts {
+		ids = append(ids, [2]meta.ID{conflict.GetOrderID(), conflict.GetAncestorID()})
+	}
+	return ids
+}
+
+func (s *OrderSuite) TestDeadlineConflicts() {
+	tt := s.T()
+
+	o := setup.MustCreateOrderWithCleanup(tt, context.Background(), s.API, setup.OrderObj())
+	subObj := setup.OrderObj()
+	subObj.SetParentOrderID(o.GetDelegatedTasks()[0].GetID())
+	sub := setup.MustCreateOrderWithCleanup(tt, context.Background(), s.API, subObj)
+
+	tt.Run("patch.order", func(t *testing.T) {
+		resp, err := s.API.PatchOrder(t, context.Background(), &request.PatchOrderRequest{
+			Order: &order.Order{
+				Task: &order.Task{ID: o.GetID(), Deadline: o.GetTask().GetDeadline().Add(-24 * time.Hour)},
+			},
+		})
+		require.NoError(t, err)
+
+		var expected [][2]meta.ID
+		for _, delegated := range append(o.GetDelegatedTasks(), append([]*order.Task{sub.GetTask()}, sub.GetDelegatedTasks()...)...) {
+			expected = append(expected, [2]meta.ID{delegated.GetID(), o.GetID()})
+		}
+		require.ElementsMatch(t, expected, conflictIDs(resp.GetDeadlineConflicts()))
+	})
+
+	tt.Run("patch.delegated.tasks", func(t *testing.T) {
+		resp, err := s.API.PatchDelegatedTasks(t, context.Background(), &request.PatchDelegatedTasksRequest{
+			OrderID: sub.GetID(),
+			Tasks:   []*order.Task{{ID: sub.GetDelegatedTasks()[0].GetID(), Deadline: sub.GetTask().GetDeadline().Add(24 * time.Hour)}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, [][2]meta.ID{{sub.GetDelegatedTasks()[0].GetID(), sub.GetID()}}, conflictIDs(resp.GetDeadlineConflicts()))
+	})
+
+	tt.Run("move", func(t *testing.T) {
+		moved := setup.MustCreateOrderWithCleanup(t, context.Background(), s.API, setup.OrderObj())
+		resp, err := s.API.MoveOrder(t, context.Background(), &request.MoveOrderRequest{
+			ID:            moved.GetID(),
+			ParentOrderID: o.GetID(),
+		})
+		require.NoError(t, err)
+		require.Equal(t, [][2]meta.ID{{moved.GetID(), o.GetID()}}, conflictIDs(resp.GetDeadlineConflicts()))
+	})
+
+	tt.Run("no.conflicts", func(t *testing.T) {
+		resp, err := s.API.PatchOrder(t, context.Background(), &request.PatchOrderRequest{
+			Order: &order.Order{
+				Task: &order.Task{ID: sub.GetID(), Objective: "no deadline change"},
+			},
+		})
+		require.NoError(t, err)
+		require.Empty(t, resp.GetDeadlineConflicts())
+	})
+}
+
+func TestDeadlineConflicts_Enforce(t *testing.T) {
+	svc := mgmtorder.NewServiceMgmtOrder(local.NewLocalRepositoryOrder(), nil, &mgmtorder.Config{Deadlines: mgmtorder.DeadlinesEnforce})
+	orderAPI := &apiordersvc.OrderAPISvc{Svc: svc}
+
+	o := setup.MustCreateOrderWithCleanup(t, context.Background(), orderAPI, setup.OrderObj())
+
+	t.Run("post.order", func(t *testing.T) {
+		obj := setup.OrderObj()
+		obj.SetParentOrderID(o.GetDelegatedTasks()[0].GetID())
+		obj.GetTask().SetDeadline(o.GetTask().GetDeadline().Add(24 * time.Hour))
+		_, err := orderAPI.PostOrder(t, context.Background(), &request.PostOrderRequest{Order: obj})
+		require.Error(t, err)
+		require.Equal(t, http.StatusUnprocessableEntity, err.GetStatusCode(), err)
+		require.Len(t, err.GetFields(), 1)
+		require.Equal(t, "order.task.deadline", err.GetFields()[0].Field)
+
+		resp, err := orderAPI.GetOrders(t, context.Background(), &request.GetOrdersRequest{ParentOrderID: o.GetDelegatedTasks()[0].GetID()})
+		require.NoError(t, err)
+		require.Empty(t, resp.GetOrders())
+	})
+
+	t.Run("patch.order", func(t *testing.T) {
+		_, err := orderAPI.PatchOrder(t, context.Background(), &request.PatchOrderRequest{
+			Order: &order.Order{
+				Task: &order.Task{ID: o.GetID(), Deadline: o.GetTask().GetDeadline().Add(-24 * time.Hour)},
+			},
+		})
+		require.Error(t, err)
+		require.Equal(t, http.StatusUnprocessableEntity, err.GetStatusCode(), err)
+		var expected []errwrap.FieldError
+		for _, delegated := range o.GetDelegatedTasks() {
+			expected = append(expected, errwrap.FieldError{
+				Field:   "order.task.deadline",
+				Message: fmt.Sprintf("order '%s' is due after order '%s'", delegated.GetID(), o.GetID()),
+			})
+		}
+		require.ElementsMatch(t, expected, err.GetFields())
+
+		resp, err := orderAPI.GetOrderByID(t, context.Background(), &request.GetOrderByIDRequest{ID: o.GetID()})
+		require.NoError(t, err)
+		require.True(t, o.GetTask().GetDeadline().Equal(resp.GetOrder().GetTask().GetDeadline()))
+		require.Equal(t, o.GetMeta().GetVersion(), resp.GetOrder().GetMeta().GetVersion())
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/moledoc/orderly/internal/domain/meta"
+	"github.com/moledoc/orderly/internal/domain/order"
 	"github.com/moledoc/orderly/internal/domain/request"
 	"github.com/moledoc/orderly/tests/setup"
 	"github.com/stretchr/testify/require"
@@ -49,4 +50,97 @@ func (s *OrderSuite) TestDeleteOrder() {
 		require.Equal(t, "not found", err.GetStatusMessage())
 	})
 
+	subtree := func(t *testing.T) (*order.Order, *order.Order) {
+		t.Helper()
+		o := setup.MustCreateOrderWithCleanup(t, context.Background(), s.API, setup.OrderObj())
+		nestedObj := setup.OrderObj()
+		nestedObj.SetParentOrderID(o.GetDelegatedTasks()[0].GetID())
+		nested := setup.MustCreateOrderWithCleanup(t, context.Background(), s.API, nestedObj)
+		return o, nested
+	}
+
+	subtreeIDs := func(orders ...*order.Order) (orderIDs []meta.ID, sitrepIDs []meta.ID) {
+		for _, o := range orders {
+			orderIDs = append(orderIDs, o.GetID())
+			for _, delegated := range o.GetDelegatedTasks() {
+				orderIDs = append(orderIDs, delegated.GetID())
+			}
+			for _, sitrep := range o.GetSitReps() {
+				sitrepIDs = append(sitrepIDs, sitrep.GetID())
+			}
+		}
+		return orderIDs, sitrepIDs
+	}
+
+	tt.Run("subtree.cascade.no", func(t *testing.T) {
+		o, nested := subtree(t)
+
+		respDelete, err := s.API.DeleteOrder(t, context.Background(), &request.DeleteOrderRequest{
+			ID: o.GetID(),
+		})
+		require.Error(t, err)
+		require.Empty(t, respDelete)
+		require.Equal(t, http.StatusConflict, err.GetStatusCode(), err)
+
+		for _, id := range []meta.ID{o.GetID(), o.GetDelegatedTasks()[0].GetID(), nested.GetID()} {
+			_, err := s.API.GetOrderByID(t, context.Background(), &request.GetOrderByIDRequest{
+				ID: id,
+			})
+			require.NoError(t, err)
+		}
+	})
+
+	tt.Run("subtree.cascade.dry_run", func(t *testing.T) {
+		o, nested := subtree(t)
+
+		respDelete, err := s.API.DeleteOrder(t, context.Background(), &request.DeleteOrderRequest{
+			ID:      o.GetID(),
+			Cascade: true,
+			DryRun:  true,
+		})
+		require.NoError(t, err)
+
+		orderIDs, sitrepIDs := subtreeIDs(o, nested)
+		require.ElementsMatch(t, orderIDs, respDelete.GetOrderIDs())
+		require.ElementsMatch(t, orderIDs, respDelete.GetTaskIDs())
+		require.ElementsMatch(t, sitrepIDs, respDelete.GetSitRepIDs())
+
+		for _, id := range orderIDs {
+			_, err := s.API.GetOrderByID(t, context.Background(), &request.GetOrderByIDRequest{
+				ID: id,
+			})
+			require.NoError(t, err, "dry run deleted order '%s'", id)
+		}
+	})
+
+	tt.Run("subtree.cascade.yes", func(t *testing.T) {
+		o, nested := subtree(t)
+
+		respDelete, err := s.API.DeleteOrder(t, context.Background(), &request.DeleteOrderRequest{
+			ID:      o.GetID(),
+			Cascade: true,
+		})
+		require.NoError(t, err)
+		require.Empty(t, respDelete)
+
+		orderIDs, _ := subtreeIDs(o, nested)
+		for _, id := range orderIDs {
+			_, err := s.API.GetOrderByID(t, context.Background(), &request.GetOrderByIDRequest{
+				ID: id,
+			})
+			require.Error(t, err)
+			require.Equal(t, http.StatusNotFound, err.GetStatusCode(), "order '%s' not deleted", id)
+		}
+
+		_, err = s.API.RestoreOrder(t, context.Background(), &request.RestoreOrderRequest{
+			ID: o.GetID(),
+		})
+		require.NoError(t, err)
+		for _, id := range orderIDs {
+			_, err := s.API.GetOrderByID(t, context.Background(), &request.GetOrderByIDRequest{
+				ID: id,
+			})
+			require.NoError(t, err, "order '%s' not restored", id)
+		}
+	})
 }
@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/moledoc/orderly/internal/domain/meta"
+	"github.com/moledoc/orderly/internal/domain/order"
+	"github.com/moledoc/orderly/internal/domain/request"
+	"github.com/moledoc/orderly/tests/setup"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *OrderSuite) TestGetOrderDiff() {
+	tt := s.T()
+
+	o := setup.MustCreateOrderWithCleanup(tt, context.Background(), s.API, setup.OrderObj())
+
+	respPatch, err := s.API.PatchOrder(tt, context.Background(), &request.PatchOrderRequest{
+		Order: &order.Order{
+			Task: &order.Task{
+				ID:        o.GetID(),
+				Objective: "patched objective",
+			},
+		},
+	})
+	require.NoError(tt, err)
+
+	respPutSitReps, err := s.API.PutSitReps(tt, context.Background(), &request.PutSitRepsRequest{
+		OrderID: o.GetID(),
+		SitReps: []*order.SitRep{setup.SitrepObj("added")},
+	})
+	require.NoError(tt, err)
+	addedSitRep := respPutSitReps.GetOrder().GetSitReps()[len(respPutSitReps.GetOrder().GetSitReps())-1]
+
+	removedTask := o.GetDelegatedTasks()[0]
+	_, err = s.API.DeleteDelegatedTasks(tt, context.Background(), &request.DeleteDelegatedTasksRequest{
+		OrderID:          o.GetID(),
+		DelegatedTaskIDs: []meta.ID{removedTask.GetID()},
+	})
+	require.NoError(tt, err)
+
+	patchedSitRep := o.GetSitReps()[0]
+	_, err = s.API.PatchSitReps(tt, context.Background(), &request.PatchSitRepsRequest{
+		OrderID: o.GetID(),
+		SitReps: []*order.SitRep{
+			{
+				ID:        patchedSitRep.GetID(),
+				Situation: "patched situation",
+			},
+		},
+	})
+	require.NoError(tt, err)
+
+	expected := [][]*order.FieldChange{
+		{
+			{Field: "task.objective", Kind: order.FieldChanged, Old: o.GetTask().GetObjective(), New: "patched objective"},
+		},
+		{
+			{Field: fmt.Sprintf("sitreps[%v]", addedSitRep.GetID()), Kind: order.FieldAdded, New: addedSitRep.GetSituation()},
+		},
+		{
+			{Field: fmt.Sprintf("delegated_tasks[%v]", removedTask.GetID()), Kind: order.FieldRemoved, Old: removedTask.GetObjective()},
+		},
+		{
+			{Field: fmt.Sprintf("sitreps[%v].situation", patchedSitRep.GetID()), Kind: order.FieldChanged, Old: patchedSitRep.GetSituation(), New: "patched situation"},
+		},
+	}
+
+	tt.Run("all", func(t *testing.T) {
+		resp, err := s.API.GetOrderDiff(t, context.Background(), &request.GetOrderDiffRequest{
+			ID: o.GetID(),
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.GetDiffs(), len(expected))
+
+		for i, diff := range resp.GetDiffs() {
+			require.Equal(t, expected[i], diff.GetChanges())
+			require.Less(t, diff.GetFrom(), diff.GetTo())
+			require.False(t, diff.GetUpdated().IsZero())
+		}
+		require.Equal(t, o.GetMeta().GetVersion(), resp.GetDiffs()[0].GetFrom())
+	})
+
+	tt.Run("range", func(t *testing.T) {
+		to := respPatch.GetOrder().GetMeta().GetVersion()
+		resp, err := s.API.GetOrderDiff(t, context.Background(), &request.GetOrderDiffRequest{
+			ID:   o.GetID(),
+			From: o.GetMeta().GetVersion(),
+			To:   to,
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.GetDiffs(), 1)
+		require.Equal(t, to, resp.GetDiffs()[0].GetTo())
+		require.Equal(t, expected[0], resp.GetDiffs()[0].GetChanges())
+	})
+}
+
+func (s *OrderSuite) TestGetOrderDiff_Failed() {
+	tt := s.T()
+
+	o := setup.MustCreateOrderWithCleanup(tt, context.Background(), s.API, setup.OrderObj())
+
+	tt.Run("NotFound", func(t *testing.T) {
+		resp, err := s.API.GetOrderDiff(t, context.Background(), &request.GetOrderDiffRequest{
+			ID: meta.NewID(),
+		})
+		require.Error(t, err)
+		require.Equal(t, http.StatusNotFound, err.GetStatusCode())
+		require.Empty(t, resp)
+	})
+
+	tt.Run("invalid.range", func(t *testing.T) {
+		resp, err := s.API.GetOrderDiff(t, context.Background(), &request.GetOrderDiffRequest{
+			ID:   o.GetID(),
+			From: 2,
+			To:   1,
+		})
+		require.Error(t, err)
+		require.Equal(t, http.StatusBadRequest, err.GetStatusCode())
+		require.Empty(t, resp)
+	})
+}
@@ -0,0 +1,166 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moledoc/orderly/internal/domain/meta"
+	"github.com/moledoc/orderly/internal/domain/order"
+	"github.com/moledoc/orderly/internal/domain/request"
+	"github.com/moledoc/orderly/pkg/utils"
+	apiordersvc "github.com/moledoc/orderly/tests/api/order/svc"
+	apiusersvc "github.com/moledoc/orderly/tests/api/user/svc"
+	"github.com/moledoc/orderly/tests/setup"
+	"github.com/stretchr/testify/require"
+)
+
+// requireInOrder checks that the parts are found in the document one after another.
+func requireInOrder(t *testing.T, document string, parts ...string) {
+	t.Helper()
+	rest := document
+	for _, part := range parts {
+		i := strings.Index(rest, part)
+		require.GreaterOrEqual(t, i, 0, "'%s' not found in order in:\n%s", part, document)
+		rest = rest[i+len(part):]
+	}
+}
+
+func (s *OrderSuite) TestGetOrderDocument() {
+	tt := s.T()
+
+	past := time.Now().UTC().Add(-24 * time.Hour)
+
+	obj := setup.OrderObj()
+	obj.GetTask().SetObjective("Secure the <bridge>\n\nHold it until relieved.")
+	obj.GetTask().SetState(order.InProgress)
+	for i, sitrep := range obj.GetSitReps() {
+		sitrep.SetDateTime(past.Add(time.Duration(i) * time.Hour))
+		sitrep.SetSituation(fmt.Sprintf("situation %v", i))
+	}
+	obj.GetSitReps()[2].SetIssues("fuel | ammo low")
+	for i, delegated := range obj.GetDelegatedTasks() {
+		delegated.SetObjective(fmt.Sprintf("task %v", i))
+	}
+	obj.GetDelegatedTasks()[1].SetState(order.Blocked)
+	obj.GetDelegatedTasks()[1].SetDeadline(past)
+	o := setup.MustCreateOrderWithCleanup(tt, context.Background(), s.API, obj)
+
+	subObj := setup.OrderObj()
+	subObj.SetParentOrderID(o.GetDelegatedTasks()[0].GetID())
+	subObj.GetTask().SetObjective("subtask")
+	subObj.SetDelegatedTasks(nil)
+	subObj.SetSitReps(nil)
+	setup.MustCreateOrderWithCleanup(tt, context.Background(), s.API, subObj)
+
+	tt.Run("md", func(t *testing.T) {
+		resp, err := s.API.GetOrderDocument(t, context.Background(), &request.GetOrderDocumentRequest{
+			ID: o.GetID(),
+		})
+		require.NoError(t, err)
+		require.Equal(t, order.DocumentMarkdown.ContentType(), resp.GetContentType())
+		requireInOrder(t, resp.GetDocument(),
+			"# ORDER: Secure the <bridge>",
+			"## 1. Situation",
+			"Delegated tasks: 4 total, 0 completed, 1 blocked, 1 overdue.",
+			"### 1.1 Sitrep", "situation 2",
+			"### 1.2 Sitrep", "situation 1",
+			"### 1.3 Sitrep", "situation 0",
+			"## 2. Mission",
+			"Secure the <bridge>\n\nHold it until relieved.",
+			"- **State:** In Progress",
+			"## 3. Execution",
+			"| 3.1 | task 0 |",
+			"| 3.1.1 | &nbsp;&nbsp;subtask |",
+			"| 3.2 | task 1 |", "| Blocked, overdue |",
+			"| 3.3 | task 2 |",
+			"## 4. Sustainment",
+			`fuel \| ammo low`,
+			"## 5. Command and Signal",
+			"- **Accountable:**",
+		)
+	})
+
+	tt.Run("html", func(t *testing.T) {
+		resp, err := s.API.GetOrderDocument(t, context.Background(), &request.GetOrderDocumentRequest{
+			ID:     o.GetID(),
+			Format: order.DocumentHTML,
+		})
+		require.NoError(t, err)
+		require.Equal(t, order.DocumentHTML.ContentType(), resp.GetContentType())
+		require.NotContains(t, resp.GetDocument(), "<bridge>")
+		requireInOrder(t, resp.GetDocument(),
+			"<h1>ORDER: Secure the &lt;bridge&gt;</h1>",
+			"<h2>1. Situation</h2>",
+			"<h2>2. Mission</h2>",
+			"<h2>3. Execution</h2>",
+			"<td>3.1</td>", "task 0",
+			"<td>3.1.1</td>", "subtask",
+			"<h2>4. Sustainment</h2>",
+			"<h2>5. Command and Signal</h2>",
+		)
+	})
+
+	tt.Run("sitreps", func(t *testing.T) {
+		resp, err := s.API.GetOrderDocument(t, context.Background(), &request.GetOrderDocumentRequest{
+			ID:      o.GetID(),
+			SitReps: 1,
+		})
+		require.NoError(t, err)
+		require.Contains(t, resp.GetDocument(), "situation 2")
+		require.NotContains(t, resp.GetDocument(), "situation 1")
+	})
+
+	tt.Run("format.invalid", func(t *testing.T) {
+		resp, err := s.API.GetOrderDocument(t, context.Background(), &request.GetOrderDocumentRequest{
+			ID:     o.GetID(),
+			Format: "pdf",
+		})
+		require.Error(t, err)
+		require.Empty(t, resp)
+		require.Equal(t, http.StatusBadRequest, err.GetStatusCode(), err)
+	})
+
+	tt.Run("NotFound", func(t *testing.T) {
+		resp, err := s.API.GetOrderDocument(t, context.Background(), &request.GetOrderDocumentRequest{
+			ID: meta.NewID(),
+		})
+		require.Error(t, err)
+		require.Empty(t, resp)
+		require.Equal(t, http.StatusNotFound, err.GetStatusCode(), err)
+	})
+}
+
+func TestGetOrderDocument_Names(t *testing.T) {
+	userAPI := apiusersvc.NewUserAPISvc()
+	orderAPI := apiordersvc.NewOrderAPISvcWithUserLookup(userAPI.Svc)
+
+	lead := setup.MustCreateUserWithCleanup(t, context.Background(), userAPI, setup.UserObj(utils.RandAlphanum()))
+	parentObj := setup.OrderObj()
+	parentObj.GetTask().SetAccountable(lead.GetEmail())
+	parentObj.SetDelegatedTasks(nil)
+	parentObj.SetSitReps(nil)
+	parent := setup.MustCreateOrderWithCleanup(t, context.Background(), orderAPI, parentObj)
+
+	member := setup.MustCreateUserWithCleanup(t, context.Background(), userAPI, setup.UserObj(utils.RandAlphanum()))
+	obj := setup.OrderObj()
+	obj.SetParentOrderID(parent.GetID())
+	obj.GetTask().SetAccountable(member.GetEmail())
+	obj.SetDelegatedTasks(nil)
+	obj.SetSitReps(nil)
+	o := setup.MustCreateOrderWithCleanup(t, context.Background(), orderAPI, obj)
+
+	resp, err := orderAPI.GetOrderDocument(t, context.Background(), &request.GetOrderDocumentRequest{
+		ID: o.GetID(),
+	})
+	require.NoError(t, err)
+	requireInOrder(t, resp.GetDocument(),
+		"## 3. Execution", "No delegated tasks.",
+		"## 4. Sustainment", "No issues reported.",
+		fmt.Sprintf("- **Accountable:** %s <%s>", member.GetName(), member.GetEmail()),
+		fmt.Sprintf("- **Reports to:** %s <%s>", lead.GetName(), lead.GetEmail()),
+	)
+}
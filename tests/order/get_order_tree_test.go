@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/moledoc/orderly/internal/domain/meta"
+	"github.com/moledoc/orderly/internal/domain/order"
+	"github.com/moledoc/orderly/internal/domain/request"
+	"github.com/moledoc/orderly/internal/repository/local"
+	"github.com/moledoc/orderly/internal/service/mgmtorder"
+	apiordersvc "github.com/moledoc/orderly/tests/api/order/svc"
+	"github.com/moledoc/orderly/tests/setup"
+	"github.com/stretchr/testify/require"
+)
+
+func treeIDs(tree *order.Tree) map[meta.ID]any {
+	ids := make(map[meta.ID]any)
+	for _, suborder := range tree.GetSuborders() {
+		ids[suborder.GetOrder().GetID()] = treeIDs(suborder)
+	}
+	return ids
+}
+
+func (s *OrderSuite) TestGetOrderTree() {
+	tt := s.T()
+
+	past := time.Now().UTC().Add(-24 * time.Hour)
+	future := time.Now().UTC().Add(24 * time.Hour)
+
+	obj := setup.OrderObj()
+	for i, state := range []order.State{order.Completed, order.Blocked, order.NotStarted} {
+		obj.GetDelegatedTasks()[i].SetState(state)
+		obj.GetDelegatedTasks()[i].SetDeadline(past)
+	}
+	obj.GetDelegatedTasks()[1].SetDeadline(future)
+	o := setup.MustCreateOrderWithCleanup(tt, context.Background(), s.API, obj)
+
+	subObj := setup.OrderObj()
+	subObj.SetParentOrderID(o.GetDelegatedTasks()[2].GetID())
+	subObj.GetTask().SetState(order.InProgress)
+	subObj.GetTask().SetDeadline(past)
+	subObj.SetDelegatedTasks(nil)
+	sub := setup.MustCreateOrderWithCleanup(tt, context.Background(), s.API, subObj)
+
+	tt.Run("depth.0", func(t *testing.T) {
+		resp, err := s.API.GetOrderTree(t, context.Background(), &request.GetOrderTreeRequest{
+			ID: o.GetID(),
+		})
+		require.NoError(t, err)
+		require.Equal(t, map[meta.ID]any{
+			o.GetDelegatedTasks()[0].GetID(): map[meta.ID]any{},
+			o.GetDelegatedTasks()[1].GetID(): map[meta.ID]any{},
+			o.GetDelegatedTasks()[2].GetID(): map[meta.ID]any{
+				sub.GetID(): map[meta.ID]any{},
+			},
+		}, treeIDs(resp.GetTree()))
+		require.Equal(t, &order.Rollup{Total: 4, Completed: 1, Blocked: 1, Overdue: 2}, resp.GetTree().GetRollup())
+	})
+
+	tt.Run("depth.1", func(t *testing.T) {
+		resp, err := s.API.GetOrderTree(t, context.Background(), &request.GetOrderTreeRequest{
+			ID:    o.GetID(),
+			Depth: 1,
+		})
+		require.NoError(t, err)
+		require.Equal(t, map[meta.ID]any{
+			o.GetDelegatedTasks()[0].GetID(): map[meta.ID]any{},
+			o.GetDelegatedTasks()[1].GetID(): map[meta.ID]any{},
+			o.GetDelegatedTasks()[2].GetID(): map[meta.ID]any{},
+		}, treeIDs(resp.GetTree()))
+		require.Equal(t, &order.Rollup{Total: 4, Completed: 1, Blocked: 1, Overdue: 2}, resp.GetTree().GetRollup())
+		for _, suborder := range resp.GetTree().GetSuborders() {
+			if suborder.GetOrder().GetID() == o.GetDelegatedTasks()[2].GetID() {
+				require.Equal(t, &order.Rollup{Total: 1, Overdue: 1}, suborder.GetRollup()) // NOTE: rollup covers the levels cut off by depth
+			}
+		}
+	})
+
+	tt.Run("NotFound", func(t *testing.T) {
+		resp, err := s.API.GetOrderTree(t, context.Background(), &request.GetOrderTreeRequest{
+			ID: meta.NewID(),
+		})
+		require.Error(t, err)
+		require.Empty(t, resp)
+		require.Equal(t, http.StatusNotFound, err.GetStatusCode(), err)
+	})
+}
+
+func TestGetOrderTree_RootOrder(t *testing.T) {
+	svc := mgmtorder.NewServiceMgmtOrder(local.NewLocalRepositoryOrder(), nil, nil)
+	orderAPI := &apiordersvc.OrderAPISvc{Svc: svc}
+	root := svc.GetRootOrder(context.Background())
+
+	obj := setup.OrderObj()
+	obj.SetParentOrderID(root.GetID())
+	o := setup.MustCreateOrderWithCleanup(t, context.Background(), orderAPI, obj)
+
+	subObj := setup.OrderObj()
+	subObj.SetParentOrderID(o.GetDelegatedTasks()[0].GetID())
+	sub := setup.MustCreateOrderWithCleanup(t, context.Background(), orderAPI, subObj)
+
+	resp, err := orderAPI.GetOrderTree(t, context.Background(), &request.GetOrderTreeRequest{
+		ID: root.GetID(),
+	})
+	require.NoError(t, err)
+	require.Equal(t, root.GetID(), resp.GetTree().GetOrder().GetID())
+	require.Equal(t, map[meta.ID]any{
+		o.GetID(): map[meta.ID]any{
+			o.GetDelegatedTasks()[0].GetID(): map[meta.ID]any{
+				sub.GetID(): map[meta.ID]any{
+					sub.GetDelegatedTasks()[0].GetID(): map[meta.ID]any{},
+					sub.GetDelegatedTasks()[1].GetID(): map[meta.ID]any{},
+					sub.GetDelegatedTasks()[2].GetID(): map[meta.ID]any{},
+				},
+			},
+			o.GetDelegatedTasks()[1].GetID(): map[meta.ID]any{},
+			o.GetDelegatedTasks()[2].GetID(): map[meta.ID]any{},
+		},
+	}, treeIDs(resp.GetTree()))
+	require.Equal(t, 8, resp.GetTree().GetRollup().GetTotal())
+}
@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/moledoc/orderly/internal/domain/meta"
+	"github.com/moledoc/orderly/internal/domain/order"
+	"github.com/moledoc/orderly/internal/domain/request"
+	"github.com/moledoc/orderly/tests/compare"
+	"github.com/moledoc/orderly/tests/setup"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *OrderSuite) TestGetOrderVersions() {
+	tt := s.T()
+
+	original := setup.MustCreateOrderWithCleanup(tt, context.Background(), s.API, setup.OrderObj())
+
+	objectives := []string{"second objective", "third objective"}
+	for _, objective := range objectives {
+		_, err := s.API.PatchOrder(tt, context.Background(), &request.PatchOrderRequest{
+			Order: &order.Order{
+				Task: &order.Task{
+					ID:        original.GetID(),
+					Objective: objective,
+				},
+			},
+		})
+		require.NoError(tt, err)
+	}
+
+	current, err := s.API.GetOrderByID(tt, context.Background(), &request.GetOrderByIDRequest{
+		ID: original.GetID(),
+	})
+	require.NoError(tt, err)
+
+	opts := []cmp.Option{
+		cmpopts.EquateEmpty(),
+	}
+
+	tt.Run("versions", func(t *testing.T) {
+		resp, err := s.API.GetOrderVersions(t, context.Background(), &request.GetOrderVersionsRequest{
+			ID: original.GetID(),
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.GetVersions(), len(objectives)+1)
+
+		for i, version := range resp.GetVersions() {
+			require.Equal(t, uint(i+1), version.GetMeta().GetVersion())
+		}
+		compare.RequireEqual(t, original, resp.GetVersions()[0], opts...)
+		compare.RequireEqual(t, current.GetOrder(), resp.GetVersions()[len(objectives)], opts...)
+	})
+
+	tt.Run("version", func(t *testing.T) {
+		resp, err := s.API.GetOrderVersion(t, context.Background(), &request.GetOrderVersionRequest{
+			ID:      original.GetID(),
+			Version: 1,
+		})
+		require.NoError(t, err)
+		compare.RequireEqual(t, original, resp.GetOrder(), opts...)
+
+		resp, err = s.API.GetOrderVersion(t, context.Background(), &request.GetOrderVersionRequest{
+			ID:      original.GetID(),
+			Version: 2,
+		})
+		require.NoError(t, err)
+		require.Equal(t, objectives[0], resp.GetOrder().GetTask().GetObjective())
+	})
+}
+
+func (s *OrderSuite) TestGetOrderVersions_Failed() {
+	tt := s.T()
+
+	o := setup.MustCreateOrderWithCleanup(tt, context.Background(), s.API, setup.OrderObj())
+
+	tt.Run("versions.NotFound", func(t *testing.T) {
+		resp, err := s.API.GetOrderVersions(t, context.Background(), &request.GetOrderVersionsRequest{
+			ID: meta.NewID(),
+		})
+		require.Error(t, err)
+		require.Equal(t, http.StatusNotFound, err.GetStatusCode())
+		require.Empty(t, resp)
+	})
+
+	tt.Run("version.NotFound", func(t *testing.T) {
+		resp, err := s.API.GetOrderVersion(t, context.Background(), &request.GetOrderVersionRequest{
+			ID:      o.GetID(),
+			Version: o.GetMeta().GetVersion() + 1,
+		})
+		require.Error(t, err)
+		require.Equal(t, http.StatusNotFound, err.GetStatusCode())
+		require.Empty(t, resp)
+	})
+
+	tt.Run("version.Invalid", func(t *testing.T) {
+		resp, err := s.API.GetOrderVersion(t, context.Background(), &request.GetOrderVersionRequest{
+			ID:      o.GetID(),
+			Version: 0,
+		})
+		require.Error(t, err)
+		require.Equal(t, http.StatusBadRequest, err.GetStatusCode())
+		require.Empty(t, resp)
+	})
+}
@@ -3,12 +3,17 @@ package tests
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/moledoc/orderly/internal/domain/meta"
 	"github.com/moledoc/orderly/internal/domain/order"
 	"github.com/moledoc/orderly/internal/domain/request"
 	"github.com/moledoc/orderly/internal/domain/response"
+	"github.com/moledoc/orderly/internal/domain/user"
+	"github.com/moledoc/orderly/pkg/utils"
 	"github.com/moledoc/orderly/tests/compare"
 	"github.com/moledoc/orderly/tests/setup"
 	"github.com/stretchr/testify/require"
@@ -17,22 +22,28 @@ import (
 func (s *OrderSuite) TestGetOrders() {
 	tt := s.T()
 
-	createOrders := func(t *testing.T, count int) []*order.Order {
+	// NOTE: orders are scoped by a parent of their own, other tests' orders and the orders delegated by these are left out
+	createOrders := func(t *testing.T, parentOrderID meta.ID, count int) []*order.Order {
 		if count == 0 {
 			return nil
 		}
 		orders := make([]*order.Order, count)
 		for i := 1; i <= count; i++ {
-			orders[i-1] = setup.MustCreateOrderWithCleanup(t, context.Background(), s.API, setup.OrderObj())
+			obj := setup.OrderObj()
+			obj.SetParentOrderID(parentOrderID)
+			orders[i-1] = setup.MustCreateOrderWithCleanup(t, context.Background(), s.API, obj)
 		}
 		return orders
 	}
 
 	for _, i := range []int{0, 1, 10} {
 		tt.Run(fmt.Sprintf("count.%v", i), func(t *testing.T) {
-			orders := createOrders(t, i)
+			parentOrderID := meta.NewID()
+			orders := createOrders(t, parentOrderID, i)
 
-			resp, err := s.API.GetOrders(t, context.Background(), &request.GetOrdersRequest{})
+			resp, err := s.API.GetOrders(t, context.Background(), &request.GetOrdersRequest{
+				ParentOrderID: parentOrderID,
+			})
 			require.NoError(t, err)
 
 			opts := []cmp.Option{
@@ -41,6 +52,7 @@ func (s *OrderSuite) TestGetOrders() {
 
 			expected := &response.GetOrdersResponse{
 				Orders: orders,
+				Page:   &meta.Page{Total: len(orders)},
 			}
 			compare.RequireEqual(t, expected, resp, opts...)
 		})
@@ -50,13 +62,14 @@ func (s *OrderSuite) TestGetOrders() {
 func (s *OrderSuite) TestGetOrders_ByAccountable() {
 	tt := s.T()
 
-	createOrders := func(t *testing.T, count int) []*order.Order {
+	// NOTE: orders are scoped by an accountable of their own, delegated tasks are accountable to someone else
+	createOrders := func(t *testing.T, accountable string, count int) []*order.Order {
 		if count == 0 {
 			return nil
 		}
 		orders := make([]*order.Order, count)
 		for i := 1; i <= count; i++ {
-			orders[i-1] = setup.MustCreateOrderWithCleanup(t, context.Background(), s.API, setup.OrderObj())
+			orders[i-1] = setup.MustCreateOrderWithCleanup(t, context.Background(), s.API, setup.OrderObj(accountable))
 			setup.MustCreateOrderWithCleanup(t, context.Background(), s.API, setup.OrderObj("should-not-get-these-orders"))
 		}
 		return orders
@@ -64,10 +77,11 @@ func (s *OrderSuite) TestGetOrders_ByAccountable() {
 
 	for _, i := range []int{0, 1, 10} {
 		tt.Run(fmt.Sprintf("count.%v", i), func(t *testing.T) {
-			orders := createOrders(t, i)
+			accountable := utils.RandAlphanum()
+			orders := createOrders(t, accountable, i)
 
 			resp, err := s.API.GetOrders(t, context.Background(), &request.GetOrdersRequest{
-				Accountable: setup.OrderObj().GetTask().GetAccountable(),
+				Accountable: setup.OrderObj(accountable).GetTask().GetAccountable(),
 			})
 			require.NoError(t, err)
 
@@ -77,6 +91,7 @@ func (s *OrderSuite) TestGetOrders_ByAccountable() {
 
 			expected := &response.GetOrdersResponse{
 				Orders: orders,
+				Page:   &meta.Page{Total: len(orders)},
 			}
 			compare.RequireEqual(t, expected, resp, opts...)
 		})
@@ -86,8 +101,13 @@ func (s *OrderSuite) TestGetOrders_ByAccountable() {
 func (s *OrderSuite) TestGetOrders_ByParentOrderID() {
 	tt := s.T()
 
-	parent := setup.MustCreateOrderWithCleanup(tt, context.Background(), s.API, setup.OrderObj())
-	createOrders := func(t *testing.T, count int) []*order.Order {
+	// NOTE: every subtest has a parent of its own, which delegates only the subtest's orders
+	createParent := func(t *testing.T) *order.Order {
+		obj := setup.OrderObj()
+		obj.SetDelegatedTasks(nil)
+		return setup.MustCreateOrderWithCleanup(t, context.Background(), s.API, obj)
+	}
+	createOrders := func(t *testing.T, parent *order.Order, count int) []*order.Order {
 		if count == 0 {
 			return nil
 		}
@@ -103,7 +123,8 @@ func (s *OrderSuite) TestGetOrders_ByParentOrderID() {
 
 	for _, i := range []int{0, 1, 10} {
 		tt.Run(fmt.Sprintf("count.%v", i), func(t *testing.T) {
-			orders := createOrders(t, i)
+			parent := createParent(t)
+			orders := createOrders(t, parent, i)
 
 			resp, err := s.API.GetOrders(t, context.Background(), &request.GetOrdersRequest{
 				ParentOrderID: parent.GetID(),
@@ -116,8 +137,225 @@ func (s *OrderSuite) TestGetOrders_ByParentOrderID() {
 
 			expected := &response.GetOrdersResponse{
 				Orders: orders,
+				Page:   &meta.Page{Total: len(orders)},
 			}
 			compare.RequireEqual(t, expected, resp, opts...)
 		})
 	}
 }
+
+func (s *OrderSuite) TestGetOrders_Page() {
+	tt := s.T()
+
+	parentObj := setup.OrderObj()
+	parentObj.SetDelegatedTasks(nil)
+	parent := setup.MustCreateOrderWithCleanup(tt, context.Background(), s.API, parentObj)
+	var children []meta.ID
+	for i := range 3 {
+		obj := setup.OrderObj()
+		obj.SetParentOrderID(parent.GetID())
+		obj.SetDelegatedTasks(nil)
+		obj.GetTask().SetDeadline(parent.GetTask().GetDeadline().Add(-time.Duration(3-i) * time.Hour))
+		children = append(children, setup.MustCreateOrderWithCleanup(tt, context.Background(), s.API, obj).GetID())
+	}
+	ids := func(orders []*order.Order) []meta.ID {
+		var ids []meta.ID
+		for _, o := range orders {
+			ids = append(ids, o.GetID())
+		}
+		return ids
+	}
+
+	tt.Run("deadline", func(t *testing.T) {
+		resp, err := s.API.GetOrders(t, context.Background(), &request.GetOrdersRequest{
+			ParentOrderID: parent.GetID(),
+			Limit:         2,
+			Sort:          meta.SortDeadline,
+		})
+		require.NoError(t, err)
+		require.Equal(t, children[:2], ids(resp.GetOrders()))
+		require.Equal(t, 3, resp.GetPage().GetTotal())
+		require.NotEmpty(t, resp.GetPage().GetNextCursor())
+
+		resp, err = s.API.GetOrders(t, context.Background(), &request.GetOrdersRequest{
+			ParentOrderID: parent.GetID(),
+			Limit:         2,
+			Sort:          meta.SortDeadline,
+			Cursor:        resp.GetPage().GetNextCursor(),
+		})
+		require.NoError(t, err)
+		require.Equal(t, children[2:], ids(resp.GetOrders()))
+		require.Equal(t, &meta.Page{Total: 3}, resp.GetPage())
+	})
+
+	tt.Run("deadline.desc", func(t *testing.T) {
+		resp, err := s.API.GetOrders(t, context.Background(), &request.GetOrdersRequest{
+			ParentOrderID: parent.GetID(),
+			Limit:         1,
+			Sort:          "-" + meta.SortDeadline,
+		})
+		require.NoError(t, err)
+		require.Equal(t, children[2:], ids(resp.GetOrders()))
+	})
+}
+
+func (s *OrderSuite) TestGetOrders_Page_Failed() {
+	tt := s.T()
+
+	for name, req := range map[string]*request.GetOrdersRequest{
+		"invalid.sort":   {Sort: "priority"},
+		"invalid.cursor": {Limit: 1, Cursor: "not a cursor"},
+		"cursor.of.other.sort": {
+			Limit:  1,
+			Sort:   meta.SortState,
+			Cursor: (&meta.Cursor{Sort: meta.SortCreated, ID: meta.NewID()}).Encode(),
+		},
+	} {
+		tt.Run(name, func(t *testing.T) {
+			resp, err := s.API.GetOrders(t, context.Background(), req)
+			require.Error(t, err)
+			require.Empty(t, resp)
+			require.Equal(t, http.StatusBadRequest, err.GetStatusCode(), err)
+		})
+	}
+}
+
+func (s *OrderSuite) TestGetOrders_Filters() {
+	tt := s.T()
+
+	parentObj := setup.OrderObj()
+	parentObj.SetDelegatedTasks(nil)
+	parent := setup.MustCreateOrderWithCleanup(tt, context.Background(), s.API, parentObj)
+	sitrepBy := user.Email(fmt.Sprintf("sitrep.%v@example.com", utils.RandAlphanum()))
+
+	alphaObj := setup.OrderObj()
+	alphaObj.SetParentOrderID(parent.GetID())
+	alphaObj.GetTask().SetState(order.InProgress)
+	alphaObj.GetTask().SetObjective("Alpha LAUNCH plan")
+	alphaObj.GetTask().SetDeadline(parent.GetTask().GetDeadline().Add(-48 * time.Hour))
+	alphaObj.GetSitReps()[1].SetBy(sitrepBy)
+	alpha := setup.MustCreateOrderWithCleanup(tt, context.Background(), s.API, alphaObj)
+
+	between := time.Now().UTC()
+
+	betaObj := setup.OrderObj()
+	betaObj.SetParentOrderID(parent.GetID())
+	betaObj.SetDelegatedTasks(nil)
+	betaObj.SetSitReps(nil)
+	betaObj.GetTask().SetState(order.Blocked)
+	betaObj.GetTask().SetObjective("beta at 100%")
+	betaObj.GetTask().SetDeadline(parent.GetTask().GetDeadline().Add(-24 * time.Hour))
+	beta := setup.MustCreateOrderWithCleanup(tt, context.Background(), s.API, betaObj)
+
+	gammaObj := setup.OrderObj()
+	gammaObj.SetParentOrderID(parent.GetID())
+	gammaObj.SetDelegatedTasks(nil)
+	gammaObj.GetTask().SetObjective("gamma")
+	gammaObj.GetTask().SetDeadline(parent.GetTask().GetDeadline())
+	gamma := setup.MustCreateOrderWithCleanup(tt, context.Background(), s.API, gammaObj)
+
+	tcs := []struct {
+		name     string
+		req      *request.GetOrdersRequest
+		expected []*order.Order
+	}{
+		{
+			name:     "states",
+			req:      &request.GetOrdersRequest{States: []order.State{order.InProgress, order.Blocked}},
+			expected: []*order.Order{alpha, beta},
+		},
+		{
+			name: "deadline",
+			req: &request.GetOrdersRequest{
+				DeadlineFrom: parent.GetTask().GetDeadline().Add(-36 * time.Hour),
+				DeadlineTo:   parent.GetTask().GetDeadline(),
+			},
+			expected: []*order.Order{beta},
+		},
+		{
+			name:     "created.to",
+			req:      &request.GetOrdersRequest{CreatedTo: between},
+			expected: []*order.Order{alpha},
+		},
+		{
+			name:     "created.from",
+			req:      &request.GetOrdersRequest{CreatedFrom: between},
+			expected: []*order.Order{beta, gamma},
+		},
+		{
+			name:     "updated.from",
+			req:      &request.GetOrdersRequest{UpdatedFrom: between},
+			expected: []*order.Order{beta, gamma},
+		},
+		{
+			name:     "objective",
+			req:      &request.GetOrdersRequest{Objective: "alpha launch"},
+			expected: []*order.Order{alpha},
+		},
+		{
+			name:     "objective.wildcard",
+			req:      &request.GetOrdersRequest{Objective: "%"},
+			expected: []*order.Order{beta},
+		},
+		{
+			name:     "sitrep_by",
+			req:      &request.GetOrdersRequest{SitRepBy: sitrepBy},
+			expected: []*order.Order{alpha},
+		},
+		{
+			name:     "has_delegated_tasks",
+			req:      &request.GetOrdersRequest{HasDelegatedTasks: utils.Ptr(true)},
+			expected: []*order.Order{alpha},
+		},
+		{
+			name:     "has_no_delegated_tasks",
+			req:      &request.GetOrdersRequest{HasDelegatedTasks: utils.Ptr(false)},
+			expected: []*order.Order{beta, gamma},
+		},
+		{
+			name: "combined",
+			req: &request.GetOrdersRequest{
+				States:            []order.State{order.InProgress, order.NotStarted},
+				HasDelegatedTasks: utils.Ptr(false),
+			},
+			expected: []*order.Order{gamma},
+		},
+	}
+
+	ids := func(orders []*order.Order) []meta.ID {
+		var ids []meta.ID
+		for _, o := range orders {
+			ids = append(ids, o.GetID())
+		}
+		return ids
+	}
+	for _, tc := range tcs {
+		tt.Run(tc.name, func(t *testing.T) {
+			tc.req.ParentOrderID = parent.GetID()
+			resp, err := s.API.GetOrders(t, context.Background(), tc.req)
+			require.NoError(t, err)
+			require.ElementsMatch(t, ids(tc.expected), ids(resp.GetOrders()))
+			require.Equal(t, len(tc.expected), resp.GetPage().GetTotal())
+		})
+	}
+}
+
+func (s *OrderSuite) TestGetOrders_Filters_Failed() {
+	tt := s.T()
+
+	now := time.Now().UTC()
+	for name, req := range map[string]*request.GetOrdersRequest{
+		"invalid.state":     {States: []order.State{order.Completed + 1}},
+		"invalid.deadline":  {DeadlineFrom: now, DeadlineTo: now.Add(-time.Hour)},
+		"invalid.created":   {CreatedFrom: now, CreatedTo: now},
+		"invalid.updated":   {UpdatedFrom: now.Add(time.Hour), UpdatedTo: now},
+		"invalid.sitrep_by": {SitRepBy: "not an email"},
+	} {
+		tt.Run(name, func(t *testing.T) {
+			resp, err := s.API.GetOrders(t, context.Background(), req)
+			require.Error(t, err)
+			require.Empty(t, resp)
+			require.Equal(t, http.StatusBadRequest, err.GetStatusCode(), err)
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/moledoc/orderly/internal/domain/errwrap"
+	"github.com/moledoc/orderly/internal/domain/meta"
+	"github.com/moledoc/orderly/internal/domain/order"
+	"github.com/moledoc/orderly/internal/domain/request"
+	"github.com/moledoc/orderly/tests/setup"
+	"github.com/stretchr/testify/require"
+)
+
+func delegatedTaskIDs(o *order.Order) []meta.ID {
+	var ids []meta.ID
+	for _, delegated := range o.GetDelegatedTasks() {
+		ids = append(ids, delegated.GetID())
+	}
+	return ids
+}
+
+func (s *OrderSuite) TestMoveOrder() {
+	tt := s.T()
+
+	oldParent := setup.MustCreateOrderWithCleanup(tt, context.Background(), s.API, setup.OrderObj())
+	newParent := setup.MustCreateOrderWithCleanup(tt, context.Background(), s.API, setup.OrderObj())
+	obj := setup.OrderObj()
+	obj.SetParentOrderID(oldParent.GetID())
+	o := setup.MustCreateOrderWithCleanup(tt, context.Background(), s.API, obj)
+
+	resp, err := s.API.MoveOrder(tt, context.Background(), &request.MoveOrderRequest{
+		ID:              o.GetID(),
+		ParentOrderID:   newParent.GetID(),
+		ExpectedVersion: o.GetMeta().GetVersion(),
+	})
+	require.NoError(tt, err)
+	require.Equal(tt, newParent.GetID(), resp.GetOrder().GetParentOrderID())
+	require.Equal(tt, o.GetMeta().GetVersion()+1, resp.GetOrder().GetMeta().GetVersion())
+	require.Equal(tt, delegatedTaskIDs(o), delegatedTaskIDs(resp.GetOrder()))
+
+	tt.Run("parents", func(t *testing.T) {
+		respOld, err := s.API.GetOrderByID(t, context.Background(), &request.GetOrderByIDRequest{ID: oldParent.GetID()})
+		require.NoError(t, err)
+		require.NotContains(t, delegatedTaskIDs(respOld.GetOrder()), o.GetID())
+		require.Equal(t, oldParent.GetMeta().GetVersion()+1, respOld.GetOrder().GetMeta().GetVersion())
+
+		respNew, err := s.API.GetOrderByID(t, context.Background(), &request.GetOrderByIDRequest{ID: newParent.GetID()})
+		require.NoError(t, err)
+		require.Contains(t, delegatedTaskIDs(respNew.GetOrder()), o.GetID())
+		require.Equal(t, newParent.GetMeta().GetVersion()+1, respNew.GetOrder().GetMeta().GetVersion())
+	})
+
+	tt.Run("subtree", func(t *testing.T) {
+		respGet, err := s.API.GetOrders(t, context.Background(), &request.GetOrdersRequest{ParentOrderID: o.GetID()})
+		require.NoError(t, err)
+		var ids []meta.ID
+		for _, delegated := range respGet.GetOrders() {
+			ids = append(ids, delegated.GetID())
+		}
+		require.ElementsMatch(t, delegatedTaskIDs(o), ids)
+	})
+
+	tt.Run("history", func(t *testing.T) {
+		respDiff, err := s.API.GetOrderDiff(t, context.Background(), &request.GetOrderDiffRequest{ID: o.GetID()})
+		require.NoError(t, err)
+		require.NotEmpty(t, respDiff.GetDiffs())
+		last := respDiff.GetDiffs()[len(respDiff.GetDiffs())-1]
+		require.Equal(t, []*order.FieldChange{{
+			Field: "parent_order_id",
+			Kind:  order.FieldChanged,
+			Old:   string(oldParent.GetID()),
+			New:   string(newParent.GetID()),
+		}}, last.Changes)
+	})
+}
+
+func (s *OrderSuite) TestMoveOrder_Failed() {
+	tt := s.T()
+
+	o := setup.MustCreateOrderWithCleanup(tt, context.Background(), s.API, setup.OrderObj())
+	subObj := setup.OrderObj()
+	subObj.SetParentOrderID(o.GetDelegatedTasks()[0].GetID())
+	sub := setup.MustCreateOrderWithCleanup(tt, context.Background(), s.API, subObj)
+
+	invalidParent := func(t *testing.T, err errwrap.Error, message string) {
+		t.Helper()
+		require.Error(t, err)
+		require.Equal(t, http.StatusUnprocessableEntity, err.GetStatusCode(), err)
+		require.Equal(t, []errwrap.FieldError{{Field: "parent_order_id", Message: message}}, err.GetFields())
+	}
+
+	tt.Run("parent.not.found", func(t *testing.T) {
+		parentID := meta.NewID()
+		_, err := s.API.MoveOrder(t, context.Background(), &request.MoveOrderRequest{
+			ID:            o.GetID(),
+			ParentOrderID: parentID,
+		})
+		invalidParent(t, err, fmt.Sprintf("order '%s' not found", parentID))
+	})
+
+	tt.Run("cycle.self", func(t *testing.T) {
+		_, err := s.API.MoveOrder(t, context.Background(), &request.MoveOrderRequest{
+			ID:            o.GetID(),
+			ParentOrderID: o.GetID(),
+		})
+		invalidParent(t, err, fmt.Sprintf("order '%s' is delegated below order '%s'", o.GetID(), o.GetID()))
+	})
+
+	tt.Run("cycle.subtree", func(t *testing.T) {
+		_, err := s.API.MoveOrder(t, context.Background(), &request.MoveOrderRequest{
+			ID:            o.GetID(),
+			ParentOrderID: sub.GetID(),
+		})
+		invalidParent(t, err, fmt.Sprintf("order '%s' is delegated below order '%s'", sub.GetID(), o.GetID()))
+
+		respGet, err := s.API.GetOrderByID(t, context.Background(), &request.GetOrderByIDRequest{ID: o.GetID()})
+		require.NoError(t, err)
+		require.Equal(t, o.GetParentOrderID(), respGet.GetOrder().GetParentOrderID())
+		require.Equal(t, o.GetMeta().GetVersion(), respGet.GetOrder().GetMeta().GetVersion())
+	})
+
+	tt.Run("patch.cycle", func(t *testing.T) {
+		_, err := s.API.PatchOrder(t, context.Background(), &request.PatchOrderRequest{
+			Order: &order.Order{
+				Task:          &order.Task{ID: o.GetID()},
+				ParentOrderID: sub.GetID(),
+			},
+		})
+		require.Error(t, err)
+		require.Equal(t, http.StatusUnprocessableEntity, err.GetStatusCode(), err)
+		require.Equal(t, []errwrap.FieldError{{
+			Field:   "order.parent_order_id",
+			Message: fmt.Sprintf("order '%s' is delegated below order '%s'", sub.GetID(), o.GetID()),
+		}}, err.GetFields())
+	})
+
+	tt.Run("version.mismatch", func(t *testing.T) {
+		parent := setup.MustCreateOrderWithCleanup(t, context.Background(), s.API, setup.OrderObj())
+		_, err := s.API.MoveOrder(t, context.Background(), &request.MoveOrderRequest{
+			ID:              o.GetID(),
+			ParentOrderID:   parent.GetID(),
+			ExpectedVersion: o.GetMeta().GetVersion() + 1,
+		})
+		require.Error(t, err)
+		require.Equal(t, http.StatusConflict, err.GetStatusCode(), err)
+	})
+}
@@ -13,8 +13,8 @@ import (
 )
 
 func TestOrderHTTPTestSuite(t *testing.T) {
-	orderMux := router.RouteOrder(mgmtorder.NewServiceMgmtOrder(local.NewLocalRepositoryOrder()))
-	userMux := router.RouteUser(mgmtuser.NewServiceMgmtUser(local.NewLocalRepositoryUser()))
+	orderMux := router.RouteOrder(mgmtorder.NewServiceMgmtOrder(local.NewLocalRepositoryOrder(), nil, nil))
+	userMux := router.RouteUser(mgmtuser.NewServiceMgmtUser(local.NewLocalRepositoryUser(), nil))
 	t.Run("OrderAPIHTTPTest", func(t *testing.T) {
 		suite.Run(t, &OrderSuite{
 			API:     apiorderhttptest.NewOrderAPIHTTPTest(orderMux),
@@ -24,8 +24,8 @@ func TestOrderHTTPTestSuite(t *testing.T) {
 }
 
 func TestOrderHTTPTestPerformanceSuite(t *testing.T) {
-	orderMux := router.RouteOrder(mgmtorder.NewServiceMgmtOrder(local.NewLocalRepositoryOrder()))
-	userMux := router.RouteUser(mgmtuser.NewServiceMgmtUser(local.NewLocalRepositoryUser()))
+	orderMux := router.RouteOrder(mgmtorder.NewServiceMgmtOrder(local.NewLocalRepositoryOrder(), nil, nil))
+	userMux := router.RouteUser(mgmtuser.NewServiceMgmtUser(local.NewLocalRepositoryUser(), nil))
 
 	t.Run("OrderAPIHTTPTestPerformance", func(t *testing.T) {
 		suite.Run(t, &OrderPerformanceSuite{
@@ -19,8 +19,8 @@ func TestOrderReqSuite(t *testing.T) {
 	var wg sync.WaitGroup
 	wg.Add(1)
 	go func() {
-		router.RouteOrder(mgmtorder.NewServiceMgmtOrder(local.NewLocalRepositoryOrder()))
-		router.RouteUser(mgmtuser.NewServiceMgmtUser(local.NewLocalRepositoryUser()))
+		router.RouteOrder(mgmtorder.NewServiceMgmtOrder(local.NewLocalRepositoryOrder(), nil, nil))
+		router.RouteUser(mgmtuser.NewServiceMgmtUser(local.NewLocalRepositoryUser(), nil))
 		go http.ListenAndServe(":8080", nil)
 		wg.Done()
 	}()
@@ -39,8 +39,8 @@ func TestOrderReqPerformanceSuite(t *testing.T) {
 	var wg sync.WaitGroup
 	wg.Add(1)
 	go func() {
-		router.RouteOrder(mgmtorder.NewServiceMgmtOrder(local.NewLocalRepositoryOrder()))
-		router.RouteUser(mgmtuser.NewServiceMgmtUser(local.NewLocalRepositoryUser()))
+		router.RouteOrder(mgmtorder.NewServiceMgmtOrder(local.NewLocalRepositoryOrder(), nil, nil))
+		router.RouteUser(mgmtuser.NewServiceMgmtUser(local.NewLocalRepositoryUser(), nil))
 		go http.ListenAndServe(":8080", nil)
 		wg.Done()
 	}()
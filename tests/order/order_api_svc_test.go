@@ -3,8 +3,10 @@ package tests
 import (
 	"testing"
 
+	"github.com/moledoc/orderly/internal/repository/sqldb"
 	apiordersvc "github.com/moledoc/orderly/tests/api/order/svc"
 	apiusersvc "github.com/moledoc/orderly/tests/api/user/svc"
+	"github.com/moledoc/orderly/tests/setup"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -18,6 +20,17 @@ func TestOrderSvcSuite(t *testing.T) {
 	})
 }
 
+func TestOrderSvcSQLSuite(t *testing.T) {
+
+	t.Run("OrderAPISvcSQL", func(t *testing.T) {
+		db := setup.SQLiteDB(t)
+		suite.Run(t, &OrderSuite{
+			API:     apiordersvc.NewOrderAPISvcWithRepository(sqldb.NewSQLRepositoryOrder(db)),
+			UserAPI: apiusersvc.NewUserAPISvcWithRepository(sqldb.NewSQLRepositoryUser(db)),
+		})
+	})
+}
+
 func TestOrderSvcPerformanceSuite(t *testing.T) {
 
 	t.Run("OrderAPISvcPerformance", func(t *testing.T) {
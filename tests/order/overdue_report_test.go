@@ -0,0 +1,180 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/moledoc/orderly/internal/domain/meta"
+	"github.com/moledoc/orderly/internal/domain/order"
+	"github.com/moledoc/orderly/internal/domain/request"
+	"github.com/moledoc/orderly/internal/domain/user"
+	"github.com/moledoc/orderly/internal/repository/local"
+	"github.com/moledoc/orderly/internal/service/mgmtorder"
+	"github.com/moledoc/orderly/pkg/utils"
+	apiordersvc "github.com/moledoc/orderly/tests/api/order/svc"
+	apiusersvc "github.com/moledoc/orderly/tests/api/user/svc"
+	"github.com/moledoc/orderly/tests/setup"
+	"github.com/stretchr/testify/require"
+)
+
+// reportIDs lists the overdue and at risk task ids of each group, in the order of the report.
+func reportIDs(groups []*order.ReportGroup) []string {
+	var ids []string
+	for _, group := range groups {
+		for _, task := range group.GetOverdue() {
+			ids = append(ids, fmt.Sprintf("%s:overdue:%s", group.GetAccountable(), task.GetID()))
+		}
+		for _, task := range group.GetAtRisk() {
+			ids = append(ids, fmt.Sprintf("%s:at_risk:%s", group.GetAccountable(), task.GetID()))
+		}
+	}
+	return ids
+}
+
+func (s *OrderSuite) TestGetOverdueReport() {
+	tt := s.T()
+
+	now := time.Now().UTC()
+	suffix := utils.RandAlphanum()
+	overdue := user.Email(fmt.Sprintf("a.overdue.%v@example.com", suffix))
+	atRisk := user.Email(fmt.Sprintf("b.at.risk.%v@example.com", suffix))
+
+	obj := setup.OrderObj()
+	obj.GetDelegatedTasks()[0].SetAccountable(overdue)
+	obj.GetDelegatedTasks()[0].SetDeadline(now.Add(-24 * time.Hour))
+	obj.GetDelegatedTasks()[1].SetAccountable(atRisk)
+	obj.GetDelegatedTasks()[1].SetState(order.Blocked)
+	obj.GetDelegatedTasks()[1].SetDeadline(now.Add(2 * 24 * time.Hour))
+	obj.GetDelegatedTasks()[2].SetAccountable(atRisk)
+	obj.GetDelegatedTasks()[2].SetState(order.HavingIssues)
+	obj.GetDelegatedTasks()[2].SetDeadline(now.Add(20 * 24 * time.Hour))
+	o := setup.MustCreateOrderWithCleanup(tt, context.Background(), s.API, obj)
+	delegated := o.GetDelegatedTasks()
+
+	tt.Run("default", func(t *testing.T) {
+		resp, err := s.API.GetOverdueReport(t, context.Background(), &request.GetOverdueReportRequest{
+			RootOrderID: o.GetID(),
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			fmt.Sprintf("%s:overdue:%s", overdue, delegated[0].GetID()),
+			fmt.Sprintf("%s:at_risk:%s", atRisk, delegated[1].GetID()),
+		}, reportIDs(resp.GetGroups()))
+	})
+
+	tt.Run("days", func(t *testing.T) {
+		resp, err := s.API.GetOverdueReport(t, context.Background(), &request.GetOverdueReportRequest{
+			RootOrderID: o.GetID(),
+			Days:        30,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			fmt.Sprintf("%s:overdue:%s", overdue, delegated[0].GetID()),
+			fmt.Sprintf("%s:at_risk:%s", atRisk, delegated[1].GetID()),
+			fmt.Sprintf("%s:at_risk:%s", atRisk, delegated[2].GetID()),
+		}, reportIDs(resp.GetGroups()))
+	})
+
+	tt.Run("all.orders", func(t *testing.T) {
+		resp, err := s.API.GetOverdueReport(t, context.Background(), &request.GetOverdueReportRequest{})
+		require.NoError(t, err)
+		require.Contains(t, reportIDs(resp.GetGroups()), fmt.Sprintf("%s:overdue:%s", overdue, delegated[0].GetID()))
+	})
+
+	tt.Run("completed", func(t *testing.T) {
+		_, err := s.API.PatchDelegatedTasks(t, context.Background(), &request.PatchDelegatedTasksRequest{
+			OrderID: o.GetID(),
+			Tasks:   []*order.Task{{ID: delegated[0].GetID(), State: utils.Ptr(order.Completed)}},
+		})
+		require.NoError(t, err)
+
+		resp, err := s.API.GetOverdueReport(t, context.Background(), &request.GetOverdueReportRequest{
+			RootOrderID: o.GetID(),
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			fmt.Sprintf("%s:at_risk:%s", atRisk, delegated[1].GetID()),
+		}, reportIDs(resp.GetGroups()))
+	})
+}
+
+func (s *OrderSuite) TestGetOverdueReport_Failed() {
+	tt := s.T()
+
+	tt.Run("invalid.root_order_id", func(t *testing.T) {
+		resp, err := s.API.GetOverdueReport(t, context.Background(), &request.GetOverdueReportRequest{
+			RootOrderID: "invalid",
+		})
+		require.Error(t, err)
+		require.Empty(t, resp)
+		require.Equal(t, http.StatusBadRequest, err.GetStatusCode(), err)
+	})
+
+	tt.Run("root.not.found", func(t *testing.T) {
+		resp, err := s.API.GetOverdueReport(t, context.Background(), &request.GetOverdueReportRequest{
+			RootOrderID: meta.NewID(),
+		})
+		require.Error(t, err)
+		require.Empty(t, resp)
+		require.Equal(t, http.StatusNotFound, err.GetStatusCode(), err)
+	})
+
+	tt.Run("supervisor.without.users", func(t *testing.T) {
+		resp, err := s.API.GetOverdueReport(t, context.Background(), &request.GetOverdueReportRequest{
+			Supervisor: "supervisor@example.com",
+		})
+		require.Error(t, err)
+		require.Empty(t, resp)
+		require.Equal(t, http.StatusNotImplemented, err.GetStatusCode(), err)
+	})
+}
+
+func TestGetOverdueReport_Supervisor(t *testing.T) {
+	userAPI := apiusersvc.NewUserAPISvc()
+	orderAPI := &apiordersvc.OrderAPISvc{Svc: mgmtorder.NewServiceMgmtOrder(local.NewLocalRepositoryOrder(), userAPI.Svc, nil)}
+
+	supervisor := setup.MustCreateUserWithCleanup(t, context.Background(), userAPI, setup.UserObj(utils.RandAlphanum()))
+	subordinateObj := setup.UserObj(utils.RandAlphanum())
+	subordinateObj.SetSupervisor(supervisor.GetEmail())
+	subordinate := setup.MustCreateUserWithCleanup(t, context.Background(), userAPI, subordinateObj)
+	indirectObj := setup.UserObj(utils.RandAlphanum())
+	indirectObj.SetSupervisor(subordinate.GetEmail())
+	indirect := setup.MustCreateUserWithCleanup(t, context.Background(), userAPI, indirectObj)
+
+	obj := setup.OrderObj()
+	obj.GetTask().SetAccountable(supervisor.GetEmail())
+	for _, sitrep := range obj.GetSitReps() {
+		sitrep.SetBy(supervisor.GetEmail())
+	}
+	for i, delegated := range obj.GetDelegatedTasks() {
+		delegated.SetAccountable(supervisor.GetEmail())
+		switch i {
+		case 0:
+			delegated.SetAccountable(subordinate.GetEmail())
+		case 1:
+			delegated.SetAccountable(indirect.GetEmail())
+		}
+		delegated.SetDeadline(time.Now().UTC().Add(-time.Hour))
+	}
+	o := setup.MustCreateOrderWithCleanup(t, context.Background(), orderAPI, obj)
+
+	resp, err := orderAPI.GetOverdueReport(t, context.Background(), &request.GetOverdueReportRequest{
+		RootOrderID: o.GetID(),
+		Supervisor:  supervisor.GetEmail(),
+	})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{
+		fmt.Sprintf("%s:overdue:%s", subordinate.GetEmail(), o.GetDelegatedTasks()[0].GetID()),
+		fmt.Sprintf("%s:overdue:%s", indirect.GetEmail(), o.GetDelegatedTasks()[1].GetID()),
+	}, reportIDs(resp.GetGroups()))
+
+	resp, err = orderAPI.GetOverdueReport(t, context.Background(), &request.GetOverdueReportRequest{
+		RootOrderID: o.GetID(),
+		Supervisor:  user.Email(fmt.Sprintf("%s@example.com", utils.RandAlphanum())),
+	})
+	require.NoError(t, err)
+	require.Empty(t, resp.GetGroups())
+}
@@ -2,6 +2,7 @@ package tests
 
 import (
 	"context"
+	"net/http"
 	"testing"
 	"time"
 
@@ -154,3 +155,27 @@ func (s *OrderSuite) TestPatchDelegatedTasks() {
 		})
 	}
 }
+
+func (s *OrderSuite) TestPatchDelegatedTasks_Failed() {
+	tt := s.T()
+
+	tt.Run("version.stale", func(t *testing.T) {
+		o := setup.MustCreateOrderWithCleanup(t, context.Background(), s.API, setup.OrderObj())
+
+		_, err := s.API.PatchDelegatedTasks(t, context.Background(), &request.PatchDelegatedTasksRequest{
+			OrderID:         o.GetID(),
+			Tasks:           []*order.Task{{ID: o.GetDelegatedTasks()[0].GetID(), Objective: "first objective"}},
+			ExpectedVersion: o.GetMeta().GetVersion(),
+		})
+		require.NoError(t, err)
+
+		resp, err := s.API.PatchDelegatedTasks(t, context.Background(), &request.PatchDelegatedTasksRequest{
+			OrderID:         o.GetID(),
+			Tasks:           []*order.Task{{ID: o.GetDelegatedTasks()[0].GetID(), Objective: "second objective"}},
+			ExpectedVersion: o.GetMeta().GetVersion(),
+		})
+		require.Error(t, err)
+		require.Empty(t, resp)
+		require.Equal(t, http.StatusConflict, err.GetStatusCode(), err)
+	})
+}
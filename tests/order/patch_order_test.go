@@ -2,11 +2,11 @@ package tests
 
 import (
 	"context"
+	"net/http"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
-	"github.com/moledoc/orderly/internal/domain/meta"
 	"github.com/moledoc/orderly/internal/domain/order"
 	"github.com/moledoc/orderly/internal/domain/request"
 	"github.com/moledoc/orderly/internal/domain/response"
@@ -19,6 +19,7 @@ import (
 func (s *OrderSuite) TestPatchOrder() {
 	tt := s.T()
 
+	parent := setup.MustCreateOrderWithCleanup(tt, context.Background(), s.API, setup.OrderObj())
 	expected := setup.MustCreateOrderWithCleanup(tt, context.Background(), s.API, setup.OrderObj())
 
 	changes := []struct {
@@ -28,13 +29,12 @@ func (s *OrderSuite) TestPatchOrder() {
 		{
 			name: "parentOrgId",
 			f: func() *request.PatchOrderRequest {
-				newID := meta.NewID()
-				expected.SetParentOrderID(newID)
+				expected.SetParentOrderID(parent.GetID())
 
 				return &request.PatchOrderRequest{
 					Order: &order.Order{
 						Task:          &order.Task{ID: expected.GetID()},
-						ParentOrderID: newID,
+						ParentOrderID: parent.GetID(),
 					},
 				}
 			},
@@ -47,7 +47,7 @@ func (s *OrderSuite) TestPatchOrder() {
 				patchedTask.SetState(order.Blocked)
 				patchedTask.SetAccountable("changed@email.com")
 				patchedTask.SetObjective("patched main objective")
-				patchedTask.SetDeadline(time.Now().UTC())
+				patchedTask.SetDeadline(time.Now().UTC().Add(30 * 24 * time.Hour))
 
 				expected.SetTask(patchedTask)
 
@@ -69,7 +69,7 @@ func (s *OrderSuite) TestPatchOrder() {
 					State:       utils.Ptr(order.InProgress),
 					Accountable: "new.accountable@email.com",
 					Objective:   "new objective",
-					Deadline:    time.Now().UTC().Add(30 * 24 * time.Hour),
+					Deadline:    time.Now().UTC().Add(24 * time.Hour),
 				}
 				expected.GetDelegatedTasks()[0].SetState(patchedDelegatedTask.GetState())
 				expected.GetDelegatedTasks()[0].SetAccountable(patchedDelegatedTask.GetAccountable())
@@ -158,6 +158,7 @@ func (s *OrderSuite) TestPatchOrder() {
 
 			opts := []cmp.Option{
 				compare.IgnorePaths("Order.Meta.Updated"),
+				compare.IgnorePaths("DeadlineConflicts"), // NOTE: covered in TestDeadlineConflicts
 				compare.ComparerState(),
 				compare.SorterOrder(compare.SortOrderByID),
 				compare.SorterTask(compare.SortTaskByID),
@@ -177,3 +178,30 @@ func (s *OrderSuite) TestPatchOrder() {
 		})
 	}
 }
+
+func (s *OrderSuite) TestPatchOrder_Failed() {
+	tt := s.T()
+
+	tt.Run("version.stale", func(t *testing.T) {
+		o := setup.MustCreateOrderWithCleanup(t, context.Background(), s.API, setup.OrderObj())
+
+		respPatch, err := s.API.PatchOrder(t, context.Background(), &request.PatchOrderRequest{
+			Order: &order.Order{
+				Task: &order.Task{ID: o.GetID(), Objective: "first objective"},
+			},
+			ExpectedVersion: o.GetMeta().GetVersion(),
+		})
+		require.NoError(t, err)
+		require.Equal(t, o.GetMeta().GetVersion()+1, respPatch.GetOrder().GetMeta().GetVersion())
+
+		resp, err := s.API.PatchOrder(t, context.Background(), &request.PatchOrderRequest{
+			Order: &order.Order{
+				Task: &order.Task{ID: o.GetID(), Objective: "second objective"},
+			},
+			ExpectedVersion: o.GetMeta().GetVersion(),
+		})
+		require.Error(t, err)
+		require.Empty(t, resp)
+		require.Equal(t, http.StatusConflict, err.GetStatusCode(), err)
+	})
+}
@@ -2,6 +2,7 @@ package tests
 
 import (
 	"context"
+	"net/http"
 	"testing"
 	"time"
 
@@ -194,3 +195,27 @@ func (s *OrderSuite) TestPatchSitReps() {
 		})
 	}
 }
+
+func (s *OrderSuite) TestPatchSitReps_Failed() {
+	tt := s.T()
+
+	tt.Run("version.stale", func(t *testing.T) {
+		o := setup.MustCreateOrderWithCleanup(t, context.Background(), s.API, setup.OrderObj())
+
+		_, err := s.API.PatchSitReps(t, context.Background(), &request.PatchSitRepsRequest{
+			OrderID:         o.GetID(),
+			SitReps:         []*order.SitRep{{ID: o.GetSitReps()[0].GetID(), Situation: "first situation"}},
+			ExpectedVersion: o.GetMeta().GetVersion(),
+		})
+		require.NoError(t, err)
+
+		resp, err := s.API.PatchSitReps(t, context.Background(), &request.PatchSitRepsRequest{
+			OrderID:         o.GetID(),
+			SitReps:         []*order.SitRep{{ID: o.GetSitReps()[0].GetID(), Situation: "second situation"}},
+			ExpectedVersion: o.GetMeta().GetVersion(),
+		})
+		require.Error(t, err)
+		require.Empty(t, resp)
+		require.Equal(t, http.StatusConflict, err.GetStatusCode(), err)
+	})
+}
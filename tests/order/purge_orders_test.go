@@ -0,0 +1,108 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/moledoc/orderly/internal/domain/meta"
+	"github.com/moledoc/orderly/internal/domain/request"
+	"github.com/moledoc/orderly/tests/setup"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *OrderSuite) TestPurgeOrders() {
+	tt := s.T()
+
+	o := setup.MustCreateOrderWithCleanup(tt, context.Background(), s.API, setup.OrderObj())
+	_, err := s.API.DeleteOrder(tt, context.Background(), &request.DeleteOrderRequest{
+		ID: o.GetID(),
+	})
+	require.NoError(tt, err)
+
+	tt.Run("retention.not_passed", func(t *testing.T) {
+		resp, err := s.API.PurgeOrders(t, context.Background(), &request.PurgeOrdersRequest{
+			Before: time.Now().UTC().Add(-time.Hour),
+		})
+		require.NoError(t, err)
+		require.NotContains(t, resp.GetIDs(), o.GetID())
+		require.NotNil(t, s.findDeletedOrder(t, o.GetID()), "order not in trash")
+	})
+
+	tt.Run("retention.passed", func(t *testing.T) {
+		resp, err := s.API.PurgeOrders(t, context.Background(), &request.PurgeOrdersRequest{
+			Before: time.Now().UTC().Add(time.Second),
+		})
+		require.NoError(t, err)
+		require.Contains(t, resp.GetIDs(), o.GetID())
+		require.Nil(t, s.findDeletedOrder(t, o.GetID()), "purged order still in trash")
+
+		_, err = s.API.RestoreOrder(t, context.Background(), &request.RestoreOrderRequest{
+			ID: o.GetID(),
+		})
+		require.Error(t, err)
+		require.Equal(t, http.StatusNotFound, err.GetStatusCode(), err)
+
+		_, err = s.API.GetOrderVersions(t, context.Background(), &request.GetOrderVersionsRequest{
+			ID: o.GetID(),
+		})
+		require.Error(t, err)
+		require.Equal(t, http.StatusNotFound, err.GetStatusCode(), err)
+	})
+}
+
+func (s *OrderSuite) TestPurgeOrders_RestoredDelegated() {
+	tt := s.T()
+
+	o := setup.MustCreateOrderWithCleanup(tt, context.Background(), s.API, setup.OrderObj())
+	delegated := o.GetDelegatedTasks()[0]
+	_, err := s.API.DeleteDelegatedTasks(tt, context.Background(), &request.DeleteDelegatedTasksRequest{
+		OrderID:          o.GetID(),
+		DelegatedTaskIDs: []meta.ID{delegated.GetID()},
+	})
+	require.NoError(tt, err)
+	_, err = s.API.DeleteOrder(tt, context.Background(), &request.DeleteOrderRequest{
+		ID: o.GetID(),
+	})
+	require.NoError(tt, err)
+
+	_, err = s.API.RestoreOrder(tt, context.Background(), &request.RestoreOrderRequest{
+		ID: delegated.GetID(),
+	})
+	require.Error(tt, err)
+	require.Equal(tt, http.StatusConflict, err.GetStatusCode(), err)
+
+	_, err = s.API.RestoreOrder(tt, context.Background(), &request.RestoreOrderRequest{ // NOTE: delegated order was trashed on its own, so it stays in trash
+		ID: o.GetID(),
+	})
+	require.NoError(tt, err)
+	_, err = s.API.RestoreOrder(tt, context.Background(), &request.RestoreOrderRequest{
+		ID: delegated.GetID(),
+	})
+	require.NoError(tt, err)
+
+	resp, err := s.API.PurgeOrders(tt, context.Background(), &request.PurgeOrdersRequest{
+		Before: time.Now().UTC().Add(time.Second),
+	})
+	require.NoError(tt, err)
+	require.NotContains(tt, resp.GetIDs(), o.GetID())
+	require.NotContains(tt, resp.GetIDs(), delegated.GetID())
+
+	respGet, err := s.API.GetOrderByID(tt, context.Background(), &request.GetOrderByIDRequest{
+		ID: delegated.GetID(),
+	})
+	require.NoError(tt, err)
+	require.Equal(tt, delegated.GetID(), respGet.GetOrder().GetTask().GetID())
+}
+
+func (s *OrderSuite) TestPurgeOrders_Failed() {
+	tt := s.T()
+
+	tt.Run("before.missing", func(t *testing.T) {
+		resp, err := s.API.PurgeOrders(t, context.Background(), &request.PurgeOrdersRequest{})
+		require.Error(t, err)
+		require.Empty(t, resp)
+		require.Equal(t, http.StatusBadRequest, err.GetStatusCode(), err)
+	})
+}
@@ -0,0 +1,136 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/moledoc/orderly/internal/domain/meta"
+	"github.com/moledoc/orderly/internal/domain/order"
+	"github.com/moledoc/orderly/internal/domain/request"
+	"github.com/moledoc/orderly/internal/domain/response"
+	"github.com/moledoc/orderly/tests/compare"
+	"github.com/moledoc/orderly/tests/setup"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *OrderSuite) findDeletedOrder(t *testing.T, id meta.ID) *order.Order {
+	t.Helper()
+
+	resp, err := s.API.GetDeletedOrders(t, context.Background(), &request.GetDeletedOrdersRequest{})
+	require.NoError(t, err)
+
+	idx := slices.IndexFunc(resp.GetOrders(), func(o *order.Order) bool {
+		return o.GetID() == id
+	})
+	if idx < 0 {
+		return nil
+	}
+	return resp.GetOrders()[idx]
+}
+
+func (s *OrderSuite) TestRestoreOrder() {
+	tt := s.T()
+
+	tt.Run("order", func(t *testing.T) {
+		o := setup.MustCreateOrderWithCleanup(t, context.Background(), s.API, setup.OrderObj())
+
+		_, err := s.API.DeleteOrder(t, context.Background(), &request.DeleteOrderRequest{
+			ID: o.GetID(),
+		})
+		require.NoError(t, err)
+
+		trashed := s.findDeletedOrder(t, o.GetID())
+		require.NotNil(t, trashed, "order not in trash")
+		require.True(t, trashed.GetMeta().IsDeleted())
+
+		respRestore, err := s.API.RestoreOrder(t, context.Background(), &request.RestoreOrderRequest{
+			ID: o.GetID(),
+		})
+		require.NoError(t, err)
+
+		o.GetMeta().VersionIncr() // NOTE: delete
+		o.GetMeta().VersionIncr() // NOTE: restore
+		expected := &response.RestoreOrderResponse{
+			Order: o,
+		}
+		opts := []cmp.Option{
+			compare.SorterTask(compare.SortTaskByID),
+			compare.IgnorePaths("Order.Meta.Updated"),
+		}
+		compare.RequireEqual(t, expected, respRestore, opts...)
+
+		respGet, err := s.API.GetOrderByID(t, context.Background(), &request.GetOrderByIDRequest{
+			ID: o.GetID(),
+		})
+		require.NoError(t, err)
+		compare.RequireEqual(t, &response.GetOrderByIDResponse{Order: o}, respGet, opts...)
+
+		require.Nil(t, s.findDeletedOrder(t, o.GetID()), "restored order still in trash")
+	})
+
+	tt.Run("delegated_task", func(t *testing.T) {
+		o := setup.MustCreateOrderWithCleanup(t, context.Background(), s.API, setup.OrderObj())
+		delegated := o.GetDelegatedTasks()[0]
+
+		_, err := s.API.DeleteDelegatedTasks(t, context.Background(), &request.DeleteDelegatedTasksRequest{
+			OrderID:          o.GetID(),
+			DelegatedTaskIDs: []meta.ID{delegated.GetID()},
+		})
+		require.NoError(t, err)
+		require.NotNil(t, s.findDeletedOrder(t, delegated.GetID()), "delegated order not in trash")
+
+		_, err = s.API.RestoreOrder(t, context.Background(), &request.RestoreOrderRequest{
+			ID: delegated.GetID(),
+		})
+		require.NoError(t, err)
+
+		respGet, err := s.API.GetOrderByID(t, context.Background(), &request.GetOrderByIDRequest{
+			ID: o.GetID(),
+		})
+		require.NoError(t, err)
+		compare.RequireEqual(t, o.GetDelegatedTasks(), respGet.GetOrder().GetDelegatedTasks(), compare.SorterTask(compare.SortTaskByID))
+	})
+}
+
+func (s *OrderSuite) TestRestoreOrder_Failed() {
+	tt := s.T()
+
+	tt.Run("NotFound", func(t *testing.T) {
+		resp, err := s.API.RestoreOrder(t, context.Background(), &request.RestoreOrderRequest{
+			ID: meta.NewID(),
+		})
+		require.Error(t, err)
+		require.Empty(t, resp)
+		require.Equal(t, http.StatusNotFound, err.GetStatusCode(), err)
+	})
+
+	tt.Run("parent.deleted", func(t *testing.T) {
+		o := setup.MustCreateOrderWithCleanup(t, context.Background(), s.API, setup.OrderObj())
+		_, err := s.API.DeleteOrder(t, context.Background(), &request.DeleteOrderRequest{
+			ID: o.GetID(),
+		})
+		require.NoError(t, err)
+
+		resp, err := s.API.RestoreOrder(t, context.Background(), &request.RestoreOrderRequest{
+			ID: o.GetDelegatedTasks()[0].GetID(),
+		})
+		require.Error(t, err)
+		require.Empty(t, resp)
+		require.Equal(t, http.StatusConflict, err.GetStatusCode(), err)
+		require.NotNil(t, s.findDeletedOrder(t, o.GetDelegatedTasks()[0].GetID()), "delegated order not in trash")
+	})
+
+	tt.Run("not.deleted", func(t *testing.T) {
+		o := setup.MustCreateOrderWithCleanup(t, context.Background(), s.API, setup.OrderObj())
+
+		resp, err := s.API.RestoreOrder(t, context.Background(), &request.RestoreOrderRequest{
+			ID: o.GetID(),
+		})
+		require.Error(t, err)
+		require.Empty(t, resp)
+		require.Equal(t, http.StatusNotFound, err.GetStatusCode(), err)
+	})
+}
@@ -0,0 +1,66 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/moledoc/orderly/internal/domain/meta"
+	"github.com/moledoc/orderly/internal/domain/order"
+	"github.com/moledoc/orderly/internal/domain/request"
+	"github.com/moledoc/orderly/internal/domain/response"
+	"github.com/moledoc/orderly/tests/compare"
+	"github.com/moledoc/orderly/tests/setup"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *OrderSuite) TestRestoreSitReps() {
+	tt := s.T()
+
+	tt.Run("sitrep_ids.no_match", func(t *testing.T) {
+		o := setup.MustCreateOrderWithCleanup(t, context.Background(), s.API, setup.OrderObj())
+
+		respRestore, err := s.API.RestoreSitReps(t, context.Background(), &request.RestoreSitRepsRequest{
+			OrderID:   o.GetID(),
+			SitRepIDs: []meta.ID{meta.NewID(), o.GetSitReps()[0].GetID()}, // NOTE: unknown and not deleted
+		})
+		require.NoError(t, err)
+
+		compare.RequireEqual(t, &response.RestoreSitRepsResponse{Order: o}, respRestore, compare.SorterTask(compare.SortTaskByID))
+	})
+
+	tt.Run("sitrep_ids.match", func(t *testing.T) {
+		o := setup.MustCreateOrderWithCleanup(t, context.Background(), s.API, setup.OrderObj())
+		deleted := o.GetSitReps()[0]
+
+		_, err := s.API.DeleteSitReps(t, context.Background(), &request.DeleteSitRepsRequest{
+			OrderID:   o.GetID(),
+			SitRepIDs: []meta.ID{deleted.GetID()},
+		})
+		require.NoError(t, err)
+
+		respRestore, err := s.API.RestoreSitReps(t, context.Background(), &request.RestoreSitRepsRequest{
+			OrderID:   o.GetID(),
+			SitRepIDs: []meta.ID{deleted.GetID()},
+		})
+		require.NoError(t, err)
+
+		o.SetSitReps(append(append([]*order.SitRep{}, o.GetSitReps()[1:]...), deleted)) // NOTE: restored sitreps are appended
+		o.GetMeta().VersionIncr()                                                       // NOTE: delete
+		o.GetMeta().VersionIncr()                                                       // NOTE: restore
+		expected := &response.RestoreSitRepsResponse{
+			Order: o,
+		}
+		opts := []cmp.Option{
+			compare.SorterTask(compare.SortTaskByID),
+			compare.IgnorePaths("Order.Meta.Updated"),
+		}
+		compare.RequireEqual(t, expected, respRestore, opts...)
+
+		respGet, err := s.API.GetOrderByID(t, context.Background(), &request.GetOrderByIDRequest{
+			ID: o.GetID(),
+		})
+		require.NoError(t, err)
+		compare.RequireEqual(t, &response.GetOrderByIDResponse{Order: o}, respGet, opts...)
+	})
+}
@@ -0,0 +1,229 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/moledoc/orderly/internal/domain/errwrap"
+	"github.com/moledoc/orderly/internal/domain/order"
+	"github.com/moledoc/orderly/internal/domain/request"
+	"github.com/moledoc/orderly/pkg/utils"
+	"github.com/moledoc/orderly/tests/setup"
+	"github.com/stretchr/testify/require"
+)
+
+// createRollupOrder creates an order 'In Progress' with the given rollup policy.
+func (s *OrderSuite) createRollupOrder(t *testing.T, policy *order.RollupPolicy) *order.Order {
+	t.Helper()
+
+	obj := setup.OrderObj()
+	obj.GetTask().SetState(order.InProgress)
+	obj.SetRollupPolicy(policy)
+	return setup.MustCreateOrderWithCleanup(t, context.Background(), s.API, obj)
+}
+
+func (s *OrderSuite) TestRollupPolicy() {
+	tt := s.T()
+
+	policy := &order.RollupPolicy{IssuesOnBlocked: true, CompleteDelegated: true}
+
+	tt.Run("blocked.delegated", func(t *testing.T) {
+		o := s.createRollupOrder(t, policy)
+		require.Equal(t, policy, o.GetRollupPolicy())
+
+		resp, err := s.API.PatchDelegatedTasks(t, context.Background(), &request.PatchDelegatedTasksRequest{
+			OrderID: o.GetID(),
+			Tasks:   []*order.Task{{ID: o.GetDelegatedTasks()[0].GetID(), State: utils.Ptr(order.Blocked)}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, order.HavingIssues, resp.GetOrder().GetTask().GetState())
+		require.Equal(t, o.GetMeta().GetVersion()+1, resp.GetOrder().GetMeta().GetVersion()) // NOTE: rollup is part of the same change
+	})
+
+	tt.Run("blocked.child", func(t *testing.T) {
+		o := s.createRollupOrder(t, policy)
+		childObj := setup.OrderObj()
+		childObj.SetParentOrderID(o.GetID())
+		child := setup.MustCreateOrderWithCleanup(t, context.Background(), s.API, childObj)
+
+		_, err := s.API.PatchOrder(t, context.Background(), &request.PatchOrderRequest{
+			Order: &order.Order{Task: &order.Task{ID: child.GetID(), State: utils.Ptr(order.Blocked)}},
+		})
+		require.NoError(t, err)
+
+		resp, err := s.API.GetOrderByID(t, context.Background(), &request.GetOrderByIDRequest{ID: o.GetID()})
+		require.NoError(t, err)
+		require.Equal(t, order.HavingIssues, resp.GetOrder().GetTask().GetState())
+	})
+
+	tt.Run("unblocked", func(t *testing.T) {
+		for _, state := range []order.State{order.NotStarted, order.InProgress} {
+			obj := setup.OrderObj()
+			obj.GetTask().SetState(state)
+			obj.SetRollupPolicy(policy)
+			o := setup.MustCreateOrderWithCleanup(t, context.Background(), s.API, obj)
+			blockedID := o.GetDelegatedTasks()[0].GetID()
+
+			resp, err := s.API.PatchDelegatedTasks(t, context.Background(), &request.PatchDelegatedTasksRequest{
+				OrderID: o.GetID(),
+				Tasks:   []*order.Task{{ID: blockedID, State: utils.Ptr(order.Blocked)}},
+			})
+			require.NoError(t, err)
+			require.Equal(t, order.HavingIssues, resp.GetOrder().GetTask().GetState(), state)
+
+			resp, err = s.API.PatchDelegatedTasks(t, context.Background(), &request.PatchDelegatedTasksRequest{
+				OrderID: o.GetID(),
+				Tasks:   []*order.Task{{ID: blockedID, State: utils.Ptr(order.InProgress)}},
+			})
+			require.NoError(t, err)
+			require.Equal(t, state, resp.GetOrder().GetTask().GetState()) // NOTE: state before the rollup is restored
+			require.Equal(t, policy, resp.GetOrder().GetRollupPolicy())
+		}
+	})
+
+	tt.Run("unblocked.child", func(t *testing.T) {
+		o := s.createRollupOrder(t, policy)
+		childObj := setup.OrderObj()
+		childObj.SetParentOrderID(o.GetID())
+		child := setup.MustCreateOrderWithCleanup(t, context.Background(), s.API, childObj)
+
+		for _, state := range []order.State{order.Blocked, order.NotStarted} {
+			_, err := s.API.PatchOrder(t, context.Background(), &request.PatchOrderRequest{
+				Order: &order.Order{Task: &order.Task{ID: child.GetID(), State: utils.Ptr(state)}},
+			})
+			require.NoError(t, err)
+		}
+
+		resp, err := s.API.GetOrderByID(t, context.Background(), &request.GetOrderByIDRequest{ID: o.GetID()})
+		require.NoError(t, err)
+		require.Equal(t, order.InProgress, resp.GetOrder().GetTask().GetState())
+	})
+
+	tt.Run("unblocked.state.changed", func(t *testing.T) {
+		o := s.createRollupOrder(t, policy)
+		blockedID := o.GetDelegatedTasks()[0].GetID()
+
+		_, err := s.API.PatchDelegatedTasks(t, context.Background(), &request.PatchDelegatedTasksRequest{
+			OrderID: o.GetID(),
+			Tasks:   []*order.Task{{ID: blockedID, State: utils.Ptr(order.Blocked)}},
+		})
+		require.NoError(t, err)
+		_, err = s.API.PatchOrder(t, context.Background(), &request.PatchOrderRequest{
+			Order: &order.Order{Task: &order.Task{ID: o.GetID(), State: utils.Ptr(order.Blocked)}},
+		})
+		require.NoError(t, err)
+
+		resp, err := s.API.PatchDelegatedTasks(t, context.Background(), &request.PatchDelegatedTasksRequest{
+			OrderID: o.GetID(),
+			Tasks:   []*order.Task{{ID: blockedID, State: utils.Ptr(order.InProgress)}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, order.Blocked, resp.GetOrder().GetTask().GetState()) // NOTE: state set after the rollup is kept
+		require.Equal(t, policy, resp.GetOrder().GetRollupPolicy())
+	})
+
+	tt.Run("completed", func(t *testing.T) {
+		o := s.createRollupOrder(t, policy)
+
+		var delegatedTasks []*order.Task
+		for _, delegated := range o.GetDelegatedTasks() {
+			delegatedTasks = append(delegatedTasks, &order.Task{ID: delegated.GetID(), State: utils.Ptr(order.Completed)})
+		}
+		resp, err := s.API.PatchOrder(t, context.Background(), &request.PatchOrderRequest{
+			Order: &order.Order{
+				Task:           &order.Task{ID: o.GetID(), State: utils.Ptr(order.Completed)},
+				DelegatedTasks: delegatedTasks,
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, order.Completed, resp.GetOrder().GetTask().GetState())
+
+		respPatch, err := s.API.PatchDelegatedTasks(t, context.Background(), &request.PatchDelegatedTasksRequest{
+			OrderID: o.GetID(),
+			Tasks:   []*order.Task{{ID: o.GetDelegatedTasks()[1].GetID(), State: utils.Ptr(order.InProgress)}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, order.InProgress, respPatch.GetOrder().GetTask().GetState()) // NOTE: reopened delegated task reopens the order
+	})
+
+	tt.Run("policy.patched", func(t *testing.T) {
+		obj := setup.OrderObj()
+		obj.GetTask().SetState(order.Completed)
+		o := setup.MustCreateOrderWithCleanup(t, context.Background(), s.API, obj)
+
+		resp, err := s.API.PatchOrder(t, context.Background(), &request.PatchOrderRequest{
+			Order: &order.Order{
+				Task:         &order.Task{ID: o.GetID()},
+				RollupPolicy: &order.RollupPolicy{CompleteDelegated: true},
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, &order.RollupPolicy{CompleteDelegated: true}, resp.GetOrder().GetRollupPolicy())
+		require.Equal(t, order.InProgress, resp.GetOrder().GetTask().GetState())
+
+		respOff, err := s.API.PatchOrder(t, context.Background(), &request.PatchOrderRequest{
+			Order: &order.Order{
+				Task:         &order.Task{ID: o.GetID()},
+				RollupPolicy: &order.RollupPolicy{},
+			},
+		})
+		require.NoError(t, err)
+		require.Nil(t, respOff.GetOrder().GetRollupPolicy())
+	})
+
+	tt.Run("no.policy", func(t *testing.T) {
+		o := s.createRollupOrder(t, nil)
+
+		resp, err := s.API.PatchDelegatedTasks(t, context.Background(), &request.PatchDelegatedTasksRequest{
+			OrderID: o.GetID(),
+			Tasks:   []*order.Task{{ID: o.GetDelegatedTasks()[0].GetID(), State: utils.Ptr(order.Blocked)}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, order.InProgress, resp.GetOrder().GetTask().GetState())
+	})
+}
+
+func (s *OrderSuite) TestRollupPolicy_Failed() {
+	tt := s.T()
+
+	policy := &order.RollupPolicy{CompleteDelegated: true}
+
+	tt.Run("patch.order", func(t *testing.T) {
+		o := s.createRollupOrder(t, policy)
+
+		_, err := s.API.PatchOrder(t, context.Background(), &request.PatchOrderRequest{
+			Order: &order.Order{Task: &order.Task{ID: o.GetID(), State: utils.Ptr(order.Completed)}},
+		})
+		require.Error(t, err)
+		require.Equal(t, http.StatusUnprocessableEntity, err.GetStatusCode(), err)
+		require.Equal(t, []errwrap.FieldError{{
+			Field:   "order.task.state",
+			Message: fmt.Sprintf("delegated task '%s' of order '%s' isn't completed", o.GetDelegatedTasks()[0].GetID(), o.GetID()),
+		}}, err.GetFields())
+	})
+
+	tt.Run("patch.delegated.tasks", func(t *testing.T) {
+		parent := setup.MustCreateOrderWithCleanup(t, context.Background(), s.API, setup.OrderObj())
+		obj := setup.OrderObj()
+		obj.SetParentOrderID(parent.GetID())
+		obj.SetRollupPolicy(policy)
+		o := setup.MustCreateOrderWithCleanup(t, context.Background(), s.API, obj)
+
+		_, err := s.API.PatchDelegatedTasks(t, context.Background(), &request.PatchDelegatedTasksRequest{
+			OrderID: parent.GetID(),
+			Tasks:   []*order.Task{{ID: o.GetID(), State: utils.Ptr(order.Completed)}},
+		})
+		require.Error(t, err)
+		require.Equal(t, http.StatusUnprocessableEntity, err.GetStatusCode(), err)
+		require.Equal(t, []errwrap.FieldError{{
+			Field:   "tasks[0].state",
+			Message: fmt.Sprintf("delegated task '%s' of order '%s' isn't completed", o.GetDelegatedTasks()[0].GetID(), o.GetID()),
+		}}, err.GetFields())
+
+		resp, err := s.API.GetOrderByID(t, context.Background(), &request.GetOrderByIDRequest{ID: o.GetID()})
+		require.NoError(t, err)
+		require.Equal(t, order.NotStarted, resp.GetOrder().GetTask().GetState())
+	})
+}
@@ -0,0 +1,106 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/moledoc/orderly/internal/domain/meta"
+	"github.com/moledoc/orderly/internal/domain/order"
+	"github.com/moledoc/orderly/internal/domain/request"
+	"github.com/moledoc/orderly/pkg/utils"
+	"github.com/moledoc/orderly/tests/setup"
+	"github.com/stretchr/testify/require"
+)
+
+// highlighted lists the highlighted words of the hit's snippets.
+func highlighted(hit *order.SearchHit) []string {
+	var words []string
+	for _, snippet := range hit.GetSnippets() {
+		for _, part := range snippet.Parts() {
+			if part.Match {
+				words = append(words, part.Text)
+			}
+		}
+	}
+	return words
+}
+
+func (s *OrderSuite) TestSearch() {
+	tt := s.T()
+
+	word := strings.ToLower(utils.RandAlphanum())
+
+	inObjectiveObj := setup.OrderObj()
+	inObjectiveObj.SetDelegatedTasks(nil)
+	inObjectiveObj.GetTask().SetObjective("Renegotiate the supplier contract " + word)
+	inObjective := setup.MustCreateOrderWithCleanup(tt, context.Background(), s.API, inObjectiveObj)
+
+	inSitRepObj := setup.OrderObj()
+	inSitRepObj.SetDelegatedTasks(nil)
+	inSitRepObj.GetTask().SetObjective("Ship the goods " + word)
+	inSitRepObj.GetSitReps()[1].SetIssues(strings.Repeat("Nothing to report. ", 10) + "Suppliers report a delay at the port.")
+	inSitRep := setup.MustCreateOrderWithCleanup(tt, context.Background(), s.API, inSitRepObj)
+
+	tt.Run("ranked", func(t *testing.T) {
+		resp, err := s.API.Search(t, context.Background(), &request.SearchRequest{
+			Query: "supplier " + word,
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.GetHits(), 2)
+		require.Equal(t, []meta.ID{inObjective.GetID(), inSitRep.GetID()}, []meta.ID{resp.GetHits()[0].GetOrderID(), resp.GetHits()[1].GetOrderID()})
+		require.Greater(t, resp.GetHits()[0].GetScore(), resp.GetHits()[1].GetScore())
+	})
+
+	tt.Run("snippets", func(t *testing.T) {
+		resp, err := s.API.Search(t, context.Background(), &request.SearchRequest{
+			Query: "DELAY " + word,
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.GetHits(), 1)
+
+		hit := resp.GetHits()[0]
+		require.Equal(t, inSitRep.GetID(), hit.GetOrderID())
+		require.Equal(t, []string{word, "delay"}, highlighted(hit))
+		snippet := hit.GetSnippets()[1]
+		require.Equal(t, "sitrep.issues", snippet.GetField())
+		require.Equal(t, inSitRep.GetSitReps()[1].GetID(), snippet.GetSitRepID())
+		require.Less(t, len(snippet.GetText()), len(inSitRep.GetSitReps()[1].GetIssues()))
+	})
+
+	tt.Run("limit", func(t *testing.T) {
+		resp, err := s.API.Search(t, context.Background(), &request.SearchRequest{
+			Query: word,
+			Limit: 1,
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.GetHits(), 1)
+	})
+
+	tt.Run("no.hits", func(t *testing.T) {
+		resp, err := s.API.Search(t, context.Background(), &request.SearchRequest{
+			Query: "unknown " + word,
+		})
+		require.NoError(t, err)
+		require.Empty(t, resp.GetHits())
+	})
+}
+
+func (s *OrderSuite) TestSearch_Failed() {
+	tt := s.T()
+
+	for name, query := range map[string]string{
+		"empty":    "",
+		"no.words": " ,.- ",
+	} {
+		tt.Run(name, func(t *testing.T) {
+			resp, err := s.API.Search(t, context.Background(), &request.SearchRequest{
+				Query: query,
+			})
+			require.Error(t, err)
+			require.Empty(t, resp)
+			require.Equal(t, http.StatusBadRequest, err.GetStatusCode(), err)
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/moledoc/orderly/internal/domain/errwrap"
+	"github.com/moledoc/orderly/internal/domain/order"
+	"github.com/moledoc/orderly/internal/domain/request"
+	"github.com/moledoc/orderly/internal/domain/response"
+	"github.com/moledoc/orderly/internal/domain/user"
+	"github.com/moledoc/orderly/internal/repository"
+	"github.com/moledoc/orderly/internal/repository/local"
+	"github.com/moledoc/orderly/internal/repository/sqldb"
+	"github.com/moledoc/orderly/internal/service/mgmtorder"
+	"github.com/moledoc/orderly/internal/service/mgmtuser"
+	"github.com/moledoc/orderly/pkg/utils"
+	apiordersvc "github.com/moledoc/orderly/tests/api/order/svc"
+	apiusersvc "github.com/moledoc/orderly/tests/api/user/svc"
+	"github.com/moledoc/orderly/tests/setup"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderUserEmailChange(t *testing.T) {
+	var orderSvc mgmtorder.ServiceMgmtOrderAPI
+	var propagateErr errwrap.Error
+	userAPI := &apiusersvc.UserAPISvc{
+		Svc: mgmtuser.NewServiceMgmtUser(local.NewLocalRepositoryUser(), mgmtuser.EmailPropagatorFunc(func(ctx context.Context, req *request.ChangeUserEmailRequest) (*response.ChangeUserEmailResponse, errwrap.Error) {
+			if propagateErr != nil {
+				return nil, propagateErr
+			}
+			return orderSvc.ChangeUserEmail(ctx, req)
+		})),
+	}
+	orderSvc = mgmtorder.NewServiceMgmtOrder(local.NewLocalRepositoryOrder(), userAPI.Svc, nil)
+	orderAPI := &apiordersvc.OrderAPISvc{Svc: orderSvc}
+
+	t.Run("propagated", func(t *testing.T) {
+		u := setup.MustCreateUserWithCleanup(t, context.Background(), userAPI, setup.UserObj(utils.RandAlphanum()))
+		other := setup.MustCreateUserWithCleanup(t, context.Background(), userAPI, setup.UserObj(utils.RandAlphanum()))
+
+		obj := setup.OrderObj()
+		obj.GetTask().SetAccountable(u.GetEmail())
+		for _, delegated := range obj.GetDelegatedTasks() {
+			delegated.SetAccountable(other.GetEmail())
+		}
+		obj.GetDelegatedTasks()[0].SetAccountable(u.GetEmail())
+		for _, sitrep := range obj.GetSitReps() {
+			sitrep.SetBy(other.GetEmail())
+		}
+		obj.GetSitReps()[1].SetBy(u.GetEmail())
+		o := setup.MustCreateOrderWithCleanup(t, context.Background(), orderAPI, obj)
+
+		trashedObj := setup.OrderObj()
+		trashedObj.GetTask().SetAccountable(u.GetEmail())
+		trashedObj.SetDelegatedTasks(nil)
+		trashedObj.SetSitReps(nil)
+		trashed := setup.MustCreateOrderWithCleanup(t, context.Background(), orderAPI, trashedObj)
+		_, err := orderAPI.DeleteOrder(t, context.Background(), &request.DeleteOrderRequest{ID: trashed.GetID()})
+		require.NoError(t, err)
+
+		email := user.Email(fmt.Sprintf("example.updated.%v@example.com", utils.RandAlphanum()))
+		_, err = userAPI.PatchUser(t, context.Background(), &request.PatchUserRequest{
+			User: &user.User{ID: u.GetID(), Email: email},
+		})
+		require.NoError(t, err)
+
+		respGet, err := orderAPI.GetOrderByID(t, context.Background(), &request.GetOrderByIDRequest{ID: o.GetID()})
+		require.NoError(t, err)
+		got := respGet.GetOrder()
+		require.Equal(t, email, got.GetTask().GetAccountable())
+		require.Equal(t, o.GetMeta().GetVersion()+1, got.GetMeta().GetVersion())
+		for _, delegated := range got.GetDelegatedTasks() {
+			expected := other.GetEmail()
+			if delegated.GetID() == o.GetDelegatedTasks()[0].GetID() {
+				expected = email
+			}
+			require.Equal(t, expected, delegated.GetAccountable(), delegated.GetID())
+		}
+		for i, sitrep := range got.GetSitReps() {
+			expected := other.GetEmail()
+			if i == 1 {
+				expected = email
+			}
+			require.Equal(t, expected, sitrep.GetBy(), sitrep.GetID())
+		}
+
+		respRestore, err := orderAPI.RestoreOrder(t, context.Background(), &request.RestoreOrderRequest{ID: trashed.GetID()})
+		require.NoError(t, err)
+		require.Equal(t, email, respRestore.GetOrder().GetTask().GetAccountable())
+	})
+
+	t.Run("undone", func(t *testing.T) {
+		u := setup.MustCreateUserWithCleanup(t, context.Background(), userAPI, setup.UserObj(utils.RandAlphanum()))
+		subordinateObj := setup.UserObj(utils.RandAlphanum())
+		subordinateObj.SetSupervisor(u.GetEmail())
+		subordinate := setup.MustCreateUserWithCleanup(t, context.Background(), userAPI, subordinateObj)
+
+		propagateErr = errwrap.NewError(http.StatusServiceUnavailable, "orders unavailable")
+		defer func() { propagateErr = nil }()
+
+		_, err := userAPI.PatchUser(t, context.Background(), &request.PatchUserRequest{
+			User: &user.User{ID: u.GetID(), Email: user.Email(fmt.Sprintf("example.updated.%v@example.com", utils.RandAlphanum()))},
+		})
+		require.Error(t, err)
+		require.Equal(t, http.StatusServiceUnavailable, err.GetStatusCode(), err)
+
+		respGet, err := userAPI.GetUserByID(t, context.Background(), &request.GetUserByIDRequest{ID: u.GetID()})
+		require.NoError(t, err)
+		require.Equal(t, u.GetEmail(), respGet.GetUser().GetEmail())
+		respGet, err = userAPI.GetUserByID(t, context.Background(), &request.GetUserByIDRequest{ID: subordinate.GetID()})
+		require.NoError(t, err)
+		require.Equal(t, u.GetEmail(), respGet.GetUser().GetSupervisor())
+	})
+}
+
+func TestOrderUserEmailChange_SQL(t *testing.T) {
+	db := setup.SQLiteDB(t)
+	var orderSvc mgmtorder.ServiceMgmtOrderAPI
+	var propagateErr errwrap.Error
+	userAPI := &apiusersvc.UserAPISvc{
+		Svc: mgmtuser.NewServiceMgmtUser(sqldb.NewSQLRepositoryUser(db), mgmtuser.EmailPropagatorFunc(func(ctx context.Context, req *request.ChangeUserEmailRequest) (*response.ChangeUserEmailResponse, errwrap.Error) {
+			resp, err := orderSvc.ChangeUserEmail(ctx, req)
+			if err != nil {
+				return nil, err
+			}
+			if propagateErr != nil { // NOTE: fails once the orders are changed
+				return nil, propagateErr
+			}
+			return resp, nil
+		})),
+	}
+	orderSvc = mgmtorder.NewServiceMgmtOrder(sqldb.NewSQLRepositoryOrder(db), userAPI.Svc, nil)
+	orderAPI := &apiordersvc.OrderAPISvc{Svc: orderSvc}
+
+	create := func(t *testing.T) (*user.User, *order.Order) {
+		u := setup.MustCreateUserWithCleanup(t, context.Background(), userAPI, setup.UserObj(utils.RandAlphanum()))
+		obj := setup.OrderObj()
+		obj.GetTask().SetAccountable(u.GetEmail())
+		obj.SetDelegatedTasks(nil)
+		obj.SetSitReps(nil)
+		return u, setup.MustCreateOrderWithCleanup(t, context.Background(), orderAPI, obj)
+	}
+
+	t.Run("propagated", func(t *testing.T) {
+		u, o := create(t)
+
+		email := user.Email(fmt.Sprintf("example.updated.%v@example.com", utils.RandAlphanum()))
+		_, err := userAPI.PatchUser(t, context.Background(), &request.PatchUserRequest{
+			User: &user.User{ID: u.GetID(), Email: email},
+		})
+		require.NoError(t, err)
+
+		respGet, err := orderAPI.GetOrderByID(t, context.Background(), &request.GetOrderByIDRequest{ID: o.GetID()})
+		require.NoError(t, err)
+		require.Equal(t, email, respGet.GetOrder().GetTask().GetAccountable())
+	})
+
+	t.Run("rolled.back", func(t *testing.T) {
+		u, o := create(t)
+
+		propagateErr = errwrap.NewError(http.StatusServiceUnavailable, "orders unavailable")
+		defer func() { propagateErr = nil }()
+
+		_, err := userAPI.PatchUser(t, context.Background(), &request.PatchUserRequest{
+			User: &user.User{ID: u.GetID(), Email: user.Email(fmt.Sprintf("example.updated.%v@example.com", utils.RandAlphanum()))},
+		})
+		require.Error(t, err)
+		require.Equal(t, http.StatusServiceUnavailable, err.GetStatusCode(), err)
+
+		respGetUser, err := userAPI.GetUserByID(t, context.Background(), &request.GetUserByIDRequest{ID: u.GetID()})
+		require.NoError(t, err)
+		require.Equal(t, u.GetEmail(), respGetUser.GetUser().GetEmail())
+		require.Equal(t, u.GetMeta().GetVersion(), respGetUser.GetUser().GetMeta().GetVersion()) // NOTE: rolled back, not undone
+
+		respGetOrder, err := orderAPI.GetOrderByID(t, context.Background(), &request.GetOrderByIDRequest{ID: o.GetID()})
+		require.NoError(t, err)
+		require.Equal(t, u.GetEmail(), respGetOrder.GetOrder().GetTask().GetAccountable())
+		require.Equal(t, o.GetMeta().GetVersion(), respGetOrder.GetOrder().GetMeta().GetVersion())
+	})
+}
+
+// failingUsers fails updating users while failing is set, the transactions it's used in included
+type failingUsers struct {
+	repository.RepositoryUserAPI
+	failing *bool
+}
+
+func (r *failingUsers) WithTx(ctx context.Context, fn func(tx repository.RepositoryUserAPI) errwrap.Error) errwrap.Error {
+	return r.RepositoryUserAPI.WithTx(ctx, func(tx repository.RepositoryUserAPI) errwrap.Error {
+		return fn(&failingUsers{RepositoryUserAPI: tx, failing: r.failing})
+	})
+}
+
+func (r *failingUsers) UpdateUser(ctx context.Context, u *user.User) (*user.User, errwrap.Error) {
+	if *r.failing {
+		return nil, errwrap.NewError(http.StatusInternalServerError, "updating user '%s' failed", u.GetID())
+	}
+	return r.RepositoryUserAPI.UpdateUser(ctx, u)
+}
+
+func TestOrderUserEmailChange_UndoFailed(t *testing.T) {
+	failing := false
+	userAPI := &apiusersvc.UserAPISvc{
+		Svc: mgmtuser.NewServiceMgmtUser(&failingUsers{RepositoryUserAPI: local.NewLocalRepositoryUser(), failing: &failing}, mgmtuser.EmailPropagatorFunc(func(ctx context.Context, req *request.ChangeUserEmailRequest) (*response.ChangeUserEmailResponse, errwrap.Error) {
+			failing = true // NOTE: the undo fails as well
+			return nil, errwrap.NewError(http.StatusServiceUnavailable, "orders unavailable")
+		})),
+	}
+
+	u := setup.MustCreateUserWithCleanup(t, context.Background(), userAPI, setup.UserObj(utils.RandAlphanum()))
+	subordinateObj := setup.UserObj(utils.RandAlphanum())
+	subordinateObj.SetSupervisor(u.GetEmail())
+	subordinate := setup.MustCreateUserWithCleanup(t, context.Background(), userAPI, subordinateObj)
+
+	email := user.Email(fmt.Sprintf("example.updated.%v@example.com", utils.RandAlphanum()))
+	_, err := userAPI.PatchUser(t, context.Background(), &request.PatchUserRequest{
+		User: &user.User{ID: u.GetID(), Email: email},
+	})
+	failing = false
+	require.Error(t, err)
+	require.Equal(t, http.StatusInternalServerError, err.GetStatusCode(), err)
+	require.Contains(t, err.GetStatusMessage(), fmt.Sprintf("users left changed: '%s' (%s), '%s' (%s)", email, u.GetID(), subordinate.GetEmail(), subordinate.GetID()))
+
+	respGet, err := userAPI.GetUserByID(t, context.Background(), &request.GetUserByIDRequest{ID: u.GetID()})
+	require.NoError(t, err)
+	require.Equal(t, email, respGet.GetUser().GetEmail())
+}
@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/moledoc/orderly/internal/domain/errwrap"
+	"github.com/moledoc/orderly/internal/domain/order"
+	"github.com/moledoc/orderly/internal/domain/request"
+	"github.com/moledoc/orderly/internal/domain/user"
+	"github.com/moledoc/orderly/internal/repository/local"
+	"github.com/moledoc/orderly/internal/router"
+	"github.com/moledoc/orderly/internal/service/mgmtorder"
+	"github.com/moledoc/orderly/pkg/utils"
+	"github.com/moledoc/orderly/tests/api"
+	apiorderhttptest "github.com/moledoc/orderly/tests/api/order/httptest"
+	apiordersvc "github.com/moledoc/orderly/tests/api/order/svc"
+	apiusersvc "github.com/moledoc/orderly/tests/api/user/svc"
+	"github.com/moledoc/orderly/tests/setup"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderUserLookup(t *testing.T) {
+	userAPI := apiusersvc.NewUserAPISvc()
+	known := setup.MustCreateUserWithCleanup(t, context.Background(), userAPI, setup.UserObj(utils.RandAlphanum())).GetEmail()
+	unknown := user.Email(fmt.Sprintf("unknown.%v@example.com", utils.RandAlphanum()))
+
+	orderObj := func() *order.Order {
+		o := setup.OrderObj()
+		o.GetTask().SetAccountable(known)
+		for _, delegated := range o.GetDelegatedTasks() {
+			delegated.SetAccountable(known)
+		}
+		for _, sitrep := range o.GetSitReps() {
+			sitrep.SetBy(known)
+		}
+		return o
+	}
+	notFound := func(field string) errwrap.FieldError {
+		return errwrap.FieldError{Field: field, Message: fmt.Sprintf("user '%s' not found", unknown)}
+	}
+
+	apis := map[string]api.Order{
+		"svc":      apiordersvc.NewOrderAPISvcWithUserLookup(userAPI.Svc),
+		"httptest": apiorderhttptest.NewOrderAPIHTTPTest(router.RouteOrder(mgmtorder.NewServiceMgmtOrder(local.NewLocalRepositoryOrder(), userAPI.Svc, nil))),
+	}
+	for name, orderAPI := range apis {
+		t.Run(name+".post.known", func(t *testing.T) {
+			setup.MustCreateOrderWithCleanup(t, context.Background(), orderAPI, orderObj())
+		})
+
+		t.Run(name+".post.unknown", func(t *testing.T) {
+			o := orderObj()
+			o.GetDelegatedTasks()[1].SetAccountable(unknown)
+			o.GetSitReps()[2].SetBy(unknown)
+
+			resp, err := orderAPI.PostOrder(t, context.Background(), &request.PostOrderRequest{
+				Order: o,
+			})
+			require.Error(t, err)
+			require.Empty(t, resp)
+			require.Equal(t, http.StatusUnprocessableEntity, err.GetStatusCode(), err)
+			require.Equal(t, []errwrap.FieldError{
+				notFound("order.delegated_tasks[1].accountable"),
+				notFound("order.sitreps[2].by"),
+			}, err.GetFields())
+		})
+
+		t.Run(name+".patch.unknown", func(t *testing.T) {
+			o := setup.MustCreateOrderWithCleanup(t, context.Background(), orderAPI, orderObj())
+
+			resp, err := orderAPI.PatchOrder(t, context.Background(), &request.PatchOrderRequest{
+				Order: &order.Order{
+					Task: &order.Task{
+						ID:          o.GetID(),
+						Accountable: unknown,
+					},
+				},
+			})
+			require.Error(t, err)
+			require.Empty(t, resp)
+			require.Equal(t, http.StatusUnprocessableEntity, err.GetStatusCode(), err)
+			require.Equal(t, []errwrap.FieldError{notFound("order.task.accountable")}, err.GetFields())
+		})
+
+		t.Run(name+".delegated_tasks.unknown", func(t *testing.T) {
+			o := setup.MustCreateOrderWithCleanup(t, context.Background(), orderAPI, orderObj())
+			task := setup.TaskObj()
+			task.SetAccountable(unknown)
+
+			resp, err := orderAPI.PutDelegatedTasks(t, context.Background(), &request.PutDelegatedTasksRequest{
+				OrderID: o.GetID(),
+				Tasks:   []*order.Task{orderObj().GetTask(), task},
+			})
+			require.Error(t, err)
+			require.Empty(t, resp)
+			require.Equal(t, http.StatusUnprocessableEntity, err.GetStatusCode(), err)
+			require.Equal(t, []errwrap.FieldError{notFound("tasks[1].accountable")}, err.GetFields())
+		})
+
+		t.Run(name+".sitreps.unknown", func(t *testing.T) {
+			o := setup.MustCreateOrderWithCleanup(t, context.Background(), orderAPI, orderObj())
+			sitrep := setup.SitrepObj()
+			sitrep.SetBy(unknown)
+
+			resp, err := orderAPI.PutSitReps(t, context.Background(), &request.PutSitRepsRequest{
+				OrderID: o.GetID(),
+				SitReps: []*order.SitRep{sitrep},
+			})
+			require.Error(t, err)
+			require.Empty(t, resp)
+			require.Equal(t, http.StatusUnprocessableEntity, err.GetStatusCode(), err)
+			require.Equal(t, []errwrap.FieldError{notFound("sitreps[0].by")}, err.GetFields())
+		})
+	}
+}
@@ -3,6 +3,7 @@ package tests
 import (
 	"context"
 	"net/http"
+	"slices"
 	"testing"
 	"time"
 
@@ -223,15 +224,19 @@ func (s *OrderSuite) TestValidation_GetOrderByIDRequest() {
 
 func (s *OrderSuite) TestValidation_GetOrdersRequest() {
 	tt := s.T()
+	// NOTE: unfiltered requests list every order, the root order always among them
+	isRoot := func(o *order.Order) bool { return o.GetID() == o.GetParentOrderID() }
 	tt.Run("nil.request", func(t *testing.T) {
 		resp, err := s.API.GetOrders(t, context.Background(), nil)
 		require.NoError(t, err)
-		require.Empty(t, resp)
+		require.True(t, slices.ContainsFunc(resp.GetOrders(), isRoot))
+		require.Equal(t, len(resp.GetOrders()), resp.GetPage().GetTotal())
 	})
 	tt.Run("empty.request", func(t *testing.T) {
 		resp, err := s.API.GetOrders(t, context.Background(), &request.GetOrdersRequest{})
 		require.NoError(t, err)
-		require.Empty(t, resp)
+		require.True(t, slices.ContainsFunc(resp.GetOrders(), isRoot))
+		require.Equal(t, len(resp.GetOrders()), resp.GetPage().GetTotal())
 	})
 
 	tt.Run("invalid.parent_order_id", func(t *testing.T) {
@@ -0,0 +1,104 @@
+package performance
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/moledoc/orderly/internal/domain/meta"
+	"github.com/moledoc/orderly/internal/domain/order"
+	"github.com/moledoc/orderly/internal/domain/request"
+	"github.com/moledoc/orderly/internal/domain/user"
+	"github.com/moledoc/orderly/internal/repository/local"
+	"github.com/moledoc/orderly/pkg/utils"
+)
+
+const childrenPerOrder = 10
+
+// seedOrders stores count orders as a tree, where every order has at most childrenPerOrder children
+// and every accountable owns childrenPerOrder orders, so lookup results stay the same size regardless of count.
+// NOTE: the i-th order's deadline is i hours after the returned time
+func seedOrders(b *testing.B, count int) (*local.LocalRepositoryOrder, []meta.ID, time.Time) {
+	b.Helper()
+
+	repo := local.NewLocalRepositoryOrder()
+	deadlines := time.Now().UTC().Truncate(time.Hour)
+	ids := make([]meta.ID, 0, count)
+	for i := range count {
+		o := &order.Order{
+			Task: &order.Task{
+				ID:          meta.NewID(),
+				State:       utils.Ptr(order.State(i % len(order.ListStates()))),
+				Accountable: user.Email(fmt.Sprintf("user%v@example.com", i/childrenPerOrder)),
+				Objective:   "objective description",
+				Deadline:    deadlines.Add(time.Duration(i) * time.Hour),
+			},
+			Meta: &meta.Meta{
+				Version: 1,
+				Created: time.Now().UTC(),
+				Updated: time.Now().UTC(),
+			},
+		}
+		if i > 0 {
+			o.SetParentOrderID(ids[(i-1)/childrenPerOrder])
+		}
+		if _, err := repo.CreateOrder(context.Background(), o); err != nil {
+			b.Fatal(err)
+		}
+		ids = append(ids, o.GetID())
+	}
+	return repo, ids, deadlines
+}
+
+// BenchmarkLocalRepositoryOrderReadBy shows that ns/op of filtered lookups doesn't grow with the number of stored orders.
+func BenchmarkLocalRepositoryOrderReadBy(b *testing.B) {
+	ctx := context.Background()
+
+	for _, count := range []int{1_000, 10_000, 100_000} {
+		repo, ids, deadlines := seedOrders(b, count)
+
+		b.Run(fmt.Sprintf("parent_order_id/orders.%v", count), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				req := &request.GetOrdersRequest{ParentOrderID: ids[i%(count/childrenPerOrder)]}
+				if _, _, err := repo.ReadBy(ctx, req); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("accountable/orders.%v", count), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				req := &request.GetOrdersRequest{Accountable: user.Email(fmt.Sprintf("user%v@example.com", i%(count/childrenPerOrder)))}
+				if _, _, err := repo.ReadBy(ctx, req); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("parent_order_id.accountable/orders.%v", count), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				req := &request.GetOrdersRequest{
+					ParentOrderID: ids[i%(count/childrenPerOrder)],
+					Accountable:   user.Email(fmt.Sprintf("user%v@example.com", (i%(count/childrenPerOrder))+1)),
+				}
+				if _, _, err := repo.ReadBy(ctx, req); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("deadline/orders.%v", count), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				from := deadlines.Add(time.Duration(i%(count-childrenPerOrder)) * time.Hour)
+				req := &request.GetOrdersRequest{
+					DeadlineFrom: from,
+					DeadlineTo:   from.Add(childrenPerOrder * time.Hour),
+				}
+				if _, _, err := repo.ReadBy(ctx, req); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
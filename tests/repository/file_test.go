@@ -0,0 +1,173 @@
+package tests
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/moledoc/orderly/internal/domain/meta"
+	"github.com/moledoc/orderly/internal/domain/request"
+	"github.com/moledoc/orderly/internal/repository/file"
+	"github.com/moledoc/orderly/tests/setup"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileRepositoryOrder(t *testing.T) {
+
+	t.Run("reopen", func(t *testing.T) {
+		dir := t.TempDir()
+		repo, err := file.NewFileRepositoryOrder(dir, file.DefaultOptions)
+		require.NoError(t, err)
+
+		written, err := repo.CreateOrder(context.Background(), setup.OrderObjWithIDs())
+		require.NoError(t, err)
+		require.NoError(t, repo.Close(context.Background()))
+
+		reopened, err := file.NewFileRepositoryOrder(dir, file.DefaultOptions)
+		require.NoError(t, err)
+		defer reopened.Close(context.Background())
+
+		read, err := reopened.ReadByID(context.Background(), written.GetID())
+		require.NoError(t, err)
+		require.Equal(t, written.GetTask().GetObjective(), read.GetTask().GetObjective())
+		require.Len(t, read.GetDelegatedTasks(), len(written.GetDelegatedTasks()))
+		require.Len(t, read.GetSitReps(), len(written.GetSitReps()))
+	})
+
+	t.Run("replay.without.close", func(t *testing.T) {
+		dir := t.TempDir()
+		repo, err := file.NewFileRepositoryOrder(dir, file.Options{})
+		require.NoError(t, err)
+
+		kept, err := repo.CreateOrder(context.Background(), setup.OrderObjWithIDs())
+		require.NoError(t, err)
+		deleted, err := repo.CreateOrder(context.Background(), setup.OrderObjWithIDs())
+		require.NoError(t, err)
+		require.NoError(t, repo.DeleteOrder(context.Background(), deleted.GetID()))
+
+		reopened, err := file.NewFileRepositoryOrder(dir, file.Options{})
+		require.NoError(t, err)
+		defer reopened.Close(context.Background())
+
+		_, err = reopened.ReadByID(context.Background(), kept.GetID())
+		require.NoError(t, err)
+		_, err = reopened.ReadByID(context.Background(), deleted.GetID())
+		require.Error(t, err)
+	})
+
+	t.Run("torn.record", func(t *testing.T) {
+		dir := t.TempDir()
+		repo, err := file.NewFileRepositoryOrder(dir, file.Options{})
+		require.NoError(t, err)
+
+		written, err := repo.CreateOrder(context.Background(), setup.OrderObjWithIDs())
+		require.NoError(t, err)
+
+		fptr, errf := os.OpenFile(filepath.Join(dir, "orders.journal"), os.O_WRONLY|os.O_APPEND, 0600)
+		require.NoError(t, errf)
+		_, errf = fptr.Write([]byte{0, 0, 1, 0, 0, 0, 0, 0, 42, 42}) // NOTE: header claims more bytes than were written
+		require.NoError(t, errf)
+		require.NoError(t, fptr.Close())
+
+		reopened, err := file.NewFileRepositoryOrder(dir, file.Options{})
+		require.NoError(t, err)
+
+		_, err = reopened.ReadByID(context.Background(), written.GetID())
+		require.NoError(t, err)
+
+		another, err := reopened.CreateOrder(context.Background(), setup.OrderObjWithIDs())
+		require.NoError(t, err)
+		require.NoError(t, reopened.Close(context.Background()))
+
+		reopenedAgain, err := file.NewFileRepositoryOrder(dir, file.Options{})
+		require.NoError(t, err)
+		defer reopenedAgain.Close(context.Background())
+
+		orders, _, err := reopenedAgain.ReadBy(context.Background(), &request.GetOrdersRequest{})
+		require.NoError(t, err)
+		ids := []meta.ID{}
+		for _, o := range orders {
+			ids = append(ids, o.GetID())
+		}
+		require.Contains(t, ids, written.GetID())
+		require.Contains(t, ids, another.GetID())
+	})
+
+	t.Run("snapshot", func(t *testing.T) {
+		dir := t.TempDir()
+		opts := file.Options{SnapshotEvery: 2}
+		repo, err := file.NewFileRepositoryOrder(dir, opts)
+		require.NoError(t, err)
+
+		var ids []meta.ID
+		for range 5 {
+			o, err := repo.CreateOrder(context.Background(), setup.OrderObjWithIDs())
+			require.NoError(t, err)
+			ids = append(ids, o.GetID())
+		}
+		_, errf := os.Stat(filepath.Join(dir, "orders.snapshot"))
+		require.NoError(t, errf)
+
+		reopened, err := file.NewFileRepositoryOrder(dir, opts)
+		require.NoError(t, err)
+		defer reopened.Close(context.Background())
+
+		for _, id := range ids {
+			_, err := reopened.ReadByID(context.Background(), id)
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("versions", func(t *testing.T) {
+		dir := t.TempDir()
+		repo, err := file.NewFileRepositoryOrder(dir, file.DefaultOptions)
+		require.NoError(t, err)
+
+		o := setup.OrderObjWithIDs()
+		_, err = repo.CreateOrder(context.Background(), o)
+		require.NoError(t, err)
+		patched := o.Clone()
+		patched.GetTask().SetObjective("patched objective")
+		patched.GetMeta().VersionIncr()
+		_, err = repo.UpdateTask(context.Background(), patched.GetID(), patched.GetTask(), patched.GetMeta())
+		require.NoError(t, err)
+		require.NoError(t, repo.Close(context.Background()))
+
+		reopened, err := file.NewFileRepositoryOrder(dir, file.DefaultOptions)
+		require.NoError(t, err)
+		defer reopened.Close(context.Background())
+
+		versions, err := reopened.ReadVersions(context.Background(), o.GetID())
+		require.NoError(t, err)
+		require.Len(t, versions, 2)
+		require.Equal(t, o.GetTask().GetObjective(), versions[0].GetTask().GetObjective())
+		require.Equal(t, patched.GetTask().GetObjective(), versions[1].GetTask().GetObjective())
+	})
+}
+
+func TestFileRepositoryUser(t *testing.T) {
+
+	t.Run("reopen", func(t *testing.T) {
+		dir := t.TempDir()
+		repo, err := file.NewFileRepositoryUser(dir, file.DefaultOptions)
+		require.NoError(t, err)
+
+		kept, err := repo.CreateUser(context.Background(), setup.UserObjWithID())
+		require.NoError(t, err)
+		deleted, err := repo.CreateUser(context.Background(), setup.UserObjWithID("deleted"))
+		require.NoError(t, err)
+		require.NoError(t, repo.Delete(context.Background(), deleted.GetID()))
+		require.NoError(t, repo.Close(context.Background()))
+
+		reopened, err := file.NewFileRepositoryUser(dir, file.DefaultOptions)
+		require.NoError(t, err)
+		defer reopened.Close(context.Background())
+
+		read, err := reopened.ReadByID(context.Background(), kept.GetID())
+		require.NoError(t, err)
+		require.Equal(t, kept.GetEmail(), read.GetEmail())
+		_, err = reopened.ReadByID(context.Background(), deleted.GetID())
+		require.Error(t, err)
+	})
+}
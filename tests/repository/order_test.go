@@ -0,0 +1,258 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/moledoc/orderly/internal/domain/errwrap"
+	"github.com/moledoc/orderly/internal/domain/meta"
+	"github.com/moledoc/orderly/internal/domain/order"
+	"github.com/moledoc/orderly/internal/domain/request"
+	"github.com/moledoc/orderly/internal/domain/user"
+	"github.com/moledoc/orderly/internal/repository"
+	"github.com/moledoc/orderly/pkg/utils"
+	"github.com/moledoc/orderly/tests/setup"
+	"github.com/stretchr/testify/require"
+)
+
+func delegatedTaskIDs(o *order.Order) []meta.ID {
+	var ids []meta.ID
+	for _, delegated := range o.GetDelegatedTasks() {
+		ids = append(ids, delegated.GetID())
+	}
+	return ids
+}
+
+func orderIDs(orders []*order.Order) []meta.ID {
+	var ids []meta.ID
+	for _, o := range orders {
+		ids = append(ids, o.GetID())
+	}
+	return ids
+}
+
+func TestRepositoryOrderOperations(t *testing.T) {
+	ctx := context.Background()
+
+	for name, repo := range orderRepositories(t) {
+		t.Run(name+".update.task.keeps.parent.links", func(t *testing.T) {
+			parent, err := repo.CreateOrder(ctx, setup.OrderObjWithIDs())
+			require.NoError(t, err)
+			childObj := setup.OrderObjWithIDs()
+			childObj.SetParentOrderID(parent.GetID())
+			child, err := repo.CreateOrder(ctx, childObj)
+			require.NoError(t, err)
+
+			m := child.GetMeta().Clone()
+			for range 3 { // NOTE: repeated changes must not duplicate the child in its parent
+				m.VersionIncr()
+				child.GetTask().SetObjective("patched objective")
+				_, err = repo.UpdateTask(ctx, child.GetID(), child.GetTask(), m)
+				require.NoError(t, err)
+			}
+
+			read, err := repo.ReadByID(ctx, parent.GetID())
+			require.NoError(t, err)
+			require.Equal(t, append(delegatedTaskIDs(parent), child.GetID()), delegatedTaskIDs(read))
+
+			versions, err := repo.ReadVersions(ctx, child.GetID())
+			require.NoError(t, err)
+			require.Len(t, versions, 4)
+		})
+
+		t.Run(name+".append.delegated.tasks", func(t *testing.T) {
+			o, err := repo.CreateOrder(ctx, setup.OrderObjWithIDs())
+			require.NoError(t, err)
+
+			task := setup.TaskObjWithID()
+			m := o.GetMeta().Clone()
+			m.VersionIncr()
+			_, err = repo.AppendDelegatedTasks(ctx, o.GetID(), []*order.Task{task}, m)
+			require.NoError(t, err)
+			appended, err := repo.AppendDelegatedTasks(ctx, o.GetID(), []*order.Task{task}, m)
+			require.NoError(t, err)
+			require.Equal(t, append(delegatedTaskIDs(o), task.GetID()), delegatedTaskIDs(appended))
+
+			delegated, err := repo.ReadByID(ctx, task.GetID())
+			require.NoError(t, err)
+			require.Equal(t, o.GetID(), delegated.GetParentOrderID())
+		})
+
+		t.Run(name+".sitreps", func(t *testing.T) {
+			o, err := repo.CreateOrder(ctx, setup.OrderObjWithIDs())
+			require.NoError(t, err)
+			m := o.GetMeta().Clone()
+			m.VersionIncr()
+
+			sitrep := o.GetSitReps()[1]
+			sitrep.SetSituation("patched situation")
+			updated, err := repo.UpdateSitRep(ctx, o.GetID(), sitrep, m)
+			require.NoError(t, err)
+			require.Equal(t, "patched situation", updated.GetSitReps()[1].GetSituation())
+
+			removed, err := repo.RemoveSitReps(ctx, o.GetID(), []meta.ID{sitrep.GetID()}, m)
+			require.NoError(t, err)
+			require.Len(t, removed.GetSitReps(), len(o.GetSitReps())-1)
+
+			_, err = repo.UpdateSitRep(ctx, o.GetID(), sitrep, m)
+			require.Error(t, err)
+			require.Equal(t, http.StatusNotFound, err.GetStatusCode())
+
+			appended, err := repo.AppendSitReps(ctx, o.GetID(), []*order.SitRep{sitrep}, m)
+			require.NoError(t, err)
+			require.Len(t, appended.GetSitReps(), len(o.GetSitReps()))
+			require.Equal(t, sitrep.GetID(), appended.GetSitReps()[len(o.GetSitReps())-1].GetID())
+
+			versions, err := repo.ReadVersions(ctx, o.GetID())
+			require.NoError(t, err)
+			require.Len(t, versions, 2)
+		})
+
+		t.Run(name+".reparent", func(t *testing.T) {
+			from, err := repo.CreateOrder(ctx, setup.OrderObjWithIDs())
+			require.NoError(t, err)
+			to, err := repo.CreateOrder(ctx, setup.OrderObjWithIDs())
+			require.NoError(t, err)
+			childObj := setup.OrderObjWithIDs()
+			childObj.SetParentOrderID(from.GetID())
+			child, err := repo.CreateOrder(ctx, childObj)
+			require.NoError(t, err)
+
+			m := child.GetMeta().Clone()
+			m.VersionIncr()
+			moved, err := repo.Reparent(ctx, child.GetID(), to.GetID(), m)
+			require.NoError(t, err)
+			require.Equal(t, to.GetID(), moved.GetParentOrderID())
+
+			readFrom, err := repo.ReadByID(ctx, from.GetID())
+			require.NoError(t, err)
+			require.NotContains(t, delegatedTaskIDs(readFrom), child.GetID())
+			readTo, err := repo.ReadByID(ctx, to.GetID())
+			require.NoError(t, err)
+			require.Contains(t, delegatedTaskIDs(readTo), child.GetID())
+		})
+
+		t.Run(name+".rollup.policy", func(t *testing.T) {
+			obj := setup.OrderObjWithIDs()
+			obj.SetRollupPolicy(&order.RollupPolicy{IssuesOnBlocked: true, PreRollupState: order.InProgress})
+			o, err := repo.CreateOrder(ctx, obj)
+			require.NoError(t, err)
+			require.Equal(t, &order.RollupPolicy{IssuesOnBlocked: true, PreRollupState: order.InProgress}, o.GetRollupPolicy())
+
+			m := o.GetMeta().Clone()
+			m.VersionIncr()
+			updated, err := repo.UpdateRollupPolicy(ctx, o.GetID(), &order.RollupPolicy{CompleteDelegated: true}, m)
+			require.NoError(t, err)
+			require.Equal(t, &order.RollupPolicy{CompleteDelegated: true}, updated.GetRollupPolicy())
+
+			m = m.Clone()
+			m.VersionIncr()
+			_, err = repo.UpdateRollupPolicy(ctx, o.GetID(), &order.RollupPolicy{}, m)
+			require.NoError(t, err)
+			read, err := repo.ReadByID(ctx, o.GetID())
+			require.NoError(t, err)
+			require.Nil(t, read.GetRollupPolicy())
+
+			versions, err := repo.ReadVersions(ctx, o.GetID())
+			require.NoError(t, err)
+			require.Len(t, versions, 3)
+			require.Equal(t, &order.RollupPolicy{CompleteDelegated: true}, versions[1].GetRollupPolicy())
+		})
+
+		t.Run(name+".read.by.follows.changes", func(t *testing.T) {
+			parent, err := repo.CreateOrder(ctx, setup.OrderObjWithIDs())
+			require.NoError(t, err)
+			other, err := repo.CreateOrder(ctx, setup.OrderObjWithIDs())
+			require.NoError(t, err)
+			childObj := setup.OrderObjWithIDs(utils.RandAlphanum())
+			childObj.SetParentOrderID(parent.GetID())
+			child, err := repo.CreateOrder(ctx, childObj)
+			require.NoError(t, err)
+			accountable := child.GetTask().GetAccountable()
+
+			read, _, err := repo.ReadBy(ctx, &request.GetOrdersRequest{ParentOrderID: parent.GetID()})
+			require.NoError(t, err)
+			require.ElementsMatch(t, append(delegatedTaskIDs(parent), child.GetID()), orderIDs(read))
+			read, _, err = repo.ReadBy(ctx, &request.GetOrdersRequest{ParentOrderID: parent.GetID(), Accountable: accountable})
+			require.NoError(t, err)
+			require.Equal(t, []meta.ID{child.GetID()}, orderIDs(read))
+
+			m := child.GetMeta().Clone()
+			m.VersionIncr()
+			task := child.GetTask().Clone()
+			task.SetAccountable(user.Email(utils.RandAlphanum() + "@example.com"))
+			_, err = repo.UpdateTask(ctx, child.GetID(), task, m)
+			require.NoError(t, err)
+			read, _, err = repo.ReadBy(ctx, &request.GetOrdersRequest{Accountable: accountable})
+			require.NoError(t, err)
+			require.Empty(t, read)
+			read, _, err = repo.ReadBy(ctx, &request.GetOrdersRequest{Accountable: task.GetAccountable()})
+			require.NoError(t, err)
+			require.Equal(t, []meta.ID{child.GetID()}, orderIDs(read))
+
+			err = repo.WithTx(ctx, func(tx repository.RepositoryOrderAPI) errwrap.Error {
+				if _, err := tx.Reparent(ctx, child.GetID(), other.GetID(), m); err != nil {
+					return err
+				}
+				return errwrap.NewError(http.StatusConflict, "abort")
+			})
+			require.Error(t, err)
+			read, _, err = repo.ReadBy(ctx, &request.GetOrdersRequest{ParentOrderID: parent.GetID()})
+			require.NoError(t, err)
+			require.Contains(t, orderIDs(read), child.GetID())
+
+			_, err = repo.Reparent(ctx, child.GetID(), other.GetID(), m)
+			require.NoError(t, err)
+			read, _, err = repo.ReadBy(ctx, &request.GetOrdersRequest{ParentOrderID: parent.GetID()})
+			require.NoError(t, err)
+			require.NotContains(t, orderIDs(read), child.GetID())
+			read, _, err = repo.ReadBy(ctx, &request.GetOrdersRequest{ParentOrderID: other.GetID()})
+			require.NoError(t, err)
+			require.Contains(t, orderIDs(read), child.GetID())
+
+			require.NoError(t, repo.DeleteOrder(ctx, child.GetID()))
+			read, _, err = repo.ReadBy(ctx, &request.GetOrdersRequest{Accountable: task.GetAccountable()})
+			require.NoError(t, err)
+			require.Empty(t, read)
+		})
+
+		t.Run(name+".delete.keeps.live.delegated", func(t *testing.T) {
+			parent, err := repo.CreateOrder(ctx, setup.OrderObjWithIDs())
+			require.NoError(t, err)
+			childObj := setup.OrderObjWithIDs()
+			childObj.SetParentOrderID(parent.GetID())
+			child, err := repo.CreateOrder(ctx, childObj)
+			require.NoError(t, err)
+
+			m := parent.GetMeta().Clone()
+			m.VersionIncr()
+			m.SetDeleted(time.Now().UTC())
+			_, err = repo.UpdateMeta(ctx, parent.GetID(), m)
+			require.NoError(t, err)
+			require.NoError(t, repo.DeleteOrder(ctx, parent.GetID()))
+
+			read, err := repo.ReadByID(ctx, child.GetID())
+			require.NoError(t, err)
+			require.Equal(t, child.GetTask(), read.GetTask())
+		})
+
+		t.Run(name+".not.found", func(t *testing.T) {
+			o, err := repo.CreateOrder(ctx, setup.OrderObjWithIDs())
+			require.NoError(t, err)
+
+			_, err = repo.UpdateMeta(ctx, meta.NewID(), o.GetMeta())
+			require.Error(t, err)
+			require.Equal(t, http.StatusNotFound, err.GetStatusCode())
+
+			_, err = repo.UpdateTask(ctx, o.GetID(), setup.TaskObjWithID(), o.GetMeta())
+			require.Error(t, err)
+			require.Equal(t, http.StatusNotFound, err.GetStatusCode())
+
+			_, err = repo.CreateOrder(ctx, o)
+			require.Error(t, err)
+			require.Equal(t, http.StatusConflict, err.GetStatusCode())
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package tests
+
+import (
+	"cmp"
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/moledoc/orderly/internal/domain/meta"
+	"github.com/moledoc/orderly/internal/domain/order"
+	"github.com/moledoc/orderly/internal/domain/request"
+	"github.com/moledoc/orderly/internal/domain/user"
+	"github.com/moledoc/orderly/pkg/utils"
+	"github.com/moledoc/orderly/tests/setup"
+	"github.com/stretchr/testify/require"
+)
+
+// isListed reports whether the objects are listed by the sort and then by id.
+func isListed[T interface {
+	GetID() meta.ID
+	SortKey(field meta.Sort) int64
+}](items []T, sort meta.Sort) bool {
+	return slices.IsSortedFunc(items, func(a, b T) int {
+		keys := cmp.Compare(a.SortKey(sort.Field()), b.SortKey(sort.Field()))
+		if sort.IsDesc() {
+			keys = -keys
+		}
+		return cmp.Or(keys, cmp.Compare(a.GetID(), b.GetID()))
+	})
+}
+
+func TestRepositoryOrderReadByPage(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	for name, repo := range orderRepositories(t) {
+		for i := range 2 {
+			obj := setup.OrderObjWithIDs()
+			for j, task := range append([]*order.Task{obj.GetTask()}, obj.GetDelegatedTasks()...) {
+				task.SetDeadline(now.Add(time.Duration((i+j)%3) * time.Hour))
+				task.SetState(order.NotStarted + order.State(j))
+			}
+			_, err := repo.CreateOrder(ctx, obj)
+			require.NoError(t, err)
+		}
+
+		for _, sort := range []meta.Sort{"", meta.SortUpdated, meta.SortDeadline, "-" + meta.SortDeadline, meta.SortState} {
+			t.Run(name+".sort."+string(sort), func(t *testing.T) {
+				all, page, err := repo.ReadBy(ctx, &request.GetOrdersRequest{Sort: sort})
+				require.NoError(t, err)
+				require.Len(t, all, 8)
+				require.Equal(t, &meta.Page{Total: 8}, page)
+				require.True(t, isListed(all, sort))
+
+				var paged []*order.Order
+				var cursor string
+				for range len(all) {
+					read, page, err := repo.ReadBy(ctx, &request.GetOrdersRequest{Sort: sort, Limit: 3, Cursor: cursor})
+					require.NoError(t, err)
+					require.Equal(t, 8, page.GetTotal())
+					paged = append(paged, read...)
+					cursor = page.GetNextCursor()
+					if len(cursor) == 0 {
+						break
+					}
+				}
+				require.Empty(t, cursor)
+				require.Equal(t, orderIDs(all), orderIDs(paged))
+			})
+		}
+
+		t.Run(name+".filtered", func(t *testing.T) {
+			parent, err := repo.CreateOrder(ctx, setup.OrderObjWithIDs())
+			require.NoError(t, err)
+
+			read, page, err := repo.ReadBy(ctx, &request.GetOrdersRequest{ParentOrderID: parent.GetID(), Limit: 2})
+			require.NoError(t, err)
+			require.Len(t, read, 2)
+			require.Equal(t, 3, page.GetTotal())
+			read, page, err = repo.ReadBy(ctx, &request.GetOrdersRequest{ParentOrderID: parent.GetID(), Limit: 2, Cursor: page.GetNextCursor()})
+			require.NoError(t, err)
+			require.Len(t, read, 1)
+			require.Empty(t, page.GetNextCursor())
+		})
+
+		t.Run(name+".invalid.cursor", func(t *testing.T) {
+			_, _, err := repo.ReadBy(ctx, &request.GetOrdersRequest{Limit: 1, Cursor: "not a cursor"})
+			require.Error(t, err)
+			require.Equal(t, http.StatusBadRequest, err.GetStatusCode(), err)
+
+			_, page, err := repo.ReadBy(ctx, &request.GetOrdersRequest{Limit: 1})
+			require.NoError(t, err)
+			_, _, err = repo.ReadBy(ctx, &request.GetOrdersRequest{Limit: 1, Cursor: page.GetNextCursor(), Sort: meta.SortDeadline})
+			require.Error(t, err)
+			require.Equal(t, http.StatusBadRequest, err.GetStatusCode(), err)
+		})
+	}
+}
+
+func TestRepositoryUserReadByPage(t *testing.T) {
+	ctx := context.Background()
+
+	for name, repo := range userRepositories(t) {
+		supervisor := user.Email(utils.RandAlphanum() + "@example.com")
+		for range 5 {
+			obj := setup.UserObjWithID(utils.RandAlphanum())
+			obj.SetSupervisor(supervisor)
+			_, err := repo.CreateUser(ctx, obj)
+			require.NoError(t, err)
+		}
+
+		for _, sort := range []meta.Sort{"", "-" + meta.SortCreated, meta.SortUpdated} {
+			t.Run(name+".sort."+string(sort), func(t *testing.T) {
+				all, page, err := repo.ReadBy(ctx, &request.GetUsersRequest{Supervisor: supervisor, Sort: sort})
+				require.NoError(t, err)
+				require.Len(t, all, 5)
+				require.Equal(t, 5, page.GetTotal())
+				require.True(t, isListed(all, sort))
+
+				var paged []*user.User
+				var cursor string
+				for range len(all) {
+					read, page, err := repo.ReadBy(ctx, &request.GetUsersRequest{Supervisor: supervisor, Sort: sort, Limit: 2, Cursor: cursor})
+					require.NoError(t, err)
+					require.Equal(t, 5, page.GetTotal())
+					paged = append(paged, read...)
+					cursor = page.GetNextCursor()
+					if len(cursor) == 0 {
+						break
+					}
+				}
+				require.Empty(t, cursor)
+				require.Equal(t, all, paged)
+			})
+		}
+	}
+}
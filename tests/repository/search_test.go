@@ -0,0 +1,123 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/moledoc/orderly/internal/domain/errwrap"
+	"github.com/moledoc/orderly/internal/domain/meta"
+	"github.com/moledoc/orderly/internal/domain/order"
+	"github.com/moledoc/orderly/internal/domain/request"
+	"github.com/moledoc/orderly/internal/repository"
+	"github.com/moledoc/orderly/internal/repository/file"
+	"github.com/moledoc/orderly/pkg/utils"
+	"github.com/moledoc/orderly/tests/setup"
+	"github.com/stretchr/testify/require"
+)
+
+func hitIDs(hits []*order.SearchHit) []meta.ID {
+	var ids []meta.ID
+	for _, hit := range hits {
+		ids = append(ids, hit.GetOrderID())
+	}
+	return ids
+}
+
+func mustSearch(t *testing.T, repo repository.RepositoryOrderAPI, query string) []meta.ID {
+	t.Helper()
+	hits, err := repo.Search(context.Background(), &request.SearchRequest{Query: query})
+	require.NoError(t, err)
+	return hitIDs(hits)
+}
+
+func TestRepositoryOrderSearch(t *testing.T) {
+	ctx := context.Background()
+
+	for name, repo := range orderRepositories(t) {
+		word := utils.RandAlphanum() // NOTE: unique word, so hits aren't shared between the runs
+
+		obj := setup.OrderObjWithIDs()
+		obj.GetTask().SetObjective("Supplier " + word)
+		o, err := repo.CreateOrder(ctx, obj)
+		require.NoError(t, err)
+		delegatedID := o.GetDelegatedTasks()[0].GetID()
+
+		t.Run(name+".created", func(t *testing.T) {
+			require.Equal(t, []meta.ID{o.GetID()}, mustSearch(t, repo, word))
+			require.Equal(t, []meta.ID{o.GetID()}, mustSearch(t, repo, "supp "+word[:5]))
+			require.Empty(t, mustSearch(t, repo, "delay "+word))
+		})
+
+		t.Run(name+".sitrep", func(t *testing.T) {
+			sitrep := setup.SitrepObjWithID()
+			sitrep.SetIssues("supplier delay " + word)
+			_, err := repo.AppendSitReps(ctx, o.GetID(), []*order.SitRep{sitrep}, o.GetMeta())
+			require.NoError(t, err)
+			require.Equal(t, []meta.ID{o.GetID()}, mustSearch(t, repo, "delay "+word))
+
+			sitrep.SetIssues("resolved")
+			_, err = repo.UpdateSitRep(ctx, o.GetID(), sitrep, o.GetMeta())
+			require.NoError(t, err)
+			require.Empty(t, mustSearch(t, repo, "delay "+word))
+		})
+
+		t.Run(name+".delegated.task", func(t *testing.T) {
+			task := o.GetDelegatedTasks()[0].Clone()
+			task.SetObjective("delegated " + word)
+			_, err := repo.UpdateTask(ctx, o.GetID(), task, o.GetMeta())
+			require.NoError(t, err)
+			require.Equal(t, []meta.ID{delegatedID}, mustSearch(t, repo, "delegated "+word))
+		})
+
+		t.Run(name+".trash", func(t *testing.T) {
+			m := o.GetMeta().Clone()
+			m.SetDeleted(time.Now().UTC())
+			_, err := repo.UpdateMeta(ctx, o.GetID(), m)
+			require.NoError(t, err)
+			require.Equal(t, []meta.ID{delegatedID}, mustSearch(t, repo, word))
+
+			_, err = repo.UpdateMeta(ctx, o.GetID(), o.GetMeta())
+			require.NoError(t, err)
+			require.ElementsMatch(t, []meta.ID{o.GetID(), delegatedID}, mustSearch(t, repo, word))
+		})
+
+		t.Run(name+".rollback", func(t *testing.T) {
+			err := repo.WithTx(ctx, func(tx repository.RepositoryOrderAPI) errwrap.Error {
+				task := o.GetTask().Clone()
+				task.SetObjective("rolled back " + word)
+				if _, err := tx.UpdateTask(ctx, o.GetID(), task, o.GetMeta()); err != nil {
+					return err
+				}
+				return errwrap.NewError(http.StatusConflict, "rollback")
+			})
+			require.Error(t, err)
+			require.Empty(t, mustSearch(t, repo, "rolled "+word))
+			require.ElementsMatch(t, []meta.ID{o.GetID(), delegatedID}, mustSearch(t, repo, word))
+		})
+
+		t.Run(name+".deleted", func(t *testing.T) {
+			require.NoError(t, repo.DeleteOrder(ctx, o.GetID()))
+			require.Empty(t, mustSearch(t, repo, word))
+		})
+	}
+}
+
+func TestFileRepositoryOrderSearch_Reopen(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := file.NewFileRepositoryOrder(dir, file.DefaultOptions)
+	require.NoError(t, err)
+
+	obj := setup.OrderObjWithIDs()
+	obj.GetTask().SetObjective("reopened " + utils.RandAlphanum())
+	written, err := repo.CreateOrder(context.Background(), obj)
+	require.NoError(t, err)
+	require.NoError(t, repo.Close(context.Background()))
+
+	reopened, err := file.NewFileRepositoryOrder(dir, file.DefaultOptions)
+	require.NoError(t, err)
+	defer reopened.Close(context.Background())
+
+	require.Equal(t, []meta.ID{written.GetID()}, mustSearch(t, reopened, obj.GetTask().GetObjective()))
+}
@@ -0,0 +1,62 @@
+package tests
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/moledoc/orderly/internal/repository/sqldb"
+	"github.com/moledoc/orderly/tests/setup"
+	"github.com/stretchr/testify/require"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestSQLRepositoryOrder(t *testing.T) {
+
+	t.Run("reopen", func(t *testing.T) {
+		dsn := filepath.Join(t.TempDir(), "orderly.db")
+		db, err := sqldb.Open(context.Background(), sqldb.DriverSQLite, dsn)
+		require.NoError(t, err)
+
+		repo := sqldb.NewSQLRepositoryOrder(db)
+		written, err := repo.CreateOrder(context.Background(), setup.OrderObjWithIDs())
+		require.NoError(t, err)
+		require.NoError(t, repo.Close(context.Background()))
+
+		// NOTE: migrations are already applied, reopening must not re-apply them
+		reopenedDB, err := sqldb.Open(context.Background(), sqldb.DriverSQLite, dsn)
+		require.NoError(t, err)
+		reopened := sqldb.NewSQLRepositoryOrder(reopenedDB)
+		defer reopened.Close(context.Background())
+
+		read, err := reopened.ReadByID(context.Background(), written.GetID())
+		require.NoError(t, err)
+		require.Equal(t, written.GetTask().GetObjective(), read.GetTask().GetObjective())
+		require.Len(t, read.GetDelegatedTasks(), len(written.GetDelegatedTasks()))
+		require.Len(t, read.GetSitReps(), len(written.GetSitReps()))
+	})
+}
+
+func TestSQLRepositoryUser(t *testing.T) {
+
+	t.Run("reopen", func(t *testing.T) {
+		dsn := filepath.Join(t.TempDir(), "orderly.db")
+		db, err := sqldb.Open(context.Background(), sqldb.DriverSQLite, dsn)
+		require.NoError(t, err)
+
+		repo := sqldb.NewSQLRepositoryUser(db)
+		written, err := repo.CreateUser(context.Background(), setup.UserObjWithID())
+		require.NoError(t, err)
+		require.NoError(t, repo.Close(context.Background()))
+
+		reopenedDB, err := sqldb.Open(context.Background(), sqldb.DriverSQLite, dsn)
+		require.NoError(t, err)
+		reopened := sqldb.NewSQLRepositoryUser(reopenedDB)
+		defer reopened.Close(context.Background())
+
+		read, err := reopened.ReadByID(context.Background(), written.GetID())
+		require.NoError(t, err)
+		require.Equal(t, written.GetEmail(), read.GetEmail())
+	})
+}
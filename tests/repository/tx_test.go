@@ -0,0 +1,202 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/moledoc/orderly/internal/domain/errwrap"
+	"github.com/moledoc/orderly/internal/domain/meta"
+	"github.com/moledoc/orderly/internal/domain/order"
+	"github.com/moledoc/orderly/internal/repository"
+	"github.com/moledoc/orderly/internal/repository/file"
+	"github.com/moledoc/orderly/internal/repository/local"
+	"github.com/moledoc/orderly/internal/repository/sqldb"
+	"github.com/moledoc/orderly/tests/setup"
+	"github.com/stretchr/testify/require"
+
+	_ "modernc.org/sqlite"
+)
+
+func orderRepositories(t *testing.T) map[string]repository.RepositoryOrderAPI {
+	fileRepo, err := file.NewFileRepositoryOrder(t.TempDir(), file.Options{})
+	require.NoError(t, err)
+	db, errs := sqldb.Open(context.Background(), sqldb.DriverSQLite, filepath.Join(t.TempDir(), "orderly.db"))
+	require.NoError(t, errs)
+
+	repos := map[string]repository.RepositoryOrderAPI{
+		"local": local.NewLocalRepositoryOrder(),
+		"file":  fileRepo,
+		"sql":   sqldb.NewSQLRepositoryOrder(db),
+	}
+	t.Cleanup(func() {
+		for _, repo := range repos {
+			repo.Close(context.Background())
+		}
+	})
+	return repos
+}
+
+func userRepositories(t *testing.T) map[string]repository.RepositoryUserAPI {
+	fileRepo, err := file.NewFileRepositoryUser(t.TempDir(), file.Options{})
+	require.NoError(t, err)
+	db, errs := sqldb.Open(context.Background(), sqldb.DriverSQLite, filepath.Join(t.TempDir(), "orderly.db"))
+	require.NoError(t, errs)
+
+	repos := map[string]repository.RepositoryUserAPI{
+		"local": local.NewLocalRepositoryUser(),
+		"file":  fileRepo,
+		"sql":   sqldb.NewSQLRepositoryUser(db),
+	}
+	t.Cleanup(func() {
+		for _, repo := range repos {
+			repo.Close(context.Background())
+		}
+	})
+	return repos
+}
+
+func TestRepositoryOrderWithTx(t *testing.T) {
+	ctx := context.Background()
+
+	for name, repo := range orderRepositories(t) {
+		t.Run(name+".rollback", func(t *testing.T) {
+			existing, err := repo.CreateOrder(ctx, setup.OrderObjWithIDs())
+			require.NoError(t, err)
+
+			var created *order.Order
+			err = repo.WithTx(ctx, func(tx repository.RepositoryOrderAPI) errwrap.Error {
+				changed := existing.Clone()
+				changed.GetTask().SetObjective("changed in rolled back tx")
+				changed.GetMeta().VersionIncr()
+				if _, err := tx.UpdateTask(ctx, changed.GetID(), changed.GetTask(), changed.GetMeta()); err != nil {
+					return err
+				}
+				if _, err := tx.RemoveSitReps(ctx, changed.GetID(), []meta.ID{existing.GetSitReps()[0].GetID()}, changed.GetMeta()); err != nil {
+					return err
+				}
+				var err errwrap.Error
+				created, err = tx.CreateOrder(ctx, setup.OrderObjWithIDs())
+				if err != nil {
+					return err
+				}
+				return errwrap.NewError(http.StatusConflict, "abort")
+			})
+			require.Error(t, err)
+			require.Equal(t, http.StatusConflict, err.GetStatusCode())
+
+			read, err := repo.ReadByID(ctx, existing.GetID())
+			require.NoError(t, err)
+			require.Equal(t, existing.GetTask().GetObjective(), read.GetTask().GetObjective())
+			require.Equal(t, existing.GetMeta().GetVersion(), read.GetMeta().GetVersion())
+			require.Len(t, read.GetSitReps(), len(existing.GetSitReps()))
+
+			versions, err := repo.ReadVersions(ctx, existing.GetID())
+			require.NoError(t, err)
+			require.Len(t, versions, 1)
+
+			_, err = repo.ReadByID(ctx, created.GetID())
+			require.Error(t, err)
+			require.Equal(t, http.StatusNotFound, err.GetStatusCode())
+		})
+
+		t.Run(name+".commit", func(t *testing.T) {
+			var created *order.Order
+			err := repo.WithTx(ctx, func(tx repository.RepositoryOrderAPI) errwrap.Error {
+				var err errwrap.Error
+				created, err = tx.CreateOrder(ctx, setup.OrderObjWithIDs())
+				if err != nil {
+					return err
+				}
+				// NOTE: nested transaction joins the ongoing one
+				return tx.WithTx(ctx, func(tx repository.RepositoryOrderAPI) errwrap.Error {
+					_, err := tx.ReadByID(ctx, created.GetID())
+					return err
+				})
+			})
+			require.NoError(t, err)
+
+			read, err := repo.ReadByID(ctx, created.GetID())
+			require.NoError(t, err)
+			require.Equal(t, created.GetTask().GetObjective(), read.GetTask().GetObjective())
+		})
+	}
+}
+
+func TestRepositoryUserWithTx(t *testing.T) {
+	ctx := context.Background()
+
+	for name, repo := range userRepositories(t) {
+		t.Run(name+".rollback", func(t *testing.T) {
+			existing, err := repo.CreateUser(ctx, setup.UserObjWithID())
+			require.NoError(t, err)
+
+			err = repo.WithTx(ctx, func(tx repository.RepositoryUserAPI) errwrap.Error {
+				changed := existing.Clone()
+				changed.SetName("changed in rolled back tx")
+				changed.GetMeta().VersionIncr()
+				if _, err := tx.UpdateUser(ctx, changed); err != nil {
+					return err
+				}
+				return errwrap.NewError(http.StatusConflict, "abort")
+			})
+			require.Error(t, err)
+
+			read, err := repo.ReadByID(ctx, existing.GetID())
+			require.NoError(t, err)
+			require.Equal(t, existing.GetName(), read.GetName())
+
+			versions, err := repo.ReadVersions(ctx, existing.GetID())
+			require.NoError(t, err)
+			require.Len(t, versions, 1)
+		})
+
+		t.Run(name+".commit", func(t *testing.T) {
+			created := setup.UserObjWithID()
+			err := repo.WithTx(ctx, func(tx repository.RepositoryUserAPI) errwrap.Error {
+				_, err := tx.CreateUser(ctx, created)
+				return err
+			})
+			require.NoError(t, err)
+
+			_, err = repo.ReadByID(ctx, created.GetID())
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestFileRepositoryOrderWithTx(t *testing.T) {
+
+	t.Run("replay", func(t *testing.T) {
+		dir := t.TempDir()
+		repo, err := file.NewFileRepositoryOrder(dir, file.Options{})
+		require.NoError(t, err)
+
+		var committed, rolledBack *order.Order
+		err = repo.WithTx(context.Background(), func(tx repository.RepositoryOrderAPI) errwrap.Error {
+			var err errwrap.Error
+			committed, err = tx.CreateOrder(context.Background(), setup.OrderObjWithIDs())
+			return err
+		})
+		require.NoError(t, err)
+		err = repo.WithTx(context.Background(), func(tx repository.RepositoryOrderAPI) errwrap.Error {
+			var err errwrap.Error
+			rolledBack, err = tx.CreateOrder(context.Background(), setup.OrderObjWithIDs())
+			if err != nil {
+				return err
+			}
+			return errwrap.NewError(http.StatusConflict, "abort")
+		})
+		require.Error(t, err)
+
+		reopened, err := file.NewFileRepositoryOrder(dir, file.Options{})
+		require.NoError(t, err)
+		defer reopened.Close(context.Background())
+
+		_, err = reopened.ReadByID(context.Background(), committed.GetID())
+		require.NoError(t, err)
+		_, err = reopened.ReadByID(context.Background(), rolledBack.GetID())
+		require.Error(t, err)
+	})
+}
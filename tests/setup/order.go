@@ -18,13 +18,16 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+// NOTE: fixtures share the deadline, so delegated tasks aren't due after the orders they're delegated from
+var deadline = time.Now().UTC().Add(7 * 24 * time.Hour)
+
 func TaskObj(extra ...string) *order.Task {
 	ee := strings.Join(append([]string{""}, extra...), ".")
 	return &order.Task{
 		State:       utils.Ptr(order.NotStarted),
 		Accountable: user.Email(fmt.Sprintf("example%v@example.com", ee)),
 		Objective:   "objective description",
-		Deadline:    time.Now().UTC(),
+		Deadline:    deadline,
 	}
 }
 func TaskObjWithID(extra ...string) *order.Task {
@@ -0,0 +1,22 @@
+package setup
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/moledoc/orderly/internal/repository/sqldb"
+	"github.com/stretchr/testify/require"
+
+	_ "modernc.org/sqlite"
+)
+
+// SQLiteDB opens a migrated sqlite database in a temporary directory, which is closed on test cleanup.
+func SQLiteDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sqldb.Open(context.Background(), sqldb.DriverSQLite, filepath.Join(t.TempDir(), "orderly.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/moledoc/orderly/internal/domain/meta"
 	"github.com/moledoc/orderly/internal/domain/request"
 	"github.com/moledoc/orderly/internal/domain/user"
+	"github.com/moledoc/orderly/internal/service/mgmtuser"
 	"github.com/moledoc/orderly/tests/api"
 	"github.com/moledoc/orderly/tests/cleanup"
 	"github.com/stretchr/testify/require"
@@ -21,7 +22,7 @@ func UserObj(extra ...string) *user.User {
 	return &user.User{
 		Name:       fmt.Sprintf("name%v", ee),
 		Email:      user.Email(fmt.Sprintf("example%v@example.com", ee)),
-		Supervisor: user.Email(fmt.Sprintf("example.supervisor%v@example.com", ee)),
+		Supervisor: mgmtuser.RootEmail,
 		Meta: &meta.Meta{
 			Version: 1,
 			Created: time.Now().UTC(),
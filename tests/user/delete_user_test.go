@@ -8,6 +8,7 @@ import (
 	"github.com/moledoc/orderly/internal/domain/meta"
 	"github.com/moledoc/orderly/internal/domain/request"
 	"github.com/moledoc/orderly/internal/domain/user"
+	"github.com/moledoc/orderly/internal/service/mgmtuser"
 	"github.com/moledoc/orderly/tests/setup"
 	"github.com/stretchr/testify/require"
 )
@@ -19,7 +20,7 @@ func (s *UserSuite) TestDeleteUser() {
 		userObj := &user.User{
 			Name:       "name",
 			Email:      user.Email("example@example.com"),
-			Supervisor: user.Email("example.supervisor@example.com"),
+			Supervisor: mgmtuser.RootEmail,
 		}
 
 		user := setup.MustCreateUserWithCleanup(t, context.Background(), s.API, userObj)
@@ -10,6 +10,7 @@ import (
 	"github.com/moledoc/orderly/internal/domain/request"
 	"github.com/moledoc/orderly/internal/domain/response"
 	"github.com/moledoc/orderly/internal/domain/user"
+	"github.com/moledoc/orderly/internal/service/mgmtuser"
 	"github.com/moledoc/orderly/tests/compare"
 	"github.com/moledoc/orderly/tests/setup"
 	"github.com/stretchr/testify/require"
@@ -21,7 +22,7 @@ func (s *UserSuite) TestGetUserByID() {
 	userObj := &user.User{
 		Name:       "name",
 		Email:      user.Email("example@example.com"),
-		Supervisor: user.Email("example.supervisor@example.com"),
+		Supervisor: mgmtuser.RootEmail,
 	}
 
 	user := setup.MustCreateUserWithCleanup(tt, context.Background(), s.API, userObj)
@@ -0,0 +1,91 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/moledoc/orderly/internal/domain/meta"
+	"github.com/moledoc/orderly/internal/domain/request"
+	"github.com/moledoc/orderly/internal/domain/user"
+	"github.com/moledoc/orderly/tests/compare"
+	"github.com/moledoc/orderly/tests/setup"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *UserSuite) TestGetUserVersions() {
+	tt := s.T()
+
+	original := setup.MustCreateUserWithCleanup(tt, context.Background(), s.API, setup.UserObj())
+
+	names := []string{"second name", "third name"}
+	var current *user.User
+	for _, name := range names {
+		resp, err := s.API.PatchUser(tt, context.Background(), &request.PatchUserRequest{
+			User: &user.User{
+				ID:   original.GetID(),
+				Name: name,
+			},
+		})
+		require.NoError(tt, err)
+		current = resp.GetUser()
+	}
+
+	tt.Run("versions", func(t *testing.T) {
+		resp, err := s.API.GetUserVersions(t, context.Background(), &request.GetUserVersionsRequest{
+			ID: original.GetID(),
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.GetVersions(), len(names)+1)
+
+		for i, version := range resp.GetVersions() {
+			require.Equal(t, uint(i+1), version.GetMeta().GetVersion())
+		}
+		compare.RequireEqual(t, original, resp.GetVersions()[0])
+		compare.RequireEqual(t, current, resp.GetVersions()[len(names)])
+	})
+
+	tt.Run("version", func(t *testing.T) {
+		resp, err := s.API.GetUserVersion(t, context.Background(), &request.GetUserVersionRequest{
+			ID:      original.GetID(),
+			Version: 2,
+		})
+		require.NoError(t, err)
+		require.Equal(t, names[0], resp.GetUser().GetName())
+	})
+}
+
+func (s *UserSuite) TestGetUserVersions_Failed() {
+	tt := s.T()
+
+	u := setup.MustCreateUserWithCleanup(tt, context.Background(), s.API, setup.UserObj())
+
+	tt.Run("versions.NotFound", func(t *testing.T) {
+		resp, err := s.API.GetUserVersions(t, context.Background(), &request.GetUserVersionsRequest{
+			ID: meta.NewID(),
+		})
+		require.Error(t, err)
+		require.Equal(t, http.StatusNotFound, err.GetStatusCode())
+		require.Empty(t, resp)
+	})
+
+	tt.Run("version.NotFound", func(t *testing.T) {
+		resp, err := s.API.GetUserVersion(t, context.Background(), &request.GetUserVersionRequest{
+			ID:      u.GetID(),
+			Version: u.GetMeta().GetVersion() + 1,
+		})
+		require.Error(t, err)
+		require.Equal(t, http.StatusNotFound, err.GetStatusCode())
+		require.Empty(t, resp)
+	})
+
+	tt.Run("version.Invalid", func(t *testing.T) {
+		resp, err := s.API.GetUserVersion(t, context.Background(), &request.GetUserVersionRequest{
+			ID:      u.GetID(),
+			Version: 0,
+		})
+		require.Error(t, err)
+		require.Equal(t, http.StatusBadRequest, err.GetStatusCode())
+		require.Empty(t, resp)
+	})
+}
@@ -3,12 +3,15 @@ package tests
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/moledoc/orderly/internal/domain/meta"
 	"github.com/moledoc/orderly/internal/domain/request"
 	"github.com/moledoc/orderly/internal/domain/response"
 	"github.com/moledoc/orderly/internal/domain/user"
+	"github.com/moledoc/orderly/pkg/utils"
 	"github.com/moledoc/orderly/tests/compare"
 	"github.com/moledoc/orderly/tests/setup"
 	"github.com/stretchr/testify/require"
@@ -16,7 +19,8 @@ import (
 
 func (s *UserSuite) TestGetUsers() {
 
-	createUsers := func(t *testing.T, count int) []*user.User {
+	// NOTE: users are scoped by a supervisor of their own, other tests' users are left out
+	createUsers := func(t *testing.T, supervisor *user.User, count int) []*user.User {
 		if count == 0 {
 			return nil
 		}
@@ -25,7 +29,7 @@ func (s *UserSuite) TestGetUsers() {
 			userObj := &user.User{
 				Name:       fmt.Sprintf("name-%d", count),
 				Email:      user.Email(fmt.Sprintf("example.%s.%d.%d@example.com", t.Name(), count, i)),
-				Supervisor: user.Email(fmt.Sprintf("example.supervisor.%d@example.com", count)),
+				Supervisor: supervisor.GetEmail(),
 			}
 
 			user := setup.MustCreateUserWithCleanup(t, context.Background(), s.API, userObj)
@@ -37,9 +41,12 @@ func (s *UserSuite) TestGetUsers() {
 
 	for _, i := range []int{0, 1, 10} {
 		s.T().Run(fmt.Sprintf("count.%v", i), func(t *testing.T) {
-			users := createUsers(t, i)
+			supervisor := setup.MustCreateUserWithCleanup(t, context.Background(), s.API, setup.UserObj(utils.RandAlphanum()))
+			users := createUsers(t, supervisor, i)
 
-			resp, err := s.API.GetUsers(t, context.Background(), &request.GetUsersRequest{})
+			resp, err := s.API.GetUsers(t, context.Background(), &request.GetUsersRequest{
+				Supervisor: supervisor.GetEmail(),
+			})
 			require.NoError(t, err)
 
 			opts := []cmp.Option{
@@ -48,6 +55,7 @@ func (s *UserSuite) TestGetUsers() {
 
 			expected := &response.GetUsersResponse{
 				Users: users,
+				Page:  &meta.Page{Total: len(users)},
 			}
 			compare.RequireEqual(t, expected, resp, opts...)
 		})
@@ -75,9 +83,9 @@ func (s *UserSuite) TestGetUsers_ByEmails() {
 		s.T().Run(fmt.Sprintf("count.%v", i), func(t *testing.T) {
 			users := createUsers(t, i)
 
-			emails := make([]user.Email, len(users))
-			for i, u := range users {
-				emails[i] = u.GetEmail()
+			emails := []user.Email{setup.UserObj(utils.RandAlphanum()).GetEmail()} // NOTE: no such user, keeps the query scoped when no users are created
+			for _, u := range users {
+				emails = append(emails, u.GetEmail())
 			}
 			resp, err := s.API.GetUsers(t, context.Background(), &request.GetUsersRequest{
 				Emails: emails,
@@ -90,6 +98,7 @@ func (s *UserSuite) TestGetUsers_ByEmails() {
 
 			expected := &response.GetUsersResponse{
 				Users: users,
+				Page:  &meta.Page{Total: len(users)},
 			}
 			compare.RequireEqual(t, expected, resp, opts...)
 		})
@@ -131,8 +140,56 @@ func (s *UserSuite) TestGetUsers_BySupervisor() {
 
 			expected := &response.GetUsersResponse{
 				Users: users,
+				Page:  &meta.Page{Total: len(users)},
 			}
 			compare.RequireEqual(t, expected, resp, opts...)
 		})
 	}
 }
+
+func (s *UserSuite) TestGetUsers_Page() {
+	tt := s.T()
+
+	supervisor := setup.MustCreateUserWithCleanup(tt, context.Background(), s.API, setup.UserObj(utils.RandAlphanum()))
+	for range 3 {
+		userObj := setup.UserObj(utils.RandAlphanum())
+		userObj.SetSupervisor(supervisor.GetEmail())
+		setup.MustCreateUserWithCleanup(tt, context.Background(), s.API, userObj)
+	}
+
+	for _, sort := range []meta.Sort{meta.SortCreated, "-" + meta.SortUpdated} {
+		tt.Run(string(sort), func(t *testing.T) {
+			all, err := s.API.GetUsers(t, context.Background(), &request.GetUsersRequest{
+				Supervisor: supervisor.GetEmail(),
+				Sort:       sort,
+			})
+			require.NoError(t, err)
+			require.Len(t, all.GetUsers(), 3)
+
+			var paged []*user.User
+			req := &request.GetUsersRequest{
+				Supervisor: supervisor.GetEmail(),
+				Sort:       sort,
+				Limit:      2,
+			}
+			for range 2 {
+				resp, err := s.API.GetUsers(t, context.Background(), req)
+				require.NoError(t, err)
+				require.Equal(t, 3, resp.GetPage().GetTotal())
+				paged = append(paged, resp.GetUsers()...)
+				req.Cursor = resp.GetPage().GetNextCursor()
+			}
+			require.Empty(t, req.Cursor)
+			require.Equal(t, all.GetUsers(), paged)
+		})
+	}
+
+	tt.Run("invalid.sort", func(t *testing.T) {
+		resp, err := s.API.GetUsers(t, context.Background(), &request.GetUsersRequest{
+			Sort: meta.SortDeadline,
+		})
+		require.Error(t, err)
+		require.Empty(t, resp)
+		require.Equal(t, http.StatusBadRequest, err.GetStatusCode(), err)
+	})
+}
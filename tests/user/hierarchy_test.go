@@ -0,0 +1,167 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/moledoc/orderly/internal/domain/errwrap"
+	"github.com/moledoc/orderly/internal/domain/meta"
+	"github.com/moledoc/orderly/internal/domain/request"
+	"github.com/moledoc/orderly/internal/domain/user"
+	"github.com/moledoc/orderly/internal/service/mgmtuser"
+	"github.com/moledoc/orderly/pkg/utils"
+	"github.com/moledoc/orderly/tests/setup"
+	"github.com/stretchr/testify/require"
+)
+
+// createChain creates count users, each supervising the next one.
+func (s *UserSuite) createChain(t *testing.T, count int) []*user.User {
+	t.Helper()
+
+	supervisor := mgmtuser.RootEmail
+	users := make([]*user.User, count)
+	for i := range count {
+		obj := setup.UserObj(utils.RandAlphanum())
+		obj.SetSupervisor(supervisor)
+		users[i] = setup.MustCreateUserWithCleanup(t, context.Background(), s.API, obj)
+		supervisor = users[i].GetEmail()
+	}
+	return users
+}
+
+func emails(users ...*user.User) []user.Email {
+	var emails []user.Email
+	for _, u := range users {
+		emails = append(emails, u.GetEmail())
+	}
+	return emails
+}
+
+func subordinateEmails(subordinates []*user.Subordinate) map[user.Email]any {
+	tree := make(map[user.Email]any)
+	for _, s := range subordinates {
+		tree[s.GetUser().GetEmail()] = subordinateEmails(s.GetSubordinates())
+	}
+	return tree
+}
+
+func (s *UserSuite) TestGetUserChain() {
+	tt := s.T()
+
+	users := s.createChain(tt, 3)
+
+	resp, err := s.API.GetUserChain(tt, context.Background(), &request.GetUserChainRequest{
+		ID: users[2].GetID(),
+	})
+	require.NoError(tt, err)
+	require.Equal(tt, []user.Email{users[1].GetEmail(), users[0].GetEmail(), mgmtuser.RootEmail}, emails(resp.GetUsers()...))
+
+	tt.Run("NotFound", func(t *testing.T) {
+		_, err := s.API.GetUserChain(t, context.Background(), &request.GetUserChainRequest{
+			ID: meta.NewID(),
+		})
+		require.Error(t, err)
+		require.Equal(t, http.StatusNotFound, err.GetStatusCode(), err)
+	})
+}
+
+func (s *UserSuite) TestGetUserSubordinates() {
+	tt := s.T()
+
+	users := s.createChain(tt, 3)
+	otherObj := setup.UserObj(utils.RandAlphanum())
+	otherObj.SetSupervisor(users[0].GetEmail())
+	other := setup.MustCreateUserWithCleanup(tt, context.Background(), s.API, otherObj)
+
+	for depth, expected := range map[uint]map[user.Email]any{
+		0: {
+			users[1].GetEmail(): map[user.Email]any{
+				users[2].GetEmail(): map[user.Email]any{},
+			},
+			other.GetEmail(): map[user.Email]any{},
+		},
+		1: {
+			users[1].GetEmail(): map[user.Email]any{},
+			other.GetEmail():    map[user.Email]any{},
+		},
+	} {
+		tt.Run(fmt.Sprintf("depth.%v", depth), func(t *testing.T) {
+			resp, err := s.API.GetUserSubordinates(t, context.Background(), &request.GetUserSubordinatesRequest{
+				ID:    users[0].GetID(),
+				Depth: depth,
+			})
+			require.NoError(t, err)
+			require.Equal(t, expected, subordinateEmails(resp.GetSubordinates()))
+		})
+	}
+}
+
+func (s *UserSuite) TestUserHierarchy_Failed() {
+	tt := s.T()
+
+	invalidSupervisor := func(t *testing.T, err errwrap.Error, message string) {
+		t.Helper()
+		require.Error(t, err)
+		require.Equal(t, http.StatusUnprocessableEntity, err.GetStatusCode(), err)
+		require.Equal(t, []errwrap.FieldError{{Field: "user.supervisor", Message: message}}, err.GetFields())
+	}
+
+	tt.Run("supervisor.not.found", func(t *testing.T) {
+		supervisor := user.Email(fmt.Sprintf("unknown.%v@example.com", utils.RandAlphanum()))
+		obj := setup.UserObj(utils.RandAlphanum())
+		obj.SetSupervisor(supervisor)
+
+		_, err := s.API.PostUser(t, context.Background(), &request.PostUserRequest{
+			User: obj,
+		})
+		invalidSupervisor(t, err, fmt.Sprintf("user '%s' not found", supervisor))
+
+		u := setup.MustCreateUserWithCleanup(t, context.Background(), s.API, setup.UserObj(utils.RandAlphanum()))
+		_, err = s.API.PatchUser(t, context.Background(), &request.PatchUserRequest{
+			User: &user.User{
+				ID:         u.GetID(),
+				Supervisor: supervisor,
+			},
+		})
+		invalidSupervisor(t, err, fmt.Sprintf("user '%s' not found", supervisor))
+	})
+
+	tt.Run("supervisor.cycle", func(t *testing.T) {
+		users := s.createChain(t, 3)
+
+		_, err := s.API.PatchUser(t, context.Background(), &request.PatchUserRequest{
+			User: &user.User{
+				ID:         users[0].GetID(),
+				Supervisor: users[2].GetEmail(),
+			},
+		})
+		invalidSupervisor(t, err, fmt.Sprintf("user '%s' reports to user '%s'", users[2].GetEmail(), users[0].GetEmail()))
+	})
+
+	tt.Run("delete.with.subordinates", func(t *testing.T) {
+		users := s.createChain(t, 2)
+
+		_, err := s.API.DeleteUser(t, context.Background(), &request.DeleteUserRequest{
+			ID: users[0].GetID(),
+		})
+		require.Error(t, err)
+		require.Equal(t, http.StatusConflict, err.GetStatusCode(), err)
+	})
+
+	tt.Run("restore.supervisor.deleted", func(t *testing.T) {
+		users := s.createChain(t, 2)
+		for _, u := range []*user.User{users[1], users[0]} {
+			_, err := s.API.DeleteUser(t, context.Background(), &request.DeleteUserRequest{
+				ID: u.GetID(),
+			})
+			require.NoError(t, err)
+		}
+
+		_, err := s.API.RestoreUser(t, context.Background(), &request.RestoreUserRequest{
+			ID: users[1].GetID(),
+		})
+		invalidSupervisor(t, err, fmt.Sprintf("user '%s' not found", users[0].GetEmail()))
+	})
+}
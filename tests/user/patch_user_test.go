@@ -2,6 +2,7 @@ package tests
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"testing"
 	"time"
@@ -11,6 +12,8 @@ import (
 	"github.com/moledoc/orderly/internal/domain/request"
 	"github.com/moledoc/orderly/internal/domain/response"
 	"github.com/moledoc/orderly/internal/domain/user"
+	"github.com/moledoc/orderly/internal/service/mgmtuser"
+	"github.com/moledoc/orderly/pkg/utils"
 	"github.com/moledoc/orderly/tests/compare"
 	"github.com/moledoc/orderly/tests/setup"
 	"github.com/stretchr/testify/require"
@@ -19,10 +22,15 @@ import (
 func (s *UserSuite) TestPatchUser() {
 	tt := s.T()
 
+	supervisor := setup.MustCreateUserWithCleanup(tt, context.Background(), s.API, &user.User{
+		Name:       "supervisor",
+		Email:      user.Email("example.supervisor.updated@example.com"),
+		Supervisor: mgmtuser.RootEmail,
+	})
 	u := setup.MustCreateUserWithCleanup(tt, context.Background(), s.API, &user.User{
 		Name:       "name",
 		Email:      user.Email("example@example.com"),
-		Supervisor: user.Email("example.supervisor@example.com"),
+		Supervisor: mgmtuser.RootEmail,
 	})
 
 	changes := []struct {
@@ -60,7 +68,7 @@ func (s *UserSuite) TestPatchUser() {
 		{
 			Name: "supervisor",
 			f: func() *request.PatchUserRequest {
-				u.SetSupervisor("example.supervisor.updated@example.com")
+				u.SetSupervisor(supervisor.GetEmail())
 				u.GetMeta().VersionIncr()
 
 				return &request.PatchUserRequest{
@@ -118,6 +126,32 @@ func (s *UserSuite) TestPatchUser() {
 	}
 }
 
+func (s *UserSuite) TestPatchUser_EmailSubordinates() {
+	tt := s.T()
+
+	supervisor := setup.MustCreateUserWithCleanup(tt, context.Background(), s.API, setup.UserObj(utils.RandAlphanum()))
+	subordinateObj := setup.UserObj(utils.RandAlphanum())
+	subordinateObj.SetSupervisor(supervisor.GetEmail())
+	subordinate := setup.MustCreateUserWithCleanup(tt, context.Background(), s.API, subordinateObj)
+
+	email := user.Email(fmt.Sprintf("example.updated.%v@example.com", utils.RandAlphanum()))
+	_, err := s.API.PatchUser(tt, context.Background(), &request.PatchUserRequest{
+		User: &user.User{
+			ID:    supervisor.GetID(),
+			Email: email,
+		},
+	})
+	require.NoError(tt, err)
+
+	subordinate.SetSupervisor(email)
+	subordinate.GetMeta().VersionIncr()
+	respGet, err := s.API.GetUserByID(tt, context.Background(), &request.GetUserByIDRequest{
+		ID: subordinate.GetID(),
+	})
+	require.NoError(tt, err)
+	compare.RequireEqual(tt, &response.GetUserByIDResponse{User: subordinate}, respGet, compare.IgnorePaths("User.Meta.Updated"))
+}
+
 func (s *UserSuite) TestPatchUser_Failed() {
 	tt := s.T()
 
@@ -135,4 +169,34 @@ func (s *UserSuite) TestPatchUser_Failed() {
 		require.Equal(t, http.StatusNotFound, err.GetStatusCode(), err)
 	})
 
+	tt.Run("version.stale", func(t *testing.T) {
+		u := setup.MustCreateUserWithCleanup(t, context.Background(), s.API, setup.UserObj("version.stale"))
+
+		respPatch, err := s.API.PatchUser(t, context.Background(), &request.PatchUserRequest{
+			User:            &user.User{ID: u.GetID(), Name: "first name"},
+			ExpectedVersion: u.GetMeta().GetVersion(),
+		})
+		require.NoError(t, err)
+		require.Equal(t, u.GetMeta().GetVersion()+1, respPatch.GetUser().GetMeta().GetVersion())
+
+		resp, err := s.API.PatchUser(t, context.Background(), &request.PatchUserRequest{
+			User:            &user.User{ID: u.GetID(), Name: "second name"},
+			ExpectedVersion: u.GetMeta().GetVersion(),
+		})
+		require.Error(t, err)
+		require.Empty(t, resp)
+		require.Equal(t, http.StatusConflict, err.GetStatusCode(), err)
+	})
+
+	tt.Run("email.taken", func(t *testing.T) {
+		u := setup.MustCreateUserWithCleanup(t, context.Background(), s.API, setup.UserObj(utils.RandAlphanum()))
+		other := setup.MustCreateUserWithCleanup(t, context.Background(), s.API, setup.UserObj(utils.RandAlphanum()))
+
+		resp, err := s.API.PatchUser(t, context.Background(), &request.PatchUserRequest{
+			User: &user.User{ID: u.GetID(), Email: other.GetEmail()},
+		})
+		require.Error(t, err)
+		require.Empty(t, resp)
+		require.Equal(t, http.StatusConflict, err.GetStatusCode(), err)
+	})
 }
@@ -0,0 +1,57 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/moledoc/orderly/internal/domain/request"
+	"github.com/moledoc/orderly/tests/setup"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *UserSuite) TestPurgeUsers() {
+	tt := s.T()
+
+	u := setup.MustCreateUserWithCleanup(tt, context.Background(), s.API, setup.UserObj("purge"))
+	_, err := s.API.DeleteUser(tt, context.Background(), &request.DeleteUserRequest{
+		ID: u.GetID(),
+	})
+	require.NoError(tt, err)
+
+	tt.Run("retention.not_passed", func(t *testing.T) {
+		resp, err := s.API.PurgeUsers(t, context.Background(), &request.PurgeUsersRequest{
+			Before: time.Now().UTC().Add(-time.Hour),
+		})
+		require.NoError(t, err)
+		require.NotContains(t, resp.GetIDs(), u.GetID())
+		require.NotNil(t, s.findDeletedUser(t, u.GetID()), "user not in trash")
+	})
+
+	tt.Run("retention.passed", func(t *testing.T) {
+		resp, err := s.API.PurgeUsers(t, context.Background(), &request.PurgeUsersRequest{
+			Before: time.Now().UTC().Add(time.Second),
+		})
+		require.NoError(t, err)
+		require.Contains(t, resp.GetIDs(), u.GetID())
+		require.Nil(t, s.findDeletedUser(t, u.GetID()), "purged user still in trash")
+
+		_, err = s.API.GetUserVersions(t, context.Background(), &request.GetUserVersionsRequest{
+			ID: u.GetID(),
+		})
+		require.Error(t, err)
+		require.Equal(t, http.StatusNotFound, err.GetStatusCode(), err)
+	})
+}
+
+func (s *UserSuite) TestPurgeUsers_Failed() {
+	tt := s.T()
+
+	tt.Run("before.missing", func(t *testing.T) {
+		resp, err := s.API.PurgeUsers(t, context.Background(), &request.PurgeUsersRequest{})
+		require.Error(t, err)
+		require.Empty(t, resp)
+		require.Equal(t, http.StatusBadRequest, err.GetStatusCode(), err)
+	})
+}
@@ -0,0 +1,93 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/moledoc/orderly/internal/domain/meta"
+	"github.com/moledoc/orderly/internal/domain/request"
+	"github.com/moledoc/orderly/internal/domain/response"
+	"github.com/moledoc/orderly/internal/domain/user"
+	"github.com/moledoc/orderly/tests/compare"
+	"github.com/moledoc/orderly/tests/setup"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *UserSuite) findDeletedUser(t *testing.T, id meta.ID) *user.User {
+	t.Helper()
+
+	resp, err := s.API.GetDeletedUsers(t, context.Background(), &request.GetDeletedUsersRequest{})
+	require.NoError(t, err)
+
+	idx := slices.IndexFunc(resp.GetUsers(), func(u *user.User) bool {
+		return u.GetID() == id
+	})
+	if idx < 0 {
+		return nil
+	}
+	return resp.GetUsers()[idx]
+}
+
+func (s *UserSuite) TestRestoreUser() {
+	tt := s.T()
+
+	u := setup.MustCreateUserWithCleanup(tt, context.Background(), s.API, setup.UserObj("restore"))
+
+	_, err := s.API.DeleteUser(tt, context.Background(), &request.DeleteUserRequest{
+		ID: u.GetID(),
+	})
+	require.NoError(tt, err)
+
+	trashed := s.findDeletedUser(tt, u.GetID())
+	require.NotNil(tt, trashed, "user not in trash")
+	require.True(tt, trashed.GetMeta().IsDeleted())
+
+	respRestore, err := s.API.RestoreUser(tt, context.Background(), &request.RestoreUserRequest{
+		ID: u.GetID(),
+	})
+	require.NoError(tt, err)
+
+	u.GetMeta().VersionIncr() // NOTE: delete
+	u.GetMeta().VersionIncr() // NOTE: restore
+	compare.RequireEqual(tt, &response.RestoreUserResponse{User: u}, respRestore, compare.IgnorePaths("User.Meta.Updated"))
+
+	respGet, err := s.API.GetUserByID(tt, context.Background(), &request.GetUserByIDRequest{
+		ID: u.GetID(),
+	})
+	require.NoError(tt, err)
+	compare.RequireEqual(tt, &response.GetUserByIDResponse{User: u}, respGet, compare.IgnorePaths("User.Meta.Updated"))
+
+	require.Nil(tt, s.findDeletedUser(tt, u.GetID()), "restored user still in trash")
+}
+
+func (s *UserSuite) TestRestoreUser_Failed() {
+	tt := s.T()
+
+	tt.Run("NotFound", func(t *testing.T) {
+		resp, err := s.API.RestoreUser(t, context.Background(), &request.RestoreUserRequest{
+			ID: meta.NewID(),
+		})
+		require.Error(t, err)
+		require.Empty(t, resp)
+		require.Equal(t, http.StatusNotFound, err.GetStatusCode(), err)
+	})
+
+	tt.Run("email.taken", func(t *testing.T) {
+		deleted := setup.MustCreateUserWithCleanup(t, context.Background(), s.API, setup.UserObj("restore.taken"))
+		_, err := s.API.DeleteUser(t, context.Background(), &request.DeleteUserRequest{
+			ID: deleted.GetID(),
+		})
+		require.NoError(t, err)
+
+		setup.MustCreateUserWithCleanup(t, context.Background(), s.API, setup.UserObj("restore.taken"))
+
+		resp, err := s.API.RestoreUser(t, context.Background(), &request.RestoreUserRequest{
+			ID: deleted.GetID(),
+		})
+		require.Error(t, err)
+		require.Empty(t, resp)
+		require.Equal(t, http.StatusConflict, err.GetStatusCode(), err)
+	})
+}
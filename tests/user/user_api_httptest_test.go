@@ -11,7 +11,7 @@ import (
 )
 
 func TestUserHTTPTestSuite(t *testing.T) {
-	mux := router.RouteUser(mgmtuser.NewServiceMgmtUser(local.NewLocalRepositoryUser()))
+	mux := router.RouteUser(mgmtuser.NewServiceMgmtUser(local.NewLocalRepositoryUser(), nil))
 
 	t.Run("UserAPIHTTPTest", func(t *testing.T) {
 		suite.Run(t, &UserSuite{
@@ -21,7 +21,7 @@ func TestUserHTTPTestSuite(t *testing.T) {
 }
 
 func TestUserHTTPTestPerformanceSuite(t *testing.T) {
-	mux := router.RouteUser(mgmtuser.NewServiceMgmtUser(local.NewLocalRepositoryUser()))
+	mux := router.RouteUser(mgmtuser.NewServiceMgmtUser(local.NewLocalRepositoryUser(), nil))
 	t.Run("UserAPIHTTPTestPerformance", func(t *testing.T) {
 		suite.Run(t, &UserPerformanceSuite{
 			API: apiuserhttptest.NewUserAPIHTTPTest(mux),
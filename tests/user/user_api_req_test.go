@@ -17,7 +17,7 @@ func TestUserReqSuite(t *testing.T) {
 	var wg sync.WaitGroup
 	wg.Add(1)
 	go func() {
-		router.RouteUser(mgmtuser.NewServiceMgmtUser(local.NewLocalRepositoryUser()))
+		router.RouteUser(mgmtuser.NewServiceMgmtUser(local.NewLocalRepositoryUser(), nil))
 		wg.Done()
 		http.ListenAndServe(":8080", nil)
 	}()
@@ -35,7 +35,7 @@ func TestUserReqPerformanceSuite(t *testing.T) {
 	var wg sync.WaitGroup
 	wg.Add(1)
 	go func() {
-		router.RouteUser(mgmtuser.NewServiceMgmtUser(local.NewLocalRepositoryUser()))
+		router.RouteUser(mgmtuser.NewServiceMgmtUser(local.NewLocalRepositoryUser(), nil))
 		wg.Done()
 		http.ListenAndServe(":8080", nil)
 	}()
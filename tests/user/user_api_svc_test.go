@@ -3,7 +3,9 @@ package tests
 import (
 	"testing"
 
+	"github.com/moledoc/orderly/internal/repository/sqldb"
 	apiusersvc "github.com/moledoc/orderly/tests/api/user/svc"
+	"github.com/moledoc/orderly/tests/setup"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -15,6 +17,14 @@ func TestUserSvcSuite(t *testing.T) {
 	})
 }
 
+func TestUserSvcSQLSuite(t *testing.T) {
+	t.Run("UserAPISvcSQL", func(t *testing.T) {
+		suite.Run(t, &UserSuite{
+			API: apiusersvc.NewUserAPISvcWithRepository(sqldb.NewSQLRepositoryUser(setup.SQLiteDB(t))),
+		})
+	})
+}
+
 func TestUserSvcPerformanceSuite(t *testing.T) {
 	t.Run("UserAPISvcPerformance", func(t *testing.T) {
 		suite.Run(t, &UserPerformanceSuite{
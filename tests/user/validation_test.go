@@ -3,6 +3,7 @@ package tests
 import (
 	"context"
 	"net/http"
+	"slices"
 	"testing"
 	"time"
 
@@ -104,10 +105,11 @@ func (s *UserSuite) TestValidation_GetUserByIDRequest() {
 func (s *UserSuite) TestValidation_GetUsersRequest() {
 	tt := s.T()
 
-	tt.Run("empty.request", func(t *testing.T) {
+	tt.Run("empty.request", func(t *testing.T) { // NOTE: unfiltered request lists every user, the root user always among them
 		resp, err := s.API.GetUsers(t, context.Background(), &request.GetUsersRequest{})
 		require.NoError(t, err)
-		require.Empty(t, resp.GetUsers())
+		require.True(t, slices.ContainsFunc(resp.GetUsers(), func(u *user.User) bool { return u.GetEmail() == mgmtuser.RootEmail }))
+		require.Equal(t, len(resp.GetUsers()), resp.GetPage().GetTotal())
 	})
 	tt.Run("invalid.email", func(t *testing.T) {
 		resp, err := s.API.GetUsers(t, context.Background(), &request.GetUsersRequest{